@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// ShadowedAlternatives returns the indexes of the pattern alternatives
+// that are never selected by the matcher.
+//
+// Only the patterns that are a single alternation (possibly wrapped
+// into a group) are checked, nil is returned for all other patterns.
+//
+// With the leftmost-first (Perl) semantics, an alternative is never
+// selected if every string it matches starts with a string matched by
+// the preceding alternatives, like `ab` in `a|ab`.
+// With the leftmost-longest (POSIX) semantics, selected by longest,
+// it's never selected if every string it matches is also matched by
+// the preceding alternatives, like `a+` in `a*|a+`: they win the
+// equal length matches.
+//
+// The alternatives can't contain anchors. See the package
+// documentation for the other syntax restrictions.
+func ShadowedAlternatives(re *syntax.Regexp, longest bool) ([]int, error) {
+	e := re.Expr
+	for e.Op == syntax.OpGroup || e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		e = e.Args[0]
+	}
+	if e.Op != syntax.OpAlt {
+		return nil, nil
+	}
+	if anchor, ok := findAnchor(e); ok {
+		return nil, fmt.Errorf("%s: anchors are not supported", anchor.Value)
+	}
+
+	var indexes []int
+	for i := 1; i < len(e.Args); i++ {
+		preceding := syntax.Expr{Op: syntax.OpAlt, Args: e.Args[:i]}
+		na, err := newNFA(preceding, false, !longest)
+		if err != nil {
+			return nil, err
+		}
+		nb, err := newNFA(e.Args[i], false, false)
+		if err != nil {
+			return nil, err
+		}
+		found := func(aAccepts, bAccepts bool) bool {
+			return bAccepts && !aAccepts
+		}
+		dead := func(as, bs []int) bool {
+			return len(bs) == 0 || (!longest && na.hasMatch(as))
+		}
+		_, selected, err := productSearch(na, nb, found, dead)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+func findAnchor(e syntax.Expr) (syntax.Expr, bool) {
+	switch {
+	case e.Op == syntax.OpCaret, e.Op == syntax.OpDollar:
+		return e, true
+	case e.Op == syntax.OpEscapeChar && (e.Value == `\A` || e.Value == `\z` || e.Value == `\Z`):
+		return e, true
+	}
+	for _, a := range e.Args {
+		if anchor, ok := findAnchor(a); ok {
+			return anchor, true
+		}
+	}
+	return syntax.Expr{}, false
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestShadowedAlternatives(t *testing.T) {
+	tests := []struct {
+		pattern string
+		first   string
+		longest string
+	}{
+		{`a|ab`, `[1]`, `[]`},
+		{`ab|a`, `[]`, `[]`},
+		{`a|a`, `[1]`, `[1]`},
+		{`a*|a+`, `[1]`, `[1]`},
+		{`a+|a*`, `[]`, `[]`},
+		{`(foo|foobar|bar)`, `[1]`, `[]`},
+		{`[a-z]+|\d+|foo`, `[2]`, `[2]`},
+		{`x|y|[xy]z`, `[2]`, `[]`},
+		{`x|y|[xy]`, `[2]`, `[2]`},
+		{`foo`, `[]`, `[]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		for _, longest := range []bool{false, true} {
+			want := test.first
+			if longest {
+				want = test.longest
+			}
+			indexes, err := ShadowedAlternatives(re, longest)
+			if err != nil {
+				t.Errorf("ShadowedAlternatives(%q, %v): %v", test.pattern, longest, err)
+				continue
+			}
+			if have := fmt.Sprint(indexes); have != want {
+				t.Errorf("ShadowedAlternatives(%q, %v):\nhave: %s\nwant: %s", test.pattern, longest, have, want)
+			}
+		}
+	}
+}
+
+func TestShadowedAlternativesErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`^a|ab`, `^: anchors are not supported`},
+		{`a|b\z`, `\z: anchors are not supported`},
+		{`a|\1`, `\1: unsupported by the automaton analysis`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = ShadowedAlternatives(re, false)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ShadowedAlternatives(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// Package analysis implements various checks and measurements
+// on top of the regexp AST produced by the syntax package.
+//
+// Language-level checks (like Subsumes) convert patterns to finite
+// automata, so they only support the regular subset of the syntax:
+// backreferences, lookarounds, atomic groups, possessive quantifiers,
+// flags and word boundary assertions are rejected with an error.
+// The automata follow the Go regexp semantics: `.` doesn't match
+// a newline and `$` matches only at the end of the input.
+// Too big automata are rejected with ErrTooComplex.
+package analysis
@@ -0,0 +1,193 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// MatchMode is the way a target applies a pattern to the input.
+type MatchMode byte
+
+const (
+	// MatchSearch finds the pattern anywhere in the input,
+	// like the Go regexp MatchString.
+	MatchSearch MatchMode = iota
+
+	// MatchFull matches the whole input, as if the pattern
+	// was wrapped in `^(?:...)$`, like the Java matches().
+	MatchFull
+
+	// MatchEither is used by the targets which implementations
+	// disagree: some of them search, the others match the whole input.
+	MatchEither
+)
+
+// AnchorTarget is a pattern destination with its matching mode.
+type AnchorTarget struct {
+	// Name is used in the explanations, like "Java matches()".
+	Name string
+
+	Mode MatchMode
+}
+
+var (
+	TargetJavaMatches = &AnchorTarget{Name: "Java matches()", Mode: MatchFull}
+	TargetHTMLPattern = &AnchorTarget{Name: "HTML pattern attribute", Mode: MatchFull}
+	TargetXMLSchema   = &AnchorTarget{Name: "XML Schema", Mode: MatchFull}
+	TargetEnvoy       = &AnchorTarget{Name: "envoy safe_regex", Mode: MatchFull}
+
+	// TargetJSONSchema follows the JSON Schema validators: the
+	// specification requires a search, but some validators
+	// match the whole string.
+	TargetJSONSchema = &AnchorTarget{Name: "JSON Schema", Mode: MatchEither}
+)
+
+// AnchoringKind is a kind of the anchoring problem.
+type AnchoringKind byte
+
+const (
+	// AnchorRedundant is an edge anchor that the target already implies.
+	AnchorRedundant AnchoringKind = iota + 1
+
+	// AnchorPartial is an unanchored edge of a pattern which other edge
+	// is anchored: the author relied on a partial match there,
+	// but the target matches the whole input.
+	AnchorPartial
+
+	// AnchorAmbiguous is an unanchored edge that is
+	// matched differently by the target implementations.
+	AnchorAmbiguous
+)
+
+func (k AnchoringKind) String() string {
+	switch k {
+	case AnchorRedundant:
+		return "redundant anchor"
+	case AnchorPartial:
+		return "partial match"
+	case AnchorAmbiguous:
+		return "ambiguous anchoring"
+	default:
+		return "?"
+	}
+}
+
+// AnchoringIssue is an anchoring problem with its explanation.
+type AnchoringIssue struct {
+	Kind AnchoringKind
+
+	// Expr is the anchor or the unanchored edge expression.
+	Expr syntax.Expr
+
+	Message string
+}
+
+// CheckAnchoring returns the re anchoring problems for the target,
+// in the pattern text order.
+//
+// Every top-level alternation branch is checked separately.
+// The `^`, `$`, `\A`, `\z` and `\Z` anchors are recognized,
+// the `^` and `$` with the `m` flag are not anchors.
+// An edge that is a `.*` or `.+` wildcard is neither
+// anchored nor unanchored.
+func CheckAnchoring(re *syntax.Regexp, target *AnchorTarget) []AnchoringIssue {
+	if target.Mode == MatchSearch {
+		return nil
+	}
+
+	textAnchors := make(map[syntax.Position]bool)
+	for _, a := range ResolveAnchors(re, syntax.DialectPCRE, 0) {
+		switch a.Meaning {
+		case CaretTextBegin, DollarTextEnd, DollarFinalNewline:
+			textAnchors[a.Expr.Pos] = true
+		}
+	}
+	isAnchor := func(e syntax.Expr, begin bool) bool {
+		switch e.Op {
+		case syntax.OpCaret:
+			return begin && textAnchors[e.Pos]
+		case syntax.OpDollar:
+			return !begin && textAnchors[e.Pos]
+		case syntax.OpEscapeChar:
+			if begin {
+				return e.Value == `\A`
+			}
+			return e.Value == `\z` || e.Value == `\Z`
+		}
+		return false
+	}
+
+	var issues []AnchoringIssue
+	report := func(kind AnchoringKind, e syntax.Expr, message string) {
+		issues = append(issues, AnchoringIssue{Kind: kind, Expr: e, Message: message})
+	}
+	for _, branch := range anchoringBranches(re.Expr) {
+		first := branch[0]
+		last := branch[len(branch)-1]
+		begin := isAnchor(first, true)
+		end := isAnchor(last, false)
+
+		switch target.Mode {
+		case MatchFull:
+			if begin {
+				report(AnchorRedundant, first, first.Value+" is redundant: "+target.Name+" matches the whole string")
+			}
+			if end && !begin && !isWildcard(first) && len(branch) > 1 {
+				report(AnchorPartial, first, "the pattern is anchored only at the end, but "+target.Name+
+					" also anchors its start; add .* to match any prefix")
+			}
+			if begin && !end && !isWildcard(last) && len(branch) > 1 {
+				report(AnchorPartial, last, "the pattern is anchored only at the start, but "+target.Name+
+					" also anchors its end; add .* to match any suffix")
+			}
+			if end {
+				report(AnchorRedundant, last, last.Value+" is redundant: "+target.Name+" matches the whole string")
+			}
+
+		case MatchEither:
+			if len(branch) == 1 && !begin && !end && !isWildcard(first) {
+				report(AnchorAmbiguous, first, "the pattern is not anchored: some "+target.Name+
+					" implementations match the whole string, others search; add ^ and $")
+				continue
+			}
+			if !begin && !isWildcard(first) {
+				report(AnchorAmbiguous, first, "the pattern start is not anchored: some "+target.Name+
+					" implementations match the whole string, others search; add ^ or .*")
+			}
+			if !end && !isWildcard(last) {
+				report(AnchorAmbiguous, last, "the pattern end is not anchored: some "+target.Name+
+					" implementations match the whole string, others search; add $ or .*")
+			}
+		}
+	}
+	return issues
+}
+
+// anchoringBranches returns the elements of the top-level
+// alternation branches, the wrapping groups are unwrapped.
+func anchoringBranches(e syntax.Expr) [][]syntax.Expr {
+	for {
+		switch e.Op {
+		case syntax.OpGroup, syntax.OpCapture, syntax.OpNamedCapture:
+			e = e.Args[0]
+			continue
+		case syntax.OpAlt:
+			var branches [][]syntax.Expr
+			for _, a := range e.Args {
+				branches = append(branches, anchoringBranches(a)...)
+			}
+			return branches
+		case syntax.OpConcat:
+			return [][]syntax.Expr{e.Args}
+		default:
+			return [][]syntax.Expr{{e}}
+		}
+	}
+}
+
+// isWildcard reports whether e is a `.*` or `.+`, possibly non-greedy.
+func isWildcard(e syntax.Expr) bool {
+	if e.Op == syntax.OpNonGreedy {
+		e = e.Args[0]
+	}
+	return (e.Op == syntax.OpStar || e.Op == syntax.OpPlus) && e.Args[0].Op == syntax.OpDot
+}
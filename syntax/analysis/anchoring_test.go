@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCheckAnchoring(t *testing.T) {
+	tests := []struct {
+		target  *AnchorTarget
+		pattern string
+		want    []string
+	}{
+		{TargetJavaMatches, `[a-z]+`, nil},
+		{TargetJavaMatches, `^[a-z]+$`, []string{
+			`0 redundant anchor: ^ is redundant: Java matches() matches the whole string`,
+			`7 redundant anchor: $ is redundant: Java matches() matches the whole string`,
+		}},
+		{TargetHTMLPattern, `^\d{3}`, []string{
+			`0 redundant anchor: ^ is redundant: HTML pattern attribute matches the whole string`,
+			`1 partial match: the pattern is anchored only at the start, but HTML pattern attribute also anchors its end; add .* to match any suffix`,
+		}},
+		{TargetXMLSchema, `\.pdf\z`, []string{
+			`0 partial match: the pattern is anchored only at the end, but XML Schema also anchors its start; add .* to match any prefix`,
+			`5 redundant anchor: \z is redundant: XML Schema matches the whole string`,
+		}},
+		{TargetJavaMatches, `^a.*|(b)$`, []string{
+			`0 redundant anchor: ^ is redundant: Java matches() matches the whole string`,
+			`5 partial match: the pattern is anchored only at the end, but Java matches() also anchors its start; add .* to match any prefix`,
+			`8 redundant anchor: $ is redundant: Java matches() matches the whole string`,
+		}},
+		{TargetJavaMatches, `(?m)^a$`, nil},
+
+		{TargetJSONSchema, `^a$`, nil},
+		{TargetJSONSchema, `.*a.*`, nil},
+		{TargetJSONSchema, `abc`, []string{
+			`0 ambiguous anchoring: the pattern is not anchored: some JSON Schema implementations match the whole string, others search; add ^ and $`,
+		}},
+		{TargetJSONSchema, `^a+`, []string{
+			`1 ambiguous anchoring: the pattern end is not anchored: some JSON Schema implementations match the whole string, others search; add $ or .*`,
+		}},
+
+		{&AnchorTarget{Name: "Go", Mode: MatchSearch}, `^a`, nil},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range CheckAnchoring(re, test.target) {
+			have = append(have, fmt.Sprintf("%d %s: %s", issue.Expr.Pos.Begin, issue.Kind, issue.Message))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("CheckAnchoring(%q, %s):\nhave: %q\nwant: %q", test.pattern, test.target.Name, have, test.want)
+		}
+	}
+}
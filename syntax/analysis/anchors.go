@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Meaning is a resolved meaning of a flags-dependent expression.
+type Meaning byte
+
+const (
+	// DotNoNewline is `.` that doesn't match a line terminator.
+	DotNoNewline Meaning = iota + 1
+
+	// DotAny is `.` that matches any char (the `s` flag is set).
+	DotAny
+
+	// CaretTextBegin is `^` that matches only at the input start.
+	CaretTextBegin
+
+	// CaretLineBegin is `^` that also matches after a line terminator (the `m` flag is set).
+	CaretLineBegin
+
+	// DollarTextEnd is `$` that matches only at the input end.
+	// It's the RE2 `$` without the `m` flag.
+	DollarTextEnd
+
+	// DollarFinalNewline is `$` that matches at the input end and
+	// before the final line terminator. It's the PCRE `$` without the `m` flag.
+	DollarFinalNewline
+
+	// DollarLineEnd is `$` that also matches before a line terminator (the `m` flag is set).
+	DollarLineEnd
+)
+
+func (m Meaning) String() string {
+	switch m {
+	case DotNoNewline:
+		return "dot without newline"
+	case DotAny:
+		return "dot with newline"
+	case CaretTextBegin:
+		return "text begin"
+	case CaretLineBegin:
+		return "line begin"
+	case DollarTextEnd:
+		return "text end"
+	case DollarFinalNewline:
+		return "text end or final newline"
+	case DollarLineEnd:
+		return "line end"
+	default:
+		return "?"
+	}
+}
+
+// Anchor is a `.`, `^` or `$` expression with its resolved meaning.
+type Anchor struct {
+	Expr    syntax.Expr
+	Meaning Meaning
+
+	// Newline defines the line terminators the Meaning refers to.
+	Newline syntax.Newline
+}
+
+// ResolveAnchors returns the meanings of all `.`, `^` and `$`
+// expressions of re, in the pattern text order.
+//
+// The meaning depends on the active `s` and `m` flags and the dialect.
+// flags are the flags enabled outside of the pattern, like the PHP
+// pattern modifiers. See syntax.WalkFlags for the flags scoping rules.
+func ResolveAnchors(re *syntax.Regexp, d syntax.Dialect, flags syntax.Flags) []Anchor {
+	return ResolveAnchorsNewline(re, d, flags, syntax.NewlineDefault)
+}
+
+// ResolveAnchorsNewline is like ResolveAnchors, but the line terminators
+// are defined by the nl convention instead of the `\n`.
+// Use Newline.Terminators to get the terminators of the anchor.
+func ResolveAnchorsNewline(re *syntax.Regexp, d syntax.Dialect, flags syntax.Flags, nl syntax.Newline) []Anchor {
+	var anchors []Anchor
+	syntax.WalkFlags(re.Expr, d, flags, func(e syntax.Expr, flags syntax.Flags) {
+		var m Meaning
+		switch e.Op {
+		case syntax.OpDot:
+			m = DotNoNewline
+			if flags&syntax.FlagDotAll != 0 {
+				m = DotAny
+			}
+		case syntax.OpCaret:
+			m = CaretTextBegin
+			if flags&syntax.FlagMultiline != 0 {
+				m = CaretLineBegin
+			}
+		case syntax.OpDollar:
+			switch {
+			case flags&syntax.FlagMultiline != 0:
+				m = DollarLineEnd
+			case d == syntax.DialectPCRE:
+				m = DollarFinalNewline
+			default:
+				m = DollarTextEnd
+			}
+		default:
+			return
+		}
+		anchors = append(anchors, Anchor{Expr: e, Meaning: m, Newline: nl})
+	})
+	return anchors
+}
@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestResolveAnchors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dialect syntax.Dialect
+		flags   syntax.Flags
+		want    string
+	}{
+		{`^.$`, syntax.DialectRE2, 0, `text begin, dot without newline, text end`},
+		{`^.$`, syntax.DialectPCRE, 0, `text begin, dot without newline, text end or final newline`},
+		{`^.$`, syntax.DialectRE2, syntax.FlagMultiline | syntax.FlagDotAll, `line begin, dot with newline, line end`},
+		{`.(?s).(?-s).`, syntax.DialectRE2, 0, `dot without newline, dot with newline, dot without newline`},
+		{`(?m:^)^`, syntax.DialectRE2, 0, `line begin, text begin`},
+		{`((?m)^)^`, syntax.DialectRE2, 0, `line begin, text begin`},
+		{`a(?m)|^`, syntax.DialectRE2, 0, `line begin`},
+		{`(?m-m:^)$`, syntax.DialectRE2, 0, `text begin, text end`},
+		{`(?x).`, syntax.DialectRE2, 0, `dot without newline`},
+		{`(?s-x).`, syntax.DialectRE2, 0, `dot without newline`},
+		{`(?s-x).`, syntax.DialectPCRE, 0, `dot with newline`},
+		{`[.^$]`, syntax.DialectRE2, 0, ``},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var parts []string
+		for _, a := range ResolveAnchors(re, test.dialect, test.flags) {
+			parts = append(parts, a.Meaning.String())
+		}
+		have := strings.Join(parts, ", ")
+		if have != test.want {
+			t.Errorf("ResolveAnchors(%q, %s):\nhave: %s\nwant: %s", test.pattern, test.dialect, have, test.want)
+		}
+	}
+}
+
+func TestResolveAnchorsNewline(t *testing.T) {
+	re, err := syntax.NewParser(nil).Parse(`(?m)^.$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parts []string
+	for _, a := range ResolveAnchorsNewline(re, syntax.DialectPCRE, 0, syntax.NewlineCRLF) {
+		parts = append(parts, fmt.Sprintf("%s %q", a.Meaning, a.Newline.Terminators()))
+	}
+	have := strings.Join(parts, ", ")
+	want := `line begin ["\r\n"], dot without newline ["\r\n"], line end ["\r\n"]`
+	if have != want {
+		t.Errorf("ResolveAnchorsNewline:\nhave: %s\nwant: %s", have, want)
+	}
+}
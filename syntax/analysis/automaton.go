@@ -0,0 +1,280 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// ErrTooComplex is returned when a pattern automaton
+// exceeds the analysis size limits.
+var ErrTooComplex = errors.New("pattern automaton is too complex")
+
+// maxAutomatonStates limits the product DFA size.
+// The NFA size is limited by thompson.MaxStates.
+const maxAutomatonStates = 10000
+
+// nfa is a Thompson automaton for a pattern.
+// The search automata accept all strings that contain a pattern match:
+// the start state consumes any prefix and the match state accepts
+// all continuations.
+type nfa struct {
+	states [][]thompson.Edge
+	start  int
+	match  int
+}
+
+// newSearchNFA builds an automaton for e.
+//
+// Only the patterns that describe a regular language are supported:
+// backreferences, lookarounds, atomic groups, possessive quantifiers,
+// flags and word boundaries result in an error.
+// `.` doesn't match a newline, `$` matches only at the end of the input.
+func newSearchNFA(e syntax.Expr) (*nfa, error) {
+	return newNFA(e, true, true)
+}
+
+// newNFA builds an automaton for e, see newSearchNFA.
+//
+// If anyPrefix is false, the matches must start at the beginning
+// of the input. If anySuffix is false, they must end at its end.
+func newNFA(e syntax.Expr, anyPrefix, anySuffix bool) (*nfa, error) {
+	var b thompson.Builder
+	start := b.NewState()
+	match := b.NewState()
+	if anyPrefix {
+		b.AddEdge(start, thompson.Edge{Kind: thompson.EdgeRunes, Runes: charset.Any, To: start})
+	}
+	if anySuffix {
+		b.AddEdge(match, thompson.Edge{Kind: thompson.EdgeRunes, Runes: charset.Any, To: match})
+	}
+	in, out, err := b.Build(e)
+	if err != nil {
+		if err, ok := err.(*thompson.UnsupportedError); ok {
+			return nil, fmt.Errorf("%s: unsupported by the automaton analysis", err.Expr)
+		}
+		return nil, ErrTooComplex
+	}
+	b.AddEdge(start, thompson.Edge{To: in})
+	b.AddEdge(out, thompson.Edge{To: match})
+	return &nfa{states: b.States, start: start, match: match}, nil
+}
+
+// closure returns all states reachable from the states without
+// consuming any input. The result is sorted.
+func (a *nfa) closure(states []int, atBegin, atEnd bool) []int {
+	seen := make(map[int]bool, len(states))
+	stack := append([]int(nil), states...)
+	var result []int
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+		for _, edge := range a.states[s] {
+			switch {
+			case edge.Kind == thompson.EdgeEpsilon,
+				edge.Kind == thompson.EdgeBegin && atBegin,
+				edge.Kind == thompson.EdgeEnd && atEnd:
+				stack = append(stack, edge.To)
+			}
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// step returns the states after consuming ch.
+func (a *nfa) step(states []int, ch rune) []int {
+	var next []int
+	for _, s := range states {
+		for _, edge := range a.states[s] {
+			if edge.Kind == thompson.EdgeRunes && edge.Runes.Contains(ch) {
+				next = append(next, edge.To)
+			}
+		}
+	}
+	return a.closure(next, false, false)
+}
+
+// accepts reports whether the input that leads to states is accepted.
+func (a *nfa) accepts(states []int, atBegin bool) bool {
+	for _, s := range a.closure(states, atBegin, true) {
+		if s == a.match {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *nfa) hasMatch(states []int) bool {
+	i := sort.SearchInts(states, a.match)
+	return i < len(states) && states[i] == a.match
+}
+
+// dfaSize returns the number of the non-empty a DFA states that are
+// reachable from the start state.
+//
+// The exploration stops at limit states, or when the number of the
+// examined NFA transitions exceeds budget. limit is returned then.
+func dfaSize(a *nfa, limit, budget int) int {
+	states := []productState{{a: a.closure([]int{a.start}, true, false)}}
+	seen := map[string]bool{productStateKey(states[0]): true}
+	for i := 0; i < len(states); i++ {
+		st := states[i]
+		for _, s := range st.a {
+			for _, edge := range a.states[s] {
+				budget -= len(edge.Runes) + 1
+			}
+		}
+		if budget < 0 {
+			return limit
+		}
+		alphabet := splitAlphabet(a, st.a, a, nil)
+		budget -= len(alphabet) * len(st.a)
+		if budget < 0 {
+			return limit
+		}
+		for _, ch := range alphabet {
+			next := productState{a: a.step(st.a, ch)}
+			if len(next.a) == 0 {
+				continue
+			}
+			key := productStateKey(next)
+			if seen[key] {
+				continue
+			}
+			if len(states) >= limit {
+				return limit
+			}
+			seen[key] = true
+			states = append(states, next)
+		}
+	}
+	return len(states)
+}
+
+// productState is a state of the simultaneous a and b DFA simulation.
+type productState struct {
+	a []int
+	b []int
+
+	parent int  // Index of the previous state, -1 for the initial state
+	ch     rune // A char that leads from the parent to this state
+}
+
+// productSearch explores the a and b DFAs product in breadth-first order
+// and returns the shortest input for which found returns true.
+// States for which dead returns true are not explored.
+func productSearch(a, b *nfa, found func(aAccepts, bAccepts bool) bool, dead func(as, bs []int) bool) (string, bool, error) {
+	states := []productState{{
+		a:      a.closure([]int{a.start}, true, false),
+		b:      b.closure([]int{b.start}, true, false),
+		parent: -1,
+	}}
+	// The initial state accepts at the input beginning, so it can't
+	// be merged with the same NFA states reached later.
+	seen := map[string]bool{"^" + productStateKey(states[0]): true}
+	for i := 0; i < len(states); i++ {
+		st := states[i]
+		atBegin := i == 0
+		if found(a.accepts(st.a, atBegin), b.accepts(st.b, atBegin)) {
+			return productInput(states, i), true, nil
+		}
+		for _, ch := range splitAlphabet(a, st.a, b, st.b) {
+			next := productState{
+				a:      a.step(st.a, ch),
+				b:      b.step(st.b, ch),
+				parent: i,
+				ch:     ch,
+			}
+			if dead(next.a, next.b) {
+				continue
+			}
+			key := productStateKey(next)
+			if seen[key] {
+				continue
+			}
+			if len(states) >= maxAutomatonStates {
+				return "", false, ErrTooComplex
+			}
+			seen[key] = true
+			states = append(states, next)
+		}
+	}
+	return "", false, nil
+}
+
+// splitAlphabet returns one representative rune for every rune interval
+// that can't be distinguished by the transitions of the given states.
+func splitAlphabet(a *nfa, as []int, b *nfa, bs []int) []rune {
+	points := []rune{0, unicode.MaxRune + 1}
+	addPoints := func(x *nfa, states []int) {
+		for _, s := range states {
+			for _, edge := range x.states[s] {
+				for _, r := range edge.Runes {
+					points = append(points, r.Lo, r.Hi+1)
+				}
+			}
+		}
+	}
+	addPoints(a, as)
+	addPoints(b, bs)
+	sort.Slice(points, func(i, j int) bool {
+		return points[i] < points[j]
+	})
+
+	var result []rune
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]-1
+		if lo <= hi {
+			result = append(result, pickRune(lo, hi))
+		}
+	}
+	return result
+}
+
+// pickRune returns a readable rune from the [lo, hi] range, if possible.
+func pickRune(lo, hi rune) rune {
+	for _, ch := range "a0A_ " {
+		if ch >= lo && ch <= hi {
+			return ch
+		}
+	}
+	if lo <= '!' && hi >= '!' {
+		return '!'
+	}
+	return lo
+}
+
+func productStateKey(st productState) string {
+	var b strings.Builder
+	for _, s := range st.a {
+		fmt.Fprintf(&b, "%d,", s)
+	}
+	b.WriteByte('|')
+	for _, s := range st.b {
+		fmt.Fprintf(&b, "%d,", s)
+	}
+	return b.String()
+}
+
+func productInput(states []productState, i int) string {
+	var runes []rune
+	for ; states[i].parent != -1; i = states[i].parent {
+		runes = append(runes, states[i].ch)
+	}
+	for l, r := 0, len(runes)-1; l < r; l, r = l+1, r-1 {
+		runes[l], runes[r] = runes[r], runes[l]
+	}
+	return string(runes)
+}
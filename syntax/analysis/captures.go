@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// DemotableGroups returns the indexes of the capturing groups that
+// can be converted into the non-capturing ones without changing
+// the set of the matched strings.
+//
+// It's useful for the matchers that don't report the submatches,
+// as the capture bookkeeping is not free even for them.
+//
+// A group can't be demoted if it's a target of a backreference or
+// a recursion, either by its name or by its number. Demoting a group
+// renumbers the groups that follow it, so the groups that are located
+// before a numerical reference target are kept too.
+// Patterns with relative references that can't be resolved are
+// reported as having no demotable groups.
+func DemotableGroups(re *syntax.Regexp) []int {
+	refs := collectGroupRefs(re)
+	if refs.unresolved {
+		return nil
+	}
+
+	var indexes []int
+	index := 0
+	walkCaptures(re.Expr, func(e syntax.Expr) {
+		index++
+		if index <= refs.maxIndex {
+			return
+		}
+		if e.Op == syntax.OpNamedCapture && refs.names[e.Args[1].Value] {
+			return
+		}
+		indexes = append(indexes, index)
+	})
+	return indexes
+}
+
+type groupRefs struct {
+	names      map[string]bool
+	maxIndex   int
+	unresolved bool
+}
+
+func collectGroupRefs(re *syntax.Regexp) groupRefs {
+	refs := groupRefs{names: make(map[string]bool)}
+	groups := 0
+	addRef := func(ref string) {
+		switch {
+		case ref == "" || ref == "R" || ref == "0":
+			return
+		case isDigits(ref):
+			refs.addIndex(atoi(ref))
+		case ref[0] == '-' && isDigits(ref[1:]):
+			n := groups + 1 - atoi(ref[1:])
+			if n <= 0 {
+				refs.unresolved = true
+			}
+			refs.addIndex(n)
+		case ref[0] == '+' && isDigits(ref[1:]):
+			refs.addIndex(groups + atoi(ref[1:]))
+		default:
+			refs.names[ref] = true
+		}
+	}
+
+	var walk func(e syntax.Expr, insideClass bool)
+	walk = func(e syntax.Expr, insideClass bool) {
+		switch e.Op {
+		case syntax.OpCapture, syntax.OpNamedCapture:
+			groups++
+		case syntax.OpCharClass, syntax.OpNegCharClass:
+			insideClass = true
+		case syntax.OpEscapeOctal:
+			digits := e.Args[0].Value
+			if !insideClass && digits[0] != '0' && len(digits) < 3 {
+				addRef(digits)
+			}
+		case syntax.OpEscapeChar:
+			if !insideClass && (e.Value == `\k` || e.Value == `\g`) {
+				addRef(escapeRef(re.Pattern[e.End():]))
+			}
+		case syntax.OpFlagOnlyGroup:
+			flags := e.Args[0].Value
+			switch {
+			case strings.HasPrefix(flags, "P="), strings.HasPrefix(flags, "P>"):
+				addRef(flags[len("P="):])
+			case strings.HasPrefix(flags, "&"):
+				addRef(flags[len("&"):])
+			case isDigits(strings.TrimLeft(flags, "+-")):
+				addRef(flags)
+			}
+		}
+		for _, a := range e.Args {
+			walk(a, insideClass)
+		}
+	}
+	walk(re.Expr, false)
+	return refs
+}
+
+func (refs *groupRefs) addIndex(n int) {
+	if n > refs.maxIndex {
+		refs.maxIndex = n
+	}
+}
+
+// escapeRef returns the reference that follows the `\k` or `\g` escape,
+// like `name` for `<name>` or `-1` for `{-1}`.
+func escapeRef(s string) string {
+	if s == "" {
+		return ""
+	}
+	if closer, ok := refClosers[s[0]]; ok {
+		end := strings.IndexByte(s[1:], closer)
+		if end == -1 {
+			return ""
+		}
+		return s[1 : end+1]
+	}
+	end := 0
+	if s[0] == '-' || s[0] == '+' {
+		end++
+	}
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if _, err := strconv.Atoi(strings.TrimPrefix(s[:end], "+")); err != nil {
+		return ""
+	}
+	return s[:end]
+}
+
+var refClosers = map[byte]byte{'<': '>', '\'': '\'', '{': '}'}
+
+func walkCaptures(e syntax.Expr, visit func(syntax.Expr)) {
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		visit(e)
+	}
+	for _, a := range e.Args {
+		walkCaptures(a, visit)
+	}
+}
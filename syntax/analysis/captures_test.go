@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestDemotableGroups(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `[]`},
+		{`(a)(b)`, `[1 2]`},
+		{`(?P<x>a)(b)`, `[1 2]`},
+		{`(a)(b)\1`, `[2]`},
+		{`(a)(b)\2`, `[]`},
+		{`(a)(b)[\1]`, `[1 2]`},
+		{`(?<x>a)(b)\k<x>`, `[2]`},
+		{`(?'x'a)(b)\k'x'`, `[2]`},
+		{`(a)(?<x>b)(?P=x)`, `[1]`},
+		{`(a)(?<x>b)(?&x)`, `[1]`},
+		{`(a)(b)\g{-1}`, `[]`},
+		{`(a)(b)\g-2(c)`, `[2 3]`},
+		{`(?+1)(a)(b)`, `[2]`},
+		{`(a)(?R)?`, `[1]`},
+		{`(?-1)(a)`, `[]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := fmt.Sprint(DemotableGroups(re))
+		if have != test.want {
+			t.Errorf("DemotableGroups(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
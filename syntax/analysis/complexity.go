@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// Score is a pattern complexity measurement.
+//
+// Scores of different patterns can be compared by their Total value:
+// the greater it is, the more expensive a pattern is to compile and execute.
+// It can be used to enforce "pattern budget" limits on user-supplied regexps.
+type Score struct {
+	// Nodes is a number of AST nodes.
+	// Artificial OpString nodes are not counted.
+	Nodes int
+
+	// QuantifierDepth is a max nesting level of quantifiers.
+	// `a+` has depth 1, `(a+)*` has depth 2.
+	QuantifierDepth int
+
+	// AltFanout is a max number of branches inside a single alternation.
+	AltFanout int
+
+	// Positions is a number of pattern positions (chars, classes and
+	// other single-char matching elements) with bounded repetitions expanded,
+	// so `a{10}b` has 11 positions. It approximates the NFA size.
+	Positions int
+
+	// States is an estimated DFA size.
+	//
+	// It's a number of the search DFA states that the subset construction
+	// produces. The exploration stops at 1000 states or after a fixed
+	// amount of work, 1000 is reported then. The exponential DFA blowup,
+	// like in `[ab]*a[ab]{10}`, is reflected here, but not in Positions.
+	//
+	// The patterns with at least 1000 Positions are not explored.
+	// For them, as well as for the patterns that can't be represented
+	// by an automaton (like the ones with backreferences, lookarounds
+	// or flags), States is equal to Positions.
+	States int
+
+	// Total combines all metrics above, except for Positions,
+	// into a single number.
+	//
+	// Quantifier nesting is weighted quadratically as it's the
+	// main source of the matching slowdowns.
+	Total int
+}
+
+// Complexity computes the re complexity score.
+func Complexity(re *syntax.Regexp) Score {
+	var c complexityCounter
+	positions := c.walk(re.Expr, 0)
+	score := Score{
+		Nodes:           c.nodes,
+		QuantifierDepth: c.maxDepth,
+		AltFanout:       c.maxFanout,
+		Positions:       positions,
+		States:          estimateDFASize(re.Expr, positions),
+	}
+	total := addCount(score.Nodes, score.States)
+	total = addCount(total, mulCount(10, mulCount(score.QuantifierDepth, score.QuantifierDepth)))
+	score.Total = addCount(total, score.AltFanout)
+	return score
+}
+
+// maxComplexityStates limits the Score.States estimation.
+const maxComplexityStates = 1000
+
+// maxComplexityWork limits the number of NFA transitions that are
+// examined during the Score.States estimation. Patterns with large
+// char classes, like `\pL`, can't be explored up to maxComplexityStates
+// in a reasonable time.
+const maxComplexityWork = 200000
+
+func estimateDFASize(e syntax.Expr, positions int) int {
+	if positions >= maxComplexityStates {
+		// The NFA alone is big enough, there is no need
+		// to build it, as the DFA is rarely smaller.
+		return positions
+	}
+	a, err := newNFA(e, true, false)
+	if err != nil {
+		return positions
+	}
+	return dfaSize(a, maxComplexityStates, maxComplexityWork)
+}
+
+type complexityCounter struct {
+	nodes     int
+	maxDepth  int
+	maxFanout int
+}
+
+// walk returns the number of positions for e.
+func (c *complexityCounter) walk(e syntax.Expr, depth int) int {
+	if e.Op == syntax.OpString {
+		return 0
+	}
+	c.nodes++
+
+	switch e.Op {
+	case syntax.OpAlt:
+		if len(e.Args) > c.maxFanout {
+			c.maxFanout = len(e.Args)
+		}
+		return c.walkArgs(e.Args, depth)
+
+	case syntax.OpConcat, syntax.OpLiteral:
+		return c.walkArgs(e.Args, depth)
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion:
+		depth++
+		if depth > c.maxDepth {
+			c.maxDepth = depth
+		}
+		return c.walk(e.Args[0], depth)
+
+	case syntax.OpRepeat:
+		depth++
+		if depth > c.maxDepth {
+			c.maxDepth = depth
+		}
+		states := c.walk(e.Args[0], depth)
+		min, max := thompson.RepeatBounds(e.Args[1].Value, maxCount)
+		if max == -1 {
+			// x{min,} is x{min}x*, so we need at least 1 copy.
+			max = min
+			if max == 0 {
+				max = 1
+			}
+		}
+		return mulCount(states, max)
+
+	case syntax.OpNonGreedy, syntax.OpPossessive,
+		syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun,
+		syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return c.walk(e.Args[0], depth)
+
+	case syntax.OpQuote:
+		return utf8.RuneCountInString(e.QuotedLiteral())
+
+	case syntax.OpCaret, syntax.OpDollar, syntax.OpComment, syntax.OpFlagOnlyGroup, syntax.OpEmptyMatch:
+		return 0
+
+	default:
+		c.walkArgs(e.Args, depth)
+		return 1
+	}
+}
+
+func (c *complexityCounter) walkArgs(args []syntax.Expr, depth int) int {
+	states := 0
+	for _, a := range args {
+		states = addCount(states, c.walk(a, depth))
+	}
+	return states
+}
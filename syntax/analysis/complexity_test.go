@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    Score
+	}{
+		{``, Score{Nodes: 1, States: 1, Total: 2}},
+		{`abc`, Score{Nodes: 4, Positions: 3, States: 4, Total: 8}},
+		{`a+`, Score{Nodes: 2, QuantifierDepth: 1, Positions: 1, States: 2, Total: 14}},
+		{`(a+)*`, Score{Nodes: 4, QuantifierDepth: 2, Positions: 1, States: 2, Total: 46}},
+		{`a{10}b`, Score{Nodes: 4, QuantifierDepth: 1, Positions: 11, States: 12, Total: 26}},
+		{`a{2,}`, Score{Nodes: 2, QuantifierDepth: 1, Positions: 2, States: 4, Total: 16}},
+		{`a{0,}`, Score{Nodes: 2, QuantifierDepth: 1, Positions: 1, States: 2, Total: 14}},
+		{`x|y|z`, Score{Nodes: 4, AltFanout: 3, Positions: 3, States: 4, Total: 11}},
+		{`^[a-z\d]$`, Score{Nodes: 8, Positions: 1, States: 3, Total: 11}},
+		{`\Qa.b\E`, Score{Nodes: 1, Positions: 3, States: 4, Total: 5}},
+		{`(?i)(?#c)`, Score{Nodes: 3, Total: 3}},
+		{`[ab]*a[ab]{5}`, Score{Nodes: 10, QuantifierDepth: 1, Positions: 7, States: 65, Total: 85}},
+		{`[ab]*a[ab]{12}`, Score{Nodes: 10, QuantifierDepth: 1, Positions: 14, States: 1000, Total: 1020}},
+		{`(a)b\1`, Score{Nodes: 5, Positions: 3, States: 3, Total: 8}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := Complexity(re)
+		if have != test.want {
+			t.Errorf("Complexity(%q):\nhave: %+v\nwant: %+v", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestComplexityOrder(t *testing.T) {
+	patterns := []string{
+		`abc`,
+		`[a-z]+@[a-z]+`,
+		`(a|b|c)+(d|e)*`,
+		`((a+)+)+`,
+	}
+	p := syntax.NewParser(nil)
+	prev := -1
+	for _, pat := range patterns {
+		re, err := p.Parse(pat)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pat, err)
+		}
+		score := Complexity(re).Total
+		if score <= prev {
+			t.Errorf("%q score %d is not greater than the previous one (%d)", pat, score, prev)
+		}
+		prev = score
+	}
+}
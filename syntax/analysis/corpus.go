@@ -0,0 +1,266 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Feature is a syntax feature that can be unsupported by some dialects.
+type Feature byte
+
+// All features that follow FeatureBackreference are PCRE-only.
+const (
+	FeatureCapture Feature = iota
+	FeatureNamedCapture
+	FeatureNonGreedy
+	FeatureFlags
+	FeatureQuote
+	FeatureUnicodeClass
+	FeaturePosixClass
+	FeatureBackreference
+	FeatureLookahead
+	FeatureLookbehind
+	FeatureAtomicGroup
+	FeaturePossessive
+	FeatureComment
+	FeatureRecursion
+	FeatureScriptRun
+
+	numFeatures
+)
+
+var featureNames = [numFeatures]string{
+	FeatureCapture:       "capture",
+	FeatureNamedCapture:  "named capture",
+	FeatureNonGreedy:     "non-greedy quantifier",
+	FeatureFlags:         "flags",
+	FeatureQuote:         "quote",
+	FeatureUnicodeClass:  "unicode class",
+	FeaturePosixClass:    "posix class",
+	FeatureBackreference: "backreference",
+	FeatureLookahead:     "lookahead",
+	FeatureLookbehind:    "lookbehind",
+	FeatureAtomicGroup:   "atomic group",
+	FeaturePossessive:    "possessive quantifier",
+	FeatureComment:       "comment",
+	FeatureRecursion:     "recursion",
+	FeatureScriptRun:     "script run",
+}
+
+func (f Feature) String() string {
+	if f < numFeatures {
+		return featureNames[f]
+	}
+	return "?"
+}
+
+// Dialect returns the most restrictive dialect that supports f.
+func (f Feature) Dialect() syntax.Dialect {
+	if f >= FeatureBackreference {
+		return syntax.DialectPCRE
+	}
+	return syntax.DialectRE2
+}
+
+// CorpusStats collects aggregate statistics over many patterns.
+//
+// It's intended for the codebase audits: for example, before
+// migrating from PCRE to RE2 one could check how many patterns
+// use the PCRE-only features.
+type CorpusStats struct {
+	// Patterns is a number of added patterns.
+	Patterns int
+
+	// TotalDepth is a sum of all patterns AST depths.
+	TotalDepth int
+
+	// MaxDepth is the max pattern AST depth.
+	// Artificial OpString nodes are not counted.
+	MaxDepth int
+
+	// Ops counts the expressions of every kind.
+	Ops map[syntax.Operation]int
+
+	// Features counts the patterns that use a feature.
+	Features map[Feature]int
+
+	// CharClasses counts the char classes by their textual value,
+	// like `[0-9]`, `\d` or `\pL`.
+	CharClasses map[string]int
+}
+
+// NewCorpusStats returns an empty stats collector.
+func NewCorpusStats() *CorpusStats {
+	return &CorpusStats{
+		Ops:         make(map[syntax.Operation]int),
+		Features:    make(map[Feature]int),
+		CharClasses: make(map[string]int),
+	}
+}
+
+// Add includes re into the stats.
+func (s *CorpusStats) Add(re *syntax.Regexp) {
+	var features [numFeatures]bool
+	depth := s.walk(re.Expr, &features)
+	for f, used := range features {
+		if used {
+			s.Features[Feature(f)]++
+		}
+	}
+	s.Patterns++
+	s.TotalDepth += depth
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+}
+
+// AverageDepth returns the average pattern AST depth.
+func (s *CorpusStats) AverageDepth() float64 {
+	if s.Patterns == 0 {
+		return 0
+	}
+	return float64(s.TotalDepth) / float64(s.Patterns)
+}
+
+// ClassCount is a char class usage counter.
+type ClassCount struct {
+	Class string
+	Count int
+}
+
+// TopCharClasses returns up to n most common char classes.
+// Classes with equal counts are sorted alphabetically.
+func (s *CorpusStats) TopCharClasses(n int) []ClassCount {
+	list := make([]ClassCount, 0, len(s.CharClasses))
+	for class, count := range s.CharClasses {
+		list = append(list, ClassCount{Class: class, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Class < list[j].Class
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// Features returns the syntax features that are used by re,
+// sorted in the Feature constants order.
+func Features(re *syntax.Regexp) []Feature {
+	var used [numFeatures]bool
+	walkFeatures(re.Expr, &used)
+	var list []Feature
+	for f, ok := range used {
+		if ok {
+			list = append(list, Feature(f))
+		}
+	}
+	return list
+}
+
+func walkFeatures(e syntax.Expr, used *[numFeatures]bool) {
+	if f, ok := ExprFeature(e); ok {
+		used[f] = true
+	}
+	for _, a := range e.Args {
+		walkFeatures(a, used)
+	}
+}
+
+// walk returns the e depth.
+func (s *CorpusStats) walk(e syntax.Expr, features *[numFeatures]bool) int {
+	s.Ops[e.Op]++
+	if f, ok := ExprFeature(e); ok {
+		features[f] = true
+	}
+
+	switch e.Op {
+	case syntax.OpCharClass, syntax.OpNegCharClass, syntax.OpEscapeUni:
+		s.CharClasses[e.Value]++
+	case syntax.OpEscapeChar:
+		if strings.ContainsAny(e.Value, "dDwWsS") && len(e.Value) == 2 {
+			s.CharClasses[e.Value]++
+		}
+	}
+
+	depth := 0
+	for _, a := range e.Args {
+		if a.Op == syntax.OpString {
+			continue
+		}
+		if d := s.walk(a, features); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// ExprFeature returns the feature that is used by the e node itself,
+// the e.Args are not inspected.
+func ExprFeature(e syntax.Expr) (Feature, bool) {
+	switch e.Op {
+	case syntax.OpCapture:
+		return FeatureCapture, true
+	case syntax.OpNamedCapture:
+		return FeatureNamedCapture, true
+	case syntax.OpNonGreedy:
+		return FeatureNonGreedy, true
+	case syntax.OpGroupWithFlags:
+		return FeatureFlags, true
+	case syntax.OpQuote:
+		return FeatureQuote, true
+	case syntax.OpEscapeUni:
+		return FeatureUnicodeClass, true
+	case syntax.OpPosixClass:
+		return FeaturePosixClass, true
+	case syntax.OpPositiveLookahead, syntax.OpNegativeLookahead:
+		return FeatureLookahead, true
+	case syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return FeatureLookbehind, true
+	case syntax.OpAtomicGroup:
+		return FeatureAtomicGroup, true
+	case syntax.OpPossessive:
+		return FeaturePossessive, true
+	case syntax.OpComment:
+		return FeatureComment, true
+	case syntax.OpScriptRun:
+		return FeatureScriptRun, true
+	case syntax.OpEscapeOctal:
+		digits := e.Args[0].Value
+		if digits[0] != '0' && len(digits) < 3 {
+			return FeatureBackreference, true
+		}
+	case syntax.OpEscapeChar:
+		if e.Value == `\k` || e.Value == `\g` {
+			return FeatureBackreference, true
+		}
+	case syntax.OpFlagOnlyGroup:
+		flags := e.Args[0].Value
+		switch {
+		case strings.HasPrefix(flags, "P="):
+			return FeatureBackreference, true
+		case flags == "R" || strings.HasPrefix(flags, "P>") || strings.HasPrefix(flags, "&") || isDigits(strings.TrimLeft(flags, "+-")):
+			return FeatureRecursion, true
+		default:
+			return FeatureFlags, true
+		}
+	}
+	return 0, false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCorpusStats(t *testing.T) {
+	patterns := []string{
+		`^[0-9]+$`,
+		`(?P<year>\d{4})-(\d\d)`,
+		`(a)\1`,
+		`(?<=x)(?i)[0-9]`,
+		`(?R)?|(?&x)|(?-1)`,
+		`\pL+?`,
+	}
+
+	p := syntax.NewParser(nil)
+	stats := NewCorpusStats()
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		stats.Add(re)
+	}
+
+	if stats.Patterns != len(patterns) {
+		t.Errorf("patterns:\nhave: %d\nwant: %d", stats.Patterns, len(patterns))
+	}
+	if stats.MaxDepth != 5 {
+		t.Errorf("max depth:\nhave: %d\nwant: 5", stats.MaxDepth)
+	}
+	if have := stats.AverageDepth(); have != 22.0/6 {
+		t.Errorf("average depth:\nhave: %v\nwant: %v", have, 22.0/6)
+	}
+	if have := stats.Ops[syntax.OpCapture]; have != 2 {
+		t.Errorf("captures:\nhave: %d\nwant: 2", have)
+	}
+
+	wantFeatures := map[Feature]int{
+		FeatureCapture:       2,
+		FeatureNamedCapture:  1,
+		FeatureNonGreedy:     1,
+		FeatureFlags:         1,
+		FeatureUnicodeClass:  1,
+		FeatureBackreference: 1,
+		FeatureLookbehind:    1,
+		FeatureRecursion:     1,
+	}
+	if !reflect.DeepEqual(stats.Features, wantFeatures) {
+		t.Errorf("features:\nhave: %v\nwant: %v", stats.Features, wantFeatures)
+	}
+
+	wantTop := []ClassCount{{`\d`, 3}, {`[0-9]`, 2}}
+	if have := stats.TopCharClasses(2); !reflect.DeepEqual(have, wantTop) {
+		t.Errorf("top classes:\nhave: %v\nwant: %v", have, wantTop)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []Feature
+	}{
+		{`abc`, nil},
+		{`(?<=x)(a)\1`, []Feature{FeatureCapture, FeatureBackreference, FeatureLookbehind}},
+		{`(?P<x>\pL+?)`, []Feature{FeatureNamedCapture, FeatureNonGreedy, FeatureUnicodeClass}},
+		{`(*sr:\p{scx=Greek}+)`, []Feature{FeatureUnicodeClass, FeatureScriptRun}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if have := Features(re); !reflect.DeepEqual(have, test.want) {
+			t.Errorf("Features(%q):\nhave: %v\nwant: %v", test.pattern, have, test.want)
+		}
+	}
+}
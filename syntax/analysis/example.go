@@ -0,0 +1,263 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// exampleString returns a string that is likely to be matched by e.
+//
+// Optional parts (like `x?` and `x*`) are included once, so the
+// result is usually non-empty. Char classes are approximated.
+func exampleString(e syntax.Expr) string {
+	var b strings.Builder
+	writeExample(&b, e)
+	return b.String()
+}
+
+func writeExample(b *strings.Builder, e syntax.Expr) {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral:
+		for _, a := range e.Args {
+			writeExample(b, a)
+		}
+	case syntax.OpAlt:
+		writeExample(b, e.Args[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion,
+		syntax.OpNonGreedy, syntax.OpPossessive,
+		syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun:
+		writeExample(b, e.Args[0])
+	case syntax.OpRepeat:
+		min, max := thompson.RepeatBounds(e.Args[1].Value, maxCount)
+		if max == 0 {
+			return
+		}
+		if min == 0 {
+			min = 1
+		}
+		if min > 1000 {
+			min = 1000
+		}
+		s := exampleString(e.Args[0])
+		b.WriteString(strings.Repeat(s, min))
+	case syntax.OpQuote:
+		b.WriteString(e.QuotedLiteral())
+	case syntax.OpDot:
+		b.WriteByte('a')
+	case syntax.OpCharClass:
+		if ch, ok := charClassExample(e); ok {
+			b.WriteRune(ch)
+		}
+	case syntax.OpNegCharClass:
+		for _, ch := range "a0_ !~" {
+			if !charClassContains(e, ch) {
+				b.WriteRune(ch)
+				return
+			}
+		}
+	default:
+		if ch, ok := charValue(e); ok {
+			b.WriteRune(ch)
+		} else if ch, ok := charClassElemExample(e); ok {
+			b.WriteRune(ch)
+		}
+	}
+}
+
+func charClassExample(e syntax.Expr) (rune, bool) {
+	for _, a := range e.Args {
+		if ch, ok := charClassElemExample(a); ok {
+			return ch, true
+		}
+	}
+	return 0, false
+}
+
+func charClassElemExample(e syntax.Expr) (rune, bool) {
+	switch e.Op {
+	case syntax.OpCharRange:
+		return charValue(e.Args[0])
+	case syntax.OpPosixClass:
+		for _, ch := range "a0 !" {
+			if posixClassContains(e.Value, ch) {
+				return ch, true
+			}
+		}
+		return 0, false
+	case syntax.OpEscapeUni:
+		for _, ch := range "a0 !αж" {
+			if uniClassContains(e, ch) {
+				return ch, true
+			}
+		}
+		return 0, false
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\d`:
+			return '0', true
+		case `\D`, `\S`, `\W`:
+			return '!', true
+		case `\w`:
+			return 'a', true
+		case `\s`:
+			return ' ', true
+		}
+	}
+	return charValue(e)
+}
+
+// charClassContains reports whether ch is matched by one of the class elements.
+// It doesn't take the class negation into account.
+func charClassContains(e syntax.Expr, ch rune) bool {
+	for _, a := range e.Args {
+		switch a.Op {
+		case syntax.OpCharRange:
+			lo, ok1 := charValue(a.Args[0])
+			hi, ok2 := charValue(a.Args[1])
+			if ok1 && ok2 && ch >= lo && ch <= hi {
+				return true
+			}
+		case syntax.OpPosixClass:
+			if posixClassContains(a.Value, ch) {
+				return true
+			}
+		case syntax.OpEscapeUni:
+			if uniClassContains(a, ch) {
+				return true
+			}
+		case syntax.OpEscapeChar:
+			if perlClassContains(a.Value, ch) {
+				return true
+			}
+		}
+		if v, ok := charValue(a); ok && v == ch {
+			return true
+		}
+	}
+	return false
+}
+
+func perlClassContains(class string, ch rune) bool {
+	switch class {
+	case `\d`:
+		return ch >= '0' && ch <= '9'
+	case `\D`:
+		return !(ch >= '0' && ch <= '9')
+	case `\w`:
+		return ch == '_' || (ch < utf8.RuneSelf && isAlphanumeric(byte(ch)))
+	case `\W`:
+		return !(ch == '_' || (ch < utf8.RuneSelf && isAlphanumeric(byte(ch))))
+	case `\s`:
+		return ch == ' ' || (ch >= '\t' && ch <= '\r')
+	case `\S`:
+		return !(ch == ' ' || (ch >= '\t' && ch <= '\r'))
+	}
+	return false
+}
+
+func posixClassContains(class string, ch rune) bool {
+	name := strings.TrimSuffix(strings.TrimPrefix(class, "[:"), ":]")
+	negated := strings.HasPrefix(name, "^")
+	name = strings.TrimPrefix(name, "^")
+	var result bool
+	switch name {
+	case "alpha":
+		result = (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+	case "digit":
+		result = ch >= '0' && ch <= '9'
+	case "alnum":
+		result = ch < utf8.RuneSelf && isAlphanumeric(byte(ch))
+	case "word":
+		result = ch == '_' || (ch < utf8.RuneSelf && isAlphanumeric(byte(ch)))
+	case "space":
+		result = ch == ' ' || (ch >= '\t' && ch <= '\r')
+	case "blank":
+		result = ch == ' ' || ch == '\t'
+	case "punct":
+		result = ch < utf8.RuneSelf && (unicode.IsPunct(ch) || unicode.IsSymbol(ch))
+	case "lower":
+		result = ch >= 'a' && ch <= 'z'
+	case "upper":
+		result = ch >= 'A' && ch <= 'Z'
+	case "xdigit":
+		result = ch < utf8.RuneSelf && isHexDigit(byte(ch))
+	case "print":
+		result = ch >= ' ' && ch <= '~'
+	case "graph":
+		result = ch > ' ' && ch <= '~'
+	case "cntrl":
+		result = ch < ' ' || ch == 0x7f
+	case "ascii":
+		result = ch < utf8.RuneSelf
+	}
+	return result != negated
+}
+
+func uniClassContains(e syntax.Expr, ch rune) bool {
+	name := e.Args[0].Value
+	negated := strings.HasPrefix(e.Value, `\P`)
+	if strings.HasPrefix(name, "^") {
+		negated = !negated
+		name = name[1:]
+	}
+	table := unicode.Categories[name]
+	if table == nil {
+		table = unicode.Scripts[name]
+	}
+	if table == nil {
+		return false
+	}
+	return unicode.Is(table, ch) != negated
+}
+
+// charValue returns a rune that is represented by the e.
+// If e does not match exactly one rune, ok is false.
+func charValue(e syntax.Expr) (ch rune, ok bool) {
+	switch e.Op {
+	case syntax.OpChar:
+		ch, _ = utf8.DecodeRuneInString(e.Value)
+		return ch, true
+	case syntax.OpEscapeMeta:
+		ch, _ = utf8.DecodeRuneInString(e.Args[0].Value)
+		return ch, true
+	case syntax.OpEscapeOctal:
+		n, err := strconv.ParseUint(e.Args[0].Value, 8, 32)
+		return rune(n), err == nil
+	case syntax.OpEscapeHex:
+		if e.Args[0].Value == "" {
+			return 0, true
+		}
+		n, err := strconv.ParseUint(e.Args[0].Value, 16, 32)
+		return rune(n), err == nil
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\a`:
+			return '\a', true
+		case `\f`:
+			return '\f', true
+		case `\t`:
+			return '\t', true
+		case `\n`:
+			return '\n', true
+		case `\r`:
+			return '\r', true
+		case `\v`:
+			return '\v', true
+		case `\e`:
+			return 0x1b, true
+		}
+		ch, _ = utf8.DecodeRuneInString(e.Args[0].Value)
+		if ch < utf8.RuneSelf && isAlphanumeric(byte(ch)) {
+			// Most likely some special escape sequence.
+			return 0, false
+		}
+		return ch, true
+	}
+	return 0, false
+}
@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// FirstRuneSet returns a set of runes that can start a re match.
+//
+// It's intended for the prefilters that skip the input
+// positions that can't start a match.
+// Zero-width assertions, like anchors and lookarounds, are skipped.
+// If re can match an empty string, any rune can start a match,
+// so charset.Any is returned. Backreferences and recursion
+// also result in charset.Any.
+//
+// The RE2 tables are used, the `i` and `s` flags are respected.
+func FirstRuneSet(re *syntax.Regexp) charset.RuneSet {
+	s, nullable := firstRunes(re.Expr, 0)
+	if nullable {
+		return charset.Any
+	}
+	return s
+}
+
+// firstRunes returns the set of runes that can start an e match
+// and whether e can match an empty string.
+func firstRunes(e syntax.Expr, flags syntax.Flags) (s charset.RuneSet, nullable bool) {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral, syntax.OpEmptyMatch:
+		for _, a := range e.Args {
+			if a.Op == syntax.OpFlagOnlyGroup {
+				var ok bool
+				if flags, ok = applyFlags(flags, a.Args[0].Value); !ok {
+					return charset.Any, false
+				}
+				continue
+			}
+			first, nullable := firstRunes(a, flags)
+			s = s.Union(first)
+			if !nullable {
+				return s, false
+			}
+		}
+		return s, true
+
+	case syntax.OpAlt:
+		for _, a := range e.Args {
+			first, n := firstRunes(a, flags)
+			s = s.Union(first)
+			nullable = nullable || n
+			// Flags that are set inside a branch affect the next branches.
+			flags = branchFlags(a, flags)
+		}
+		return s, nullable
+
+	case syntax.OpStar, syntax.OpQuestion:
+		s, _ = firstRunes(e.Args[0], flags)
+		return s, true
+	case syntax.OpRepeat:
+		s, nullable = firstRunes(e.Args[0], flags)
+		min, _ := thompson.RepeatBounds(e.Args[1].Value, maxCount)
+		return s, nullable || min == 0
+	case syntax.OpPlus, syntax.OpNonGreedy, syntax.OpPossessive,
+		syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup, syntax.OpAtomicGroup,
+		syntax.OpScriptRun:
+		return firstRunes(e.Args[0], flags)
+	case syntax.OpGroupWithFlags:
+		flags, ok := applyFlags(flags, e.Args[1].Value)
+		if !ok {
+			return charset.Any, false
+		}
+		return firstRunes(e.Args[0], flags)
+
+	case syntax.OpCaret, syntax.OpDollar, syntax.OpComment,
+		syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return nil, true
+
+	case syntax.OpPlaceholder:
+		// The substituted text is unknown, it can be empty.
+		return charset.Any, true
+
+	case syntax.OpFlagOnlyGroup:
+		// Recursion or a named backreference, flags are handled by OpConcat.
+		if _, ok := applyFlags(flags, e.Args[0].Value); ok {
+			return nil, true
+		}
+		return charset.Any, false
+
+	case syntax.OpQuote:
+		lit := e.QuotedLiteral()
+		if lit == "" {
+			return nil, true
+		}
+		ch, _ := utf8.DecodeRuneInString(lit)
+		return foldSet(charset.Of(ch), flags), false
+
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\b`, `\B`, `\A`, `\z`, `\Z`, `\G`, `\K`:
+			return nil, true
+		}
+
+	case syntax.OpDot:
+		if flags&syntax.FlagDotAll != 0 {
+			return charset.Any, false
+		}
+	}
+
+	fold := charset.FoldNone
+	if flags&syntax.FlagCaseInsensitive != 0 {
+		fold = charset.FoldSimple
+	}
+	s, ok := charset.RE2.FromExprFold(e, fold)
+	if !ok {
+		return charset.Any, false
+	}
+	return s, false
+}
+
+// branchFlags returns the flags that are active after the alternation branch e.
+func branchFlags(e syntax.Expr, flags syntax.Flags) syntax.Flags {
+	parts := []syntax.Expr{e}
+	if e.Op == syntax.OpConcat {
+		parts = e.Args
+	}
+	for _, a := range parts {
+		if a.Op == syntax.OpFlagOnlyGroup {
+			flags, _ = applyFlags(flags, a.Args[0].Value)
+		}
+	}
+	return flags
+}
+
+// applyFlags returns the flags modified by the flags group string.
+// ok is false if s is not a flags string.
+func applyFlags(flags syntax.Flags, s string) (syntax.Flags, bool) {
+	enable, disable, err := syntax.ParseFlags(s, syntax.DialectPCRE)
+	if err != nil {
+		return flags, false
+	}
+	return flags&^disable | enable, true
+}
+
+func foldSet(s charset.RuneSet, flags syntax.Flags) charset.RuneSet {
+	if flags&syntax.FlagCaseInsensitive != 0 {
+		return s.Fold()
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestFirstRuneSet(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `[a]`},
+		{`foo|bar|baz`, `[bf]`},
+		{`a?b*c`, `[a-c]`},
+		{`x{0,2}[0-9]+`, `[0-9x]`},
+		{`^\b(?=a)(?:\Qhi\E|(?#c)z)`, `[hz]`},
+		{`(?i)k|x`, "[KXkx\u212a]"},
+		{`(?i:a)b`, `[Aa]`},
+		{`(?i)(?-i)a`, `[a]`},
+		{`a(?i)|b`, `[Bab]`},
+		{`\d+\.`, `[0-9]`},
+		{`.x`, `[^\n]`},
+
+		{`(?s).x`, `[\x{0}-\x{10FFFF}]`},
+		{`a*`, `[\x{0}-\x{10FFFF}]`},
+		{`a|`, `[\x{0}-\x{10FFFF}]`},
+		{`(a)?\1`, `[\x{0}-\x{10FFFF}]`},
+		{`(?R)a`, `[\x{0}-\x{10FFFF}]`},
+		{`^$`, `[\x{0}-\x{10FFFF}]`},
+		{`%s`, `[\x{0}-\x{10FFFF}]`},
+		{`(?:%sa)?b`, `[\x{0}-\x{10FFFF}]`},
+		{`a%s`, `[a]`},
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Placeholders: syntax.PlaceholdersPrintf})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := FirstRuneSet(re).String()
+		if have != test.want {
+			t.Errorf("FirstRuneSet(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
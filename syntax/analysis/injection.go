@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// InjectionKind is a kind of the placeholder injection risk.
+type InjectionKind byte
+
+const (
+	// InjectionUnquoted is a placeholder outside of `\Q...\E`:
+	// the metachars of the substituted text change the pattern structure.
+	InjectionUnquoted InjectionKind = iota + 1
+
+	// InjectionCharClass is a placeholder inside a char class:
+	// the substituted `]`, `^` and `-` change the class.
+	// regexp.QuoteMeta doesn't escape `-`, so even
+	// a quoted text can form a char range.
+	InjectionCharClass
+
+	// InjectionQuantified is a placeholder with a quantifier:
+	// the quantifier only applies to the last char of the substituted text.
+	InjectionQuantified
+)
+
+func (k InjectionKind) String() string {
+	switch k {
+	case InjectionUnquoted:
+		return "unquoted placeholder"
+	case InjectionCharClass:
+		return "placeholder in char class"
+	case InjectionQuantified:
+		return "quantified placeholder"
+	default:
+		return "?"
+	}
+}
+
+// InjectionRisk is a placeholder that makes the pattern
+// structure depend on the substituted text.
+type InjectionRisk struct {
+	Kind InjectionKind
+
+	// Expr is the OpPlaceholder expression.
+	Expr syntax.Expr
+
+	Message string
+}
+
+// CheckInjection returns the re placeholders that are unsafe to
+// substitute with an unescaped user input, in the pattern text order.
+//
+// The re must be parsed with syntax.ParserOptions.Placeholders.
+// The placeholders inside `\Q...\E` are parsed as a part of the quote,
+// so they're never reported. The `%%` and the fmt verbs that only
+// produce the digits and letters, like `%d` and `%x`, are safe
+// outside of the char classes.
+func CheckInjection(re *syntax.Regexp) []InjectionRisk {
+	var risks []InjectionRisk
+	var walk func(e syntax.Expr, kind InjectionKind)
+	walk = func(e syntax.Expr, kind InjectionKind) {
+		switch e.Op {
+		case syntax.OpPlaceholder:
+			if risk, ok := injectionRisk(e, kind); ok {
+				risks = append(risks, risk)
+			}
+			return
+		case syntax.OpCharClass, syntax.OpNegCharClass:
+			kind = InjectionCharClass
+		case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat:
+			walk(e.Args[0], InjectionQuantified)
+			return
+		case syntax.OpCharRange:
+			// Keep the char class context.
+		default:
+			kind = InjectionUnquoted
+		}
+		for _, a := range e.Args {
+			walk(a, kind)
+		}
+	}
+	walk(re.Expr, InjectionUnquoted)
+	return risks
+}
+
+func injectionRisk(e syntax.Expr, kind InjectionKind) (InjectionRisk, bool) {
+	verb := byte(0)
+	if strings.HasPrefix(e.Value, "%") {
+		if e.Value == "%%" {
+			return InjectionRisk{}, false
+		}
+		verb = e.Value[len(e.Value)-1]
+	}
+	if kind == InjectionQuantified && verb == 'c' {
+		// A single char is quantified as a whole.
+		kind = InjectionUnquoted
+	}
+
+	risk := InjectionRisk{Kind: kind, Expr: e}
+	switch kind {
+	case InjectionCharClass:
+		risk.Message = e.Value + " is inside a char class: the substituted ], ^ and - change the class even if the text is quoted"
+	case InjectionQuantified:
+		risk.Message = e.Value + " is quantified: the quantifier only applies to the last char of the substituted text; wrap it in a group"
+	default:
+		if strings.IndexByte("bdoOtxX", verb) != -1 && !strings.Contains(e.Value, "+") {
+			return InjectionRisk{}, false
+		}
+		risk.Message = e.Value + " is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \\Q...\\E"
+	}
+	return risk, true
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCheckInjection(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`^\d+$`, nil},
+		{`^\Q%s\E$`, nil},
+		{`^%d-%x$`, nil},
+		{`100%%`, nil},
+		{`^%s$`, []string{
+			`1 unquoted placeholder: %s is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \Q...\E`,
+		}},
+		{`%+d|${v}`, []string{
+			`0 unquoted placeholder: %+d is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \Q...\E`,
+			`4 unquoted placeholder: ${v} is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \Q...\E`,
+		}},
+		{`[^%d\s]`, []string{
+			`2 placeholder in char class: %d is inside a char class: the substituted ], ^ and - change the class even if the text is quoted`,
+		}},
+		{`a{{.Sep}}*b`, []string{
+			`1 quantified placeholder: {{.Sep}} is quantified: the quantifier only applies to the last char of the substituted text; wrap it in a group`,
+		}},
+		{`%d{2}(?:%s)?`, []string{
+			`0 quantified placeholder: %d is quantified: the quantifier only applies to the last char of the substituted text; wrap it in a group`,
+			`8 unquoted placeholder: %s is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \Q...\E`,
+		}},
+		{`%c+`, []string{
+			`0 unquoted placeholder: %c is not quoted: the substituted metachars change the pattern structure; use regexp.QuoteMeta or \Q...\E`,
+		}},
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Placeholders: syntax.PlaceholdersAll})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, risk := range CheckInjection(re) {
+			have = append(have, fmt.Sprintf("%d %s: %s", risk.Expr.Pos.Begin, risk.Kind, risk.Message))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("CheckInjection(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Intersects reports whether there is a string that is matched by both a and b.
+//
+// If the patterns intersect, the shortest such string is returned as an example.
+// See Subsumes for the matching semantics and the supported patterns.
+func Intersects(a, b *syntax.Regexp) (bool, string, error) {
+	na, err := newSearchNFA(a.Expr)
+	if err != nil {
+		return false, "", err
+	}
+	nb, err := newSearchNFA(b.Expr)
+	if err != nil {
+		return false, "", err
+	}
+	found := func(aAccepts, bAccepts bool) bool {
+		return aAccepts && bAccepts
+	}
+	dead := func(as, bs []int) bool {
+		return len(as) == 0 || len(bs) == 0
+	}
+	example, ok, err := productSearch(na, nb, found, dead)
+	if err != nil {
+		return false, "", err
+	}
+	return ok, example, nil
+}
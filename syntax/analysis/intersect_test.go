@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestIntersects(t *testing.T) {
+	tests := []struct {
+		a       string
+		b       string
+		ok      bool
+		example string
+	}{
+		{`a`, `b`, true, `ab`},
+		{`^a`, `^b`, false, ``},
+		{`^a$`, `^a$`, true, `a`},
+		{`^\d+$`, `^[a-z]+$`, false, ``},
+		{`^\d+$`, `^[a-f0-9]{4}$`, true, `0000`},
+		{`^foo`, `bar$`, true, `foobar`},
+		{`^[a-z]+@example\.com$`, `^admin@`, true, `admin@example.com`},
+		{`^(?:ab)+$`, `^(?:a|b){3}$`, false, ``},
+		{`^(?:ab)+$`, `^(?:a|b){4}$`, true, `abab`},
+		{`^$`, `^x*$`, true, ``},
+		{`^.$`, `^\n$`, false, ``},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		a, err := p.Parse(test.a)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.a, err)
+		}
+		a = a.Clone()
+		b, err := p.Parse(test.b)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.b, err)
+		}
+		ok, example, err := Intersects(a, b)
+		if err != nil {
+			t.Errorf("intersects(%q, %q): %v", test.a, test.b, err)
+			continue
+		}
+		if ok != test.ok || example != test.example {
+			t.Errorf("intersects(%q, %q):\nhave: %v %q\nwant: %v %q",
+				test.a, test.b, ok, example, test.ok, test.example)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		for _, pattern := range []string{test.a, test.b} {
+			if !regexp.MustCompile(pattern).MatchString(example) {
+				t.Errorf("intersects(%q, %q): %q doesn't match the example %q",
+					test.a, test.b, pattern, example)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// LiteralAnchors describes how a literal pattern is anchored.
+type LiteralAnchors byte
+
+const (
+	// LiteralUnanchored is a `lit` pattern, it's strings.Contains.
+	LiteralUnanchored LiteralAnchors = iota
+
+	// LiteralPrefix is a `^lit` pattern, it's strings.HasPrefix.
+	LiteralPrefix
+
+	// LiteralSuffix is a `lit$` pattern, it's strings.HasSuffix.
+	LiteralSuffix
+
+	// LiteralExact is a `^lit$` pattern, it's a string comparison.
+	LiteralExact
+)
+
+// AsLiteral reports whether re matches exactly the returned string
+// and nothing else, so the match can be replaced with strings.Contains.
+//
+// Chars, escaped chars, `\Q...\E` quotes, comments and non-capturing
+// groups are permitted. Anchored patterns are rejected, see AsAnchoredLiteral.
+func AsLiteral(re *syntax.Regexp) (string, bool) {
+	lit, anchors, ok := AsAnchoredLiteral(re)
+	if !ok || anchors != LiteralUnanchored {
+		return "", false
+	}
+	return lit, true
+}
+
+// AsAnchoredLiteral is like AsLiteral, but it also permits
+// a leading `^` or `\A` and a trailing `$` or `\z`.
+//
+// `$` is interpreted as in Go regexp: it only matches
+// at the end of the input.
+func AsAnchoredLiteral(re *syntax.Regexp) (string, LiteralAnchors, bool) {
+	parts := []syntax.Expr{re.Expr}
+	if re.Expr.Op == syntax.OpConcat {
+		parts = re.Expr.Args
+	}
+
+	anchors := LiteralUnanchored
+	if len(parts) != 0 && isBeginAnchor(parts[0]) {
+		anchors = LiteralPrefix
+		parts = parts[1:]
+	}
+	if len(parts) != 0 && isEndAnchor(parts[len(parts)-1]) {
+		if anchors == LiteralPrefix {
+			anchors = LiteralExact
+		} else {
+			anchors = LiteralSuffix
+		}
+		parts = parts[:len(parts)-1]
+	}
+
+	var b strings.Builder
+	for _, e := range parts {
+		if !writeLiteral(&b, e) {
+			return "", LiteralUnanchored, false
+		}
+	}
+	return b.String(), anchors, true
+}
+
+func writeLiteral(b *strings.Builder, e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral, syntax.OpGroup:
+		for _, a := range e.Args {
+			if !writeLiteral(b, a) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpQuote:
+		b.WriteString(e.QuotedLiteral())
+		return true
+	case syntax.OpComment, syntax.OpEmptyMatch:
+		return true
+	case syntax.OpEscapeOctal:
+		// `\1` and alike could be a backreference.
+		if !strings.HasPrefix(e.Args[0].Value, "0") {
+			return false
+		}
+		ch, _ := charValue(e)
+		b.WriteRune(ch)
+		return true
+	default:
+		ch, ok := charValue(e)
+		if ok {
+			b.WriteRune(ch)
+		}
+		return ok
+	}
+}
+
+func isBeginAnchor(e syntax.Expr) bool {
+	return e.Op == syntax.OpCaret || (e.Op == syntax.OpEscapeChar && e.Value == `\A`)
+}
+
+func isEndAnchor(e syntax.Expr) bool {
+	return e.Op == syntax.OpDollar || (e.Op == syntax.OpEscapeChar && e.Value == `\z`)
+}
+
+// LiteralAlternatives reports whether re is an alternation of literals,
+// like `foo|bar|baz`, and returns the alternatives.
+// A single literal is returned as a list of one element.
+//
+// The alternation can be wrapped into a group, anchored with `^`, `\A`,
+// `$`, `\z` or `\b` and prefixed with the `(?i)` flag. These are skipped,
+// use ResolveAnchors and the pattern flags if they matter to the caller.
+func LiteralAlternatives(re *syntax.Regexp) ([]string, bool) {
+	parts := []syntax.Expr{re.Expr}
+	if re.Expr.Op == syntax.OpConcat {
+		parts = re.Expr.Args
+	}
+
+	for len(parts) != 0 && (isBeginAnchor(parts[0]) || isWordBoundary(parts[0]) || isCaseFlag(parts[0])) {
+		parts = parts[1:]
+	}
+	for len(parts) != 0 && (isEndAnchor(parts[len(parts)-1]) || isWordBoundary(parts[len(parts)-1])) {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 1 {
+		e := parts[0]
+		for isTransparentGroup(e) {
+			e = e.Args[0]
+		}
+		if e.Op == syntax.OpAlt {
+			parts = e.Args
+		} else {
+			parts = []syntax.Expr{e}
+		}
+	} else {
+		parts = []syntax.Expr{{Op: syntax.OpConcat, Args: parts}}
+	}
+
+	list := make([]string, 0, len(parts))
+	for _, e := range parts {
+		var b strings.Builder
+		if !writeLiteral(&b, e) {
+			return nil, false
+		}
+		list = append(list, b.String())
+	}
+	return list, true
+}
+
+func isWordBoundary(e syntax.Expr) bool {
+	return e.Op == syntax.OpEscapeChar && e.Value == `\b`
+}
+
+// isCaseFlag reports whether e is a `(?i)` or `(?-i)` group.
+func isCaseFlag(e syntax.Expr) bool {
+	return e.Op == syntax.OpFlagOnlyGroup && isCaseFlags(e.Args[0].Value)
+}
+
+func isCaseFlags(flags string) bool {
+	return flags != "" && strings.Trim(flags, "i-") == ""
+}
+
+// isTransparentGroup reports whether e is a group that
+// doesn't affect the literal text of its body.
+func isTransparentGroup(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpGroup, syntax.OpCapture, syntax.OpNamedCapture:
+		return true
+	case syntax.OpGroupWithFlags:
+		return isCaseFlags(e.Args[1].Value)
+	}
+	return false
+}
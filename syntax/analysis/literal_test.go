@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestAsAnchoredLiteral(t *testing.T) {
+	tests := []struct {
+		pattern string
+		lit     string
+		anchors LiteralAnchors
+		ok      bool
+	}{
+		{``, ``, LiteralUnanchored, true},
+		{`abc`, `abc`, LiteralUnanchored, true},
+		{`a\.b\n`, "a.b\n", LiteralUnanchored, true},
+		{`\Qa.b\E(?:c)(?#x)\x41\0`, "a.bcA\x00", LiteralUnanchored, true},
+		{`^abc`, `abc`, LiteralPrefix, true},
+		{`\Aabc`, `abc`, LiteralPrefix, true},
+		{`abc$`, `abc`, LiteralSuffix, true},
+		{`^abc\z`, `abc`, LiteralExact, true},
+		{`^`, ``, LiteralPrefix, true},
+		{`^$`, ``, LiteralExact, true},
+
+		{`a.c`, ``, LiteralUnanchored, false},
+		{`a\dc`, ``, LiteralUnanchored, false},
+		{`(abc)`, ``, LiteralUnanchored, false},
+		{`(?i)abc`, ``, LiteralUnanchored, false},
+		{`(?i:abc)`, ``, LiteralUnanchored, false},
+		{`a|b`, ``, LiteralUnanchored, false},
+		{`ab+`, ``, LiteralUnanchored, false},
+		{`a^b`, ``, LiteralUnanchored, false},
+		{`(a)\1`, ``, LiteralUnanchored, false},
+		{`[a]`, ``, LiteralUnanchored, false},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		lit, anchors, ok := AsAnchoredLiteral(re)
+		if lit != test.lit || anchors != test.anchors || ok != test.ok {
+			t.Errorf("AsAnchoredLiteral(%q):\nhave: %q %d %v\nwant: %q %d %v",
+				test.pattern, lit, anchors, ok, test.lit, test.anchors, test.ok)
+		}
+		lit, ok = AsLiteral(re)
+		wantOK := test.ok && test.anchors == LiteralUnanchored
+		if ok != wantOK || (ok && lit != test.lit) {
+			t.Errorf("AsLiteral(%q):\nhave: %q %v\nwant: %q %v", test.pattern, lit, ok, test.lit, wantOK)
+		}
+	}
+}
+
+func TestLiteralAlternatives(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`foo`, []string{"foo"}},
+		{`foo|bar|b\.az`, []string{"foo", "bar", "b.az"}},
+		{`^(?:foo|bar)$`, []string{"foo", "bar"}},
+		{`(?i)\b(foo|\Qa+b\E)\b`, []string{"foo", "a+b"}},
+		{`\A(?i:(?P<x>foo|bar))\z`, []string{"foo", "bar"}},
+		{`^foo\.com$`, []string{"foo.com"}},
+		{`a||b`, []string{"a", "", "b"}},
+
+		{`foo|ba+r`, nil},
+		{`foo(?:bar|baz)`, nil},
+		{`(?s)foo|bar`, nil},
+		{`(foo)|bar`, nil},
+		{`foo|[ab]`, nil},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, ok := LiteralAlternatives(re)
+		if ok != (test.want != nil) || !reflect.DeepEqual(have, test.want) {
+			t.Errorf("LiteralAlternatives(%q):\nhave: %q %v\nwant: %q", test.pattern, have, ok, test.want)
+		}
+	}
+}
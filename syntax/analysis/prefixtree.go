@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// PrefixNode is a node of the alternation branches prefix tree.
+//
+// Big alternations like `foo|foobar|fox|...` are slow for the backtracking
+// engines when the branches share long prefixes. The tree shows how
+// they can be factored out: `fo(?:o(?:bar)?|x)`.
+type PrefixNode struct {
+	// Prefix is the literal text that follows the parent node prefix.
+	// It's empty for the root node.
+	Prefix string
+
+	// Branches is a number of the alternation branches
+	// that start with the node prefix (including the parents prefixes).
+	Branches int
+
+	// Ends lists the indexes of the branches which literal prefix
+	// ends at this node. The branch can continue with a non-literal
+	// expression, like `\d+` in `foo\d+`.
+	Ends []int
+
+	Children []*PrefixNode
+}
+
+// AltPrefixTree returns the prefix tree of the alternation branches.
+// Only the literal prefix of every branch is used, `foo` for `foo\d+`.
+//
+// If e is not an alternation, it's treated as a single branch.
+func AltPrefixTree(e syntax.Expr) *PrefixNode {
+	branches := []syntax.Expr{e}
+	if e.Op == syntax.OpAlt {
+		branches = e.Args
+	}
+
+	root := &trieNode{}
+	for i, branch := range branches {
+		root.insert(literalPrefix(branch), i)
+	}
+	return root.compress("")
+}
+
+// literalPrefix returns the longest literal text that e starts with.
+func literalPrefix(e syntax.Expr) string {
+	parts := []syntax.Expr{e}
+	if e.Op == syntax.OpConcat {
+		parts = e.Args
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		var partText strings.Builder
+		if !writeLiteral(&partText, part) {
+			break
+		}
+		b.WriteString(partText.String())
+	}
+	return b.String()
+}
+
+type trieNode struct {
+	branches int
+	ends     []int
+	keys     []rune
+	children map[rune]*trieNode
+}
+
+func (n *trieNode) insert(s string, index int) {
+	n.branches++
+	for _, ch := range s {
+		child := n.children[ch]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			n.children[ch] = child
+			n.keys = append(n.keys, ch)
+		}
+		child.branches++
+		n = child
+	}
+	n.ends = append(n.ends, index)
+}
+
+// compress converts the trie into a PrefixNode tree,
+// merging the chains of the single-child nodes.
+func (n *trieNode) compress(prefix string) *PrefixNode {
+	for len(n.keys) == 1 && len(n.ends) == 0 && prefix != "" {
+		ch := n.keys[0]
+		prefix += string(ch)
+		n = n.children[ch]
+	}
+	node := &PrefixNode{Prefix: prefix, Branches: n.branches, Ends: n.ends}
+	for _, ch := range n.keys {
+		node.Children = append(node.Children, n.children[ch].compress(string(ch)))
+	}
+	return node
+}
+
+// String returns the tree drawing, one node per line:
+//
+//	(3)
+//	└── "fo" (3)
+//	    ├── "o" (2) #0
+//	    │   └── "bar" (1) #1
+//	    └── "x" (1) #2
+//
+// The numbers in parentheses are branch counts,
+// `#N` are the indexes of the branches that end at the node.
+func (n *PrefixNode) String() string {
+	var b strings.Builder
+	n.write(&b, "", "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (n *PrefixNode) write(b *strings.Builder, prefix, childPrefix string) {
+	b.WriteString(prefix)
+	if n.Prefix != "" {
+		fmt.Fprintf(b, "%q ", n.Prefix)
+	}
+	fmt.Fprintf(b, "(%d)", n.Branches)
+	for _, index := range n.Ends {
+		fmt.Fprintf(b, " #%d", index)
+	}
+	b.WriteByte('\n')
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			child.write(b, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			child.write(b, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestAltPrefixTree(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`foo|foobar|fox`, []string{
+			`(3)`,
+			`└── "fo" (3)`,
+			`    ├── "o" (2) #0`,
+			`    │   └── "bar" (1) #1`,
+			`    └── "x" (1) #2`,
+		}},
+		{`ab\d+|ac|\w|ab`, []string{
+			`(4) #2`,
+			`└── "a" (3)`,
+			`    ├── "b" (2) #0 #3`,
+			`    └── "c" (1) #1`,
+		}},
+		{`привет|пока`, []string{
+			`(2)`,
+			`└── "п" (2)`,
+			`    ├── "ривет" (1) #0`,
+			`    └── "ока" (1) #1`,
+		}},
+		{`abc`, []string{
+			`(1)`,
+			`└── "abc" (1) #0`,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := AltPrefixTree(re.Expr).String()
+		want := strings.Join(test.want, "\n")
+		if have != want {
+			t.Errorf("AltPrefixTree(%q):\nhave:\n%s\nwant:\n%s", test.pattern, have, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// ReplacementKind is a kind of the suggested regexp replacement.
+type ReplacementKind byte
+
+const (
+	// ReplaceContains replaces MatchString with strings.Contains.
+	ReplaceContains ReplacementKind = iota + 1
+
+	// ReplaceHasPrefix replaces MatchString with strings.HasPrefix.
+	ReplaceHasPrefix
+
+	// ReplaceHasSuffix replaces MatchString with strings.HasSuffix.
+	ReplaceHasSuffix
+
+	// ReplaceEqual replaces MatchString with a string comparison.
+	ReplaceEqual
+
+	// ReplaceContainsAny replaces MatchString with strings.ContainsAny.
+	ReplaceContainsAny
+
+	// ReplaceFieldsFunc replaces FindAllString(s, -1) with strings.FieldsFunc.
+	// It's suggested for patterns like `[0-9]+` that match
+	// the maximal runs of the chars from a set.
+	ReplaceFieldsFunc
+)
+
+// Replacement is a suggestion to use a strings package function
+// instead of a regexp. The same functions exist in the bytes package.
+type Replacement struct {
+	Kind ReplacementKind
+
+	// Literal is a string argument for all kinds except ReplaceFieldsFunc.
+	// For ReplaceContainsAny it contains all matched chars.
+	Literal string
+
+	// Set is a set of chars for ReplaceContainsAny and ReplaceFieldsFunc.
+	Set charset.RuneSet
+}
+
+// maxContainsAnyChars limits the ReplaceContainsAny literal size.
+const maxContainsAnyChars = 16
+
+// SuggestReplacement returns a strings package replacement for re.
+// If re is too complex to be replaced, ok is false.
+//
+// `$` is interpreted as in Go regexp: it only matches
+// at the end of the input.
+func SuggestReplacement(re *syntax.Regexp) (r Replacement, ok bool) {
+	if lit, anchors, ok := AsAnchoredLiteral(re); ok {
+		switch anchors {
+		case LiteralUnanchored:
+			r.Kind = ReplaceContains
+		case LiteralPrefix:
+			r.Kind = ReplaceHasPrefix
+		case LiteralSuffix:
+			r.Kind = ReplaceHasSuffix
+		case LiteralExact:
+			r.Kind = ReplaceEqual
+		}
+		r.Literal = lit
+		return r, true
+	}
+
+	e := re.Expr
+	if e.Op == syntax.OpPlus {
+		set, ok := charset.FromExpr(e.Args[0])
+		if !ok || set.IsEmpty() {
+			return r, false
+		}
+		return Replacement{Kind: ReplaceFieldsFunc, Set: set}, true
+	}
+	if set, ok := charset.FromExpr(e); ok && !set.IsEmpty() && set.Len() <= maxContainsAnyChars {
+		var b strings.Builder
+		for _, rng := range set {
+			for ch := rng.Lo; ch <= rng.Hi; ch++ {
+				b.WriteRune(ch)
+			}
+		}
+		return Replacement{Kind: ReplaceContainsAny, Literal: b.String(), Set: set}, true
+	}
+	return r, false
+}
+
+// Format returns a Go expression that implements the replacement.
+//
+// pkg is either "strings" or "bytes" and s is an argument expression.
+// For the bytes package the literal arguments are not converted,
+// so the caller may need to wrap them into []byte.
+func (r Replacement) Format(pkg, s string) string {
+	lit := strconv.Quote(r.Literal)
+	switch r.Kind {
+	case ReplaceContains:
+		return pkg + ".Contains(" + s + ", " + lit + ")"
+	case ReplaceHasPrefix:
+		return pkg + ".HasPrefix(" + s + ", " + lit + ")"
+	case ReplaceHasSuffix:
+		return pkg + ".HasSuffix(" + s + ", " + lit + ")"
+	case ReplaceEqual:
+		if pkg == "bytes" {
+			return "bytes.Equal(" + s + ", " + lit + ")"
+		}
+		return s + " == " + lit
+	case ReplaceContainsAny:
+		return pkg + ".ContainsAny(" + s + ", " + lit + ")"
+	case ReplaceFieldsFunc:
+		return pkg + ".FieldsFunc(" + s + ", func(r rune) bool { return " +
+			formatRunePredicate("r", r.Set.Negate()) + " })"
+	default:
+		return ""
+	}
+}
+
+// formatRunePredicate returns a Go expression that
+// reports whether the variable v is in s.
+func formatRunePredicate(v string, s charset.RuneSet) string {
+	if negated := s.Negate(); len(negated) < len(s) {
+		return "!(" + formatRunePredicate(v, negated) + ")"
+	}
+	if len(s) == 0 {
+		return "false"
+	}
+	parts := make([]string, len(s))
+	for i, rng := range s {
+		lo := strconv.QuoteRune(rng.Lo)
+		switch {
+		case rng.Lo == rng.Hi:
+			parts[i] = v + " == " + lo
+		case len(s) == 1:
+			parts[i] = v + " >= " + lo + " && " + v + " <= " + strconv.QuoteRune(rng.Hi)
+		default:
+			parts[i] = "(" + v + " >= " + lo + " && " + v + " <= " + strconv.QuoteRune(rng.Hi) + ")"
+		}
+	}
+	return strings.Join(parts, " || ")
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestSuggestReplacement(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`foo`, `strings.Contains(s, "foo")`},
+		{`^foo`, `strings.HasPrefix(s, "foo")`},
+		{`\.go$`, `strings.HasSuffix(s, ".go")`},
+		{`^foo$`, `s == "foo"`},
+		{`[abc]`, `strings.ContainsAny(s, "abc")`},
+		{`\n`, `strings.Contains(s, "\n")`},
+		{`[0-9]+`, `strings.FieldsFunc(s, func(r rune) bool { return !(r >= '0' && r <= '9') })`},
+		{`[a-z_]+`, `strings.FieldsFunc(s, func(r rune) bool { return !(r == '_' || (r >= 'a' && r <= 'z')) })`},
+		{`[^,]+`, `strings.FieldsFunc(s, func(r rune) bool { return r == ',' })`},
+
+		{`a+b`, ``},
+		{`[0-9]*`, ``},
+		{`\w`, ``},
+		{`(?i)foo`, ``},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := ""
+		if r, ok := SuggestReplacement(re); ok {
+			have = r.Format("strings", "s")
+		}
+		if have != test.want {
+			t.Errorf("SuggestReplacement(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// ExpandedSize estimates the compiled program size of re.
+//
+// The estimation follows the RE2 compilation scheme: bounded
+// repetitions are expanded, so `(a{100}){100}` has the size of 10000+.
+// Every char and every control instruction (split, save, assert)
+// is counted as 1.
+//
+// Engines limit the program size (RE2 rejects the programs bigger
+// than its max_mem setting allows), so linters can use this estimation
+// to flag the patterns that are likely to be rejected or too expensive.
+// See also syntax.Limits that can reject them during the parsing.
+func ExpandedSize(re *syntax.Regexp) int {
+	return expandedSize(re.Expr)
+}
+
+func expandedSize(e syntax.Expr) int {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral:
+		size := 0
+		for _, a := range e.Args {
+			size = addCount(size, expandedSize(a))
+		}
+		return size
+
+	case syntax.OpAlt:
+		// Every branch except the last one needs a split.
+		size := len(e.Args) - 1
+		for _, a := range e.Args {
+			size = addCount(size, expandedSize(a))
+		}
+		return size
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion:
+		return addCount(expandedSize(e.Args[0]), 1)
+
+	case syntax.OpRepeat:
+		size := expandedSize(e.Args[0])
+		min, max := thompson.RepeatBounds(e.Args[1].Value, maxCount)
+		if max == -1 {
+			// x{min,} is x{min}x*.
+			return addCount(mulCount(size, min), addCount(size, 1))
+		}
+		// x{min,max} is x{min}(x(x...)?)?: every optional copy needs a split.
+		return addCount(mulCount(size, max), max-min)
+
+	case syntax.OpCapture, syntax.OpNamedCapture:
+		// Save instructions for the group boundaries.
+		return addCount(expandedSize(e.Args[0]), 2)
+
+	case syntax.OpNonGreedy, syntax.OpPossessive,
+		syntax.OpGroup, syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun:
+		return expandedSize(e.Args[0])
+
+	case syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return addCount(expandedSize(e.Args[0]), 1)
+
+	case syntax.OpQuote:
+		return len([]rune(e.QuotedLiteral()))
+
+	case syntax.OpComment, syntax.OpFlagOnlyGroup, syntax.OpEmptyMatch:
+		return 0
+
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestExpandedSize(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{``, 0},
+		{`abc`, 3},
+		{`a|b|c`, 5},
+		{`(a)*`, 4},
+		{`a{3}`, 3},
+		{`a{2,4}`, 6},
+		{`a{2,}`, 4},
+		{`(?:a{100}){100}`, 10000},
+		{`(?:(?:a{1000}){1000}){1000}`, 1000000000},
+		{`(?:(?:(?:a{1000}){1000}){1000}){1000}`, maxCount},
+		{`\Qab\E(?#x)(?i)^$`, 4},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if have := ExpandedSize(re); have != test.want {
+			t.Errorf("ExpandedSize(%q):\nhave: %d\nwant: %d", test.pattern, have, test.want)
+		}
+	}
+}
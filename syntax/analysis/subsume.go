@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Subsumes reports whether a matches every string that b matches.
+//
+// A pattern is said to match a string if it matches any part of it,
+// like regexp.MatchString does. If a subsumes b, a rule guarded by b
+// is shadowed by a preceding rule guarded by a.
+//
+// Only the patterns that can be converted to a finite automaton
+// are supported, see the package documentation for details.
+func Subsumes(a, b *syntax.Regexp) (bool, error) {
+	na, err := newSearchNFA(a.Expr)
+	if err != nil {
+		return false, err
+	}
+	nb, err := newSearchNFA(b.Expr)
+	if err != nil {
+		return false, err
+	}
+	found := func(aAccepts, bAccepts bool) bool {
+		return bAccepts && !aAccepts
+	}
+	dead := func(as, bs []int) bool {
+		// Either b can't match anymore or a matches any continuation.
+		return len(bs) == 0 || na.hasMatch(as)
+	}
+	_, hasCounterexample, err := productSearch(na, nb, found, dead)
+	if err != nil {
+		return false, err
+	}
+	return !hasCounterexample, nil
+}
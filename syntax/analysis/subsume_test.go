@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestSubsumes(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{`a`, `a`, true},
+		{`a`, `ab`, true},
+		{`ab`, `a`, false},
+		{`^a`, `^ab`, true},
+		{`^a`, `a`, false},
+		{`a$`, `^a$`, true},
+		{`^a$`, `a$`, false},
+		{`[a-z]+`, `^foo\d*$`, true},
+		{`^[a-z]+$`, `^foo\d*$`, false},
+		{`^[a-z0-9]+$`, `^foo\d*$`, true},
+		{`^(?:a|b)*$`, `^(?:ab)*$`, true},
+		{`^(?:ab)*$`, `^(?:a|b)*$`, false},
+		{`^x{2,4}$`, `^xxx$`, true},
+		{`^x{2,4}$`, `^x{3,}$`, false},
+		{`^.*$`, `^[^\n]*$`, true},
+		{`^.*$`, `^\s*$`, false},
+		{`^[^a]$`, `^[\d\s]$`, true},
+		{`^\p{Greek}+$`, `^[αβγ]+$`, true},
+		{`^[[:alpha:]]$`, `^[A-Z]$`, true},
+		{`^[[:^alpha:]]$`, `^\d$`, true},
+		{`\Qa.b\E`, `a\.b`, true},
+		{`^$`, `^\A\z$`, true},
+		{`a^b`, `x`, false},
+		{`x`, `a^b`, true},
+		{`$^`, `(?:)`, false},
+		{`a|$^`, `a?`, false},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		a, err := p.Parse(test.a)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.a, err)
+		}
+		a = a.Clone()
+		b, err := p.Parse(test.b)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.b, err)
+		}
+		have, err := Subsumes(a, b)
+		if err != nil {
+			t.Errorf("subsumes(%q, %q): %v", test.a, test.b, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("subsumes(%q, %q):\nhave: %v\nwant: %v", test.a, test.b, have, test.want)
+		}
+	}
+}
+
+func TestSubsumesErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(a)\1`, `\1: unsupported by the automaton analysis`},
+		{`a(?=b)`, `(?=b): unsupported by the automaton analysis`},
+		{`(?i)a`, `(?i): unsupported by the automaton analysis`},
+		{`\bx`, `\b: unsupported by the automaton analysis`},
+		{`(?:a{1000}){1000}`, ErrTooComplex.Error()},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Subsumes(re, re)
+		have := "<nil>"
+		if err != nil {
+			have = err.Error()
+		}
+		if have != test.want {
+			t.Errorf("subsumes(%q) error:\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
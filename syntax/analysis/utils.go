@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"strconv"
+)
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return maxCount
+	}
+	return n
+}
+
+// maxCount is a saturation limit for all computed counters.
+const maxCount = 1 << 30
+
+func addCount(x, y int) int {
+	if x+y > maxCount {
+		return maxCount
+	}
+	return x + y
+}
+
+func mulCount(x, y int) int {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	if x > maxCount/y {
+		return maxCount
+	}
+	return x * y
+}
+
+func isAlphanumeric(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') ||
+		(ch >= 'A' && ch <= 'Z') ||
+		(ch >= '0' && ch <= '9')
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') ||
+		(ch >= 'a' && ch <= 'f') ||
+		(ch >= 'A' && ch <= 'F')
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// EdgeWildcard is a `.*` or `.+` at the pattern start or end.
+//
+// These wildcards don't affect whether the pattern matches,
+// but they make the matching slower: `.*foo` matches the same
+// lines as `foo`, but the engine has to scan the line prefix first.
+type EdgeWildcard struct {
+	// Pos is the pattern part that can be replaced,
+	// it includes the anchor if the wildcard is anchored.
+	Pos syntax.Position
+
+	// Leading is true for the wildcard at the pattern start.
+	Leading bool
+
+	// Replacement is the equivalent text: an empty string
+	// for `.*` and `.` for `.+` (at least one char is required).
+	Replacement string
+
+	// Note describes the semantic differences of the replacement.
+	Note string
+}
+
+// Notes for the EdgeWildcard replacements.
+const (
+	wildcardNote = "the match no longer includes the wildcard part, " +
+		"update the code that uses the match text or indexes"
+	anchoredWildcardNote = "the anchored wildcard limits the match to the first or the last line, " +
+		"the replacement is only equivalent for single-line inputs or with the s flag"
+)
+
+// EdgeWildcards returns the redundant wildcards at the pattern edges.
+//
+// Only the top-level concatenation is inspected, the wildcard must be
+// followed (or preceded) by other expressions: `.*` alone is not reported.
+func EdgeWildcards(re *syntax.Regexp) []EdgeWildcard {
+	if re.Expr.Op != syntax.OpConcat {
+		return nil
+	}
+	parts := re.Expr.Args
+	for len(parts) != 0 && parts[0].Op == syntax.OpFlagOnlyGroup {
+		parts = parts[1:]
+	}
+
+	var result []EdgeWildcard
+	first := 0
+	if len(parts) != 0 && isBeginAnchor(parts[0]) {
+		first = 1
+	}
+	if first < len(parts) && hasNonAnchor(parts[first+1:]) {
+		if repl, ok := wildcardReplacement(parts[first]); ok {
+			result = append(result, newEdgeWildcard(parts[0], parts[first], true, repl))
+			parts = parts[first+1:]
+		}
+	}
+
+	last := len(parts) - 1
+	if last >= 0 && isEndAnchor(parts[last]) {
+		last--
+	}
+	if last >= 0 && hasNonAnchor(parts[:last]) {
+		if repl, ok := wildcardReplacement(parts[last]); ok {
+			result = append(result, newEdgeWildcard(parts[last], parts[len(parts)-1], false, repl))
+		}
+	}
+	return result
+}
+
+func newEdgeWildcard(begin, end syntax.Expr, leading bool, repl string) EdgeWildcard {
+	w := EdgeWildcard{
+		Pos:         syntax.Position{Begin: begin.Begin(), End: end.End()},
+		Leading:     leading,
+		Replacement: repl,
+		Note:        wildcardNote,
+	}
+	if begin.Pos != end.Pos {
+		w.Note = anchoredWildcardNote
+	}
+	return w
+}
+
+func hasNonAnchor(parts []syntax.Expr) bool {
+	for _, e := range parts {
+		if !isBeginAnchor(e) && !isEndAnchor(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardReplacement returns the replacement for `.*` and `.+`,
+// including their non-greedy forms.
+func wildcardReplacement(e syntax.Expr) (string, bool) {
+	if e.Op == syntax.OpNonGreedy {
+		e = e.Args[0]
+	}
+	if len(e.Args) == 0 || e.Args[0].Op != syntax.OpDot {
+		return "", false
+	}
+	switch e.Op {
+	case syntax.OpStar:
+		return "", true
+	case syntax.OpPlus:
+		return ".", true
+	}
+	return "", false
+}
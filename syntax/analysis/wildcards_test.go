@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestEdgeWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`.*foo`, []string{`leading [0,2] "": the match no`}},
+		{`foo.+`, []string{`trailing [3,5] ".": the match no`}},
+		{`(?i).*?foo.*`, []string{
+			`leading [4,7] "": the match no`,
+			`trailing [10,12] "": the match no`,
+		}},
+		{`^.*foo.+$`, []string{
+			`leading [0,3] "": the anchored wildcard`,
+			`trailing [6,9] ".": the anchored wildcard`,
+		}},
+		{`\A.+(a|b)`, []string{`leading [0,4] ".": the anchored wildcard`}},
+
+		{`.*`, nil},
+		{`^.*$`, nil},
+		{`.*|foo`, nil},
+		{`foo`, nil},
+		{`a.*b`, nil},
+		{`.?foo`, nil},
+		{`x*foo`, nil},
+		{`^`, nil},
+		{``, nil},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, w := range EdgeWildcards(re) {
+			kind := "trailing"
+			if w.Leading {
+				kind = "leading"
+			}
+			note := strings.Join(strings.Fields(w.Note)[:3], " ")
+			have = append(have, fmt.Sprintf("%s [%d,%d] %q: %s", kind, w.Pos.Begin, w.Pos.End, w.Replacement, note))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("EdgeWildcards(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
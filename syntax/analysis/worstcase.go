@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// WorstCaseInput generates an adversarial input of approximately
+// length bytes that is likely to maximize the backtracking work for re.
+//
+// The generated string consists of a prefix that leads to the most nested
+// unbounded quantifier, a repeated "pumping" string matched by that
+// quantifier body and a suffix that tries to make the overall match fail,
+// so the engine has to explore all ways to split the pumped part.
+//
+// The result is a heuristic: it's intended for load testing rather
+// than for proving that a pattern is vulnerable.
+func WorstCaseInput(re *syntax.Regexp, length int) string {
+	var w worstCaseFinder
+	w.findHot(&re.Expr, 0)
+	if w.hot == nil {
+		return exampleString(re.Expr)
+	}
+
+	prefix, _ := w.prefixOf(&re.Expr)
+	pump := exampleString(w.hot.Args[0])
+	if pump == "" {
+		pump = "a"
+	}
+	suffix := "!"
+	for _, s := range []string{"!", "~", "\x00"} {
+		if !strings.Contains(pump, s) {
+			suffix = s
+			break
+		}
+	}
+
+	n := (length - len(prefix) - len(suffix)) / len(pump)
+	if n < 1 {
+		n = 1
+	}
+	return prefix + strings.Repeat(pump, n) + suffix
+}
+
+type worstCaseFinder struct {
+	hot      *syntax.Expr
+	hotDepth int
+}
+
+// findHot finds the most nested unbounded quantifier.
+func (w *worstCaseFinder) findHot(e *syntax.Expr, depth int) {
+	if isUnboundedRepeat(*e) {
+		depth++
+		if depth > w.hotDepth {
+			w.hot = e
+			w.hotDepth = depth
+		}
+	}
+	for i := range e.Args {
+		w.findHot(&e.Args[i], depth)
+	}
+}
+
+// prefixOf returns an example string that leads to the hot expression.
+func (w *worstCaseFinder) prefixOf(e *syntax.Expr) (string, bool) {
+	if e == w.hot {
+		return "", true
+	}
+	switch e.Op {
+	case syntax.OpConcat:
+		for i := range e.Args {
+			if s, ok := w.prefixOf(&e.Args[i]); ok {
+				var b strings.Builder
+				for _, prev := range e.Args[:i] {
+					writeExample(&b, prev)
+				}
+				b.WriteString(s)
+				return b.String(), true
+			}
+		}
+		return "", false
+	case syntax.OpString:
+		return "", false
+	default:
+		for i := range e.Args {
+			if s, ok := w.prefixOf(&e.Args[i]); ok {
+				return s, true
+			}
+		}
+		return "", false
+	}
+}
+
+func isUnboundedRepeat(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		_, max := thompson.RepeatBounds(e.Args[1].Value, maxCount)
+		return max == -1
+	default:
+		return false
+	}
+}
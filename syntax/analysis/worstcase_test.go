@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestWorstCaseInput(t *testing.T) {
+	tests := []struct {
+		pattern string
+		length  int
+		want    string
+	}{
+		{`(a+)+$`, 10, `aaaaaaaaa!`},
+		{`^(a|a)*$`, 6, `aaaaa!`},
+		{`^x(\d+\s?)+y`, 8, `x000000!`},
+		{`^[a-z]+@([a-z]+\.)+com$`, 12, `a@aaaaaaaaa!`},
+		{`(?:!+)+`, 4, `!!!~`},
+		{`abc`, 10, `abc`},
+		{`a{2}b?`, 10, `aab`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := WorstCaseInput(re, test.length)
+		if have != test.want {
+			t.Errorf("WorstCaseInput(%q, %d):\nhave: %q\nwant: %q",
+				test.pattern, test.length, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package syntax
+
+// exprArena is a chunked Expr allocator that is reused between parses.
+//
+// Objects are never moved or freed: after the reset, they're handed out
+// again together with their Args backing arrays, so the steady state
+// parsing doesn't allocate at all.
+type exprArena struct {
+	chunks [][]Expr
+	chunk  int // Current chunk index
+	used   int // Number of allocated objects inside the current chunk
+	total  int // Number of allocated objects since the last reset
+}
+
+const exprArenaChunkSize = 256
+
+func (a *exprArena) reset() {
+	a.chunk = 0
+	a.used = 0
+	a.total = 0
+}
+
+// release resets the arena and clears all objects.
+// Args backing arrays are kept, but their contents are cleared too.
+func (a *exprArena) release() {
+	a.reset()
+	for _, chunk := range a.chunks {
+		for i := range chunk {
+			args := chunk[i].Args[:cap(chunk[i].Args)]
+			for j := range args {
+				args[j] = Expr{}
+			}
+			chunk[i] = Expr{Args: args[:0]}
+		}
+	}
+}
+
+func (a *exprArena) alloc() *Expr {
+	if len(a.chunks) == 0 {
+		a.chunks = append(a.chunks, make([]Expr, exprArenaChunkSize))
+	}
+	if a.used == len(a.chunks[a.chunk]) {
+		a.chunk++
+		a.used = 0
+		if a.chunk == len(a.chunks) {
+			size := 2 * len(a.chunks[a.chunk-1])
+			a.chunks = append(a.chunks, make([]Expr, size))
+		}
+	}
+	e := &a.chunks[a.chunk][a.used]
+	a.used++
+	a.total++
+	return e
+}
@@ -7,6 +7,56 @@ import (
 type Regexp struct {
 	Pattern string
 	Expr    Expr
+
+	// Warnings is a list of non-fatal issues found during the parsing.
+	// See ParserOptions to learn which diagnostics can be reported.
+	Warnings []Warning
+}
+
+// Clone returns a deep copy of re.
+//
+// The copy doesn't share any memory with the parser that produced re,
+// so it stays valid after the parser is reused.
+func (re *Regexp) Clone() *Regexp {
+	clone := &Regexp{
+		Pattern: re.Pattern,
+		Expr:    re.Expr.Clone(),
+	}
+	if len(re.Warnings) != 0 {
+		clone.Warnings = append([]Warning(nil), re.Warnings...)
+	}
+	return clone
+}
+
+// ExprAt returns the innermost expression that covers the byte offset
+// along with its ancestors, starting from the root expression.
+//
+// Zero-width expressions (like an empty alternation branch) are never returned.
+// If offset is outside of the pattern, a zero Expr is returned.
+func (re *Regexp) ExprAt(offset int) (Expr, []Expr) {
+	if !exprCovers(re.Expr, offset) {
+		return Expr{}, nil
+	}
+	var ancestors []Expr
+	e := re.Expr
+	for {
+		next := -1
+		for i, a := range e.Args {
+			if exprCovers(a, offset) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return e, ancestors
+		}
+		ancestors = append(ancestors, e)
+		e = e.Args[next]
+	}
+}
+
+func exprCovers(e Expr, offset int) bool {
+	return int(e.Begin()) <= offset && offset < int(e.End())
 }
 
 type RegexpPCRE struct {
@@ -43,15 +93,48 @@ type Expr struct {
 	//
 	// Usually, that value is identical to src[Begin():End()],
 	// but this is not true for programmatically generated objects.
+	//
+	// The parser sets values to the pattern substrings, so they
+	// share the pattern memory and never need to be interned.
 	Value string
 }
 
+// Clone returns a deep copy of e.
+func (e Expr) Clone() Expr {
+	if len(e.Args) != 0 {
+		args := make([]Expr, len(e.Args))
+		for i, a := range e.Args {
+			args[i] = a.Clone()
+		}
+		e.Args = args
+	} else {
+		e.Args = nil
+	}
+	return e
+}
+
 // Begin returns expression leftmost offset.
 func (e Expr) Begin() uint16 { return e.Pos.Begin }
 
 // End returns expression rightmost offset.
 func (e Expr) End() uint16 { return e.Pos.End }
 
+// IsEmptyMatch reports whether e is an empty expression.
+//
+// Both OpEmptyMatch and OpConcat with 0 Args are considered to be empty,
+// so it works for the trees produced with ParserOptions.EmptyConcat too.
+func (e Expr) IsEmptyMatch() bool {
+	return e.Op == OpEmptyMatch || (e.Op == OpConcat && len(e.Args) == 0)
+}
+
+// QuotedLiteral returns the text enclosed by a `\Q...\E` quote,
+// without the delimiters. For `\Q.?\E` it returns `.?`.
+//
+// Should only be called on OpQuote expressions.
+func (e Expr) QuotedLiteral() string {
+	return e.Args[0].Value
+}
+
 // LastArg returns expression last argument.
 //
 // Should not be called on expressions that may have 0 arguments.
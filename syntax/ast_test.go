@@ -0,0 +1,110 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprAt(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+		want    string
+	}{
+		{`abc`, 1, `Literal < Char[b]`},
+		{`a|(b+)`, 3, `Alt < Capture < Plus < Char[b]`},
+		{`a|(b+)`, 4, `Alt < Capture < Plus[b+]`},
+		{`a|(b+)`, 5, `Alt < Capture[(b+)]`},
+		{`a|(b+)`, 1, `Alt[a|(b+)]`},
+		{`x[^a-z]`, 4, `Concat < NegCharClass < CharRange[a-z]`},
+		{`\pL`, 2, `EscapeUni < String[L]`},
+		{`abc`, 3, ``},
+		{`abc`, -1, ``},
+		{``, 0, ``},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		e, ancestors := re.ExprAt(test.offset)
+		have := formatExprPath(e, ancestors)
+		if have != test.want {
+			t.Errorf("ExprAt(%q, %d):\nhave: %s\nwant: %s", test.pattern, test.offset, have, test.want)
+		}
+	}
+}
+
+// formatExprPath prints the ancestors ops followed by the e op and value.
+func formatExprPath(e Expr, ancestors []Expr) string {
+	if e.Value == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(ancestors)+1)
+	for _, x := range ancestors {
+		parts = append(parts, strings.TrimPrefix(x.Op.String(), "Op"))
+	}
+	parts = append(parts, strings.TrimPrefix(e.Op.String(), "Op")+"["+e.Value+"]")
+	return strings.Join(parts, " < ")
+}
+
+func TestQuotedLiteral(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`\Q.?\E`, `.?`},
+		{`\Q\E`, ``},
+		{`\Qa\b`, `a\b`},
+		{`\Q\\E`, `\`},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if re.Expr.Op != OpQuote {
+			t.Fatalf("%q: expected a quote, got %s", test.pattern, re.Expr.Op)
+		}
+		if have := re.Expr.QuotedLiteral(); have != test.want {
+			t.Errorf("QuotedLiteral(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestIsEmptyMatch(t *testing.T) {
+	for _, opts := range []*ParserOptions{nil, {EmptyConcat: true}} {
+		p := NewParser(opts)
+		wantOp := OpEmptyMatch
+		if opts != nil {
+			wantOp = OpConcat
+		}
+		for _, pattern := range []string{``, `()`, `x|`, `(|x)`} {
+			re, err := p.Parse(pattern)
+			if err != nil {
+				t.Fatalf("parse(%q): %v", pattern, err)
+			}
+			var empty []Expr
+			var walk func(e Expr)
+			walk = func(e Expr) {
+				if e.IsEmptyMatch() {
+					empty = append(empty, e)
+				}
+				for _, a := range e.Args {
+					walk(a)
+				}
+			}
+			walk(re.Expr)
+			if len(empty) != 1 {
+				t.Fatalf("%q: expected 1 empty expr, found %d", pattern, len(empty))
+			}
+			if empty[0].Op != wantOp || empty[0].Value != "" {
+				t.Errorf("%q: unexpected empty expr: %s %q", pattern, empty[0].Op, empty[0].Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package syntax
+
+import (
+	"strconv"
+)
+
+// BackrefRules selects how `\N` escapes are disambiguated
+// between backreferences and octal or literal char escapes.
+type BackrefRules byte
+
+const (
+	// BackrefsUnresolved parses all `\N` escapes as OpEscapeOctal
+	// (or OpEscapeChar for `\8` and `\9`) and doesn't mark backreferences.
+	BackrefsUnresolved BackrefRules = iota
+
+	// BackrefsRE2 follows Go regexp: backreferences are not supported,
+	// so `\1`-`\9` are rejected; `\12` and alike are octal escapes.
+	BackrefsRE2
+
+	// BackrefsPCRE follows PCRE: outside of the char classes, `\N` is
+	// a backreference if N is less than 10, if it starts with 8 or 9
+	// or if the pattern has at least N capture groups.
+	BackrefsPCRE
+
+	// BackrefsJS follows JavaScript Annex B rules: outside of the char
+	// classes, `\N` is a backreference if the pattern has at least N
+	// capture groups, otherwise it's a legacy octal or a literal escape.
+	BackrefsJS
+)
+
+// resolveBackrefs marks the backreference escapes with FormEscapeBackref.
+//
+// Only the digits that were read by the lexer are considered,
+// so `\18` is always treated as `\1` followed by `8`.
+func (p *Parser) resolveBackrefs(e *Expr) {
+	if p.opts.Backrefs == BackrefsUnresolved {
+		return
+	}
+	p.markBackrefs(e, countCaptures(*e), false)
+}
+
+func (p *Parser) markBackrefs(e *Expr, groups int, insideClass bool) {
+	switch e.Op {
+	case OpCharClass, OpNegCharClass:
+		insideClass = true
+	case OpEscapeOctal, OpEscapeChar:
+		if p.isBackref(*e, groups, insideClass) {
+			e.Form = FormEscapeBackref
+		}
+		return
+	}
+	for i := range e.Args {
+		p.markBackrefs(&e.Args[i], groups, insideClass)
+	}
+}
+
+func (p *Parser) isBackref(e Expr, groups int, insideClass bool) bool {
+	digits := e.Args[0].Value
+	if e.Op == OpEscapeChar && digits != "8" && digits != "9" {
+		return false
+	}
+	if digits[0] == '0' {
+		return false
+	}
+
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return false
+	}
+	switch p.opts.Backrefs {
+	case BackrefsRE2:
+		if len(digits) == 1 {
+			throw(CodeUnsupportedBackref, e.Pos, "backreferences are not supported")
+		}
+		return false
+	case BackrefsPCRE:
+		return !insideClass && (n < 10 || digits[0] >= '8' || n <= groups)
+	case BackrefsJS:
+		return !insideClass && n <= groups
+	default:
+		return false
+	}
+}
+
+func countCaptures(e Expr) int {
+	n := 0
+	if e.Op == OpCapture || e.Op == OpNamedCapture {
+		n++
+	}
+	for _, a := range e.Args {
+		n += countCaptures(a)
+	}
+	return n
+}
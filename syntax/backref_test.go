@@ -0,0 +1,56 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserBackrefs(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rules   BackrefRules
+		want    string
+	}{
+		{`(a)\1\2\0`, BackrefsUnresolved, ``},
+		{`(a)\1\2\0\8`, BackrefsPCRE, `\1 \2 \8`},
+		{`(a)[\1\8]`, BackrefsPCRE, ``},
+		{`\12(a)`, BackrefsPCRE, ``},
+		{`\12((((((((((((a))))))))))))`, BackrefsPCRE, `\12`},
+		{`(a)\1\2\0\8`, BackrefsJS, `\1`},
+		{`\2(a)(b)`, BackrefsJS, `\2`},
+		{`(a)\12\0`, BackrefsRE2, ``},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&ParserOptions{Backrefs: test.rules})
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var backrefs []string
+		var walk func(e Expr)
+		walk = func(e Expr) {
+			if e.Form == FormEscapeBackref {
+				backrefs = append(backrefs, e.Value)
+			}
+			for _, a := range e.Args {
+				walk(a)
+			}
+		}
+		walk(re.Expr)
+		have := strings.Join(backrefs, " ")
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestParserBackrefsRE2Errors(t *testing.T) {
+	p := NewParser(&ParserOptions{Backrefs: BackrefsRE2})
+	for _, pattern := range []string{`(a)\1`, `\8`, `[\1]`} {
+		_, err := p.Parse(pattern)
+		if err == nil || !strings.Contains(err.Error(), "backreferences are not supported") {
+			t.Errorf("parse(%q): unexpected error: %v", pattern, err)
+		}
+	}
+}
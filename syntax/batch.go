@@ -0,0 +1,77 @@
+package syntax
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchOptions configure ParseAll.
+type BatchOptions struct {
+	Parser ParserOptions
+
+	// Workers is the number of the parsing goroutines.
+	// The default is GOMAXPROCS.
+	Workers int
+
+	// Analyze is called for every successfully parsed pattern,
+	// its result is stored in BatchResult.Value.
+	//
+	// The re is valid only during the call. When Analyze is set,
+	// the ASTs are not retained, so the memory usage doesn't depend
+	// on the number of patterns.
+	// Analyze is called concurrently from the worker goroutines.
+	Analyze func(re *Regexp) interface{}
+}
+
+// BatchResult is a ParseAll result for a single pattern.
+type BatchResult struct {
+	// Regexp is the parsed pattern.
+	// It's nil if BatchOptions.Analyze is set.
+	Regexp *Regexp
+
+	// Value is the BatchOptions.Analyze result.
+	Value interface{}
+
+	Err error
+}
+
+// ParseAll parses the patterns concurrently.
+// Results are returned in the patterns order.
+func ParseAll(patterns []string, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(patterns))
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(patterns) {
+		workers = len(patterns)
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			p := NewParser(&opts.Parser)
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(patterns) {
+					return
+				}
+				re, err := p.Parse(patterns[i])
+				switch {
+				case err != nil:
+					results[i].Err = err
+				case opts.Analyze != nil:
+					results[i].Value = opts.Analyze(re)
+				default:
+					results[i].Regexp = re.Clone()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
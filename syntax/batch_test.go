@@ -0,0 +1,49 @@
+package syntax
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseAll(t *testing.T) {
+	var patterns []string
+	for i := 0; i < 100; i++ {
+		if i%10 == 0 {
+			patterns = append(patterns, "(")
+		} else {
+			patterns = append(patterns, fmt.Sprintf("x{%d}", i))
+		}
+	}
+
+	results := ParseAll(patterns, BatchOptions{Workers: 4})
+	if len(results) != len(patterns) {
+		t.Fatalf("results:\nhave: %d\nwant: %d", len(results), len(patterns))
+	}
+	for i, r := range results {
+		if i%10 == 0 {
+			if r.Err == nil {
+				t.Errorf("%q: expected an error", patterns[i])
+			}
+			continue
+		}
+		if r.Err != nil || r.Regexp.Pattern != patterns[i] || r.Value != nil {
+			t.Errorf("%q: unexpected result: %+v", patterns[i], r)
+		}
+	}
+
+	results = ParseAll(patterns, BatchOptions{
+		Analyze: func(re *Regexp) interface{} { return re.Expr.Op },
+	})
+	for i, r := range results {
+		if i%10 == 0 {
+			continue
+		}
+		if r.Err != nil || r.Regexp != nil || r.Value != OpRepeat {
+			t.Errorf("%q: unexpected result: %+v", patterns[i], r)
+		}
+	}
+
+	if results := ParseAll(nil, BatchOptions{}); len(results) != 0 {
+		t.Errorf("unexpected results for no patterns: %v", results)
+	}
+}
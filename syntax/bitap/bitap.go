@@ -0,0 +1,428 @@
+// Package bitap implements the shift-or (bitap) matching
+// for the short fixed-length patterns.
+package bitap
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// MaxLen is the max number of the pattern positions.
+const MaxLen = 64
+
+// Matcher is a compiled shift-or matcher.
+// It's safe for concurrent use.
+type Matcher struct {
+	sets []charset.RuneSet
+
+	// ascii are the precomputed masks for the ASCII runes.
+	// The ith bit is 0 if the ith position accepts the rune.
+	ascii [utf8.RuneSelf]uint64
+
+	// groups are the [begin, end) position ranges of the capture groups,
+	// the groups that never participate in a match, like `(a){0}`,
+	// have {-1, -1} ranges.
+	// names are the group names, "" for the unnamed groups.
+	groups  [][2]int
+	names   []string
+	indexes map[syntax.Position]int
+
+	// prefix is the literal that every match starts with.
+	prefix   string
+	searcher Searcher
+}
+
+// Searcher finds the literal pattern prefixes inside the input.
+//
+// The matcher uses it to skip the input parts that can't contain
+// a match. Implement it to plug in an accelerated substring search.
+type Searcher interface {
+	// Index returns the index of the first literal instance in s,
+	// or -1 if literal is not present in s.
+	Index(s, literal string) int
+}
+
+// DefaultSearcher is a Searcher that uses strings.Index.
+var DefaultSearcher Searcher = stringsSearcher{}
+
+type stringsSearcher struct{}
+
+func (stringsSearcher) Index(s, literal string) int { return strings.Index(s, literal) }
+
+// SetSearcher replaces the DefaultSearcher used for the literal prefixes.
+//
+// It must be called before the matcher is used.
+func (m *Matcher) SetSearcher(s Searcher) {
+	m.searcher = s
+}
+
+// Prefix returns the literal that every match starts with.
+// The Searcher is only used if it's not empty.
+func (m *Matcher) Prefix() string {
+	return m.prefix
+}
+
+// Compile returns a matcher for re.
+//
+// Only the fixed-length patterns are supported: a sequence of at most
+// MaxLen chars, char classes, escapes and dots. `\Q...\E` quotes and
+// fixed repetitions like `\d{3}` are expanded. For other patterns
+// an error is returned.
+//
+// Capture groups are supported, as they always have fixed offsets.
+// Like in Go regexp, a repeated group captures its last iteration.
+//
+// The dot doesn't match a newline, flags are not supported.
+func Compile(re *syntax.Regexp) (*Matcher, error) {
+	m := &Matcher{indexes: make(map[syntax.Position]int)}
+	if err := m.compile(re.Expr); err != nil {
+		return nil, err
+	}
+	if len(m.sets) == 0 {
+		return nil, errors.New("empty pattern")
+	}
+	for ch := range m.ascii {
+		m.ascii[ch] = m.mask(rune(ch))
+	}
+	var prefix strings.Builder
+	for _, s := range m.sets {
+		if len(s) != 1 || s[0].Lo != s[0].Hi {
+			break
+		}
+		prefix.WriteRune(s[0].Lo)
+	}
+	m.prefix = prefix.String()
+	m.searcher = DefaultSearcher
+	return m, nil
+}
+
+func (m *Matcher) compile(e syntax.Expr) error {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral, syntax.OpGroup:
+		for _, a := range e.Args {
+			if err := m.compile(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpEmptyMatch, syntax.OpComment:
+		return nil
+	case syntax.OpCapture, syntax.OpNamedCapture:
+		i, ok := m.indexes[e.Pos]
+		if !ok {
+			i = len(m.groups)
+			m.indexes[e.Pos] = i
+			name := ""
+			if e.Op == syntax.OpNamedCapture {
+				name = e.Args[1].Value
+			}
+			m.groups = append(m.groups, [2]int{})
+			m.names = append(m.names, name)
+		}
+		begin := len(m.sets)
+		if err := m.compile(e.Args[0]); err != nil {
+			return err
+		}
+		m.groups[i] = [2]int{begin, len(m.sets)}
+		return nil
+	case syntax.OpQuote:
+		for _, ch := range e.QuotedLiteral() {
+			if err := m.add(charset.Of(ch)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpRepeat:
+		min, max, ok := fixedCount(e.Args[1].Value)
+		if !ok || min != max {
+			return errors.New("variable repetition " + e.Value + " is not supported")
+		}
+		if min == 0 {
+			m.addUnmatchedGroups(e.Args[0])
+			return nil
+		}
+		for i := 0; i < min; i++ {
+			if err := m.compile(e.Args[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s, ok := charset.FromExpr(e)
+	if !ok {
+		return errors.New(e.Value + " is not supported")
+	}
+	return m.add(s)
+}
+
+// addUnmatchedGroups registers the e capture groups that
+// never participate in a match, so the later groups keep their indexes.
+func (m *Matcher) addUnmatchedGroups(e syntax.Expr) {
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		if _, ok := m.indexes[e.Pos]; !ok {
+			m.indexes[e.Pos] = len(m.groups)
+			name := ""
+			if e.Op == syntax.OpNamedCapture {
+				name = e.Args[1].Value
+			}
+			m.groups = append(m.groups, [2]int{-1, -1})
+			m.names = append(m.names, name)
+		}
+	}
+	for _, a := range e.Args {
+		m.addUnmatchedGroups(a)
+	}
+}
+
+func (m *Matcher) add(s charset.RuneSet) error {
+	if len(m.sets) == MaxLen {
+		return errors.New("pattern is longer than 64 positions")
+	}
+	m.sets = append(m.sets, s)
+	return nil
+}
+
+// fixedCount parses a `{n}` or `{n,m}` repetition count.
+func fixedCount(s string) (min, max int, ok bool) {
+	s = s[1 : len(s)-1]
+	min, n := atoi(s)
+	if n == 0 {
+		return 0, 0, false
+	}
+	if n == len(s) {
+		return min, min, true
+	}
+	if s[n] != ',' {
+		return 0, 0, false
+	}
+	max, k := atoi(s[n+1:])
+	return min, max, k != 0 && n+1+k == len(s)
+}
+
+// atoi parses the decimal digits prefix of s and returns
+// its value and length. The value is saturated to MaxLen+1.
+func atoi(s string) (value, n int) {
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		if value <= MaxLen {
+			value = value*10 + int(s[n]-'0')
+		}
+		n++
+	}
+	if value > MaxLen {
+		value = MaxLen + 1
+	}
+	return value, n
+}
+
+// mask returns a bitmask with 0 bits for the positions that accept ch.
+func (m *Matcher) mask(ch rune) uint64 {
+	mask := ^uint64(0)
+	for i, s := range m.sets {
+		if s.Contains(ch) {
+			mask &^= 1 << uint(i)
+		}
+	}
+	return mask
+}
+
+// Index returns the leftmost match location inside s.
+// If there is no match, both begin and end are -1.
+func (m *Matcher) Index(s string) (begin, end int) {
+	begin, end = -1, -1
+	m.scan(s, func(b, e int) bool {
+		begin, end = b, e
+		return false
+	})
+	return begin, end
+}
+
+// NumSubexp returns the number of the capture groups.
+func (m *Matcher) NumSubexp() int {
+	return len(m.groups)
+}
+
+// SubexpNames returns the capture group names, like regexp.Regexp.SubexpNames.
+// The name for the whole pattern and the unnamed groups is "".
+func (m *Matcher) SubexpNames() []string {
+	return append([]string{""}, m.names...)
+}
+
+// FindSubmatchIndex returns the leftmost match location and the
+// locations of its submatches, like regexp.Regexp.FindStringSubmatchIndex.
+// If there is no match, nil is returned.
+func (m *Matcher) FindSubmatchIndex(s string) []int {
+	begin, end := m.Index(s)
+	if begin == -1 {
+		return nil
+	}
+	return m.submatches(s, begin, end)
+}
+
+// submatches returns the submatch locations for the s[begin:end] match.
+func (m *Matcher) submatches(s string, begin, end int) []int {
+	loc := []int{begin, end}
+	if len(m.groups) == 0 {
+		return loc
+	}
+	// offsets[i] is the s offset of the ith pattern position.
+	offsets := make([]int, 0, len(m.sets)+1)
+	offset := begin
+	for range m.sets {
+		offsets = append(offsets, offset)
+		_, size := utf8.DecodeRuneInString(s[offset:])
+		offset += size
+	}
+	offsets = append(offsets, end)
+	for _, g := range m.groups {
+		if g[0] == -1 {
+			loc = append(loc, -1, -1)
+			continue
+		}
+		loc = append(loc, offsets[g[0]], offsets[g[1]])
+	}
+	return loc
+}
+
+// Split slices s into the substrings separated by the matches,
+// like regexp.Regexp.Split does.
+//
+// n limits the number of the returned substrings: if n > 0,
+// at most n substrings are returned, the last one is the unsplit
+// remainder. If n == 0, the result is nil. If n < 0, all
+// substrings are returned.
+func (m *Matcher) Split(s string, n int) []string {
+	return m.split(s, n, false)
+}
+
+// SplitWithGroups is like Split, but the submatches of every separator
+// are inserted after the substring that precedes it, like the JavaScript
+// String.prototype.split does for the patterns with capture groups.
+// The submatches are not counted by n.
+func (m *Matcher) SplitWithGroups(s string, n int) []string {
+	return m.split(s, n, true)
+}
+
+func (m *Matcher) split(s string, n int, withGroups bool) []string {
+	if n == 0 {
+		return nil
+	}
+	var parts []string
+	pieces := 0
+	offset := 0
+	for pieces != n-1 {
+		begin, end := m.Index(s[offset:])
+		if begin == -1 {
+			break
+		}
+		begin += offset
+		end += offset
+		parts = append(parts, s[offset:begin])
+		pieces++
+		if withGroups {
+			loc := m.submatches(s, begin, end)
+			for i := 2; i < len(loc); i += 2 {
+				if loc[i] == -1 {
+					// JavaScript inserts undefined for such groups.
+					parts = append(parts, "")
+					continue
+				}
+				parts = append(parts, s[loc[i]:loc[i+1]])
+			}
+		}
+		offset = end
+	}
+	return append(parts, s[offset:])
+}
+
+// FindAllOverlapping returns the locations of all matches inside s,
+// including the overlapping ones, ordered by their start offsets.
+// As the patterns have a fixed length, there is
+// at most one match per start offset.
+func (m *Matcher) FindAllOverlapping(s string) [][]int {
+	var locs [][]int
+	m.scan(s, func(begin, end int) bool {
+		locs = append(locs, []int{begin, end})
+		return true
+	})
+	return locs
+}
+
+// scan calls report for every match in s until it returns false.
+func (m *Matcher) scan(s string, report func(begin, end int) bool) {
+	if m.prefix != "" {
+		m.scanPrefix(s, report)
+		return
+	}
+	accept := uint64(1) << uint(len(m.sets)-1)
+	state := ^uint64(0)
+	for i := 0; i < len(s); {
+		ch, size := rune(s[i]), 1
+		var mask uint64
+		if ch < utf8.RuneSelf {
+			mask = m.ascii[ch]
+		} else {
+			ch, size = utf8.DecodeRuneInString(s[i:])
+			mask = m.mask(ch)
+		}
+		i += size
+		state = state<<1 | mask
+		if state&accept == 0 {
+			begin := i
+			for n := 0; n < len(m.sets); n++ {
+				_, size := utf8.DecodeLastRuneInString(s[:begin])
+				begin -= size
+			}
+			if !report(begin, i) {
+				return
+			}
+		}
+	}
+}
+
+// scanPrefix finds the prefix candidates with the searcher
+// and checks the rest of the pattern at every candidate.
+func (m *Matcher) scanPrefix(s string, report func(begin, end int) bool) {
+	for start := 0; start < len(s); {
+		i := m.searcher.Index(s[start:], m.prefix)
+		if i == -1 {
+			break
+		}
+		begin := start + i
+		if end, ok := m.MatchAt(s, begin); ok && !report(begin, end) {
+			return
+		}
+		_, size := utf8.DecodeRuneInString(s[begin:])
+		start = begin + size
+	}
+}
+
+// MatchAt matches the pattern at s[offset:] and returns the match end.
+// Unlike Index, it only accepts the matches that start exactly at offset.
+// An offset outside of [0, len(s)] never matches.
+func (m *Matcher) MatchAt(s string, offset int) (end int, ok bool) {
+	if offset < 0 || offset > len(s) {
+		return -1, false
+	}
+	for _, set := range m.sets {
+		if offset == len(s) {
+			return -1, false
+		}
+		ch, size := utf8.DecodeRuneInString(s[offset:])
+		if !set.Contains(ch) {
+			return -1, false
+		}
+		offset += size
+	}
+	return offset, true
+}
+
+// MatchString reports whether s contains a match.
+func (m *Matcher) MatchString(s string) bool {
+	_, end := m.Index(s)
+	return end != -1
+}
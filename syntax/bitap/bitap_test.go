@@ -0,0 +1,226 @@
+package bitap
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestMatcher(t *testing.T) {
+	patterns := []string{
+		`abc`,
+		`a.c`,
+		`[a-c]\d{2}`,
+		`\Qa.\Eb`,
+		`x[^ab]{3}y`,
+		`ж[яa]`,
+		`aaa{2}`,
+		`(?:ab){3}`,
+		`(a)(.)`,
+		`(?:(a)b){2}`,
+		`x(?P<n>\d(\d))`,
+		`(a){0}(b)`,
+		`(70){0}[a]`,
+		`x(?:(y)(?P<z>1)){0}(.)`,
+	}
+	alphabet := []string{"a", "b", "c", "x", "y", "1", "2", ".", "ж", "я", "\n"}
+
+	p := syntax.NewParser(nil)
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		m, err := Compile(re)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", pattern, err)
+			continue
+		}
+		std := regexp.MustCompile(pattern)
+		for i := 0; i < 2000; i++ {
+			var b strings.Builder
+			for n := rng.Intn(12); n > 0; n-- {
+				b.WriteString(alphabet[rng.Intn(len(alphabet))])
+			}
+			s := b.String()
+			want := []int{-1, -1}
+			if loc := std.FindStringIndex(s); loc != nil {
+				want = loc
+			}
+			begin, end := m.Index(s)
+			if begin != want[0] || end != want[1] {
+				t.Fatalf("%q Index(%q):\nhave: %d %d\nwant: %v", pattern, s, begin, end, want)
+			}
+			if begin != -1 {
+				if end, ok := m.MatchAt(s, begin); !ok || end != want[1] {
+					t.Fatalf("%q MatchAt(%q, %d):\nhave: %d %v\nwant: %d", pattern, s, begin, end, ok, want[1])
+				}
+			}
+			if have, want := fmt.Sprint(m.FindSubmatchIndex(s)), fmt.Sprint(std.FindStringSubmatchIndex(s)); have != want {
+				t.Fatalf("%q FindSubmatchIndex(%q):\nhave: %s\nwant: %s", pattern, s, have, want)
+			}
+			for _, n := range []int{-1, 0, 2} {
+				if have, want := fmt.Sprintf("%q", m.Split(s, n)), fmt.Sprintf("%q", std.Split(s, n)); have != want {
+					t.Fatalf("%q Split(%q, %d):\nhave: %s\nwant: %s", pattern, s, n, have, want)
+				}
+			}
+		}
+	}
+}
+
+func TestMatchAtOutOfRange(t *testing.T) {
+	p := syntax.NewParser(nil)
+	for _, pattern := range []string{`a`, `[ab].`} {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		m, err := Compile(re)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		for _, offset := range []int{-1, 4, 100} {
+			if end, ok := m.MatchAt("abc", offset); ok || end != -1 {
+				t.Errorf("%q MatchAt(%q, %d):\nhave: %d %v\nwant: -1 false", pattern, "abc", offset, end, ok)
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a+`, `a+ is not supported`},
+		{`a|b`, `a|b is not supported`},
+		{`^a`, `^ is not supported`},
+		{`(?=a)`, `(?=a) is not supported`},
+		{`a{1,2}`, `variable repetition a{1,2} is not supported`},
+		{`a{65}`, `pattern is longer than 64 positions`},
+		{`(?:[ab]{8}){9}`, `pattern is longer than 64 positions`},
+		{``, `empty pattern`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Compile(re)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Compile(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
+
+func TestFindAllOverlapping(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    string
+	}{
+		{`aa`, "aaaa", `[[0 2] [1 3] [2 4]]`},
+		{`.a`, "aaaa", `[[0 2] [1 3] [2 4]]`},
+		{`ж.`, "жжж", `[[0 4] [2 6]]`},
+		{`a.a`, "ababa", `[[0 3] [2 5]]`},
+		{`x`, "aa", `[]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", test.pattern, err)
+		}
+		have := fmt.Sprint(m.FindAllOverlapping(test.input))
+		if have != test.want {
+			t.Errorf("%q FindAllOverlapping(%q):\nhave: %s\nwant: %s", test.pattern, test.input, have, test.want)
+		}
+	}
+}
+
+func TestSplitWithGroups(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		n       int
+		want    string
+	}{
+		{`,`, "a,b,c", -1, `["a" "b" "c"]`},
+		{`(,)`, "a,b,c", -1, `["a" "," "b" "," "c"]`},
+		{`(\d)(x)`, "a1xb2c", -1, `["a" "1" "x" "b2c"]`},
+		{`-(\d)-`, "a-1-b-2-c", 2, `["a" "1" "b-2-c"]`},
+		{`(-)`, "-a-", -1, `["" "-" "a" "-" ""]`},
+		{`(x){0}(-)`, "a-b", -1, `["a" "" "-" "b"]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.pattern, err)
+			continue
+		}
+		have := fmt.Sprintf("%q", m.SplitWithGroups(test.input, test.n))
+		if have != test.want {
+			t.Errorf("%q SplitWithGroups(%q, %d):\nhave: %s\nwant: %s", test.pattern, test.input, test.n, have, test.want)
+		}
+	}
+}
+
+type countingSearcher struct {
+	calls int
+}
+
+func (s *countingSearcher) Index(haystack, literal string) int {
+	s.calls++
+	return strings.Index(haystack, literal)
+}
+
+func TestSearcher(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  string
+	}{
+		{`ab\d`, "ab"},
+		{`жa.`, "жa"},
+		{`\d`, ""},
+		{`a[ab]c`, "a"},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", test.pattern, err)
+		}
+		if m.Prefix() != test.prefix {
+			t.Errorf("%q prefix:\nhave: %q\nwant: %q", test.pattern, m.Prefix(), test.prefix)
+		}
+		searcher := &countingSearcher{}
+		m.SetSearcher(searcher)
+		m.MatchString("xx ab1 abc жab ac")
+		if (searcher.calls != 0) != (test.prefix != "") {
+			t.Errorf("%q: unexpected %d searcher calls", test.pattern, searcher.calls)
+		}
+	}
+}
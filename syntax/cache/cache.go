@@ -0,0 +1,95 @@
+// Package cache implements a parsed patterns cache.
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Cache is a least recently used cache of the parsed patterns.
+//
+// It's safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	items map[key]*list.Element
+	lru   list.List // Front is the most recently used
+	pools map[syntax.ParserOptions]*syntax.ParserPool
+}
+
+type key struct {
+	pattern string
+	opts    syntax.ParserOptions
+}
+
+type entry struct {
+	key key
+	re  *syntax.Regexp
+	err error
+}
+
+// New returns a cache that holds up to size parse results.
+func New(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		size:  size,
+		items: make(map[key]*list.Element, size),
+		pools: make(map[syntax.ParserOptions]*syntax.ParserPool),
+	}
+}
+
+// Parse returns the cached result of the pattern parsing with opts.
+// The pattern is parsed if it's not in the cache yet.
+//
+// Parse errors are cached too. The returned Regexp is shared
+// between all callers, so it must not be modified, use Regexp.Clone
+// to get a private copy.
+func (c *Cache) Parse(pattern string, opts *syntax.ParserOptions) (*syntax.Regexp, error) {
+	k := key{pattern: pattern}
+	if opts != nil {
+		k.opts = *opts
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.items[k]; ok {
+		c.lru.MoveToFront(elem)
+		e := elem.Value.(*entry)
+		c.mu.Unlock()
+		return e.re, e.err
+	}
+	pool := c.pools[k.opts]
+	if pool == nil {
+		pool = syntax.NewParserPool(&k.opts)
+		c.pools[k.opts] = pool
+	}
+	c.mu.Unlock()
+
+	re, err := pool.Parse(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[k]; ok {
+		// Parsed concurrently by another goroutine.
+		c.lru.MoveToFront(elem)
+		e := elem.Value.(*entry)
+		return e.re, e.err
+	}
+	c.items[k] = c.lru.PushFront(&entry{key: k, re: re, err: err})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).key)
+	}
+	return re, err
+}
+
+// Len returns the number of the cached parse results.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCache(t *testing.T) {
+	c := New(2)
+
+	re1, err := c.Parse(`a+`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re2, _ := c.Parse(`a+`, &syntax.ParserOptions{})
+	if re1 != re2 {
+		t.Errorf("nil and zero options results are not shared")
+	}
+	re3, _ := c.Parse(`a+`, &syntax.ParserOptions{NoLiterals: true})
+	if re1 == re3 {
+		t.Errorf("different options results are shared")
+	}
+	if c.Len() != 2 {
+		t.Errorf("len:\nhave: %d\nwant: 2", c.Len())
+	}
+
+	// `a+` with the default options is the least recently used.
+	_, err1 := c.Parse(`(`, nil)
+	_, err2 := c.Parse(`(`, nil)
+	if err1 == nil || err1 != err2 {
+		t.Errorf("parse errors are not cached: %v %v", err1, err2)
+	}
+	if c.Len() != 2 {
+		t.Errorf("len:\nhave: %d\nwant: 2", c.Len())
+	}
+	if re, _ := c.Parse(`a+`, nil); re == re1 {
+		t.Errorf("the least recently used entry is not evicted")
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := New(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pattern := fmt.Sprintf("x{%d}", (i+j)%20)
+				re, err := c.Parse(pattern, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if re.Pattern != pattern {
+					t.Errorf("pattern mismatch:\nhave: %s\nwant: %s", re.Pattern, pattern)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if c.Len() != 10 {
+		t.Errorf("len:\nhave: %d\nwant: 10", c.Len())
+	}
+}
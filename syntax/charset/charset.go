@@ -0,0 +1,282 @@
+// Package charset implements rune set algebra for the char class analysis.
+package charset
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Range is an inclusive [Lo, Hi] runes interval.
+type Range struct {
+	Lo rune
+	Hi rune
+}
+
+// RuneSet is a set of runes.
+//
+// It's represented as a sorted list of non-overlapping, non-adjacent ranges.
+// All functions and methods of this package return normalized sets,
+// use New to create a RuneSet from an arbitrary list of ranges.
+// The zero value is an empty set.
+type RuneSet []Range
+
+// Any is a set of all runes.
+var Any = RuneSet{{0, unicode.MaxRune}}
+
+// New returns a set that contains all runes from the given ranges.
+// The ranges may overlap and go in any order.
+func New(ranges ...Range) RuneSet {
+	s := make(RuneSet, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Lo <= r.Hi {
+			s = append(s, r)
+		}
+	}
+	return s.normalize()
+}
+
+// Of returns a set that contains the given runes.
+func Of(runes ...rune) RuneSet {
+	s := make(RuneSet, len(runes))
+	for i, ch := range runes {
+		s[i] = Range{ch, ch}
+	}
+	return s.normalize()
+}
+
+// FromTable returns a set that contains all table runes.
+func FromTable(table *unicode.RangeTable) RuneSet {
+	var s RuneSet
+	for _, r := range table.R16 {
+		s = appendStrideRange(s, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		s = appendStrideRange(s, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return s.normalize()
+}
+
+// Table returns a table that contains all s runes.
+func (s RuneSet) Table() *unicode.RangeTable {
+	table := &unicode.RangeTable{}
+	for _, r := range s {
+		if r.Lo <= 0xFFFF {
+			hi := minRune(r.Hi, 0xFFFF)
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(r.Lo), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				table.LatinOffset++
+			}
+			if r.Hi == hi {
+				continue
+			}
+			r.Lo = 0x10000
+		}
+		table.R32 = append(table.R32, unicode.Range32{Lo: uint32(r.Lo), Hi: uint32(r.Hi), Stride: 1})
+	}
+	return table
+}
+
+// IsEmpty reports whether s contains no runes.
+func (s RuneSet) IsEmpty() bool { return len(s) == 0 }
+
+// Contains reports whether ch belongs to s.
+func (s RuneSet) Contains(ch rune) bool {
+	i := sort.Search(len(s), func(i int) bool {
+		return s[i].Hi >= ch
+	})
+	return i < len(s) && s[i].Lo <= ch
+}
+
+// Len returns the number of runes in s.
+func (s RuneSet) Len() int {
+	n := 0
+	for _, r := range s {
+		n += int(r.Hi-r.Lo) + 1
+	}
+	return n
+}
+
+// Equal reports whether s and other contain the same runes.
+func (s RuneSet) Equal(other RuneSet) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for i := range s {
+		if s[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a set of runes that belong to either s or other.
+func (s RuneSet) Union(other RuneSet) RuneSet {
+	result := make(RuneSet, 0, len(s)+len(other))
+	result = append(result, s...)
+	result = append(result, other...)
+	return result.normalize()
+}
+
+// Intersect returns a set of runes that belong to both s and other.
+func (s RuneSet) Intersect(other RuneSet) RuneSet {
+	var result RuneSet
+	i, j := 0, 0
+	for i < len(s) && j < len(other) {
+		lo := maxRune(s[i].Lo, other[j].Lo)
+		hi := minRune(s[i].Hi, other[j].Hi)
+		if lo <= hi {
+			result = append(result, Range{lo, hi})
+		}
+		if s[i].Hi < other[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// Subtract returns a set of runes that belong to s, but not to other.
+func (s RuneSet) Subtract(other RuneSet) RuneSet {
+	return s.Intersect(other.Negate())
+}
+
+// Negate returns a set of all runes that don't belong to s.
+func (s RuneSet) Negate() RuneSet {
+	var result RuneSet
+	next := rune(0)
+	for _, r := range s {
+		if r.Lo > next {
+			result = append(result, Range{next, r.Lo - 1})
+		}
+		next = r.Hi + 1
+	}
+	if next <= unicode.MaxRune {
+		result = append(result, Range{next, unicode.MaxRune})
+	}
+	return result
+}
+
+// Folding selects case folding rules.
+type Folding byte
+
+const (
+	// FoldNone disables case folding.
+	FoldNone Folding = iota
+
+	// FoldSimple uses Unicode simple case folding, like Go regexp and
+	// PCRE in UTF mode do. Some runes have more than one case variation:
+	// `k` matches `K` and U+212A KELVIN SIGN.
+	// Dotted and dotless i (U+0130 and U+0131) only match themselves.
+	FoldSimple
+
+	// FoldASCII only folds ASCII letters, like PCRE without UTF mode does.
+	FoldASCII
+
+	// FoldTurkic is FoldSimple with Turkic languages rules for i:
+	// `i` matches U+0130 and `I` matches U+0131.
+	FoldTurkic
+)
+
+// Fold returns s extended with all case variations of its runes,
+// as it would be matched with a case-insensitive flag.
+// It's a shorthand for s.FoldWith(FoldSimple).
+func (s RuneSet) Fold() RuneSet {
+	return s.FoldWith(FoldSimple)
+}
+
+// FoldWith returns s extended with all case variations of its runes
+// according to the f folding rules.
+func (s RuneSet) FoldWith(f Folding) RuneSet {
+	if f == FoldNone {
+		return s
+	}
+	result := append(RuneSet(nil), s...)
+	for _, r := range s {
+		lo := maxRune(r.Lo, minFold)
+		hi := minRune(r.Hi, maxFold)
+		for ch := lo; ch <= hi; ch++ {
+			result = appendFolded(result, ch, f)
+		}
+	}
+	return result.normalize()
+}
+
+func appendFolded(s RuneSet, ch rune, f Folding) RuneSet {
+	switch f {
+	case FoldASCII:
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			return append(s, Range{ch - 'a' + 'A', ch - 'a' + 'A'})
+		case ch >= 'A' && ch <= 'Z':
+			return append(s, Range{ch - 'A' + 'a', ch - 'A' + 'a'})
+		}
+		return s
+	case FoldTurkic:
+		if other, ok := turkicFolds[ch]; ok {
+			return append(s, Range{other, other})
+		}
+	}
+	for other := unicode.SimpleFold(ch); other != ch; other = unicode.SimpleFold(other) {
+		s = append(s, Range{other, other})
+	}
+	return s
+}
+
+var turkicFolds = map[rune]rune{
+	'i':      '\u0130',
+	'\u0130': 'i',
+	'I':      '\u0131',
+	'\u0131': 'I',
+}
+
+// Runes from outside of the [minFold, maxFold] range have no case variations.
+const (
+	minFold = 0x0041
+	maxFold = 0x1e943
+)
+
+func (s RuneSet) normalize() RuneSet {
+	if len(s) < 2 {
+		return s
+	}
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].Lo < s[j].Lo
+	})
+	result := s[:1]
+	for _, r := range s[1:] {
+		last := &result[len(result)-1]
+		if r.Lo <= last.Hi+1 {
+			if r.Hi > last.Hi {
+				last.Hi = r.Hi
+			}
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func appendStrideRange(s RuneSet, lo, hi, stride rune) RuneSet {
+	if stride == 1 {
+		return append(s, Range{lo, hi})
+	}
+	for ch := lo; ch <= hi; ch += stride {
+		s = append(s, Range{ch, ch})
+	}
+	return s
+}
+
+func minRune(x, y rune) rune {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func maxRune(x, y rune) rune {
+	if x > y {
+		return x
+	}
+	return y
+}
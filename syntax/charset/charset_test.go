@@ -0,0 +1,90 @@
+package charset
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestRuneSetAlgebra(t *testing.T) {
+	digits := New(Range{'0', '9'})
+	hex := New(Range{'a', 'f'}, Range{'0', '9'}, Range{'A', 'F'})
+	tests := []struct {
+		name string
+		have RuneSet
+		want RuneSet
+	}{
+		{"new", New(Range{'c', 'z'}, Range{'a', 'd'}, Range{'9', '0'}), New(Range{'a', 'z'})},
+		{"of", Of('b', 'a', 'c', 'x'), New(Range{'a', 'c'}, Range{'x', 'x'})},
+		{"union", digits.Union(Of('a')), New(Range{'0', '9'}, Range{'a', 'a'})},
+		{"union-adjacent", Of('a').Union(Of('b')), New(Range{'a', 'b'})},
+		{"intersect", hex.Intersect(New(Range{'5', 'b'})), New(Range{'5', '9'}, Range{'A', 'F'}, Range{'a', 'b'})},
+		{"intersect-empty", digits.Intersect(Of('a')), nil},
+		{"subtract", hex.Subtract(digits), New(Range{'A', 'F'}, Range{'a', 'f'})},
+		{"negate", Of('a').Negate(), New(Range{0, 'a' - 1}, Range{'b', unicode.MaxRune})},
+		{"negate-empty", RuneSet(nil).Negate(), Any},
+		{"negate-any", Any.Negate(), nil},
+		{"fold", Of('k', '1').Fold(), Of('1', 'K', 'k', '\u212A')},
+		{"fold-range", New(Range{'a', 'c'}).Fold(), New(Range{'A', 'C'}, Range{'a', 'c'})},
+	}
+	for _, test := range tests {
+		if !test.have.Equal(test.want) {
+			t.Errorf("%s:\nhave: %v\nwant: %v", test.name, test.have, test.want)
+		}
+	}
+}
+
+func TestRuneSetContains(t *testing.T) {
+	s := New(Range{'a', 'c'}, Range{'x', 'z'})
+	for _, ch := range "abcxyz" {
+		if !s.Contains(ch) {
+			t.Errorf("%q is not found in %v", ch, s)
+		}
+	}
+	for _, ch := range "0dwA{" {
+		if s.Contains(ch) {
+			t.Errorf("%q is unexpectedly found in %v", ch, s)
+		}
+	}
+	if s.Len() != 6 {
+		t.Errorf("len(%v):\nhave: %d\nwant: 6", s, s.Len())
+	}
+}
+
+func TestTable(t *testing.T) {
+	sets := []RuneSet{
+		nil,
+		Any,
+		FromTable(unicode.Greek),
+		FromTable(unicode.Han),
+		New(Range{'a', 'z'}, Range{0xFF00, 0x10010}),
+	}
+	for _, s := range sets {
+		table := s.Table()
+		if have := FromTable(table); !have.Equal(s) {
+			t.Errorf("%v table:\nhave: %v", s, have)
+		}
+		for _, ch := range []rune{0, 'a', 0xFF, 0x3A9, 0xFFFF, 0x10000, 0x20000} {
+			if unicode.Is(table, ch) != s.Contains(ch) {
+				t.Errorf("%v table: %U: is=%v", s, ch, unicode.Is(table, ch))
+			}
+		}
+		latin := 0
+		for _, r := range table.R16 {
+			if r.Hi <= unicode.MaxLatin1 {
+				latin++
+			}
+		}
+		if table.LatinOffset != latin {
+			t.Errorf("%v table: LatinOffset is %d, want %d", s, table.LatinOffset, latin)
+		}
+	}
+}
+
+func TestFromTable(t *testing.T) {
+	s := FromTable(unicode.Greek)
+	for ch := rune(0); ch < 0x10000; ch++ {
+		if s.Contains(ch) != unicode.Is(unicode.Greek, ch) {
+			t.Fatalf("%U: contains=%v", ch, s.Contains(ch))
+		}
+	}
+}
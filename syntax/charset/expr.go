@@ -0,0 +1,314 @@
+package charset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// FromExpr returns a set of runes matched by e using the RE2 tables.
+// See Tables.FromExpr for details.
+func FromExpr(e syntax.Expr) (s RuneSet, ok bool) {
+	return RE2.FromExpr(e)
+}
+
+// Dot returns a set of runes matched by `.` without the `s` flag
+// under the nl line terminators convention.
+//
+// The set excludes every char of the terminators, see Newline.IsTerminator.
+// FromExpr uses the NewlineDefault dot.
+func Dot(nl syntax.Newline) RuneSet {
+	var s RuneSet
+	for _, t := range nl.Terminators() {
+		for _, ch := range t {
+			s = append(s, Range{ch, ch})
+		}
+	}
+	return s.normalize().Negate()
+}
+
+// FromExpr returns a set of runes matched by e.
+//
+// e should be an expression that matches exactly one rune,
+// like a char, a char class, an escape sequence or a dot.
+// For other expressions ok is false.
+//
+// The dot doesn't match a newline.
+// Escapes like `\1` are considered to be backreferences outside of
+// the char classes, so they're not supported.
+// POSIX classes of FormPosixUnicode form use the PCREUnicode sets.
+// The `\p{scx=Name}` script extensions are not supported, as the
+// unicode package has no Script_Extensions tables.
+func (t *Tables) FromExpr(e syntax.Expr) (s RuneSet, ok bool) {
+	return t.FromExprFold(e, FoldNone)
+}
+
+// FromExprFold is like FromExpr, but it returns a set of runes matched
+// by e under the case-insensitive flag, using the specified folding.
+//
+// Negated classes are folded before the negation, so `(?i)[^k]`
+// doesn't match neither `k`, nor `K`, nor the Kelvin sign.
+func (t *Tables) FromExprFold(e syntax.Expr, f Folding) (s RuneSet, ok bool) {
+	if e.Op == syntax.OpEscapeOctal {
+		digits := e.Args[0].Value
+		if digits[0] != '0' && len(digits) < 3 {
+			return nil, false
+		}
+	}
+	return t.fromExpr(e, f)
+}
+
+func (t *Tables) fromExpr(e syntax.Expr, f Folding) (RuneSet, bool) {
+	var s RuneSet
+	negated := false
+
+	switch e.Op {
+	case syntax.OpDot:
+		s = Of('\n')
+		negated = true
+
+	case syntax.OpCharClass, syntax.OpNegCharClass:
+		for _, a := range e.Args {
+			elem, ok := t.fromExpr(a, f)
+			if !ok {
+				return nil, false
+			}
+			s = append(s, elem...)
+		}
+		s = s.normalize()
+		negated = e.Op == syntax.OpNegCharClass
+
+	case syntax.OpCharRange:
+		lo, ok1 := t.runeValue(e.Args[0])
+		hi, ok2 := t.runeValue(e.Args[1])
+		if !ok1 || !ok2 || lo > hi {
+			return nil, false
+		}
+		s = RuneSet{{lo, hi}}
+
+	case syntax.OpPosixClass:
+		tables := t
+		if e.Form == syntax.FormPosixUnicode {
+			tables = PCREUnicode
+		}
+		var ok bool
+		s, negated, ok = tables.posixClass(e.Value)
+		if !ok {
+			return nil, false
+		}
+
+	case syntax.OpEscapeUni:
+		name := e.Args[0].Value
+		negated = strings.HasPrefix(e.Value, `\P`)
+		if strings.HasPrefix(name, "^") {
+			negated = !negated
+			name = name[1:]
+		}
+		table := unicode.Categories[name]
+		if table == nil {
+			table = unicode.Scripts[name]
+		}
+		if script, extensions, ok := syntax.ScriptClass(name); ok && !extensions {
+			table = unicode.Scripts[script]
+		}
+		if table == nil {
+			return nil, false
+		}
+		s = FromTable(table)
+
+	default:
+		var ok bool
+		if e.Op == syntax.OpEscapeChar {
+			s, negated, ok = t.perlClass(e.Value)
+		}
+		if !ok {
+			ch, ok := t.runeValue(e)
+			if !ok {
+				return nil, false
+			}
+			s = RuneSet{{ch, ch}}
+		}
+	}
+
+	s = s.FoldWith(f)
+	if t.latin1 {
+		s = s.Intersect(latin1Runes)
+	}
+	if negated {
+		s = t.negate(s)
+	}
+	return s, true
+}
+
+// runeValue returns a rune that is represented by the e.
+// If e does not match exactly one rune, ok is false.
+func (t *Tables) runeValue(e syntax.Expr) (ch rune, ok bool) {
+	switch e.Op {
+	case syntax.OpChar:
+		if t.latin1 {
+			return rune(e.Value[0]), true
+		}
+		ch, _ = utf8.DecodeRuneInString(e.Value)
+		return ch, true
+	case syntax.OpEscapeMeta:
+		ch, _ = utf8.DecodeRuneInString(e.Args[0].Value)
+		return ch, true
+	case syntax.OpEscapeOctal:
+		n, err := strconv.ParseUint(e.Args[0].Value, 8, 32)
+		return rune(n), err == nil
+	case syntax.OpEscapeHex:
+		if e.Args[0].Value == "" {
+			return 0, true
+		}
+		n, err := strconv.ParseUint(e.Args[0].Value, 16, 32)
+		return rune(n), err == nil && n <= unicode.MaxRune
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\a`:
+			return '\a', true
+		case `\f`:
+			return '\f', true
+		case `\t`:
+			return '\t', true
+		case `\n`:
+			return '\n', true
+		case `\r`:
+			return '\r', true
+		case `\v`:
+			return '\v', true
+		case `\e`:
+			return 0x1b, true
+		}
+		if t.latin1 {
+			ch = rune(e.Args[0].Value[0])
+		} else {
+			ch, _ = utf8.DecodeRuneInString(e.Args[0].Value)
+		}
+		if ch < utf8.RuneSelf && (unicode.IsLetter(ch) || unicode.IsDigit(ch)) {
+			// Most likely some special escape sequence.
+			return 0, false
+		}
+		return ch, true
+	}
+	return 0, false
+}
+
+// ToExpr returns a char class expression that matches s runes.
+//
+// The negated class form is used when it's shorter.
+// Positions of the returned expression are relative to its Value.
+func ToExpr(s RuneSet) syntax.Expr {
+	var b exprBuilder
+	negated := s.Negate()
+	switch {
+	case len(s) == 0:
+		return b.class(syntax.OpNegCharClass, Any)
+	case len(negated) != 0 && len(negated) < len(s):
+		return b.class(syntax.OpNegCharClass, negated)
+	default:
+		return b.class(syntax.OpCharClass, s)
+	}
+}
+
+// TableToExpr returns a char class expression that matches the table runes.
+// It's a shorthand for ToExpr(FromTable(table)).
+func TableToExpr(table *unicode.RangeTable) syntax.Expr {
+	return ToExpr(FromTable(table))
+}
+
+// ExprToTable returns a table of the runes matched by e using the RE2 tables.
+// See FromExpr for the supported expressions.
+func ExprToTable(e syntax.Expr) (*unicode.RangeTable, bool) {
+	s, ok := FromExpr(e)
+	if !ok {
+		return nil, false
+	}
+	return s.Table(), true
+}
+
+// String returns s formatted as a char class.
+func (s RuneSet) String() string {
+	return ToExpr(s).Value
+}
+
+var controlEscapes = map[rune]string{
+	'\t': "t",
+	'\n': "n",
+	'\f': "f",
+	'\r': "r",
+	'\v': "v",
+}
+
+type exprBuilder struct {
+	buf strings.Builder
+}
+
+func (b *exprBuilder) class(op syntax.Operation, s RuneSet) syntax.Expr {
+	begin := b.buf.Len()
+	if op == syntax.OpNegCharClass {
+		b.buf.WriteString("[^")
+	} else {
+		b.buf.WriteString("[")
+	}
+	args := make([]syntax.Expr, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r.Lo == r.Hi:
+			args = append(args, b.char(r.Lo))
+		case r.Lo+1 == r.Hi:
+			args = append(args, b.char(r.Lo), b.char(r.Hi))
+		default:
+			rangeBegin := b.buf.Len()
+			lo := b.char(r.Lo)
+			b.buf.WriteString("-")
+			hi := b.char(r.Hi)
+			args = append(args, b.expr(syntax.OpCharRange, rangeBegin, lo, hi))
+		}
+	}
+	b.buf.WriteString("]")
+	return b.expr(op, begin, args...)
+}
+
+func (b *exprBuilder) char(ch rune) syntax.Expr {
+	begin := b.buf.Len()
+	switch {
+	case strings.ContainsRune(`\]-[^`, ch):
+		b.buf.WriteString(`\`)
+		value := b.leaf(syntax.OpString, string(ch))
+		return b.expr(syntax.OpEscapeMeta, begin, value)
+	case controlEscapes[ch] != "":
+		b.buf.WriteString(`\`)
+		value := b.leaf(syntax.OpString, controlEscapes[ch])
+		return b.expr(syntax.OpEscapeChar, begin, value)
+	case !unicode.IsPrint(ch):
+		b.buf.WriteString(`\x{`)
+		value := b.leaf(syntax.OpString, fmt.Sprintf("%X", ch))
+		b.buf.WriteString("}")
+		e := b.expr(syntax.OpEscapeHex, begin, value)
+		e.Form = syntax.FormEscapeHexFull
+		return e
+	default:
+		return b.leaf(syntax.OpChar, string(ch))
+	}
+}
+
+func (b *exprBuilder) leaf(op syntax.Operation, value string) syntax.Expr {
+	begin := b.buf.Len()
+	b.buf.WriteString(value)
+	return b.expr(op, begin)
+}
+
+// expr creates a node for the text that was written since begin.
+func (b *exprBuilder) expr(op syntax.Operation, begin int, args ...syntax.Expr) syntax.Expr {
+	text := b.buf.String()
+	return syntax.Expr{
+		Op:    op,
+		Pos:   syntax.Position{Begin: uint16(begin), End: uint16(len(text))},
+		Args:  args,
+		Value: text[begin:],
+	}
+}
@@ -0,0 +1,246 @@
+package charset
+
+import (
+	"regexp"
+	"testing"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestFromExpr(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a`, `[a]`},
+		{`\.`, `[.]`},
+		{`\x41`, `[A]`},
+		{`\x{1F600}`, `[😀]`},
+		{`\012`, `[\n]`},
+		{`\t`, `[\t]`},
+		{`.`, `[^\n]`},
+		{`\d`, `[0-9]`},
+		{`\D`, `[^0-9]`},
+		{`\s`, `[\t\n\f\r ]`},
+		{`\w`, `[0-9A-Z_a-z]`},
+		{`[a-z\d_]`, `[0-9_a-z]`},
+		{`[^a-z]`, `[^a-z]`},
+		{`[[:alpha:][:digit:]]`, `[0-9A-Za-z]`},
+		{`[[:^space:]]`, `[^\t-\r ]`},
+		{`[\]\-^]`, `[\-\]\^]`},
+		{`\p{Greek}`, ``},
+		{`\p{sc=Greek}`, ``},
+		{`[\1]`, `[\x{1}]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		s, ok := FromExpr(re.Expr)
+		if !ok {
+			t.Errorf("fromExpr(%q): unsupported", test.pattern)
+			continue
+		}
+		if test.want == "" {
+			continue
+		}
+		if have := s.String(); have != test.want {
+			t.Errorf("fromExpr(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+
+	for _, pattern := range []string{`\1`, `\b`, `ab`, `a*`, `\p{Unknown}`, `\p{scx=Greek}`} {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		if s, ok := FromExpr(re.Expr); ok {
+			t.Errorf("fromExpr(%q): expected to fail, got %v", pattern, s)
+		}
+	}
+}
+
+func TestToExpr(t *testing.T) {
+	sets := []RuneSet{
+		nil,
+		Any,
+		Of('a'),
+		Of('a', 'b', '-', ']'),
+		New(Range{'a', 'z'}, Range{'0', '9'}),
+		Of('\n').Negate(),
+		Of(0, ' ', 0x7f),
+		New(Range{0x100, 0x10FFFF}),
+	}
+
+	p := syntax.NewParser(nil)
+	for _, s := range sets {
+		e := ToExpr(s)
+		re, err := p.Parse(e.Value)
+		if err != nil {
+			t.Errorf("parse(%q): %v", e.Value, err)
+			continue
+		}
+		if !sameExpr(e, re.Expr) {
+			t.Errorf("toExpr(%v): AST doesn't match the parsed %q", s, e.Value)
+		}
+		parsed, ok := FromExpr(re.Expr)
+		if !ok || !parsed.Equal(s) {
+			t.Errorf("toExpr(%v): %q is parsed as %v", s, e.Value, parsed)
+		}
+	}
+}
+
+func TestDot(t *testing.T) {
+	tests := []struct {
+		newline syntax.Newline
+		want    string
+	}{
+		{syntax.NewlineDefault, `[^\n]`},
+		{syntax.NewlineLF, `[^\n]`},
+		{syntax.NewlineCR, `[^\r]`},
+		{syntax.NewlineCRLF, `[^\n\r]`},
+		{syntax.NewlineAnyCRLF, `[^\n\r]`},
+		{syntax.NewlineAny, `[^\n-\r\x{85}\x{2028}\x{2029}]`},
+	}
+
+	for _, test := range tests {
+		if have := Dot(test.newline).String(); have != test.want {
+			t.Errorf("Dot(%s):\nhave: %s\nwant: %s", test.newline, have, test.want)
+		}
+	}
+}
+
+func TestFromExprUnicodePosix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		ch      rune
+
+		// ascii reports whether ch is matched with the ASCII classes.
+		// It's the opposite with the Unicode classes.
+		ascii bool
+	}{
+		{`[[:alpha:]]`, 'ж', false},
+		{`[[:digit:]]`, '٣', false},
+		{`[[:upper:]]`, 'Ж', false},
+		{`[^[:alpha:]]`, 'ж', true},
+	}
+
+	for _, test := range tests {
+		for _, unicodeClasses := range []bool{false, true} {
+			p := syntax.NewParser(&syntax.ParserOptions{UnicodePosixClasses: unicodeClasses})
+			re, err := p.Parse(test.pattern)
+			if err != nil {
+				t.Fatalf("parse(%q): %v", test.pattern, err)
+			}
+			class := re.Expr.Args[0]
+			if have := class.Form == syntax.FormPosixUnicode; have != unicodeClasses {
+				t.Errorf("%q form: have %v, want %v", test.pattern, class.Form, unicodeClasses)
+			}
+			s, ok := FromExpr(re.Expr)
+			if !ok {
+				t.Fatalf("FromExpr(%q) failed", test.pattern)
+			}
+			want := test.ascii != unicodeClasses
+			if s.Contains(test.ch) != want {
+				t.Errorf("%q (unicode=%v): %q membership:\nhave: %v\nwant: %v",
+					test.pattern, unicodeClasses, test.ch, !want, want)
+			}
+		}
+	}
+}
+
+func TestTableExpr(t *testing.T) {
+	e := TableToExpr(unicode.Cyrillic)
+	re, err := syntax.NewParser(nil).Parse(e.Value)
+	if err != nil {
+		t.Fatalf("parse(%q): %v", e.Value, err)
+	}
+	table, ok := ExprToTable(re.Expr)
+	if !ok {
+		t.Fatalf("ExprToTable(%q) failed", e.Value)
+	}
+	if !FromTable(table).Equal(FromTable(unicode.Cyrillic)) {
+		t.Errorf("%q: the table doesn't match unicode.Cyrillic", e.Value)
+	}
+
+	if _, ok := ExprToTable(syntax.Expr{Op: syntax.OpDollar, Value: "$"}); ok {
+		t.Errorf("ExprToTable($): expected to fail")
+	}
+}
+
+func sameExpr(x, y syntax.Expr) bool {
+	if x.Op != y.Op || x.Form != y.Form || x.Pos != y.Pos || x.Value != y.Value || len(x.Args) != len(y.Args) {
+		return false
+	}
+	for i := range x.Args {
+		if !sameExpr(x.Args[i], y.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFromExprFold(t *testing.T) {
+	tests := []struct {
+		pattern string
+		fold    Folding
+		want    string
+	}{
+		{`[a-k]`, FoldNone, `[a-k]`},
+		{`[a-k]`, FoldSimple, "[A-Ka-k\u212a]"},
+		{`[a-k]`, FoldASCII, `[A-Ka-k]`},
+		{`[i]`, FoldSimple, `[Ii]`},
+		{`[i]`, FoldTurkic, "[i\u0130]"},
+		{`[I]`, FoldTurkic, "[I\u0131]"},
+		{"\u0131", FoldSimple, "[\u0131]"},
+		{`s`, FoldSimple, "[Ss\u017f]"},
+		{`[^k]`, FoldSimple, "[^Kk\u212a]"},
+		{`\W`, FoldSimple, "[^0-9A-Z_a-z\u017f\u212a]"},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		s, ok := RE2.FromExprFold(re.Expr, test.fold)
+		if !ok {
+			t.Errorf("fromExprFold(%q): unsupported", test.pattern)
+			continue
+		}
+		if have := s.String(); have != test.want {
+			t.Errorf("fromExprFold(%q, %d):\nhave: %s\nwant: %s", test.pattern, test.fold, have, test.want)
+		}
+	}
+}
+
+func TestFromExprFoldRE2(t *testing.T) {
+	patterns := []string{
+		`[a-z]`, `[^a-z]`, `[K-k]`, `\w`, `\S`, `[[:upper:]]`, `\p{Lu}`, `[^\d]`, `σ`,
+	}
+
+	p := syntax.NewParser(nil)
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		s, ok := RE2.FromExprFold(re.Expr, FoldSimple)
+		if !ok {
+			t.Errorf("fromExprFold(%q): unsupported", pattern)
+			continue
+		}
+		std := regexp.MustCompile(`^(?i:` + pattern + `)$`)
+		for ch := rune(0); ch < 0x2200; ch++ {
+			if std.MatchString(string(ch)) != s.Contains(ch) {
+				t.Errorf("fromExprFold(%q): %U mismatch", pattern, ch)
+				break
+			}
+		}
+	}
+}
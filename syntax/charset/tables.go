@@ -0,0 +1,162 @@
+package charset
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tables describes the char class semantics of a regexp engine.
+//
+// Engines disagree on what runes perl classes (`\d`, `\w`, `\s`)
+// and POSIX classes (`[:alpha:]`) match, Tables provide the
+// exact sets for a particular dialect and mode.
+type Tables struct {
+	perl  map[string]RuneSet
+	posix map[string]RuneSet
+
+	// latin1 limits all sets to the [0, 0xFF] range.
+	latin1 bool
+}
+
+var (
+	// RE2 describes Go regexp and RE2 classes.
+	// They're ASCII-only regardless of the Unicode mode.
+	RE2 *Tables
+
+	// RE2Latin1 describes RE2 classes in the Latin-1 (byte) mode.
+	// Every pattern char and every input byte is a rune from [0, 0xFF].
+	RE2Latin1 *Tables
+
+	// PCRE describes PCRE classes in the default ASCII mode.
+	PCRE *Tables
+
+	// PCREUnicode describes PCRE classes with the UCP option enabled.
+	// Perl and most POSIX classes match Unicode properties in that mode.
+	PCREUnicode *Tables
+)
+
+// Perl returns a set of runes matched by the perl class escape, like `\d` or `\W`.
+func (t *Tables) Perl(class string) (RuneSet, bool) {
+	s, negated, ok := t.perlClass(class)
+	if negated {
+		s = t.negate(s)
+	}
+	return s, ok
+}
+
+// Posix returns a set of runes matched by the POSIX class, like `[:alpha:]` or `[:^digit:]`.
+func (t *Tables) Posix(class string) (RuneSet, bool) {
+	s, negated, ok := t.posixClass(class)
+	if negated {
+		s = t.negate(s)
+	}
+	return s, ok
+}
+
+// negate returns a complement of s inside the tables rune range.
+func (t *Tables) negate(s RuneSet) RuneSet {
+	s = s.Negate()
+	if t.latin1 {
+		s = s.Intersect(latin1Runes)
+	}
+	return s
+}
+
+var latin1Runes = RuneSet{{0, 0xff}}
+
+// perlClass returns a non-negated class set and a negation flag.
+func (t *Tables) perlClass(class string) (s RuneSet, negated, ok bool) {
+	if len(class) != 2 || class[0] != '\\' {
+		return nil, false, false
+	}
+	s, ok = t.perl[strings.ToLower(class)]
+	return s, ok && class[1] >= 'A' && class[1] <= 'Z', ok
+}
+
+// posixClass returns a non-negated class set and a negation flag.
+func (t *Tables) posixClass(class string) (s RuneSet, negated, ok bool) {
+	name := strings.TrimSuffix(strings.TrimPrefix(class, "[:"), ":]")
+	s, ok = t.posix[strings.TrimPrefix(name, "^")]
+	return s, ok && strings.HasPrefix(name, "^"), ok
+}
+
+func init() {
+	asciiDigit := New(Range{'0', '9'})
+	asciiWord := New(Range{'0', '9'}, Range{'A', 'Z'}, Range{'_', '_'}, Range{'a', 'z'})
+	asciiPosix := map[string]RuneSet{
+		"alnum":  New(Range{'0', '9'}, Range{'A', 'Z'}, Range{'a', 'z'}),
+		"alpha":  New(Range{'A', 'Z'}, Range{'a', 'z'}),
+		"ascii":  New(Range{0, 0x7f}),
+		"blank":  Of('\t', ' '),
+		"cntrl":  New(Range{0, 0x1f}, Range{0x7f, 0x7f}),
+		"digit":  asciiDigit,
+		"graph":  New(Range{'!', '~'}),
+		"lower":  New(Range{'a', 'z'}),
+		"print":  New(Range{' ', '~'}),
+		"punct":  New(Range{'!', '/'}, Range{':', '@'}, Range{'[', '`'}, Range{'{', '~'}),
+		"space":  New(Range{'\t', '\r'}, Range{' ', ' '}),
+		"upper":  New(Range{'A', 'Z'}),
+		"word":   asciiWord,
+		"xdigit": New(Range{'0', '9'}, Range{'A', 'F'}, Range{'a', 'f'}),
+	}
+
+	RE2 = &Tables{
+		perl: map[string]RuneSet{
+			`\d`: asciiDigit,
+			`\s`: Of('\t', '\n', '\f', '\r', ' '),
+			`\w`: asciiWord,
+		},
+		posix: asciiPosix,
+	}
+	RE2Latin1 = &Tables{
+		perl:   RE2.perl,
+		posix:  RE2.posix,
+		latin1: true,
+	}
+
+	horizontalSpace := Of('\t', ' ', 0xa0, 0x1680, 0x180e, 0x202f, 0x205f, 0x3000).
+		Union(New(Range{0x2000, 0x200a}))
+	verticalSpace := New(Range{'\n', '\r'}, Range{0x85, 0x85}, Range{0x2028, 0x2029})
+	PCRE = &Tables{
+		perl: map[string]RuneSet{
+			`\d`: asciiDigit,
+			`\s`: asciiPosix["space"],
+			`\w`: asciiWord,
+			`\h`: horizontalSpace,
+			`\v`: verticalSpace,
+		},
+		posix: asciiPosix,
+	}
+
+	letters := FromTable(unicode.L)
+	alnum := letters.Union(FromTable(unicode.N))
+	unicodeDigit := FromTable(unicode.Nd)
+	unicodeSpace := FromTable(unicode.Z).Union(asciiPosix["space"])
+	unicodeWord := alnum.Union(Of('_'))
+	graph := FromTable(unicode.Z).Union(FromTable(unicode.C)).Negate().Union(FromTable(unicode.Cf))
+	PCREUnicode = &Tables{
+		perl: map[string]RuneSet{
+			`\d`: unicodeDigit,
+			`\s`: unicodeSpace,
+			`\w`: unicodeWord,
+			`\h`: horizontalSpace,
+			`\v`: verticalSpace,
+		},
+		posix: map[string]RuneSet{
+			"alnum":  alnum,
+			"alpha":  letters,
+			"ascii":  asciiPosix["ascii"],
+			"blank":  horizontalSpace,
+			"cntrl":  FromTable(unicode.Cc),
+			"digit":  unicodeDigit,
+			"graph":  graph,
+			"lower":  FromTable(unicode.Ll),
+			"print":  graph.Union(FromTable(unicode.Zs)),
+			"punct":  FromTable(unicode.P).Union(asciiPosix["punct"]),
+			"space":  unicodeSpace,
+			"upper":  FromTable(unicode.Lu),
+			"word":   unicodeWord,
+			"xdigit": asciiPosix["xdigit"],
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package charset
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestRE2Tables(t *testing.T) {
+	classes := []string{
+		`\d`, `\D`, `\s`, `\S`, `\w`, `\W`,
+		`[:alnum:]`, `[:alpha:]`, `[:ascii:]`, `[:blank:]`, `[:cntrl:]`,
+		`[:digit:]`, `[:graph:]`, `[:lower:]`, `[:print:]`, `[:punct:]`,
+		`[:space:]`, `[:upper:]`, `[:word:]`, `[:xdigit:]`, `[:^alpha:]`,
+	}
+	for _, class := range classes {
+		s, ok := RE2.Perl(class)
+		if !ok {
+			s, ok = RE2.Posix(class)
+		}
+		if !ok {
+			t.Errorf("%s: class not found", class)
+			continue
+		}
+		re := regexp.MustCompile(`^[` + class + `]$`)
+		for ch := rune(0); ch < 0x3000; ch++ {
+			if re.MatchString(string(ch)) != s.Contains(ch) {
+				t.Errorf("%s: %U mismatch", class, ch)
+				break
+			}
+		}
+	}
+}
+
+func TestPCRETables(t *testing.T) {
+	tests := []struct {
+		tables *Tables
+		class  string
+		yes    string
+		no     string
+	}{
+		{PCRE, `\d`, "09", "a٣"},
+		{PCRE, `\s`, " \t\v", "\u00a0_"},
+		{PCRE, `\h`, " \t\u00a0\u3000", "\n\v"},
+		{PCRE, `\v`, "\n\v\u2028", " \t"},
+		{PCRE, `\W`, "-é", "a_"},
+		{PCRE, `[:alpha:]`, "aZ", "é1"},
+		{PCREUnicode, `\d`, "0٣", "a²"},
+		{PCREUnicode, `\w`, "a_éж²", "-!"},
+		{PCREUnicode, `\s`, " \u00a0\u2028", "a"},
+		{PCREUnicode, `[:alpha:]`, "aéж", "1_"},
+		{PCREUnicode, `[:upper:]`, "AЖ", "aж"},
+		{PCREUnicode, `[:punct:]`, "!«$", "a "},
+		{PCREUnicode, `[:^digit:]`, "a", "0٣"},
+		{PCREUnicode, `[:xdigit:]`, "aF9", "g٣"},
+	}
+	for _, test := range tests {
+		s, ok := test.tables.Perl(test.class)
+		if !ok {
+			s, ok = test.tables.Posix(test.class)
+		}
+		if !ok {
+			t.Errorf("%s: class not found", test.class)
+			continue
+		}
+		for _, ch := range test.yes {
+			if !s.Contains(ch) {
+				t.Errorf("%s: %q is expected to match", test.class, ch)
+			}
+		}
+		for _, ch := range test.no {
+			if s.Contains(ch) {
+				t.Errorf("%s: %q is not expected to match", test.class, ch)
+			}
+		}
+	}
+
+	if _, ok := RE2.Perl(`\h`); ok {
+		t.Errorf(`\h is not a class in RE2`)
+	}
+	if _, ok := RE2.Posix(`[:foo:]`); ok {
+		t.Errorf(`[:foo:] is not a POSIX class`)
+	}
+}
+
+func TestRE2Latin1Tables(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`.`, `[\x{0}-\t\v-ÿ]`},
+		{`[^a]`, `[\x{0}-` + "`" + `b-ÿ]`},
+		{`\D`, `[\x{0}-/:-ÿ]`},
+		{`[[:^alpha:]]`, `[\x{0}-@\[-` + "`" + `{-ÿ]`},
+		{`\pL`, `[A-Za-zªµºÀ-ÖØ-öø-ÿ]`},
+		{"\xe9", `[é]`},
+		{"[\xe0-\xff]", `[à-ÿ]`},
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Latin1: true})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		s, ok := RE2Latin1.FromExpr(re.Expr)
+		if !ok {
+			t.Errorf("fromExpr(%q): unsupported", test.pattern)
+			continue
+		}
+		if have := s.String(); have != test.want {
+			t.Errorf("fromExpr(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package codegen generates Go code from the regexp patterns.
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/explain"
+)
+
+// ExtractorOptions configure the Extractor output.
+type ExtractorOptions struct {
+	// Package is the generated file package name.
+	Package string
+
+	// TypeName is the generated struct name.
+	// The extraction function is named "Parse" + TypeName.
+	TypeName string
+}
+
+// Extractor returns a Go source file with a struct that has a field
+// for every re named capture group and a function that fills it
+// using the stdlib regexp package.
+//
+// Field types are inferred from the group contents: groups that
+// match only decimal numbers, like `\d+` or `-?[0-9]{1,4}`, are int,
+// other groups are string. The numbers that don't fit into int
+// are reported as the extraction errors. Group names are converted to the exported
+// CamelCase field names, `user_id` becomes UserId.
+//
+// re should be a valid RE2 pattern.
+func Extractor(re *syntax.Regexp, opts ExtractorOptions) ([]byte, error) {
+	if opts.Package == "" || opts.TypeName == "" {
+		return nil, errors.New("package and type names must be specified")
+	}
+	captures := explain.Captures(re, 0)
+	if len(captures) == 0 {
+		return nil, errors.New("pattern has no named groups")
+	}
+
+	type field struct {
+		name  string
+		typ   string
+		index int
+	}
+	fields := make([]field, 0, len(captures))
+	seen := make(map[string]bool)
+	needStrconv := false
+	for _, c := range captures {
+		name := fieldName(c.Name)
+		if seen[name] {
+			return nil, fmt.Errorf("group %s: duplicated field name %s", c.Name, name)
+		}
+		seen[name] = true
+		f := field{name: name, typ: "string", index: c.Index}
+		if isInt(groupBody(re.Expr, c.Index)) {
+			f.typ = "int"
+			needStrconv = true
+		}
+		fields = append(fields, f)
+	}
+
+	var b bytes.Buffer
+	varName := strings.ToLower(opts.TypeName[:1]) + opts.TypeName[1:] + "Regexp"
+	fmt.Fprintf(&b, "// Code generated by regex/syntax/codegen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	if needStrconv {
+		fmt.Fprintf(&b, "import (\n\"errors\"\n\"regexp\"\n\"strconv\"\n)\n\n")
+	} else {
+		fmt.Fprintf(&b, "import (\n\"errors\"\n\"regexp\"\n)\n\n")
+	}
+
+	fmt.Fprintf(&b, "// %s holds the %s named groups.\n", opts.TypeName, varName)
+	fmt.Fprintf(&b, "type %s struct {\n", opts.TypeName)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s %s\n", f.name, f.typ)
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "var %s = regexp.MustCompile(%s)\n\n", varName, quote(re.Pattern))
+
+	fmt.Fprintf(&b, "// Parse%s extracts the %s fields from s.\n", opts.TypeName, opts.TypeName)
+	fmt.Fprintf(&b, "func Parse%s(s string) (%s, error) {\n", opts.TypeName, opts.TypeName)
+	fmt.Fprintf(&b, "var v %s\n", opts.TypeName)
+	fmt.Fprintf(&b, "m := %s.FindStringSubmatch(s)\n", varName)
+	fmt.Fprintf(&b, "if m == nil {\nreturn v, errors.New(\"no match\")\n}\n")
+	if needStrconv {
+		fmt.Fprintf(&b, "var err error\n")
+	}
+	for _, f := range fields {
+		switch f.typ {
+		case "int":
+			fmt.Fprintf(&b, "if m[%d] != \"\" {\n", f.index)
+			fmt.Fprintf(&b, "v.%s, err = strconv.Atoi(m[%d])\n", f.name, f.index)
+			fmt.Fprintf(&b, "if err != nil {\nreturn v, err\n}\n}\n")
+		default:
+			fmt.Fprintf(&b, "v.%s = m[%d]\n", f.name, f.index)
+		}
+	}
+	fmt.Fprintf(&b, "return v, nil\n}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func fieldName(groupName string) string {
+	var b strings.Builder
+	upper := true
+	for _, ch := range groupName {
+		if ch == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			ch = unicode.ToUpper(ch)
+			upper = false
+		}
+		b.WriteRune(ch)
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "Group" + b.String()
+	}
+	return b.String()
+}
+
+func quote(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// groupBody returns the body of the capture group with the specified index.
+func groupBody(e syntax.Expr, index int) syntax.Expr {
+	var body syntax.Expr
+	i := 0
+	var walk func(e syntax.Expr)
+	walk = func(e syntax.Expr) {
+		if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+			i++
+			if i == index {
+				body = e.Args[0]
+			}
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(e)
+	return body
+}
+
+// isInt reports whether e matches only the decimal integers.
+func isInt(e syntax.Expr) bool {
+	if e.Op == syntax.OpConcat && len(e.Args) == 2 && isSign(e.Args[0]) {
+		e = e.Args[1]
+	}
+	switch e.Op {
+	case syntax.OpPlus, syntax.OpStar, syntax.OpRepeat:
+		return isDigit(e.Args[0])
+	default:
+		return isDigit(e)
+	}
+}
+
+func isSign(e syntax.Expr) bool {
+	if e.Op != syntax.OpQuestion {
+		return false
+	}
+	x := e.Args[0]
+	switch x.Op {
+	case syntax.OpChar:
+		return x.Value == "-" || x.Value == "+"
+	case syntax.OpCharClass:
+		return x.Value == "[-+]" || x.Value == "[+-]"
+	}
+	return false
+}
+
+func isDigit(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		return e.Value == `\d`
+	case syntax.OpCharClass:
+		return e.Value == "[0-9]"
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package codegen
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestExtractor(t *testing.T) {
+	const pattern = `^(?P<user_id>-?\d+) (?P<name>\w+)(?: (?P<age>[0-9]{1,3}))?$`
+	const want = "// Code generated by regex/syntax/codegen; DO NOT EDIT.\n\n" +
+		"package users\n\n" +
+		"import (\n" +
+		"\t\"errors\"\n" +
+		"\t\"regexp\"\n" +
+		"\t\"strconv\"\n" +
+		")\n\n" +
+		"// User holds the userRegexp named groups.\n" +
+		"type User struct {\n" +
+		"\tUserId int\n" +
+		"\tName   string\n" +
+		"\tAge    int\n" +
+		"}\n\n" +
+		"var userRegexp = regexp.MustCompile(`" + pattern + "`)\n\n" +
+		"// ParseUser extracts the User fields from s.\n" +
+		"func ParseUser(s string) (User, error) {\n" +
+		"\tvar v User\n" +
+		"\tm := userRegexp.FindStringSubmatch(s)\n" +
+		"\tif m == nil {\n" +
+		"\t\treturn v, errors.New(\"no match\")\n" +
+		"\t}\n" +
+		"\tvar err error\n" +
+		"\tif m[1] != \"\" {\n" +
+		"\t\tv.UserId, err = strconv.Atoi(m[1])\n" +
+		"\t\tif err != nil {\n" +
+		"\t\t\treturn v, err\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\tv.Name = m[2]\n" +
+		"\tif m[3] != \"\" {\n" +
+		"\t\tv.Age, err = strconv.Atoi(m[3])\n" +
+		"\t\tif err != nil {\n" +
+		"\t\t\treturn v, err\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn v, nil\n" +
+		"}\n"
+
+	regexp.MustCompile(pattern)
+	p := syntax.NewParser(nil)
+	re, err := p.Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := Extractor(re, ExtractorOptions{Package: "users", TypeName: "User"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(have) != want {
+		t.Errorf("Extractor(%q):\nhave:\n%s\nwant:\n%s", pattern, have, want)
+	}
+}
+
+func TestExtractorErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(\d+)`, "pattern has no named groups"},
+		{`(?P<a_b>x)(?P<aB>y)`, "group aB: duplicated field name AB"},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Extractor(re, ExtractorOptions{Package: "p", TypeName: "T"})
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Extractor(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// MatcherOptions configure the Matcher output.
+type MatcherOptions struct {
+	// Package is the generated file package name.
+	Package string
+
+	// FuncName is the generated function name.
+	FuncName string
+}
+
+// Matcher returns a Go source file with a function that reports
+// whether its string argument matches re, like regexp.MatchString.
+// The function is implemented with plain loops and comparisons,
+// so it's much faster than the regexp package.
+//
+// Only a simple subset of the patterns is supported: a sequence
+// of the chars, char classes and escapes that can be repeated,
+// optionally anchored at its start and end, like `^\d{4}-\d{2}-\d{2}$`.
+// Repetitions are matched greedily without backtracking, so a variable
+// repetition must not match the chars that follow it.
+// For other patterns an error is returned.
+func Matcher(re *syntax.Regexp, opts MatcherOptions) ([]byte, error) {
+	if opts.Package == "" || opts.FuncName == "" {
+		return nil, errors.New("package and function names must be specified")
+	}
+	prog, err := compileSteps(re.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by regex/syntax/codegen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	if len(prog.steps) != 0 || !prog.anchorStart {
+		fmt.Fprintf(&b, "import \"unicode/utf8\"\n\n")
+	}
+
+	atName := opts.FuncName
+	fmt.Fprintf(&b, "// %s reports whether s matches the %s pattern.\n", opts.FuncName, quote(re.Pattern))
+	fmt.Fprintf(&b, "func %s(s string) bool {\n", opts.FuncName)
+	if !prog.anchorStart {
+		atName = strings.ToLower(opts.FuncName[:1]) + opts.FuncName[1:] + "At"
+		fmt.Fprintf(&b, "for start := 0; ; {\n")
+		fmt.Fprintf(&b, "if %s(s[start:]) {\nreturn true\n}\n", atName)
+		fmt.Fprintf(&b, "if start == len(s) {\nreturn false\n}\n")
+		fmt.Fprintf(&b, "_, size := utf8.DecodeRuneInString(s[start:])\n")
+		fmt.Fprintf(&b, "start += size\n")
+		fmt.Fprintf(&b, "}\n}\n\n")
+		fmt.Fprintf(&b, "func %s(s string) bool {\n", atName)
+	}
+	if len(prog.steps) != 0 {
+		fmt.Fprintf(&b, "i := 0\n")
+	}
+	for _, st := range prog.steps {
+		writeStep(&b, st)
+	}
+	if prog.anchorEnd {
+		if len(prog.steps) != 0 {
+			fmt.Fprintf(&b, "return i == len(s)\n}\n")
+		} else {
+			fmt.Fprintf(&b, "return len(s) == 0\n}\n")
+		}
+	} else {
+		fmt.Fprintf(&b, "return true\n}\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// step matches from min to max (-1 for unbounded) runes of the set.
+type step struct {
+	text     string
+	set      charset.RuneSet
+	min, max int
+}
+
+type stepsProgram struct {
+	steps       []step
+	anchorStart bool
+	anchorEnd   bool
+}
+
+func compileSteps(e syntax.Expr) (*stepsProgram, error) {
+	var prog stepsProgram
+	var elems []syntax.Expr
+	switch e.Op {
+	case syntax.OpConcat:
+		elems = e.Args
+	case syntax.OpEmptyMatch:
+	default:
+		elems = []syntax.Expr{e}
+	}
+
+	if len(elems) != 0 && isStartAnchor(elems[0]) {
+		prog.anchorStart = true
+		elems = elems[1:]
+	}
+	if len(elems) != 0 && isEndAnchor(elems[len(elems)-1]) {
+		prog.anchorEnd = true
+		elems = elems[:len(elems)-1]
+	}
+
+	for _, elem := range elems {
+		if elem.Op == syntax.OpLiteral {
+			for _, ch := range elem.Args {
+				prog.steps = append(prog.steps, step{text: ch.Value, set: charset.Of([]rune(ch.Value)[0]), min: 1, max: 1})
+			}
+			continue
+		}
+		st, ok := exprStep(elem)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported expression", elem.Value)
+		}
+		prog.steps = append(prog.steps, st)
+	}
+
+	for i, st := range prog.steps {
+		if st.min == st.max {
+			continue
+		}
+		for _, next := range prog.steps[i+1:] {
+			if !st.set.Intersect(next.set).IsEmpty() {
+				return nil, fmt.Errorf("%s: repetition can require backtracking", st.text)
+			}
+			if next.min != 0 {
+				break
+			}
+		}
+	}
+
+	return &prog, nil
+}
+
+func exprStep(e syntax.Expr) (step, bool) {
+	st := step{text: e.Value, min: 1, max: 1}
+	x := e
+	switch e.Op {
+	case syntax.OpStar:
+		st.min, st.max = 0, -1
+		x = e.Args[0]
+	case syntax.OpPlus:
+		st.min, st.max = 1, -1
+		x = e.Args[0]
+	case syntax.OpQuestion:
+		st.min, st.max = 0, 1
+		x = e.Args[0]
+	case syntax.OpRepeat:
+		var ok bool
+		st.min, st.max, ok = repeatRange(e.Args[1].Value)
+		if !ok {
+			return st, false
+		}
+		x = e.Args[0]
+	}
+	set, ok := charset.FromExpr(x)
+	if !ok {
+		return st, false
+	}
+	st.set = set
+	return st, true
+}
+
+func repeatRange(s string) (min, max int, ok bool) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	comma := strings.IndexByte(s, ',')
+	if comma == -1 {
+		n, err := strconv.Atoi(s)
+		return n, n, err == nil
+	}
+	min, err := strconv.Atoi(s[:comma])
+	if err != nil {
+		return 0, 0, false
+	}
+	if comma == len(s)-1 {
+		return min, -1, true
+	}
+	max, err = strconv.Atoi(s[comma+1:])
+	return min, max, err == nil && min <= max
+}
+
+func isStartAnchor(e syntax.Expr) bool {
+	return e.Op == syntax.OpCaret || (e.Op == syntax.OpEscapeChar && e.Value == `\A`)
+}
+
+func isEndAnchor(e syntax.Expr) bool {
+	return e.Op == syntax.OpDollar || (e.Op == syntax.OpEscapeChar && e.Value == `\z`)
+}
+
+func writeStep(b *bytes.Buffer, st step) {
+	fmt.Fprintf(b, "// %s\n", strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(st.text))
+	if st.min == 1 && st.max == 1 {
+		fmt.Fprintf(b, "if i == len(s) {\nreturn false\n}\n")
+		fmt.Fprintf(b, "if c, size := utf8.DecodeRuneInString(s[i:]); %s {\n", setCondition(st.set))
+		fmt.Fprintf(b, "i += size\n} else {\nreturn false\n}\n")
+		return
+	}
+
+	switch {
+	case st.max == -1 && st.min == 0:
+		fmt.Fprintf(b, "for {\n")
+	case st.max == -1:
+		fmt.Fprintf(b, "for n := 0; ; n++ {\n")
+	default:
+		fmt.Fprintf(b, "for n := 0; n < %d; n++ {\n", st.max)
+	}
+	fail := "break"
+	if st.min != 0 {
+		fail = fmt.Sprintf("if n < %d {\nreturn false\n}\nbreak", st.min)
+	}
+	fmt.Fprintf(b, "if i == len(s) {\n%s\n}\n", fail)
+	fmt.Fprintf(b, "c, size := utf8.DecodeRuneInString(s[i:])\n")
+	fmt.Fprintf(b, "if !(%s) {\n%s\n}\n", setCondition(st.set), fail)
+	fmt.Fprintf(b, "i += size\n}\n")
+}
+
+// setCondition returns a boolean expression that reports whether c is in s.
+func setCondition(s charset.RuneSet) string {
+	negated := s.Negate()
+	if len(negated) < len(s) {
+		return "!(" + rangesCondition(negated) + ")"
+	}
+	return rangesCondition(s)
+}
+
+func rangesCondition(s charset.RuneSet) string {
+	if len(s) == 0 {
+		return "false"
+	}
+	parts := make([]string, len(s))
+	for i, r := range s {
+		if r.Lo == r.Hi {
+			parts[i] = "c == " + runeLiteral(r.Lo)
+		} else {
+			parts[i] = "c >= " + runeLiteral(r.Lo) + " && c <= " + runeLiteral(r.Hi)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, ") || (") + ")"
+}
+
+func runeLiteral(ch rune) string {
+	if ch < unicode.MaxASCII && unicode.IsPrint(ch) {
+		return strconv.QuoteRune(ch)
+	}
+	return fmt.Sprintf("0x%X", ch)
+}
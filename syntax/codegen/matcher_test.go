@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestMatcherErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a|b`, "a|b: unsupported expression"},
+		{`x(a)`, "(a): unsupported expression"},
+		{`\d+\d`, `\d+: repetition can require backtracking`},
+		{`a*b?a`, `a*: repetition can require backtracking`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Matcher(re, MatcherOptions{Package: "p", FuncName: "F"})
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Matcher(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
+
+// TestMatcherEquivalent runs the generated matchers
+// and compares their results with the regexp package.
+func TestMatcherEquivalent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the generated code compilation in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available")
+	}
+
+	patterns := []string{
+		`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`,
+		`^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`,
+		`^\d{4}-\d{2}-\d{2}`,
+		`x+y?z*`,
+		`a.b$`,
+		`[^a-z]{2,}`,
+		`^\s*$`,
+		``,
+		`^`,
+		`ф\w`,
+	}
+	inputs := []string{
+		"", "x", "xy", "xxyzz", "zyx", "a\nb", "a.b", "aфb", "ABC", "ab12",
+		"123e4567-e89b-12d3-a456-426614174000",
+		"123e4567-e89b-12d3-a456-42661417400",
+		"192.168.0.1", "1.2.3.4.5", "1234.1.1.1",
+		"2020-01-02", "2020-01-02T10:00", "2020-1-02",
+		"  \t", " x ", "фw", "фф", "\xff\xfe",
+	}
+
+	dir, err := ioutil.TempDir("", "codegen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := syntax.NewParser(nil)
+	var main strings.Builder
+	main.WriteString("package main\n\nimport \"fmt\"\n\nfunc main() {\n")
+	for i, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		name := fmt.Sprintf("Match%d", i)
+		src, err := Matcher(re, MatcherOptions{Package: "main", FuncName: name})
+		if err != nil {
+			t.Fatalf("Matcher(%q): %v", pattern, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".go"), src, 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range inputs {
+			fmt.Fprintf(&main, "fmt.Println(%s(%q))\n", name, s)
+		}
+	}
+	main.WriteString("}\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(main.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v: %s", err, out)
+	}
+	results := strings.Fields(string(out))
+	for i, pattern := range patterns {
+		rx := regexp.MustCompile(pattern)
+		for j, s := range inputs {
+			have := results[i*len(inputs)+j]
+			want := fmt.Sprint(rx.MatchString(s))
+			if have != want {
+				t.Errorf("%q matching %q:\nhave: %s\nwant: %s", pattern, s, have, want)
+			}
+		}
+	}
+}
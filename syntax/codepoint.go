@@ -0,0 +1,62 @@
+package syntax
+
+import (
+	"strconv"
+)
+
+// EscapePolicy controls how the parser treats escapes
+// which meaning depends on the target engine.
+type EscapePolicy byte
+
+const (
+	// EscapeAccept accepts the escape silently.
+	EscapeAccept EscapePolicy = iota
+
+	// EscapeReject reports the escape as a parse error.
+	EscapeReject
+
+	// EscapeWarn accepts the escape, but adds a Regexp warning.
+	EscapeWarn
+)
+
+func (opts *ParserOptions) reportsWarnings() bool {
+	return opts.Surrogates == EscapeWarn || opts.HighByteEscapes == EscapeWarn ||
+		opts.DupNames == DupNamesWarn
+}
+
+// checkCodeEscape applies the code escape policies to the escape digits.
+// isByte is true for the escapes that can denote a single byte.
+func (p *Parser) checkCodeEscape(pos Position, digits string, base int, isByte bool) {
+	if p.opts.Surrogates == EscapeAccept && p.opts.HighByteEscapes == EscapeAccept {
+		return
+	}
+	if digits == "" {
+		return
+	}
+	code, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return
+	}
+	switch {
+	case code >= 0xD800 && code <= 0xDFFF:
+		p.applyEscapePolicy(p.opts.Surrogates, CodeSurrogateEscape, ConfidenceHigh, pos, "surrogate code point escape")
+	case isByte && code >= 0x80 && code <= 0xFF:
+		p.applyEscapePolicy(p.opts.HighByteEscapes, CodeHighByteEscape, ConfidenceMedium, pos, "ambiguous byte or code point escape")
+	}
+}
+
+func (p *Parser) applyEscapePolicy(policy EscapePolicy, code Code, confidence Confidence, pos Position, message string) {
+	message += ": " + p.out.Pattern[pos.Begin:pos.End]
+	switch policy {
+	case EscapeReject:
+		throw(code, pos, message)
+	case EscapeWarn:
+		p.out.Warnings = append(p.out.Warnings, Warning{
+			Pos:        pos,
+			Message:    message,
+			Code:       code,
+			Severity:   SeverityWarning,
+			Confidence: confidence,
+		})
+	}
+}
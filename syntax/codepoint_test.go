@@ -0,0 +1,59 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEscapePolicy(t *testing.T) {
+	tests := []struct {
+		opts    ParserOptions
+		pattern string
+		want    string
+	}{
+		{ParserOptions{}, `\x{D800}\xFF\377`, ``},
+
+		{ParserOptions{Surrogates: EscapeReject}, `\x{D7FF}\x{E000}\xFF`, ``},
+		{ParserOptions{Surrogates: EscapeReject}, `a\x{D800}`, `error 1-9: surrogate code point escape: \x{D800}`},
+		{ParserOptions{Surrogates: EscapeReject}, `[\x{dfff}]`, `error 1-9: surrogate code point escape: \x{dfff}`},
+		{ParserOptions{Surrogates: EscapeWarn}, `\x{D800}x\x{DC00}`, `warning 0-8: surrogate code point escape: \x{D800}; warning 9-17: surrogate code point escape: \x{DC00}`},
+
+		{ParserOptions{HighByteEscapes: EscapeReject}, `\x7F\x{FF}\177`, ``},
+		{ParserOptions{HighByteEscapes: EscapeReject}, `\x80`, `error 0-4: ambiguous byte or code point escape: \x80`},
+		{ParserOptions{HighByteEscapes: EscapeWarn}, `[\xFF-\377]`, `warning 1-5: ambiguous byte or code point escape: \xFF; warning 6-10: ambiguous byte or code point escape: \377`},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&test.opts)
+		re, err := p.Parse(test.pattern)
+		var have string
+		if err != nil {
+			perr := err.(ParseError)
+			have = fmt.Sprintf("error %d-%d: %s", perr.Pos.Begin, perr.Pos.End, perr.Message)
+		} else {
+			var parts []string
+			for _, w := range re.Warnings {
+				parts = append(parts, fmt.Sprintf("warning %d-%d: %s", w.Pos.Begin, w.Pos.End, w.Message))
+			}
+			have = strings.Join(parts, "; ")
+		}
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestEscapePolicyWarningsReset(t *testing.T) {
+	p := NewParser(&ParserOptions{Surrogates: EscapeWarn})
+	if _, err := p.Parse(`\x{D800}`); err != nil {
+		t.Fatal(err)
+	}
+	re, err := p.Parse(`\x{41}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(re.Warnings) != 0 {
+		t.Errorf("warnings are not reset: %v", re.Warnings)
+	}
+}
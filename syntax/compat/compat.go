@@ -0,0 +1,118 @@
+// Package compat checks the patterns against the syntax subsets
+// accepted by the specific regexp engines.
+package compat
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// Issue is a pattern part that the target engine rejects.
+type Issue struct {
+	Pos     syntax.Position
+	Message string
+}
+
+func (issue Issue) String() string { return issue.Message }
+
+// Profile describes a regexp engine syntax subset.
+type Profile struct {
+	// Name is an engine name, like "Hyperscan".
+	Name string
+
+	// Unsupported lists the features that the engine rejects.
+	Unsupported []analysis.Feature
+
+	// Flags lists the supported flag letters, like "imsU".
+	// An empty string means that all flags are supported.
+	Flags string
+
+	// MaxRepeat is a max `{n,m}` repetition bound, 0 means "no limit".
+	MaxRepeat int
+
+	// MaxProgramSize is a max analysis.ExpandedSize of the pattern,
+	// 0 means "no limit".
+	MaxProgramSize int
+
+	// Check reports the engine-specific problems that can't be
+	// described by the Unsupported list. It's called for every
+	// pattern node. Can be nil.
+	Check func(e syntax.Expr, report func(e syntax.Expr, message string))
+}
+
+// Issues returns the re parts that are not supported by the engine,
+// sorted by their position.
+func (p *Profile) Issues(re *syntax.Regexp) []Issue {
+	var issues []Issue
+	report := func(e syntax.Expr, message string) {
+		issues = append(issues, Issue{Pos: e.Pos, Message: message})
+	}
+	if p.MaxProgramSize != 0 {
+		if size := analysis.ExpandedSize(re); size > p.MaxProgramSize {
+			report(re.Expr, p.Name+" limits the program size to "+strconv.Itoa(p.MaxProgramSize)+
+				", the pattern size is about "+strconv.Itoa(size))
+		}
+	}
+	var walk func(e syntax.Expr)
+	walk = func(e syntax.Expr) {
+		if f, ok := analysis.ExprFeature(e); ok && p.rejects(f) {
+			report(e, p.Name+" doesn't support "+f.String())
+		}
+		switch e.Op {
+		case syntax.OpGroupWithFlags, syntax.OpFlagOnlyGroup:
+			p.checkFlags(e, report)
+		case syntax.OpRepeat:
+			p.checkRepeat(e, report)
+		}
+		if p.Check != nil {
+			p.Check(e, report)
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(re.Expr)
+	return issues
+}
+
+func (p *Profile) rejects(f analysis.Feature) bool {
+	for _, unsupported := range p.Unsupported {
+		if f == unsupported {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Profile) checkFlags(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	if p.Flags == "" {
+		return
+	}
+	if f, _ := analysis.ExprFeature(e); f != analysis.FeatureFlags {
+		// Recursion and backreferences are reported as unsupported features.
+		return
+	}
+	flags := e.LastArg().Value
+	for i := 0; i < len(flags); i++ {
+		if flags[i] != '-' && strings.IndexByte(p.Flags, flags[i]) == -1 {
+			report(e, p.Name+" doesn't support "+string(flags[i])+" flag")
+		}
+	}
+}
+
+func (p *Profile) checkRepeat(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	if p.MaxRepeat == 0 {
+		return
+	}
+	count := e.Args[1]
+	bounds := strings.Split(strings.Trim(count.Value, "{}"), ",")
+	for _, bound := range bounds {
+		if n, err := strconv.Atoi(bound); err == nil && n > p.MaxRepeat {
+			report(count, p.Name+" limits the repetition count to "+strconv.Itoa(p.MaxRepeat))
+			return
+		}
+	}
+}
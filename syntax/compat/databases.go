@@ -0,0 +1,119 @@
+package compat
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// Postgres is the PostgreSQL advanced regular expressions (ARE) profile,
+// it's used by the `~` operator and the regexp_* functions.
+//
+// ARE has its own escapes: `\b` is a backspace and `\y` is a word boundary,
+// `\Z` matches only at the end of the string. The flags can only
+// be set once, at the pattern start. The repetition count is limited to 255.
+var Postgres = &Profile{
+	Name: "PostgreSQL",
+	Unsupported: []analysis.Feature{
+		analysis.FeatureNamedCapture,
+		analysis.FeatureQuote,
+		analysis.FeatureUnicodeClass,
+		analysis.FeatureAtomicGroup,
+		analysis.FeaturePossessive,
+		analysis.FeatureRecursion,
+		analysis.FeatureScriptRun,
+	},
+	Flags:     "bceimnpqstwx",
+	MaxRepeat: 255,
+	Check:     checkPostgres,
+}
+
+// postgresEscapes are the ARE escapes, except the `\b` and `\B`
+// that have different meaning in ARE.
+const postgresEscapes = `\a\e\f\n\r\t\v\c\u\U\d\D\s\S\w\W\A\Z\m\M\y\Y`
+
+func checkPostgres(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\b`:
+			report(e, `\b is a backspace in PostgreSQL, use \y for a word boundary`)
+		case `\B`:
+			report(e, `\B is a backslash in PostgreSQL, use \Y for a non-boundary`)
+		case `\z`:
+			report(e, `PostgreSQL doesn't support \z, use \Z`)
+		default:
+			if !strings.Contains(postgresEscapes, e.Value) {
+				report(e, "PostgreSQL doesn't support "+e.Value)
+			}
+		}
+	case syntax.OpGroupWithFlags:
+		report(e, "PostgreSQL doesn't support scoped flags")
+	case syntax.OpFlagOnlyGroup:
+		if f, _ := analysis.ExprFeature(e); f != analysis.FeatureFlags {
+			return
+		}
+		if e.Begin() != 0 {
+			report(e, "PostgreSQL only supports flags at the pattern start")
+		}
+		if strings.Contains(e.Args[0].Value, "-") {
+			report(e, "PostgreSQL doesn't support flags negation")
+		}
+	}
+}
+
+// MySQL is the MySQL 8 profile, it's used by the REGEXP operator
+// and the REGEXP_* functions. MySQL 8 uses the ICU regexp engine.
+//
+// ICU is close to PCRE, but it only supports the `(?<name>re)` named
+// groups and requires the lookbehind to have a bounded length.
+var MySQL = &Profile{
+	Name: "MySQL",
+	Unsupported: []analysis.Feature{
+		analysis.FeatureRecursion,
+		analysis.FeatureScriptRun,
+	},
+	Flags: "imswx",
+	Check: checkMySQL,
+}
+
+func checkMySQL(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\C`, `\K`, `\g`:
+			report(e, "MySQL doesn't support "+e.Value)
+		}
+	case syntax.OpNamedCapture:
+		if e.Form != syntax.FormNamedCaptureAngle {
+			report(e, "MySQL only supports (?<name>) named groups")
+		}
+	case syntax.OpFlagOnlyGroup:
+		if strings.HasPrefix(e.Args[0].Value, "P=") {
+			report(e, `MySQL doesn't support (?P=name) backreferences, use \k<name>`)
+		}
+	case syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		if isUnbounded(e.Args[0]) {
+			report(e, "MySQL doesn't support unbounded lookbehind")
+		}
+	}
+}
+
+// isUnbounded reports whether e contains `*`, `+` or `{n,}` repetitions.
+func isUnbounded(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		if strings.HasSuffix(e.Args[1].Value, ",}") {
+			return true
+		}
+	}
+	for _, a := range e.Args {
+		if isUnbounded(a) {
+			return true
+		}
+	}
+	return false
+}
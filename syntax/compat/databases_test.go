@@ -0,0 +1,72 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestDatabaseIssues(t *testing.T) {
+	tests := []struct {
+		profile *Profile
+		pattern string
+		want    []string
+	}{
+		{Postgres, `(?i)\y(a|b)\1+?(?=x)(?<!y)[[:alpha:]]\Z(?#c)`, nil},
+		{Postgres, `\bword\B`, []string{
+			`0: \b is a backspace in PostgreSQL, use \y for a word boundary`,
+			`6: \B is a backslash in PostgreSQL, use \Y for a non-boundary`,
+		}},
+		{Postgres, `a\z\h`, []string{
+			`1: PostgreSQL doesn't support \z, use \Z`,
+			`3: PostgreSQL doesn't support \h`,
+		}},
+		{Postgres, `a(?i)(?-i)`, []string{
+			`1: PostgreSQL only supports flags at the pattern start`,
+			`5: PostgreSQL only supports flags at the pattern start`,
+			`5: PostgreSQL doesn't support flags negation`,
+		}},
+		{Postgres, `(?i:a)(?<x>b)`, []string{
+			`0: PostgreSQL doesn't support scoped flags`,
+			`6: PostgreSQL doesn't support named capture`,
+		}},
+		{Postgres, `\pL\Qa\Ea{256}`, []string{
+			`0: PostgreSQL doesn't support unicode class`,
+			`3: PostgreSQL doesn't support quote`,
+			`9: PostgreSQL limits the repetition count to 255`,
+		}},
+
+		{MySQL, `(?i)(?<x>\p{L}+)\k<x>(?>a)b++(?<=ab?)\Q.\E\h\R\X`, nil},
+		{MySQL, `(?P<x>a)(?'y'b)(?P=x)`, []string{
+			`0: MySQL only supports (?<name>) named groups`,
+			`8: MySQL only supports (?<name>) named groups`,
+			`15: MySQL doesn't support (?P=name) backreferences, use \k<name>`,
+		}},
+		{MySQL, `(?<=a+)(?<!b{2,})`, []string{
+			`0: MySQL doesn't support unbounded lookbehind`,
+			`7: MySQL doesn't support unbounded lookbehind`,
+		}},
+		{MySQL, `a\Kb(?R)(?U)`, []string{
+			`1: MySQL doesn't support \K`,
+			`4: MySQL doesn't support recursion`,
+			`8: MySQL doesn't support U flag`,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range test.profile.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s Issues(%q):\nhave: %q\nwant: %q", test.profile.Name, test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package compat
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// automataUnsupported are the features that the automata-based engines lack.
+var automataUnsupported = []analysis.Feature{
+	analysis.FeatureBackreference,
+	analysis.FeatureLookahead,
+	analysis.FeatureLookbehind,
+	analysis.FeatureAtomicGroup,
+	analysis.FeaturePossessive,
+	analysis.FeatureComment,
+	analysis.FeatureRecursion,
+	analysis.FeatureScriptRun,
+}
+
+// GoRegexp is the Go regexp package profile.
+var GoRegexp = &Profile{
+	Name:        "Go regexp",
+	Unsupported: automataUnsupported,
+	Flags:       "imsU",
+	MaxRepeat:   1000,
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		checkRE2(e, report, "Go regexp", `\C`)
+	},
+}
+
+// RE2 is the RE2 C++ library profile.
+//
+// It's almost the same as GoRegexp, but `\C` is supported.
+var RE2 = &Profile{
+	Name:        "RE2",
+	Unsupported: automataUnsupported,
+	Flags:       "imsU",
+	MaxRepeat:   1000,
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		checkRE2(e, report, "RE2", "")
+	},
+}
+
+// Rust is the rust regex crate profile.
+//
+// It supports the `x` flag, but not `\Q...\E` quotes and octal escapes.
+// The repetition count is only limited by the compiled regexp size,
+// the Unicode class names are not checked as the crate supports
+// more of them than the other engines.
+var Rust = &Profile{
+	Name:        "rust regex",
+	Unsupported: append([]analysis.Feature{analysis.FeatureQuote}, automataUnsupported...),
+	Flags:       "imsUxRu",
+	Check:       checkRust,
+}
+
+// re2Escapes are the escapes that are supported by Go, RE2 and rust regex.
+const re2Escapes = `\a\f\t\n\r\v\A\z\b\B\d\D\s\S\w\W`
+
+func checkRE2(e syntax.Expr, report func(e syntax.Expr, message string), engine, unsupportedEscape string) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		if e.Value == unsupportedEscape || !strings.Contains(re2Escapes+`\C`, e.Value) {
+			report(e, engine+" doesn't support "+e.Value)
+		}
+	case syntax.OpNamedCapture:
+		if e.Form == syntax.FormNamedCaptureQuote {
+			report(e, engine+" doesn't support (?'name') groups")
+		}
+	case syntax.OpEscapeUni:
+		name := strings.TrimPrefix(e.Args[0].Value, "^")
+		if !isGoUnicodeClass(name) {
+			report(e, engine+" doesn't support "+name+" Unicode class")
+		}
+	}
+}
+
+func checkRust(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		if !strings.Contains(re2Escapes, e.Value) {
+			report(e, "rust regex doesn't support "+e.Value)
+		}
+	case syntax.OpEscapeOctal:
+		if f, _ := analysis.ExprFeature(e); f != analysis.FeatureBackreference {
+			report(e, "rust regex doesn't support octal escapes")
+		}
+	case syntax.OpNamedCapture:
+		if e.Form == syntax.FormNamedCaptureQuote {
+			report(e, "rust regex doesn't support (?'name') groups")
+		}
+	}
+}
+
+func isGoUnicodeClass(name string) bool {
+	if name == "Any" {
+		return true
+	}
+	_, ok := unicode.Categories[name]
+	if !ok {
+		_, ok = unicode.Scripts[name]
+	}
+	return ok
+}
@@ -0,0 +1,67 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestEngineIssues(t *testing.T) {
+	tests := []struct {
+		profile *Profile
+		pattern string
+		want    []string
+	}{
+		{GoRegexp, `(?i)(?P<x>\d+)\pL\p{Greek}\p{^Any}[[:alpha:]]\Q.\E\x{41}\101`, nil},
+		{GoRegexp, `a\C`, []string{`1: Go regexp doesn't support \C`}},
+		{GoRegexp, `\e\Z\h`, []string{
+			`0: Go regexp doesn't support \e`,
+			`2: Go regexp doesn't support \Z`,
+			`4: Go regexp doesn't support \h`,
+		}},
+		{GoRegexp, `(?'x'a)(?x)`, []string{
+			`0: Go regexp doesn't support (?'name') groups`,
+			`7: Go regexp doesn't support x flag`,
+		}},
+		{GoRegexp, `a{1001}b{2,1000}`, []string{`1: Go regexp limits the repetition count to 1000`}},
+		{GoRegexp, `\p{Xan}(?#c)`, []string{
+			`0: Go regexp doesn't support Xan Unicode class`,
+			`7: Go regexp doesn't support comment`,
+		}},
+		{GoRegexp, `(a)\1`, []string{`3: Go regexp doesn't support backreference`}},
+
+		{RE2, `a\C+`, nil},
+		{RE2, `(?<x>a){2000}`, []string{`7: RE2 limits the repetition count to 1000`}},
+
+		{Rust, `(?x)(?P<x>\w+)\p{Emoji}a{5000}`, nil},
+		{Rust, `\Qa\E\101`, []string{
+			`0: rust regex doesn't support quote`,
+			`5: rust regex doesn't support octal escapes`,
+		}},
+		{Rust, `(a)\1\C`, []string{
+			`3: rust regex doesn't support backreference`,
+			`5: rust regex doesn't support \C`,
+		}},
+		{Rust, `(?J)a(?=b)`, []string{
+			`0: rust regex doesn't support J flag`,
+			`5: rust regex doesn't support lookahead`,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range test.profile.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s Issues(%q):\nhave: %q\nwant: %q", test.profile.Name, test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package compat
+
+import (
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// EnvoyMaxProgramSize is the default envoy re2.max_program_size.error_level.
+const EnvoyMaxProgramSize = 100
+
+// Envoy is the envoy safe_regex profile.
+//
+// Envoy uses RE2, but rejects the patterns which program size exceeds
+// the re2.max_program_size.error_level runtime setting, that is small
+// by default. The safe_regex matchers match the whole string,
+// see FullMatchAnchors.
+var Envoy = &Profile{
+	Name:           "envoy",
+	Unsupported:    automataUnsupported,
+	Flags:          "imsU",
+	MaxRepeat:      1000,
+	MaxProgramSize: EnvoyMaxProgramSize,
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		checkRE2(e, report, "envoy", "")
+	},
+}
+
+// CEL is the Common Expression Language `matches` function profile.
+//
+// The Go and C++ CEL implementations use the Go regexp package and RE2,
+// so only their common subset is supported. Unlike envoy,
+// `matches` is true if any substring matches.
+var CEL = &Profile{
+	Name:        "CEL",
+	Unsupported: automataUnsupported,
+	Flags:       "imsU",
+	MaxRepeat:   1000,
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		checkRE2(e, report, "CEL", `\C`)
+	},
+}
+
+// FullMatchAnchors returns the anchoring problems of re for the envoy
+// safe_regex matchers that match the whole string: the redundant anchors
+// and the edges that rely on a partial match, like in `^/api` that
+// only matches the "/api" path. See analysis.CheckAnchoring.
+func FullMatchAnchors(re *syntax.Regexp) []Issue {
+	var issues []Issue
+	for _, issue := range analysis.CheckAnchoring(re, analysis.TargetEnvoy) {
+		issues = append(issues, Issue{Pos: issue.Expr.Pos, Message: issue.Message})
+	}
+	return issues
+}
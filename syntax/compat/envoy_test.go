@@ -0,0 +1,77 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestEnvoyIssues(t *testing.T) {
+	tests := []struct {
+		profile *Profile
+		pattern string
+		want    []string
+	}{
+		{Envoy, `/api/v[0-9]+/users/[^/]+`, nil},
+		{Envoy, `a\C(?=b)`, []string{`3: envoy doesn't support lookahead`}},
+		{Envoy, `[a-z]{50}x{60}`, []string{`0: envoy limits the program size to 100, the pattern size is about 110`}},
+		{CEL, `^\w+@\w+$`, nil},
+		{CEL, `a\C\e`, []string{
+			`1: CEL doesn't support \C`,
+			`3: CEL doesn't support \e`,
+		}},
+		{CEL, `[a-z]{50}x{60}`, nil},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range test.profile.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s Issues(%q):\nhave: %q\nwant: %q", test.profile.Name, test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestFullMatchAnchors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`/api/.*`, nil},
+		{`a^b$c`, nil},
+		{`^/api`, []string{
+			`0: ^ is redundant: envoy safe_regex matches the whole string`,
+			`1: the pattern is anchored only at the start, but envoy safe_regex also anchors its end; add .* to match any suffix`,
+		}},
+		{`\A/a.*|(?:/b$)`, []string{
+			`0: \A is redundant: envoy safe_regex matches the whole string`,
+			`10: the pattern is anchored only at the end, but envoy safe_regex also anchors its start; add .* to match any prefix`,
+			`12: $ is redundant: envoy safe_regex matches the whole string`,
+		}},
+		{`$`, []string{`0: $ is redundant: envoy safe_regex matches the whole string`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range FullMatchAnchors(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("FullMatchAnchors(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package compat
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// HTMLPattern is the HTML `pattern` attribute profile.
+//
+// Browsers compile the attribute value as an ECMAScript regexp with
+// the v flag, wrapped in `^(?:...)$`, see WrapHTMLPattern. If it fails
+// to compile, the attribute is silently ignored.
+//
+// The v flag class syntax can't be represented by the parser, so the
+// patterns should be checked with HTMLPatternIssues before they're parsed.
+var HTMLPattern = &Profile{
+	Name: "HTML pattern",
+	Unsupported: []analysis.Feature{
+		analysis.FeatureFlags,
+		analysis.FeatureQuote,
+		analysis.FeaturePosixClass,
+		analysis.FeatureAtomicGroup,
+		analysis.FeaturePossessive,
+		analysis.FeatureComment,
+		analysis.FeatureRecursion,
+		analysis.FeatureScriptRun,
+	},
+	Check: checkHTMLPattern,
+}
+
+// vModeEscapes are the ECMAScript letter escapes allowed with the v flag.
+const vModeEscapes = `\t\n\v\f\r\d\D\w\W\s\S\b\B\c\u\k\p\P\x`
+
+func checkHTMLPattern(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		if isLetter(e.Value[1]) && !strings.Contains(vModeEscapes, e.Value) {
+			report(e, "HTML pattern doesn't support "+e.Value)
+		}
+	case syntax.OpEscapeHex:
+		if strings.HasPrefix(e.Value, `\x{`) {
+			report(e, `HTML pattern doesn't support \x{...}, use \u{...}`)
+		}
+	case syntax.OpEscapeOctal:
+		if f, _ := analysis.ExprFeature(e); f != analysis.FeatureBackreference && e.Value != `\0` {
+			report(e, "HTML pattern doesn't support octal escapes")
+		}
+	case syntax.OpNamedCapture:
+		if e.Form != syntax.FormNamedCaptureAngle {
+			report(e, "HTML pattern only supports (?<name>) groups")
+		}
+	}
+}
+
+// WrapHTMLPattern returns the regexp that browsers compile
+// for the pattern attribute value.
+func WrapHTMLPattern(pattern string) string {
+	return "^(?:" + pattern + ")$"
+}
+
+// HTMLPatternIssues returns the v flag syntax errors
+// of the pattern attribute value, sorted by their position.
+//
+// Unlike the other checks, it works with the pattern text:
+// the v flag classes can be nested and can use the `--` and `&&`
+// set operations, so the parser doesn't accept them.
+// It also reports the `)` that closes the implicit wrapper group.
+func HTMLPatternIssues(pattern string) []Issue {
+	var issues []Issue
+	report := func(begin, end int, message string) {
+		issues = append(issues, Issue{
+			Pos:     syntax.Position{Begin: uint16(begin), End: uint16(end)},
+			Message: message,
+		})
+	}
+
+	groups := 0
+	classes := 0
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		if ch == '\\' {
+			end := skipEscape(pattern, i)
+			if end == i+1 {
+				report(i, end, `trailing \`)
+			} else if !isLetter(pattern[i+1]) && !isDigit(pattern[i+1]) && !validIdentityEscape(pattern[i+1], classes != 0) {
+				report(i, end, pattern[i:end]+" is not a valid escape with the v flag")
+			}
+			i = end - 1
+			continue
+		}
+
+		if classes != 0 {
+			switch {
+			case ch == '[':
+				classes++
+				if i+1 < len(pattern) && pattern[i+1] == '^' {
+					i++
+				}
+			case ch == ']':
+				classes--
+			case (ch == '&' || ch == '-') && i+1 < len(pattern) && pattern[i+1] == ch:
+				// Set intersection or subtraction.
+				i++
+			case ch == '-' && (pattern[i-1] == '[' || pattern[i-1] == '^' || i+1 < len(pattern) && pattern[i+1] == ']'):
+				report(i, i+1, "- must be escaped in a class with the v flag")
+			case strings.IndexByte("(){}/|", ch) != -1:
+				report(i, i+1, string(ch)+" must be escaped in a class with the v flag")
+			case i+1 < len(pattern) && pattern[i+1] == ch && strings.IndexByte(doublePunctuators, ch) != -1:
+				report(i, i+2, pattern[i:i+2]+" is reserved in a class with the v flag")
+				i++
+			}
+			continue
+		}
+
+		switch ch {
+		case '[':
+			classes++
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				i++
+			}
+		case '(':
+			groups++
+		case ')':
+			groups--
+			if groups < 0 {
+				report(i, i+1, "unbalanced ) closes the implicit ^(?: group")
+				groups = 0
+			}
+		case ']', '}':
+			report(i, i+1, "lone "+string(ch)+" must be escaped with the v flag")
+		case '{':
+			if end := skipRepeat(pattern, i); end != -1 {
+				i = end - 1
+			} else {
+				report(i, i+1, "lone { must be escaped with the v flag")
+			}
+		}
+	}
+	if classes != 0 {
+		report(len(pattern), len(pattern), "unterminated [")
+	}
+	return issues
+}
+
+// CheckHTMLPattern returns the HTMLPatternIssues and, if there are none,
+// the HTMLPattern profile issues of the pattern attribute value.
+//
+// The classes with the v flag set operations, nested classes and `\q{...}`
+// strings are masked before the parsing, so their contents are not checked
+// by the profile. The parse errors are returned as is.
+func CheckHTMLPattern(p *syntax.Parser, pattern string) ([]Issue, error) {
+	if issues := HTMLPatternIssues(pattern); len(issues) != 0 {
+		return issues, nil
+	}
+	re, err := p.Parse(maskSetClasses(pattern))
+	if err != nil {
+		return nil, err
+	}
+	return HTMLPattern.Issues(re), nil
+}
+
+// maskSetClasses replaces the contents of the top-level classes
+// that use the v flag only syntax with `a` chars.
+// The pattern must be valid, see HTMLPatternIssues.
+func maskSetClasses(pattern string) string {
+	buf := []byte(pattern)
+	begin := -1
+	depth := 0
+	setSyntax := false
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case ch == '\\':
+			if depth != 0 && i+1 < len(pattern) && pattern[i+1] == 'q' {
+				setSyntax = true
+			}
+			i = skipEscape(pattern, i) - 1
+		case ch == '[':
+			if depth == 0 {
+				begin = i + 1
+				setSyntax = false
+			} else {
+				setSyntax = true
+			}
+			depth++
+		case ch == ']' && depth != 0:
+			depth--
+			if depth == 0 && setSyntax {
+				if buf[begin] == '^' {
+					begin++
+				}
+				for j := begin; j < i; j++ {
+					buf[j] = 'a'
+				}
+			}
+		case depth != 0 && (ch == '&' || ch == '-') && i+1 < len(pattern) && pattern[i+1] == ch:
+			setSyntax = true
+			i++
+		}
+	}
+	return string(buf)
+}
+
+// doublePunctuators are the chars that are reserved
+// when doubled inside the v flag classes.
+const doublePunctuators = "!#$%*+,.:;<=>?@^`~"
+
+// validIdentityEscape reports whether the `\ch` escape of a non-alphanumeric
+// char is allowed with the v flag. The class reserved punctuators can
+// only be escaped inside the classes.
+func validIdentityEscape(ch byte, inClass bool) bool {
+	if strings.IndexByte(`^$\.*+?()[]{}|/`, ch) != -1 {
+		return true
+	}
+	return inClass && strings.IndexByte("&-!#%,:;<=>@`~", ch) != -1
+}
+
+// skipEscape returns the offset after the escape that starts at s[i].
+func skipEscape(s string, i int) int {
+	if i+1 >= len(s) {
+		return i + 1
+	}
+	end := i + 2
+	switch s[i+1] {
+	case 'p', 'P', 'q', 'u', 'k':
+		open, close := byte('{'), byte('}')
+		if s[i+1] == 'k' {
+			open, close = '<', '>'
+		}
+		if end < len(s) && s[end] == open {
+			if j := strings.IndexByte(s[end:], close); j != -1 {
+				return end + j + 1
+			}
+		}
+	}
+	return end
+}
+
+// skipRepeat returns the offset after the `{n}`, `{n,}` or `{n,m}`
+// quantifier that starts at s[i], or -1 if it's not a quantifier.
+func skipRepeat(s string, i int) int {
+	j := strings.IndexByte(s[i:], '}')
+	if j == -1 {
+		return -1
+	}
+	body := s[i+1 : i+j]
+	parts := strings.Split(body, ",")
+	if len(parts) > 2 || parts[0] == "" {
+		return -1
+	}
+	for _, part := range parts {
+		if strings.Trim(part, "0123456789") != "" {
+			return -1
+		}
+	}
+	return i + j + 1
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
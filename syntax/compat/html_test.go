@@ -0,0 +1,82 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestHTMLPatternIssues(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`[a-z]{2,}\d{3}`, nil},
+		{`[\p{L}--[a-z]][\w&&\p{ASCII}][\q{abc|d}]\u{1F600}\k<x>`, nil},
+		{`[\-\&\(][a\-b]\.\/`, nil},
+		{`[a(b]`, []string{`2: ( must be escaped in a class with the v flag`}},
+		{`[-a][a-]`, []string{
+			`1: - must be escaped in a class with the v flag`,
+			`6: - must be escaped in a class with the v flag`,
+		}},
+		{`[a!!b]`, []string{`2: !! is reserved in a class with the v flag`}},
+		{`a{b}]`, []string{
+			`1: lone { must be escaped with the v flag`,
+			`3: lone } must be escaped with the v flag`,
+			`4: lone ] must be escaped with the v flag`,
+		}},
+		{`\-\&`, []string{
+			`0: \- is not a valid escape with the v flag`,
+			`2: \& is not a valid escape with the v flag`,
+		}},
+		{`a)|(b`, []string{`1: unbalanced ) closes the implicit ^(?: group`}},
+		{`[a`, []string{`2: unterminated [`}},
+	}
+
+	for _, test := range tests {
+		var have []string
+		for _, issue := range HTMLPatternIssues(test.pattern) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("HTMLPatternIssues(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestCheckHTMLPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`(?<year>\d{4})-\k<year>(?<=\d)`, nil},
+		{`[\p{L}--[a-z]]+\A`, []string{`15: HTML pattern doesn't support \A`}},
+		{`(?P<x>a)(?i)b++`, []string{
+			`0: HTML pattern only supports (?<name>) groups`,
+			`8: HTML pattern doesn't support flags`,
+			`12: HTML pattern doesn't support possessive quantifier`,
+		}},
+		{`[a(]`, []string{`2: ( must be escaped in a class with the v flag`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		issues, err := CheckHTMLPattern(p, test.pattern)
+		if err != nil {
+			t.Fatalf("CheckHTMLPattern(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range issues {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("CheckHTMLPattern(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+
+	if have, want := WrapHTMLPattern(`a|b`), `^(?:a|b)$`; have != want {
+		t.Errorf("WrapHTMLPattern:\nhave: %s\nwant: %s", have, want)
+	}
+}
@@ -0,0 +1,176 @@
+package compat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// Hyperscan is a Hyperscan (and Vectorscan) engine profile.
+//
+// Hyperscan accepts the PCRE syntax, but it rejects the constructs
+// that can't be compiled to automata, like backreferences and lookarounds.
+// Capturing groups are accepted, but the captures are not reported.
+var Hyperscan = &Profile{
+	Name: "Hyperscan",
+	Unsupported: []analysis.Feature{
+		analysis.FeatureBackreference,
+		analysis.FeatureLookahead,
+		analysis.FeatureLookbehind,
+		analysis.FeatureAtomicGroup,
+		analysis.FeaturePossessive,
+		analysis.FeatureRecursion,
+		analysis.FeatureScriptRun,
+	},
+	Flags: "imsx",
+	Check: checkHyperscan,
+}
+
+func checkHyperscan(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	if e.Op != syntax.OpEscapeChar {
+		return
+	}
+	switch e.Value {
+	case `\C`, `\R`, `\K`, `\X`, `\G`:
+		report(e, "Hyperscan doesn't support "+e.Value)
+	}
+}
+
+// HyperscanFlags is a set of Hyperscan HS_FLAG_* compile flags.
+type HyperscanFlags uint32
+
+// The values match the hs_compile.h definitions.
+const (
+	HyperscanCaseless    HyperscanFlags = 1 << 0
+	HyperscanDotAll      HyperscanFlags = 1 << 1
+	HyperscanMultiline   HyperscanFlags = 1 << 2
+	HyperscanSingleMatch HyperscanFlags = 1 << 3
+	HyperscanAllowEmpty  HyperscanFlags = 1 << 4
+	HyperscanUTF8        HyperscanFlags = 1 << 5
+	HyperscanUCP         HyperscanFlags = 1 << 6
+	HyperscanPrefilter   HyperscanFlags = 1 << 7
+	HyperscanSOMLeftmost HyperscanFlags = 1 << 8
+)
+
+// HyperscanExpression is an hs_compile_multi expression and its flags.
+type HyperscanExpression struct {
+	Pattern string
+	Flags   HyperscanFlags
+}
+
+// ExportHyperscan converts re to the Hyperscan compile arguments.
+//
+// flags are the flags that are enabled outside of the pattern.
+// extra flags are added to the result as is, use them to request
+// the match mode, like HyperscanSingleMatch or HyperscanSOMLeftmost.
+//
+// HyperscanUTF8 is added if the pattern uses the non-ASCII chars
+// or the Unicode classes. A pattern that can match an empty string
+// is rejected unless HyperscanAllowEmpty is passed: Hyperscan
+// refuses to compile it otherwise.
+func ExportHyperscan(re *syntax.Regexp, flags syntax.Flags, extra HyperscanFlags) (HyperscanExpression, error) {
+	if issues := Hyperscan.Issues(re); len(issues) != 0 {
+		issue := issues[0]
+		return HyperscanExpression{}, fmt.Errorf("%d: %s", issue.Pos.Begin, issue.Message)
+	}
+
+	result := HyperscanExpression{Pattern: re.Pattern, Flags: extra}
+	if flags&syntax.FlagCaseInsensitive != 0 {
+		result.Flags |= HyperscanCaseless
+	}
+	if flags&syntax.FlagMultiline != 0 {
+		result.Flags |= HyperscanMultiline
+	}
+	if flags&syntax.FlagDotAll != 0 {
+		result.Flags |= HyperscanDotAll
+	}
+	if flags&syntax.FlagExtended != 0 {
+		result.Pattern = "(?x)" + result.Pattern
+	}
+	if unsupported := flags &^ (syntax.FlagCaseInsensitive | syntax.FlagMultiline | syntax.FlagDotAll | syntax.FlagExtended); unsupported != 0 {
+		return HyperscanExpression{}, errors.New("Hyperscan doesn't support " + unsupported.String() + " flags")
+	}
+
+	if result.Flags&HyperscanUCP != 0 || needsUTF8(re.Pattern, re.Expr) {
+		result.Flags |= HyperscanUTF8
+	}
+	if result.Flags&HyperscanAllowEmpty == 0 && canMatchEmpty(re.Expr) {
+		return HyperscanExpression{}, errors.New("pattern can match an empty string, Hyperscan requires HyperscanAllowEmpty for it")
+	}
+	return result, nil
+}
+
+func needsUTF8(pattern string, e syntax.Expr) bool {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] >= 0x80 {
+			return true
+		}
+	}
+	found := false
+	var walk func(e syntax.Expr)
+	walk = func(e syntax.Expr) {
+		switch e.Op {
+		case syntax.OpEscapeUni:
+			found = true
+		case syntax.OpEscapeHex:
+			n, err := strconv.ParseUint(e.Args[0].Value, 16, 32)
+			if err == nil && n > 0xFF {
+				found = true
+			}
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(e)
+	return found
+}
+
+// canMatchEmpty reports whether e can match an empty string.
+// Assertions are treated as always matching.
+func canMatchEmpty(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpConcat:
+		for _, a := range e.Args {
+			if !canMatchEmpty(a) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlt:
+		for _, a := range e.Args {
+			if canMatchEmpty(a) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpStar, syntax.OpQuestion:
+		return true
+	case syntax.OpRepeat:
+		count := strings.TrimPrefix(e.Args[1].Value, "{")
+		if strings.HasPrefix(count, "0,") || strings.HasPrefix(count, "0}") {
+			return true
+		}
+		return canMatchEmpty(e.Args[0])
+	case syntax.OpPlus, syntax.OpNonGreedy, syntax.OpPossessive,
+		syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun:
+		return canMatchEmpty(e.Args[0])
+	case syntax.OpQuote:
+		return e.QuotedLiteral() == ""
+	case syntax.OpCaret, syntax.OpDollar, syntax.OpComment, syntax.OpFlagOnlyGroup, syntax.OpEmptyMatch,
+		syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return true
+	case syntax.OpEscapeChar:
+		switch e.Value {
+		case `\b`, `\B`, `\A`, `\z`, `\Z`, `\G`, `\K`:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestHyperscanIssues(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`(?i)foo|bar(?:x)+`, nil},
+		{`(?P<x>a)\Q*\E(?#c)\b`, nil},
+		{`(a)\1`, []string{`3: Hyperscan doesn't support backreference`}},
+		{`(?=a)(?<!b)`, []string{
+			`0: Hyperscan doesn't support lookahead`,
+			`5: Hyperscan doesn't support lookbehind`,
+		}},
+		{`(?>a)b++`, []string{
+			`0: Hyperscan doesn't support atomic group`,
+			`5: Hyperscan doesn't support possessive quantifier`,
+		}},
+		{`a\Kb\R`, []string{
+			`1: Hyperscan doesn't support \K`,
+			`4: Hyperscan doesn't support \R`,
+		}},
+		{`(?U:a*)(?J)`, []string{
+			`0: Hyperscan doesn't support U flag`,
+			`7: Hyperscan doesn't support J flag`,
+		}},
+		{`(a|(?R))`, []string{`3: Hyperscan doesn't support recursion`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range Hyperscan.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("Issues(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestExportHyperscan(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   syntax.Flags
+		extra   HyperscanFlags
+		want    HyperscanExpression
+	}{
+		{`foo`, 0, 0, HyperscanExpression{`foo`, 0}},
+		{`foo`, syntax.FlagCaseInsensitive | syntax.FlagDotAll, 0, HyperscanExpression{`foo`, HyperscanCaseless | HyperscanDotAll}},
+		{`^a$`, syntax.FlagMultiline, HyperscanSingleMatch, HyperscanExpression{`^a$`, HyperscanMultiline | HyperscanSingleMatch}},
+		{`a b`, syntax.FlagExtended, 0, HyperscanExpression{`(?x)a b`, 0}},
+		{`\pL+`, 0, 0, HyperscanExpression{`\pL+`, HyperscanUTF8}},
+		{`\x{100}|é`, 0, 0, HyperscanExpression{`\x{100}|é`, HyperscanUTF8}},
+		{`\x{FF}`, 0, 0, HyperscanExpression{`\x{FF}`, 0}},
+		{`\w`, 0, HyperscanUCP, HyperscanExpression{`\w`, HyperscanUCP | HyperscanUTF8}},
+		{`a*`, 0, HyperscanAllowEmpty, HyperscanExpression{`a*`, HyperscanAllowEmpty}},
+		{`a{0}b`, 0, 0, HyperscanExpression{`a{0}b`, 0}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, err := ExportHyperscan(re, test.flags, test.extra)
+		if err != nil {
+			t.Errorf("ExportHyperscan(%q): %v", test.pattern, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("ExportHyperscan(%q):\nhave: %+v\nwant: %+v", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestExportHyperscanErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   syntax.Flags
+		want    string
+	}{
+		{`a(?=b)`, 0, `1: Hyperscan doesn't support lookahead`},
+		{`a`, syntax.FlagUngreedy, `Hyperscan doesn't support U flags`},
+		{`a*|b`, 0, `pattern can match an empty string, Hyperscan requires HyperscanAllowEmpty for it`},
+		{`^\b(?:x{0,3})$`, 0, `pattern can match an empty string, Hyperscan requires HyperscanAllowEmpty for it`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = ExportHyperscan(re, test.flags, 0)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ExportHyperscan(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package compat
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// JSONSchema is the ECMA-262 subset that the JSON Schema specification
+// recommends for the `pattern` and `patternProperties` regexps.
+//
+// The validators are built on top of many different engines, so the
+// patterns outside of this subset are rejected by some of them.
+// Use JSONSchemaPortability to find the subset parts that are
+// matched differently.
+var JSONSchema = &Profile{
+	Name: "JSON Schema",
+	Unsupported: []analysis.Feature{
+		analysis.FeatureNamedCapture,
+		analysis.FeatureFlags,
+		analysis.FeatureQuote,
+		analysis.FeatureUnicodeClass,
+		analysis.FeaturePosixClass,
+		analysis.FeatureBackreference,
+		analysis.FeatureLookbehind,
+		analysis.FeatureAtomicGroup,
+		analysis.FeaturePossessive,
+		analysis.FeatureComment,
+		analysis.FeatureRecursion,
+		analysis.FeatureScriptRun,
+	},
+	Check: checkECMA,
+}
+
+// ecmaEscapes are the ECMA-262 letter escapes.
+// `\c` and `\u` are followed by the control letter and the hex digits.
+const ecmaEscapes = `\t\n\v\f\r\d\D\w\W\s\S\b\B\c\u`
+
+func checkECMA(e syntax.Expr, report func(e syntax.Expr, message string)) {
+	switch e.Op {
+	case syntax.OpEscapeChar:
+		if isLetter(e.Value[1]) && !strings.Contains(ecmaEscapes, e.Value) {
+			report(e, "JSON Schema doesn't support "+e.Value)
+		}
+	case syntax.OpEscapeHex:
+		if strings.HasPrefix(e.Value, `\x{`) {
+			report(e, `JSON Schema doesn't support \x{...}, use \uXXXX`)
+		}
+	case syntax.OpEscapeOctal:
+		if f, _ := analysis.ExprFeature(e); f != analysis.FeatureBackreference && e.Value != `\0` {
+			report(e, "JSON Schema doesn't support octal escapes")
+		}
+	}
+}
+
+// JSONSchemaPortability returns the re parts that are matched
+// differently by the JSON Schema validators, sorted by their position.
+//
+// The ECMA-262 semantics are used by the JavaScript validators, while
+// the others use the Go, PCRE or Python engines: they disagree on the
+// `$` final newline, the shorthand classes and the dot line terminators.
+func JSONSchemaPortability(re *syntax.Regexp) []Issue {
+	var issues []Issue
+	report := func(e syntax.Expr, message string) {
+		issues = append(issues, Issue{Pos: e.Pos, Message: message})
+	}
+	var walk func(e syntax.Expr)
+	walk = func(e syntax.Expr) {
+		switch e.Op {
+		case syntax.OpDollar:
+			report(e, "$ also matches before a final newline in the PCRE and Python validators")
+		case syntax.OpDot:
+			report(e, `. matches \r, U+2028 and U+2029 outside of the ECMA-262 validators`)
+		case syntax.OpEscapeChar:
+			switch e.Value {
+			case `\d`, `\D`, `\w`, `\W`, `\b`, `\B`:
+				report(e, e.Value+" matches non-ASCII chars in the Python validators")
+			case `\s`, `\S`:
+				report(e, e.Value+" matches Unicode spaces only in the ECMA-262 and Python validators, ASCII spaces elsewhere")
+			}
+		case syntax.OpCharClass, syntax.OpNegCharClass:
+			class := e
+			class.Op = syntax.OpCharClass
+			if s, ok := charset.FromExpr(class); ok && !s.Intersect(astral).IsEmpty() {
+				report(e, "chars above U+FFFF are two UTF-16 units in the ECMA-262 validators without the u flag")
+			}
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(re.Expr)
+	return issues
+}
+
+// astral are the chars outside of the Unicode Basic Multilingual Plane.
+var astral = charset.New(charset.Range{Lo: 0x10000, Hi: 0x10FFFF})
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,88 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestJSONSchemaIssues(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`^[a-z]+(?:-[a-z0-9]+)*?(?=x)(?!y)é\x41\cA\0\/|a{2,}`, nil},
+		{`(?<name>a)\1`, []string{
+			`0: JSON Schema doesn't support named capture`,
+			`10: JSON Schema doesn't support backreference`,
+		}},
+		{`(?i)\Aa\z`, []string{
+			`0: JSON Schema doesn't support flags`,
+			`4: JSON Schema doesn't support \A`,
+			`7: JSON Schema doesn't support \z`,
+		}},
+		{`\x{41}\101\pL`, []string{
+			`0: JSON Schema doesn't support \x{...}, use \uXXXX`,
+			`6: JSON Schema doesn't support octal escapes`,
+			`10: JSON Schema doesn't support unicode class`,
+		}},
+		{`(?<=a)b++[[:alpha:]]`, []string{
+			`0: JSON Schema doesn't support lookbehind`,
+			`6: JSON Schema doesn't support possessive quantifier`,
+			`10: JSON Schema doesn't support posix class`,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range JSONSchema.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("Issues(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestJSONSchemaPortability(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`^[a-z0-9_]+(-x)?`, nil},
+		{`^\d+$`, []string{
+			`1: \d matches non-ASCII chars in the Python validators`,
+			`4: $ also matches before a final newline in the PCRE and Python validators`,
+		}},
+		{`a.b\s`, []string{
+			`1: . matches \r, U+2028 and U+2029 outside of the ECMA-262 validators`,
+			`3: \s matches Unicode spaces only in the ECMA-262 and Python validators, ASCII spaces elsewhere`,
+		}},
+		{`[😀-😂][^\x{1F600}]`, []string{
+			`0: chars above U+FFFF are two UTF-16 units in the ECMA-262 validators without the u flag`,
+			`11: chars above U+FFFF are two UTF-16 units in the ECMA-262 validators without the u flag`,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range JSONSchemaPortability(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("JSONSchemaPortability(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package compat
+
+import (
+	"strconv"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Nginx is the nginx profile for the `location ~`, `if` and `rewrite` regexps.
+//
+// nginx uses PCRE, so all syntax features are supported. The regexps
+// are matched against the request URI that always starts with `/`,
+// so a `^` that is followed by another char makes the pattern unmatchable.
+var Nginx = &Profile{
+	Name: "nginx",
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		if ch, ok := anchoredChar(e); ok && ch != '/' {
+			report(e, "nginx URI always starts with /, the pattern never matches")
+		}
+	},
+}
+
+// Apache is the Apache RewriteRule profile for the server config context.
+//
+// Apache uses PCRE, so all syntax features are supported.
+// In the server context, the URL path always starts with `/`.
+var Apache = &Profile{
+	Name: "Apache",
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		if ch, ok := anchoredChar(e); ok && ch != '/' {
+			report(e, "Apache URL path always starts with / in the server context, the pattern never matches")
+		}
+	},
+}
+
+// ApacheHtaccess is the Apache RewriteRule profile for the .htaccess
+// and <Directory> context.
+//
+// In the per-directory context, the directory prefix is removed
+// from the URL path, so it never starts with `/`.
+var ApacheHtaccess = &Profile{
+	Name: "Apache",
+	Check: func(e syntax.Expr, report func(e syntax.Expr, message string)) {
+		if ch, ok := anchoredChar(e); ok && ch == '/' {
+			report(e, "Apache URL path never starts with / in the per-directory context, the pattern never matches")
+		}
+	},
+}
+
+// anchoredChar returns the first char of a `^c` or `\Ac` concatenation.
+func anchoredChar(e syntax.Expr) (byte, bool) {
+	if e.Op != syntax.OpConcat || len(e.Args) < 2 {
+		return 0, false
+	}
+	anchor := e.Args[0]
+	if anchor.Op != syntax.OpCaret && !(anchor.Op == syntax.OpEscapeChar && anchor.Value == `\A`) {
+		return 0, false
+	}
+	next := e.Args[1]
+	if next.Op == syntax.OpLiteral {
+		next = next.Args[0]
+	}
+	switch next.Op {
+	case syntax.OpChar:
+		return next.Value[0], true
+	case syntax.OpEscapeMeta:
+		return next.Args[0].Value[0], true
+	}
+	return 0, false
+}
+
+// CheckNginxReplacement reports the `$N` references inside the nginx
+// rewrite replacement that refer to the groups that re doesn't have.
+//
+// The `$name` references are not checked, as they can
+// refer to the nginx variables as well as to the named groups.
+func CheckNginxReplacement(re *syntax.Regexp, replacement string) []Issue {
+	return checkReplacement(re, replacement, 0)
+}
+
+// CheckApacheSubstitution reports the `$N` references inside the Apache
+// RewriteRule substitution that refer to the groups that re doesn't have.
+//
+// The `%N` RewriteCond references are not checked.
+func CheckApacheSubstitution(re *syntax.Regexp, substitution string) []Issue {
+	return checkReplacement(re, substitution, '\\')
+}
+
+// checkReplacement reports the `$N` references to the missing groups.
+// An escape char makes the next char literal, 0 means that there are no escapes.
+func checkReplacement(re *syntax.Regexp, s string, escape byte) []Issue {
+	groups := countCaptures(re.Expr)
+	var issues []Issue
+	for i := 0; i < len(s)-1; i++ {
+		switch {
+		case escape != 0 && s[i] == escape:
+			i++
+		case s[i] == '$' && s[i+1] >= '0' && s[i+1] <= '9':
+			n := int(s[i+1] - '0')
+			if n > groups {
+				issues = append(issues, Issue{
+					Pos:     syntax.Position{Begin: uint16(i), End: uint16(i + 2)},
+					Message: "$" + strconv.Itoa(n) + " refers to a non-existing group",
+				})
+			}
+			i++
+		}
+	}
+	return issues
+}
+
+func countCaptures(e syntax.Expr) int {
+	n := 0
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		n++
+	}
+	for _, a := range e.Args {
+		n += countCaptures(a)
+	}
+	return n
+}
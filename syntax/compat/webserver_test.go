@@ -0,0 +1,69 @@
+package compat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestWebServerIssues(t *testing.T) {
+	tests := []struct {
+		profile *Profile
+		pattern string
+		want    []string
+	}{
+		{Nginx, `^/api/(v\d+)/(?<rest>.*)$`, nil},
+		{Nginx, `\.php$|^\/x`, nil},
+		{Nginx, `^api/`, []string{`0: nginx URI always starts with /, the pattern never matches`}},
+		{Nginx, `(?:^images|^/img)`, []string{`3: nginx URI always starts with /, the pattern never matches`}},
+		{Apache, `\Aold(.*)`, []string{`0: Apache URL path always starts with / in the server context, the pattern never matches`}},
+		{ApacheHtaccess, `^old/(.*)$`, nil},
+		{ApacheHtaccess, `^/old/(.*)$`, []string{`0: Apache URL path never starts with / in the per-directory context, the pattern never matches`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range test.profile.Issues(re) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s Issues(%q):\nhave: %q\nwant: %q", test.profile.Name, test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestCheckReplacement(t *testing.T) {
+	tests := []struct {
+		check       func(re *syntax.Regexp, s string) []Issue
+		pattern     string
+		replacement string
+		want        []string
+	}{
+		{CheckNginxReplacement, `^/(\w+)/(?<id>\d+)$`, `/$1?id=$2&$args`, nil},
+		{CheckNginxReplacement, `^/(\w+)$`, `/$1/$2$0`, []string{`4: $2 refers to a non-existing group`}},
+		{CheckApacheSubstitution, `^old/(.*)$`, `new/$1 \$2`, nil},
+		{CheckApacheSubstitution, `^old/.*$`, `new/$1?%1`, []string{`4: $1 refers to a non-existing group`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, issue := range test.check(re, test.replacement) {
+			have = append(have, fmt.Sprintf("%d: %s", issue.Pos.Begin, issue))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("check(%q, %q):\nhave: %q\nwant: %q", test.pattern, test.replacement, have, test.want)
+		}
+	}
+}
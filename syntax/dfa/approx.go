@@ -0,0 +1,114 @@
+package dfa
+
+import (
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// MatchApprox reports whether s contains a substring that is at most
+// maxErrors edits away from a pattern match.
+//
+// An edit is a rune insertion, deletion or substitution (the Levenshtein
+// distance). The minimal number of edits is returned as errors.
+// The approximate matching uses the NFA simulation, the states cache
+// is not used.
+func (m *Matcher) MatchApprox(s string, maxErrors int) (errors int, ok bool) {
+	a := m.nfa
+	best := maxErrors + 1
+	dist := a.newDist(maxErrors)
+	dist[a.start] = 0
+	a.closureDist(dist, maxErrors, true, len(s) == 0)
+	if d := dist[a.match]; d < best {
+		best = d
+	}
+	for i := 0; i < len(s); {
+		ch, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		dist = a.stepDist(dist, ch, maxErrors)
+		a.closureDist(dist, maxErrors, false, i == len(s))
+		if d := dist[a.match]; d < best {
+			best = d
+		}
+		if best == 0 {
+			break
+		}
+	}
+	if best > maxErrors {
+		return -1, false
+	}
+	return best, true
+}
+
+// newDist returns the per-state edits counts, all set to the unreachable value.
+func (a *nfa) newDist(maxErrors int) []int {
+	dist := make([]int, len(a.states))
+	for i := range dist {
+		dist[i] = maxErrors + 1
+	}
+	return dist
+}
+
+// closureDist propagates the edits counts along the edges that don't
+// consume any input: the epsilon and the assertion edges are free,
+// the rune edges cost 1 (a deleted rune).
+func (a *nfa) closureDist(dist []int, maxErrors int, atBegin, atEnd bool) {
+	// The weights are 0 and 1, so a deque-based BFS finds
+	// the shortest paths.
+	var deque []int
+	for s, d := range dist {
+		if d <= maxErrors {
+			deque = append(deque, s)
+		}
+	}
+	for len(deque) != 0 {
+		s := deque[0]
+		deque = deque[1:]
+		for _, e := range a.states[s] {
+			cost := 0
+			switch {
+			case e.Kind == thompson.EdgeRunes:
+				cost = 1
+			case e.Kind == thompson.EdgeBegin && !atBegin, e.Kind == thompson.EdgeEnd && !atEnd:
+				continue
+			}
+			if d := dist[s] + cost; d < dist[e.To] && d <= maxErrors {
+				dist[e.To] = d
+				if cost == 0 {
+					deque = append([]int{e.To}, deque...)
+				} else {
+					deque = append(deque, e.To)
+				}
+			}
+		}
+	}
+}
+
+// stepDist returns the edits counts after consuming ch.
+// A rune can be matched, substituted or inserted.
+// The start state prefix loop consumes any prefix for free.
+func (a *nfa) stepDist(dist []int, ch rune, maxErrors int) []int {
+	next := a.newDist(maxErrors)
+	relax := func(s, d int) {
+		if d < next[s] {
+			next[s] = d
+		}
+	}
+	for s, d := range dist {
+		if d > maxErrors {
+			continue
+		}
+		relax(s, d+1)
+		for _, e := range a.states[s] {
+			if e.Kind != thompson.EdgeRunes {
+				continue
+			}
+			if e.Runes.Contains(ch) {
+				relax(e.To, d)
+			} else {
+				relax(e.To, d+1)
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,603 @@
+// Package dfa implements a lazy DFA matcher.
+//
+// The DFA states are built on the fly during the matching and cached,
+// so every state is built at most once and only the reachable states
+// are built. The cache size is bounded: when it's full, the cache is
+// flushed, and if it happens too often, the matcher falls back to the
+// NFA simulation for the rest of the input. This is how the RE2
+// hybrid NFA/DFA works.
+//
+// Only the regular subset of the syntax is supported, see Compile.
+package dfa
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Options configure the matcher.
+type Options struct {
+	// MaxStates is a max number of the cached DFA states.
+	// Every state takes about 1KB of memory.
+	// If zero, 4096 is used.
+	MaxStates int
+
+	// MaxFlushes is a number of the cache flushes during a single
+	// match after which the matcher falls back to the NFA simulation.
+	// If zero, 4 is used.
+	MaxFlushes int
+
+	// Longest selects the POSIX leftmost-longest semantics for MatchAt:
+	// the longest possible match is reported. By default, the leftmost-first
+	// (Perl) semantics is used: the alternatives and the quantifier
+	// choices are tried in their priority order, like in Go regexp.
+	Longest bool
+
+	// Newline is a line terminator convention for `.` and `\R`.
+	// See syntax.Newline and charset.Dot for details.
+	Newline syntax.Newline
+}
+
+// Stats are the matcher cache statistics.
+type Stats struct {
+	// States is a number of the currently cached DFA states.
+	States int
+
+	// Flushes is a total number of the cache flushes.
+	Flushes int
+
+	// Fallbacks is a number of the matches that used the NFA simulation.
+	Fallbacks int
+}
+
+// Matcher is a lazy DFA matcher.
+//
+// It's safe for concurrent use. All matches share the states cache:
+// the cached transitions are followed without locking, the lock is
+// only taken to add a new state.
+type Matcher struct {
+	nfa        *nfa
+	maxStates  int
+	maxFlushes int
+	longest    bool
+
+	// mu guards the caches and the stats.
+	mu sync.Mutex
+
+	// anchored runs MatchAt with the Options.Longest semantics.
+	anchored lazyDFA
+	// search finds the leftmost-first match end in a single pass.
+	search lazyDFA
+	// reverse finds the match start from its end.
+	reverse lazyDFA
+	// reverseSearch finds all match starts in a single pass.
+	reverseSearch lazyDFA
+
+	stats Stats
+}
+
+// lazyDFA is a states cache of one of the matcher automata.
+type lazyDFA struct {
+	nfa     *nfa
+	longest bool
+	cache   map[string]*state
+}
+
+// state is a DFA state, a set of the NFA states.
+type state struct {
+	nfaStates []int
+	match     bool
+
+	// accepts is 1 if the state is accepting at the end of the input,
+	// -1 if it's not and 0 if it's not computed yet.
+	// It's accessed atomically.
+	accepts int32
+
+	// ascii are the *state transitions, accessed atomically.
+	ascii [utf8.RuneSelf]unsafe.Pointer
+	// other is a map[rune]*state that is copied on write.
+	other atomic.Value
+}
+
+// Compile returns a matcher for re.
+//
+// Backreferences, lookarounds, atomic groups, possessive quantifiers,
+// flags and word boundaries are not supported.
+// `.` doesn't match a line terminator, `$` matches only at the end of the input.
+// `\X` matches an extended grapheme cluster using a regular approximation
+// of the Unicode segmentation rules.
+func Compile(re *syntax.Regexp, opts *Options) (*Matcher, error) {
+	var newline syntax.Newline
+	if opts != nil {
+		newline = opts.Newline
+	}
+	a, err := compileNFA(re.Expr, newline)
+	if err != nil {
+		return nil, err
+	}
+	m := &Matcher{
+		nfa:        a,
+		maxStates:  4096,
+		maxFlushes: 4,
+	}
+	if opts != nil && opts.MaxStates != 0 {
+		m.maxStates = opts.MaxStates
+	}
+	if opts != nil && opts.MaxFlushes != 0 {
+		m.maxFlushes = opts.MaxFlushes
+	}
+	if opts != nil {
+		m.longest = opts.Longest
+	}
+	m.anchored = lazyDFA{nfa: a, longest: m.longest}
+	m.search = lazyDFA{nfa: a}
+	m.reverse = lazyDFA{nfa: a.reverse(), longest: true}
+	m.reverseSearch = lazyDFA{nfa: m.reverse.nfa, longest: true}
+	m.flush()
+	return m, nil
+}
+
+// Stats returns the current cache statistics.
+func (m *Matcher) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.States = m.numStates()
+	return stats
+}
+
+// MatchString reports whether s contains a match.
+func (m *Matcher) MatchString(s string) bool {
+	d := &m.search
+	flushes := 0
+	st := m.initial(d, d.nfa.closure([]int{d.nfa.start}, true, false), true)
+	for i := 0; i < len(s); {
+		if st.match {
+			return true
+		}
+		ch, size := rune(s[i]), 1
+		if ch >= utf8.RuneSelf {
+			ch, size = utf8.DecodeRuneInString(s[i:])
+		}
+		next := m.next(d, st, ch, &flushes)
+		if next == nil {
+			return m.simulate(st.nfaStates, s[i:])
+		}
+		st = next
+		i += size
+	}
+	return d.acceptsAtEnd(st, len(s) == 0)
+}
+
+// MatchAt matches the pattern at s[offset:] and returns the match end.
+//
+// Only the matches that start exactly at offset are accepted,
+// even if the pattern is not anchored. `^` and `\A` still match
+// only at the beginning of s. If there are several possible
+// match ends, the one selected by the Options.Longest semantics
+// is reported. An offset outside of [0, len(s)] never matches.
+func (m *Matcher) MatchAt(s string, offset int) (end int, ok bool) {
+	if offset < 0 || offset > len(s) {
+		return -1, false
+	}
+	return m.matchAt(s, offset)
+}
+
+// Index returns the leftmost match location inside s.
+// If there is no match, both begin and end are -1.
+//
+// The match end is found by a single forward scan and its start
+// is recovered by a backward scan from the end. With the
+// Options.Longest semantics, the match end is then extended
+// like MatchAt does.
+func (m *Matcher) Index(s string) (begin, end int) {
+	return m.index(s, 0)
+}
+
+// Split slices s into the substrings separated by the matches,
+// like regexp.Regexp.Split does.
+//
+// n limits the number of the returned substrings: if n > 0,
+// at most n substrings are returned, the last one is the unsplit
+// remainder. If n == 0, the result is nil. If n < 0, all
+// substrings are returned.
+func (m *Matcher) Split(s string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	if s == "" {
+		return []string{""}
+	}
+
+	var parts []string
+	prevEnd := -1
+	offset := 0
+	lastBegin := 0
+	for pos := 0; pos <= len(s) && len(parts) != n-1; {
+		begin, end := m.index(s, pos)
+		if begin == -1 {
+			break
+		}
+		accept := true
+		if end == pos {
+			// An empty match right after the previous one is skipped,
+			// like it's done by the regexp package.
+			accept = begin != prevEnd
+			pos = nextOffset(s, pos)
+		} else {
+			pos = end
+		}
+		prevEnd = end
+		if !accept {
+			continue
+		}
+		lastBegin = begin
+		if end != 0 {
+			parts = append(parts, s[offset:begin])
+		}
+		offset = end
+	}
+	if lastBegin != len(s) {
+		parts = append(parts, s[offset:])
+	}
+	return parts
+}
+
+// index returns the leftmost match that starts at pos or later.
+//
+// The leftmost-first match end is found by the unanchored search.
+// The leftmost begin of the matches that end there is the begin
+// of the leftmost match, so it's found by the reverse automaton.
+func (m *Matcher) index(s string, pos int) (begin, end int) {
+	end, ok := m.matchFrom(&m.search, s, pos, m.nfa.start)
+	if !ok {
+		return -1, -1
+	}
+	begin = m.matchBack(s, pos, end)
+	if m.longest {
+		end, _ = m.matchAt(s, begin)
+	}
+	return begin, end
+}
+
+// nextOffset returns the offset of the rune that follows s[i].
+// For i == len(s) it returns len(s)+1.
+func nextOffset(s string, i int) int {
+	if i == len(s) {
+		return i + 1
+	}
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return i + size
+}
+
+// FindAllOverlapping returns the locations of all matches inside s,
+// including the overlapping ones, ordered by their start offsets.
+//
+// The match starts are found by a single backward scan, then every
+// start is matched like MatchAt does. It takes linear time if there
+// are no matches, but the overlapping matches can be rescanned many times.
+func (m *Matcher) FindAllOverlapping(s string) [][]int {
+	var locs [][]int
+	starts := m.matchStarts(s)
+	for i := len(starts) - 1; i >= 0; i-- {
+		if end, ok := m.matchAt(s, starts[i]); ok {
+			locs = append(locs, []int{starts[i], end})
+		}
+	}
+	return locs
+}
+
+// matchStarts returns the offsets of all match starts in the descending order.
+func (m *Matcher) matchStarts(s string) []int {
+	d := &m.reverseSearch
+	flushes := 0
+	st := m.initial(d, d.nfa.closure([]int{d.nfa.start}, true, false), true)
+	var starts []int
+	for i := len(s); i > 0; {
+		ch, size := rune(s[i-1]), 1
+		if ch >= utf8.RuneSelf {
+			ch, size = utf8.DecodeLastRuneInString(s[:i])
+		}
+		next := m.next(d, st, ch, &flushes)
+		if next == nil {
+			return m.simulateStarts(st.nfaStates, s, i, starts)
+		}
+		if st.match {
+			starts = append(starts, i)
+		}
+		st = next
+		i -= size
+	}
+	if d.acceptsAtEnd(st, len(s) == 0) {
+		starts = append(starts, 0)
+	}
+	return starts
+}
+
+func (m *Matcher) matchAt(s string, offset int) (end int, ok bool) {
+	return m.matchFrom(&m.anchored, s, offset, m.nfa.anchor)
+}
+
+// matchFrom runs d from the entry NFA state at s[offset:]
+// and returns the last match end.
+func (m *Matcher) matchFrom(d *lazyDFA, s string, offset, entry int) (end int, ok bool) {
+	flushes := 0
+	atBegin := offset == 0
+	st := m.initial(d, d.nfa.closure([]int{entry}, atBegin, false), atBegin)
+	end = -1
+	for i := offset; i < len(s); {
+		if st.match {
+			end = i
+		}
+		if len(st.nfaStates) == 0 {
+			return end, end != -1
+		}
+		ch, size := rune(s[i]), 1
+		if ch >= utf8.RuneSelf {
+			ch, size = utf8.DecodeRuneInString(s[i:])
+		}
+		next := m.next(d, st, ch, &flushes)
+		if next == nil {
+			return m.simulateAt(d, st.nfaStates, s, i, end)
+		}
+		st = next
+		i += size
+	}
+	if d.acceptsAtEnd(st, len(s) == 0) {
+		end = len(s)
+	}
+	return end, end != -1
+}
+
+// matchBack returns the leftmost begin of the match that ends at end.
+// The begin is not less than pos. If there is no such match, it returns -1.
+func (m *Matcher) matchBack(s string, pos, end int) int {
+	d := &m.reverse
+	flushes := 0
+	atBegin := end == len(s)
+	st := m.initial(d, d.nfa.closure([]int{d.nfa.anchor}, atBegin, false), atBegin)
+	begin := -1
+	for i := end; i > pos; {
+		if st.match {
+			begin = i
+		}
+		if len(st.nfaStates) == 0 {
+			return begin
+		}
+		ch, size := rune(s[i-1]), 1
+		if ch >= utf8.RuneSelf {
+			ch, size = utf8.DecodeLastRuneInString(s[:i])
+		}
+		next := m.next(d, st, ch, &flushes)
+		if next == nil {
+			return m.simulateBack(st.nfaStates, s, pos, i, begin)
+		}
+		st = next
+		i -= size
+	}
+	if pos == 0 {
+		// The reversed input ends at the beginning of s.
+		if d.acceptsAtEnd(st, len(s) == 0) {
+			begin = 0
+		}
+	} else if st.match {
+		begin = pos
+	}
+	return begin
+}
+
+// next returns the st successor for ch, building it if necessary.
+// It returns nil if the cache was flushed too many times during
+// the current match, so it should fall back to the NFA simulation.
+func (m *Matcher) next(d *lazyDFA, st *state, ch rune, flushes *int) *state {
+	if next := st.next(ch); next != nil {
+		return next
+	}
+	// The NFA step doesn't need the lock, so it's done before it.
+	nfaStates := d.nfa.step(st.nfaStates, ch)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if next := st.next(ch); next != nil {
+		// Another match has added it meanwhile.
+		return next
+	}
+	if m.numStates() >= m.maxStates {
+		m.flush()
+		m.stats.Flushes++
+		*flushes++
+		if *flushes > m.maxFlushes {
+			m.stats.Fallbacks++
+			return nil
+		}
+	}
+	next := d.newState(nfaStates, false)
+	st.setNext(ch, next)
+	return next
+}
+
+// initial returns the cached initial state for the NFA states set.
+func (m *Matcher) initial(d *lazyDFA, nfaStates []int, atBegin bool) *state {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return d.newState(nfaStates, atBegin)
+}
+
+// acceptsAtEnd reports whether st is accepting at the end of the input.
+// atBegin is true if the end of the input is also its beginning.
+func (d *lazyDFA) acceptsAtEnd(st *state, atBegin bool) bool {
+	accepts := atomic.LoadInt32(&st.accepts)
+	if accepts == 0 {
+		// The concurrent matches may compute it twice,
+		// but they store the same result.
+		accepts = -1
+		if d.nfa.accepts(st.nfaStates, atBegin) {
+			accepts = 1
+		}
+		atomic.StoreInt32(&st.accepts, accepts)
+	}
+	return accepts == 1
+}
+
+// simulate continues the MatchString with the NFA simulation.
+// states are the current NFA states, s is the rest of the input.
+func (m *Matcher) simulate(states []int, s string) bool {
+	for _, ch := range s {
+		if m.nfa.hasMatch(states) {
+			return true
+		}
+		states = m.nfa.step(states, ch)
+	}
+	return m.nfa.accepts(states, false)
+}
+
+// simulateAt is like simulate, but for the matchFrom.
+// i is the current s offset, end is the last match end so far.
+func (m *Matcher) simulateAt(d *lazyDFA, states []int, s string, i, end int) (int, bool) {
+	for i < len(s) {
+		if d.nfa.hasMatch(states) {
+			end = i
+		}
+		if len(states) == 0 {
+			return end, end != -1
+		}
+		ch, size := utf8.DecodeRuneInString(s[i:])
+		states = d.cut(d.nfa.step(states, ch))
+		i += size
+	}
+	if d.nfa.accepts(states, false) {
+		end = len(s)
+	}
+	return end, end != -1
+}
+
+// simulateBack is like simulate, but for the matchBack.
+// i is the current s offset, begin is the leftmost begin so far.
+func (m *Matcher) simulateBack(states []int, s string, pos, i, begin int) int {
+	a := m.reverse.nfa
+	for i > pos {
+		if a.hasMatch(states) {
+			begin = i
+		}
+		if len(states) == 0 {
+			return begin
+		}
+		ch, size := utf8.DecodeLastRuneInString(s[:i])
+		states = a.step(states, ch)
+		i -= size
+	}
+	if pos == 0 {
+		if a.accepts(states, false) {
+			begin = 0
+		}
+	} else if a.hasMatch(states) {
+		begin = pos
+	}
+	return begin
+}
+
+// simulateStarts continues matchStarts with the NFA simulation
+// when the cache was flushed too many times.
+func (m *Matcher) simulateStarts(states []int, s string, i int, starts []int) []int {
+	a := m.reverseSearch.nfa
+	for i > 0 {
+		if a.hasMatch(states) {
+			starts = append(starts, i)
+		}
+		ch, size := utf8.DecodeLastRuneInString(s[:i])
+		states = a.step(states, ch)
+		i -= size
+	}
+	if a.accepts(states, len(s) == 0) {
+		starts = append(starts, 0)
+	}
+	return starts
+}
+
+func (m *Matcher) numStates() int {
+	return len(m.anchored.cache) + len(m.search.cache) +
+		len(m.reverse.cache) + len(m.reverseSearch.cache)
+}
+
+// flush drops all cached states.
+// The states that are still used by the running matches stay valid,
+// they are just not reachable from the new cache.
+func (m *Matcher) flush() {
+	m.anchored.cache = make(map[string]*state)
+	m.search.cache = make(map[string]*state)
+	m.reverse.cache = make(map[string]*state)
+	m.reverseSearch.cache = make(map[string]*state)
+}
+
+// newState returns a cached state for the NFA states set.
+// It must be called with Matcher.mu held.
+// The initial states are cached separately, as the end of the input
+// assertions depend on whether it's also the beginning of the input.
+func (d *lazyDFA) newState(nfaStates []int, initial bool) *state {
+	key := stateKey(nfaStates)
+	if initial {
+		key = "^" + key
+	}
+	if st := d.cache[key]; st != nil {
+		return st
+	}
+	nfaStates = d.cut(nfaStates)
+	st := &state{
+		nfaStates: nfaStates,
+		match:     d.nfa.hasMatch(nfaStates),
+	}
+	d.cache[key] = st
+	return st
+}
+
+// cut drops the states that have lower priority than the match state
+// for the leftmost-first semantics: once a match is found, only the
+// preferred alternatives can replace it.
+func (d *lazyDFA) cut(nfaStates []int) []int {
+	if d.longest {
+		return nfaStates
+	}
+	if i := d.nfa.matchIndex(nfaStates); i != -1 {
+		return nfaStates[:i+1]
+	}
+	return nfaStates
+}
+
+func stateKey(nfaStates []int) string {
+	var b strings.Builder
+	for _, s := range nfaStates {
+		b.WriteString(strconv.Itoa(s))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func (st *state) next(ch rune) *state {
+	if ch < utf8.RuneSelf {
+		return (*state)(atomic.LoadPointer(&st.ascii[ch]))
+	}
+	other, _ := st.other.Load().(map[rune]*state)
+	return other[ch]
+}
+
+// setNext adds the st transition for ch.
+// It must be called with Matcher.mu held.
+func (st *state) setNext(ch rune, next *state) {
+	if ch < utf8.RuneSelf {
+		atomic.StorePointer(&st.ascii[ch], unsafe.Pointer(next))
+		return
+	}
+	// The map is copied, so the concurrent readers don't need the lock.
+	old, _ := st.other.Load().(map[rune]*state)
+	other := make(map[rune]*state, len(old)+1)
+	for r, s := range old {
+		other[r] = s
+	}
+	other[ch] = next
+	st.other.Store(other)
+}
@@ -0,0 +1,555 @@
+package dfa
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestMatcher(t *testing.T) {
+	patterns := []string{
+		`abc`,
+		`^a+b*$`,
+		`(?:a|bc)*c`,
+		`^$`,
+		`\Aab?\z`,
+		`[a-c]{2,3}x|y+`,
+		`.a.`,
+		`(a|b)*a(a|b){3}`,
+		`ж[^a]`,
+		`\Qa.\E|x`,
+		`(?:^|x)a+`,
+		`(?:a|^b)(?:$|c)`,
+	}
+	alphabet := []string{"a", "b", "c", "x", "y", ".", "ж", "\n"}
+
+	p := syntax.NewParser(nil)
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		m, err := Compile(re, nil)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", pattern, err)
+			continue
+		}
+		std := regexp.MustCompile(pattern)
+		for i := 0; i < 2000; i++ {
+			var b strings.Builder
+			for n := rng.Intn(10); n > 0; n-- {
+				b.WriteString(alphabet[rng.Intn(len(alphabet))])
+			}
+			s := b.String()
+			want := std.MatchString(s)
+			if have := m.MatchString(s); have != want {
+				t.Fatalf("%q MatchString(%q):\nhave: %v\nwant: %v", pattern, s, have, want)
+			}
+			if _, have := m.MatchApprox(s, 0); have != want {
+				t.Fatalf("%q MatchApprox(%q, 0):\nhave: %v\nwant: %v", pattern, s, have, want)
+			}
+			if have, want := fmt.Sprint(m.Index(s)), fmt.Sprint(stdIndex(std, s)); have != want {
+				t.Fatalf("%q Index(%q):\nhave: %s\nwant: %s", pattern, s, have, want)
+			}
+		}
+	}
+}
+
+func TestMatchAt(t *testing.T) {
+	for _, longest := range []bool{false, true} {
+		testMatchAt(t, longest)
+	}
+}
+
+func testMatchAt(t *testing.T, longest bool) {
+	patterns := []string{
+		`abc`,
+		`a+b*$`,
+		`(?:a|bc)*c`,
+		`a|ab|abc`,
+		`abc|ab|a`,
+		`a*?b?`,
+		`(?:ab)+?|a+`,
+		`a{1,3}?b??`,
+		`x?`,
+		`[a-c]{2,3}x|y+`,
+		`.a.`,
+		`ж[^a]`,
+		`^(?:a|b)x?`,
+		`\Aa*`,
+		`a$|b`,
+		`b*$`,
+		`(?:a|ab)(?:c|bcd)`,
+	}
+	alphabet := []string{"a", "b", "c", "x", "y", ".", "ж", "\n"}
+
+	p := syntax.NewParser(nil)
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		m, err := Compile(re, &Options{Longest: longest})
+		if err != nil {
+			t.Errorf("Compile(%q): %v", pattern, err)
+			continue
+		}
+		beginAnchored := strings.HasPrefix(pattern, "^") || strings.HasPrefix(pattern, `\A`)
+		std := regexp.MustCompile(`\A(?:` + pattern + `)`)
+		if longest {
+			std.Longest()
+		}
+		search := regexp.MustCompile(pattern)
+		if longest {
+			search.Longest()
+		}
+		for i := 0; i < 2000; i++ {
+			var b strings.Builder
+			for n := rng.Intn(10); n > 0; n-- {
+				b.WriteString(alphabet[rng.Intn(len(alphabet))])
+			}
+			s := b.String()
+			offset := rng.Intn(len(s) + 1)
+			for offset < len(s) && !utf8.RuneStart(s[offset]) {
+				offset--
+			}
+			want := -1
+			if offset == 0 || !beginAnchored {
+				if loc := std.FindStringIndex(s[offset:]); loc != nil {
+					want = offset + loc[1]
+				}
+			}
+			end, ok := m.MatchAt(s, offset)
+			if end != want || ok != (want != -1) {
+				t.Fatalf("%q MatchAt(%q, %d) (longest=%v):\nhave: %d %v\nwant: %d",
+					pattern, s, offset, longest, end, ok, want)
+			}
+			if have, want := fmt.Sprint(m.Index(s)), fmt.Sprint(stdIndex(search, s)); have != want {
+				t.Fatalf("%q Index(%q) (longest=%v):\nhave: %s\nwant: %s", pattern, s, longest, have, want)
+			}
+			for _, n := range []int{-1, 0, 2} {
+				if have, want := fmt.Sprintf("%q", m.Split(s, n)), fmt.Sprintf("%q", search.Split(s, n)); have != want {
+					t.Fatalf("%q Split(%q, %d) (longest=%v):\nhave: %s\nwant: %s", pattern, s, n, longest, have, want)
+				}
+			}
+		}
+	}
+}
+
+func stdIndex(re *regexp.Regexp, s string) (begin, end int) {
+	if loc := re.FindStringIndex(s); loc != nil {
+		return loc[0], loc[1]
+	}
+	return -1, -1
+}
+
+func TestMatchAtOutOfRange(t *testing.T) {
+	p := syntax.NewParser(nil)
+	for _, pattern := range []string{``, `a*`, `x?$`} {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		m, err := Compile(re, nil)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		for _, offset := range []int{-1, 4, 100} {
+			if end, ok := m.MatchAt("abc", offset); ok || end != -1 {
+				t.Errorf("%q MatchAt(%q, %d):\nhave: %d %v\nwant: -1 false", pattern, "abc", offset, end, ok)
+			}
+		}
+		if end, ok := m.MatchAt("abc", 3); !ok || end != 3 {
+			t.Errorf("%q MatchAt(%q, 3):\nhave: %d %v\nwant: 3 true", pattern, "abc", end, ok)
+		}
+	}
+}
+
+func TestFindAllOverlapping(t *testing.T) {
+	tests := []struct {
+		pattern string
+		longest bool
+		input   string
+		want    string
+	}{
+		{`aa`, false, "aaaa", `[[0 2] [1 3] [2 4]]`},
+		{`a|ab`, false, "abab", `[[0 1] [2 3]]`},
+		{`a|ab`, true, "abab", `[[0 2] [2 4]]`},
+		{`b*`, false, "ab", `[[0 0] [1 2] [2 2]]`},
+		{`ж.`, false, "жжж", `[[0 4] [2 6]]`},
+		{`^a`, false, "aa", `[[0 1]]`},
+		{`x`, false, "aa", `[]`},
+		{`a$|b`, false, "abab", `[[1 2] [3 4]]`},
+		{`$`, false, "ab", `[[2 2]]`},
+		{`(?:^|x)a`, false, "axaa", `[[0 1] [1 3]]`},
+		{`x*`, false, "", `[[0 0]]`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re, &Options{Longest: test.longest})
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", test.pattern, err)
+		}
+		have := fmt.Sprint(m.FindAllOverlapping(test.input))
+		if have != test.want {
+			t.Errorf("%q FindAllOverlapping(%q):\nhave: %s\nwant: %s", test.pattern, test.input, have, test.want)
+		}
+	}
+}
+
+func TestMatcherFallback(t *testing.T) {
+	// The DFA for this pattern has 2^11 states.
+	const pattern = `(a|b)*a(a|b){10}$`
+	re, err := syntax.NewParser(nil).Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Compile(re, &Options{MaxStates: 64, MaxFlushes: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := regexp.MustCompile(pattern)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		var b strings.Builder
+		for n := 0; n < 500; n++ {
+			b.WriteByte("ab"[rng.Intn(2)])
+		}
+		s := b.String()
+		if have, want := m.MatchString(s), std.MatchString(s); have != want {
+			t.Fatalf("MatchString(%q):\nhave: %v\nwant: %v", s, have, want)
+		}
+	}
+
+	for i := 0; i < 50; i++ {
+		var b strings.Builder
+		for n := 0; n < 500; n++ {
+			b.WriteByte("ab"[rng.Intn(2)])
+		}
+		s := b.String()
+		want := -1
+		if std.MatchString(s) {
+			want = len(s)
+		}
+		if end, _ := m.MatchAt(s, 0); end != want {
+			t.Fatalf("MatchAt(%q, 0):\nhave: %d\nwant: %d", s, end, want)
+		}
+	}
+
+	stats := m.Stats()
+	if stats.Flushes == 0 || stats.Fallbacks == 0 || stats.States > 64 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestFindAllOverlappingFallback(t *testing.T) {
+	// The reverse search automaton for this pattern is exponentially big.
+	const pattern = `(?:a|b){8}a`
+	re, err := syntax.NewParser(nil).Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Compile(re, &Options{MaxStates: 32, MaxFlushes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		var b strings.Builder
+		for n := 0; n < 200; n++ {
+			b.WriteByte("ab"[rng.Intn(2)])
+		}
+		s := b.String()
+		var want [][]int
+		for offset := 0; offset+9 <= len(s); offset++ {
+			if s[offset+8] == 'a' {
+				want = append(want, []int{offset, offset + 9})
+			}
+		}
+		have := m.FindAllOverlapping(s)
+		if fmt.Sprint(have) != fmt.Sprint(want) {
+			t.Fatalf("FindAllOverlapping(%q):\nhave: %v\nwant: %v", s, have, want)
+		}
+	}
+
+	if stats := m.Stats(); stats.Fallbacks == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestIndexFallback(t *testing.T) {
+	// The forward search automata for these patterns are exponentially big,
+	// and so is the reverse automaton for the second one.
+	patterns := []string{
+		`a(?:a|b){8}c`,
+		`(?:a|b){8}a(?:a|b)*c`,
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		re, err := syntax.NewParser(nil).Parse(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := Compile(re, &Options{MaxStates: 32, MaxFlushes: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		std := regexp.MustCompile(pattern)
+		for i := 0; i < 50; i++ {
+			var b strings.Builder
+			for n := 0; n < 200; n++ {
+				if rng.Intn(50) == 0 {
+					b.WriteByte('c')
+				} else {
+					b.WriteByte("ab"[rng.Intn(2)])
+				}
+			}
+			s := b.String()
+			if have, want := fmt.Sprint(m.Index(s)), fmt.Sprint(stdIndex(std, s)); have != want {
+				t.Fatalf("%q Index(%q):\nhave: %s\nwant: %s", pattern, s, have, want)
+			}
+		}
+		if stats := m.Stats(); stats.Fallbacks == 0 {
+			t.Errorf("%q: unexpected stats: %+v", pattern, stats)
+		}
+	}
+}
+
+func TestMatcherConcurrent(t *testing.T) {
+	// The search automaton is exponentially big, so the matches
+	// flush the small cache under each other.
+	const pattern = `a(?:é|b){6}c`
+	re, err := syntax.NewParser(nil).Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Compile(re, &Options{MaxStates: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := regexp.MustCompile(pattern)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 50; i++ {
+				var b strings.Builder
+				for n := 0; n < 100; n++ {
+					b.WriteString([]string{"a", "é", "b", "c"}[rng.Intn(4)])
+				}
+				s := b.String()
+				if have, want := fmt.Sprint(m.Index(s)), fmt.Sprint(stdIndex(std, s)); have != want {
+					t.Errorf("Index(%q):\nhave: %s\nwant: %s", s, have, want)
+					return
+				}
+				if have, want := m.MatchString(s), std.MatchString(s); have != want {
+					t.Errorf("MatchString(%q):\nhave: %v\nwant: %v", s, have, want)
+					return
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+
+	if stats := m.Stats(); stats.Flushes == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func BenchmarkIndexNoMatch(b *testing.B) {
+	s := strings.Repeat("a", 40000)
+	re, err := syntax.NewParser(nil).Parse(`a.*b`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	m, err := Compile(re, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if begin, _ := m.Index(s); begin != -1 {
+			b.Fatal("unexpected match")
+		}
+	}
+}
+
+func TestNewline(t *testing.T) {
+	tests := []struct {
+		pattern string
+		newline syntax.Newline
+		input   string
+		want    int
+	}{
+		{`a.`, syntax.NewlineDefault, "a\r", 2},
+		{`a.`, syntax.NewlineDefault, "a\n", -1},
+		{`a.`, syntax.NewlineCR, "a\n", 2},
+		{`a.`, syntax.NewlineCR, "a\r", -1},
+		{`a.`, syntax.NewlineCRLF, "a\r", -1},
+		{`a.`, syntax.NewlineAny, "a\u2028", -1},
+		{`a.`, syntax.NewlineAnyCRLF, "a\u2028", 1 + len("\u2028")},
+		{`a\R`, syntax.NewlineDefault, "a\r\n", 3},
+		{`a\R`, syntax.NewlineDefault, "a\u0085", 1 + len("\u0085")},
+		{`a\R`, syntax.NewlineLF, "a\u0085", -1},
+		{`a\R`, syntax.NewlineLF, "a\r\n", -1},
+		{`a\R`, syntax.NewlineCRLF, "a\r\n", 3},
+		{`a\R`, syntax.NewlineCRLF, "a\n", -1},
+		{`a\R\n`, syntax.NewlineAnyCRLF, "a\r\n", 3},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re, &Options{Newline: test.newline})
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", test.pattern, err)
+		}
+		end, _ := m.MatchAt(test.input, 0)
+		if end != test.want {
+			t.Errorf("%q MatchAt(%q) (%s):\nhave: %d\nwant: %d",
+				test.pattern, test.input, test.newline, end, test.want)
+		}
+	}
+}
+
+func TestGrapheme(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"ab", "a"},
+		{"\r\na", "\r\n"},
+		{"\n\r", "\n"},
+		{"\u0301a", "\u0301"},
+		{"e\u0301\u0302x", "e\u0301\u0302"},
+		{"\U0001F1FA\U0001F1F8\U0001F1EC", "\U0001F1FA\U0001F1F8"},
+		{"\U0001F44B\U0001F3FD!", "\U0001F44B\U0001F3FD"},
+		{"\U0001F468\u200D\U0001F469\u200D\U0001F467x", "\U0001F468\u200D\U0001F469\u200D\U0001F467"},
+		{"a\u200D\U0001F469", "a\u200D"},
+		{"\u1100\u1161\u11A8\u1100", "\u1100\u1161\u11A8"},
+		{"\uAC00\u11A8\u11A8\uAC00", "\uAC00\u11A8\u11A8"},
+		{"\uAC01\u1161", "\uAC01"},
+		{"\u1100\u1100\u11A8", "\u1100\u1100"},
+	}
+
+	re, err := syntax.NewParser(nil).Parse(`\X`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Compile(re, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		have := ""
+		if end, ok := m.MatchAt(test.input, 0); ok {
+			have = test.input[:end]
+		}
+		if have != test.want {
+			t.Errorf("\\X MatchAt(%+q):\nhave: %+q\nwant: %+q", test.input, have, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(a)\1`, `\1: unsupported by the DFA matcher`},
+		{`a\b`, `\b: unsupported by the DFA matcher`},
+		{`(?=a)`, `(?=a): unsupported by the DFA matcher`},
+		{`(?i)a`, `(?i): unsupported by the DFA matcher`},
+		{`(?:a{1000}){1000}`, `pattern automaton is too big`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Compile(re, nil)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Compile(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
+
+func TestMatchApprox(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		input     string
+		maxErrors int
+		want      int
+	}{
+		{`hello`, "say hello", 2, 0},
+		{`hello`, "say helo", 2, 1},
+		{`hello`, "say hallo!", 2, 1},
+		{`hello`, "say hxllxo", 2, 2},
+		{`hello`, "say hxlxxo", 2, -1},
+		{`^hello$`, "hello!", 2, 1},
+		{`^hello$`, "ohello", 0, -1},
+		{`colou?r`, "collor", 1, 1},
+		{`\d{3}-\d{4}`, "call 555 1234", 1, 1},
+		{`abc`, "", 3, 3},
+		{`a*`, "", 0, 0},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re, nil)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", test.pattern, err)
+		}
+		errors, ok := m.MatchApprox(test.input, test.maxErrors)
+		if errors != test.want || ok != (test.want != -1) {
+			t.Errorf("%q MatchApprox(%q, %d):\nhave: %d %v\nwant: %d",
+				test.pattern, test.input, test.maxErrors, errors, ok, test.want)
+		}
+	}
+}
+
+func BenchmarkFindAllOverlappingNoMatch(b *testing.B) {
+	s := strings.Repeat("a", 40000)
+	re, err := syntax.NewParser(nil).Parse(`a.*b`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	m, err := Compile(re, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if locs := m.FindAllOverlapping(s); len(locs) != 0 {
+			b.Fatal("unexpected match")
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package dfa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// ErrTooBig is returned for the patterns which automaton is too big.
+var ErrTooBig = errors.New("pattern automaton is too big")
+
+// nfa is a Thompson automaton for the pattern.
+//
+// The start state consumes any input prefix before the pattern,
+// so it finds the matches at any position. The prefix loop has
+// the lowest priority: once a match is found, the leftmost-first
+// cut stops starting the new ones. The anchor state is the pattern
+// entry, it only finds the matches that start at the current position.
+// The match state is reached whenever a match ends.
+type nfa struct {
+	states [][]thompson.Edge
+	start  int
+	anchor int
+	match  int
+}
+
+func compileNFA(e syntax.Expr, newline syntax.Newline) (*nfa, error) {
+	b := thompson.Builder{Newline: newline}
+	start := b.NewState()
+	match := b.NewState()
+	loop := b.NewState()
+	in, out, err := b.Build(e)
+	if err != nil {
+		if err, ok := err.(*thompson.UnsupportedError); ok {
+			return nil, fmt.Errorf("%s: unsupported by the DFA matcher", err.Expr)
+		}
+		return nil, ErrTooBig
+	}
+	b.AddEdge(start, thompson.Edge{To: in})
+	b.AddEdge(start, thompson.Edge{To: loop})
+	b.AddEdge(loop, thompson.Edge{Kind: thompson.EdgeRunes, Runes: charset.Any, To: start})
+	b.AddEdge(out, thompson.Edge{To: match})
+	return &nfa{states: b.States, start: start, anchor: in, match: match}, nil
+}
+
+// reverse returns the automaton that matches the reversed pattern.
+//
+// Its anchor state is the pattern exit and its match state is the
+// pattern entry, so it finds the match starts when the input is
+// scanned backwards from a match end. The start state consumes
+// any input suffix, so it finds all match starts in a single
+// backward scan. The begin and end assertions are swapped.
+func (a *nfa) reverse() *nfa {
+	r := &nfa{
+		states: make([][]thompson.Edge, len(a.states), len(a.states)+1),
+		start:  a.start,
+		match:  a.anchor,
+	}
+	for from, edges := range a.states {
+		if from == a.start {
+			continue
+		}
+		for _, e := range edges {
+			switch {
+			case e.To == a.start:
+				continue
+			case e.To == a.match:
+				r.anchor = from
+				continue
+			}
+			switch e.Kind {
+			case thompson.EdgeBegin:
+				e.Kind = thompson.EdgeEnd
+			case thompson.EdgeEnd:
+				e.Kind = thompson.EdgeBegin
+			}
+			to := e.To
+			e.To = from
+			r.states[to] = append(r.states[to], e)
+		}
+	}
+	loop := len(r.states)
+	r.states = append(r.states, []thompson.Edge{{Kind: thompson.EdgeRunes, Runes: charset.Any, To: r.start}})
+	r.states[r.start] = []thompson.Edge{{To: r.anchor}, {To: loop}}
+	return r
+}
+
+// closure returns all states reachable from the states without
+// consuming any input. The result is ordered by priority:
+// the states are visited depth-first in the edges order.
+func (a *nfa) closure(states []int, atBegin, atEnd bool) []int {
+	seen := make(map[int]bool, len(states))
+	stack := make([]int, 0, len(states))
+	for i := len(states) - 1; i >= 0; i-- {
+		stack = append(stack, states[i])
+	}
+	var result []int
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+		edges := a.states[s]
+		for i := len(edges) - 1; i >= 0; i-- {
+			e := edges[i]
+			switch {
+			case e.Kind == thompson.EdgeEpsilon,
+				e.Kind == thompson.EdgeBegin && atBegin,
+				e.Kind == thompson.EdgeEnd && atEnd:
+				stack = append(stack, e.To)
+			}
+		}
+	}
+	return result
+}
+
+// step returns the states after consuming ch.
+func (a *nfa) step(states []int, ch rune) []int {
+	var next []int
+	for _, s := range states {
+		for _, e := range a.states[s] {
+			if e.Kind == thompson.EdgeRunes && e.Runes.Contains(ch) {
+				next = append(next, e.To)
+			}
+		}
+	}
+	return a.closure(next, false, false)
+}
+
+// accepts reports whether the input that leads to states is accepted.
+func (a *nfa) accepts(states []int, atBegin bool) bool {
+	return a.hasMatch(a.closure(states, atBegin, true))
+}
+
+func (a *nfa) hasMatch(states []int) bool {
+	return a.matchIndex(states) != -1
+}
+
+// matchIndex returns the match state index inside states or -1.
+func (a *nfa) matchIndex(states []int) int {
+	for i, s := range states {
+		if s == a.match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,188 @@
+package syntax
+
+// ChangeKind describes how a node was changed.
+type ChangeKind byte
+
+const (
+	// ChangeAdded is a node that exists only in the new pattern.
+	ChangeAdded ChangeKind = iota + 1
+
+	// ChangeRemoved is a node that exists only in the old pattern.
+	ChangeRemoved
+
+	// ChangeModified is a node that was replaced by a different node.
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return "?"
+	}
+}
+
+// Change is a single structural difference between two patterns.
+type Change struct {
+	Kind ChangeKind
+
+	// Old is a node from the old pattern.
+	// It's zero for ChangeAdded.
+	Old Expr
+
+	// New is a node from the new pattern.
+	// It's zero for ChangeRemoved.
+	New Expr
+}
+
+// Diff returns a structural diff between old and new patterns.
+//
+// Unlike a text diff, it reports the changed AST nodes, so
+// `a+b` => `a*b` is reported as a single modified quantifier.
+// Nodes inside concatenations and alternations are matched using
+// the longest common subsequence, so insertions and deletions
+// don't cause the rest of the sequence to be reported as modified.
+//
+// The changes are reported in the source order.
+func Diff(old, new *Regexp) []Change {
+	var d differ
+	d.diffExpr(old.Expr, new.Expr)
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) diffExpr(x, y Expr) {
+	if exprEqual(x, y) {
+		return
+	}
+
+	if isSeqOp(x.Op) && isSeqOp(y.Op) || isListOp(x.Op) && x.Op == y.Op {
+		d.diffList(diffArgs(x), diffArgs(y))
+		return
+	}
+
+	if x.Op != y.Op || x.Form != y.Form || len(x.Args) != len(y.Args) || len(x.Args) == 0 {
+		d.changes = append(d.changes, Change{Kind: ChangeModified, Old: x, New: y})
+		return
+	}
+	switch x.Op {
+	case OpNamedCapture, OpGroupWithFlags:
+		// Args[1] is located before Args[0].
+		d.diffExpr(x.Args[1], y.Args[1])
+		d.diffExpr(x.Args[0], y.Args[0])
+	default:
+		for i := range x.Args {
+			d.diffExpr(x.Args[i], y.Args[i])
+		}
+	}
+}
+
+func (d *differ) diffList(xs, ys []Expr) {
+	// lcs[i][j] is the LCS length for xs[i:] and ys[j:].
+	lcs := make([][]int, len(xs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(ys)+1)
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for j := len(ys) - 1; j >= 0; j-- {
+			switch {
+			case exprEqual(xs[i], ys[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	gapX, gapY := 0, 0
+	for i < len(xs) || j < len(ys) {
+		switch {
+		case i < len(xs) && j < len(ys) && exprEqual(xs[i], ys[j]):
+			d.diffGap(xs[gapX:i], ys[gapY:j])
+			i++
+			j++
+			gapX, gapY = i, j
+		case j == len(ys) || i < len(xs) && lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	d.diffGap(xs[gapX:], ys[gapY:])
+}
+
+// diffGap reports the changes for the unmatched list elements.
+// Elements are paired in order, the rest is added or removed.
+func (d *differ) diffGap(xs, ys []Expr) {
+	n := len(xs)
+	if len(ys) < n {
+		n = len(ys)
+	}
+	for i := 0; i < n; i++ {
+		d.diffExpr(xs[i], ys[i])
+	}
+	for _, x := range xs[n:] {
+		d.changes = append(d.changes, Change{Kind: ChangeRemoved, Old: x})
+	}
+	for _, y := range ys[n:] {
+		d.changes = append(d.changes, Change{Kind: ChangeAdded, New: y})
+	}
+}
+
+// diffArgs returns list elements of e.
+// Literals inside concatenations are expanded to the individual chars.
+func diffArgs(e Expr) []Expr {
+	if e.Op != OpConcat {
+		return e.Args
+	}
+	var args []Expr
+	for _, a := range e.Args {
+		if a.Op == OpLiteral {
+			args = append(args, a.Args...)
+		} else {
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+func isSeqOp(op Operation) bool {
+	return op == OpConcat || op == OpLiteral || op == OpEmptyMatch
+}
+
+func isListOp(op Operation) bool {
+	switch op {
+	case OpAlt, OpCharClass, OpNegCharClass:
+		return true
+	default:
+		return isSeqOp(op)
+	}
+}
+
+// exprEqual reports whether x and y are structurally identical.
+// Positions are not compared.
+func exprEqual(x, y Expr) bool {
+	if x.Op != y.Op || x.Form != y.Form || len(x.Args) != len(y.Args) {
+		return false
+	}
+	if len(x.Args) == 0 {
+		return x.Value == y.Value
+	}
+	for i := range x.Args {
+		if !exprEqual(x.Args[i], y.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old  string
+		new  string
+		want string
+	}{
+		{`abc`, `abc`, ``},
+		{`a+b`, `a*b`, `modified 0-2 "a+" => 0-2 "a*"`},
+		{`abc`, `abxc`, `added 2-3 "x"`},
+		{`abc`, `ac`, `removed 1-2 "b"`},
+		{`ab\d`, `b\d`, `removed 0-1 "a"`},
+		{`ab`, `a\db`, `added 1-3 "\\d"`},
+		{`x{2}`, `x{2,3}`, `modified 1-4 "{2}" => 1-6 "{2,3}"`},
+		{`(?P<a>x)`, `(?P<b>y)`, `modified 4-5 "a" => 4-5 "b"; modified 6-7 "x" => 6-7 "y"`},
+		{`a|b|c`, `a|c`, `removed 2-3 "b"`},
+		{`[a-z\d]`, `[a-f\d_]`, `modified 3-4 "z" => 3-4 "f"; added 6-7 "_"`},
+		{`(a)`, `(?:a)`, `modified 0-3 "(a)" => 0-5 "(?:a)"`},
+		{`foo(bar)`, `foo(baz)|x`, `modified 0-8 "foo(bar)" => 0-10 "foo(baz)|x"`},
+		{`x(?:a|b)`, `x(?:a|b|c)y`, `added 8-9 "c"; added 10-11 "y"`},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		oldRE, err := p.Parse(test.old)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.old, err)
+		}
+		oldRE = oldRE.Clone()
+		newRE, err := p.Parse(test.new)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.new, err)
+		}
+		var parts []string
+		for _, c := range Diff(oldRE, newRE) {
+			parts = append(parts, formatChange(c))
+		}
+		have := strings.Join(parts, "; ")
+		if have != test.want {
+			t.Errorf("diff(%q, %q):\nhave: %s\nwant: %s", test.old, test.new, have, test.want)
+		}
+	}
+}
+
+func formatChange(c Change) string {
+	formatExpr := func(e Expr) string {
+		return fmt.Sprintf("%d-%d %q", e.Begin(), e.End(), e.Value)
+	}
+	switch c.Kind {
+	case ChangeAdded:
+		return "added " + formatExpr(c.New)
+	case ChangeRemoved:
+		return "removed " + formatExpr(c.Old)
+	default:
+		return "modified " + formatExpr(c.Old) + " => " + formatExpr(c.New)
+	}
+}
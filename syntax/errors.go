@@ -1,22 +1,168 @@
 package syntax
 
+import (
+	"strconv"
+)
+
+// Code is a stable diagnostic identifier, like RE1001.
+//
+// The codes never change their meaning, so they can be used
+// to suppress or to select the specific diagnostics.
+type Code uint16
+
+// Parse error codes.
+const (
+	// CodeUnexpectedToken is a misplaced pattern element, like `*` at the start.
+	CodeUnexpectedToken Code = 1001
+
+	// CodeExpectedToken is a missing pattern element, like an unclosed group.
+	CodeExpectedToken Code = 1002
+
+	// CodeUnterminatedClass is a char class without the closing `]`.
+	CodeUnterminatedClass Code = 1003
+
+	// CodeIncompleteGroup is a group prefix that is cut short, like `(?P<x`.
+	CodeIncompleteGroup Code = 1004
+
+	// CodeTrailingBackslash is a `\` at the end of the pattern.
+	CodeTrailingBackslash Code = 1005
+
+	// CodeIncompleteEscape is an escape without its argument, like `\p`.
+	CodeIncompleteEscape Code = 1006
+
+	// CodeUnclosedBrace is an escape without the closing `}`, like `\x{41`.
+	CodeUnclosedBrace Code = 1007
+
+	// CodeUnknownUnicodeClass is a `\p{Name}` with unknown name,
+	// see ParserOptions.UnicodeClasses.
+	CodeUnknownUnicodeClass Code = 1008
+
+	// CodeUnsupportedBackref is a backreference in RE2 mode,
+	// see ParserOptions.Backrefs.
+	CodeUnsupportedBackref Code = 1009
+
+	// CodeInvalidDelimiter is a PCRE source that doesn't start
+	// with a valid delimiter, see Parser.ParsePCRE.
+	CodeInvalidDelimiter Code = 1010
+
+	// CodeMissingDelimiter is a PCRE source without the ending delimiter.
+	CodeMissingDelimiter Code = 1011
+
+	// CodeUnsupportedModifier is a PCRE modifier that can't be handled, like `x`.
+	CodeUnsupportedModifier Code = 1012
+)
+
+// Codes of the diagnostics that are reported either as
+// errors or as warnings, depending on the parser options.
+const (
+	// CodeSurrogateEscape is a surrogate code point escape,
+	// see ParserOptions.Surrogates.
+	CodeSurrogateEscape Code = 1101
+
+	// CodeHighByteEscape is an ambiguous `\x80`-`\xFF` escape,
+	// see ParserOptions.HighByteEscapes.
+	CodeHighByteEscape Code = 1102
+
+	// CodeDuplicateGroupName is a repeated group name,
+	// see ParserOptions.DupNames.
+	CodeDuplicateGroupName Code = 1103
+)
+
+// Limit error codes, see LimitError.Code.
+const (
+	CodeLimitDepth         Code = 1201
+	CodeLimitNodes         Code = 1202
+	CodeLimitRepeatProduct Code = 1203
+	CodeLimitRepeatCount   Code = 1204
+	CodeLimitLength        Code = 1205
+)
+
+// String returns the code in the `RE1001` form.
+func (c Code) String() string {
+	return "RE" + strconv.Itoa(int(c))
+}
+
 type ParseError struct {
 	Pos     Position
 	Message string
+	Code    Code
 }
 
 func (e ParseError) Error() string { return e.Message }
 
-func throw(pos Position, message string) {
-	panic(ParseError{Pos: pos, Message: message})
+// Severity is a diagnostic importance level.
+type Severity byte
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "?"
+	}
+}
+
+// Confidence is a likelihood that a diagnostic describes a real problem.
+type Confidence byte
+
+const (
+	// ConfidenceLow is for the heuristic diagnostics
+	// that are often triggered by the intended code.
+	ConfidenceLow Confidence = iota + 1
+
+	// ConfidenceMedium is for the diagnostics that depend
+	// on the details that are unknown to the parser, like the engine mode.
+	ConfidenceMedium
+
+	// ConfidenceHigh is for the definite problems.
+	ConfidenceHigh
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "?"
+	}
+}
+
+// Warning is a non-fatal parsing diagnostic.
+type Warning struct {
+	Pos     Position
+	Message string
+	Code    Code
+
+	Severity   Severity
+	Confidence Confidence
+}
+
+func (w Warning) String() string { return w.Message }
+
+func throw(code Code, pos Position, message string) {
+	panic(ParseError{Pos: pos, Message: message, Code: code})
 }
 
 func throwExpectedFound(pos Position, expected, found string) {
-	throw(pos, "expected '"+expected+"', found '"+found+"'")
+	throw(CodeExpectedToken, pos, "expected '"+expected+"', found '"+found+"'")
 }
 
 func throwUnexpectedToken(pos Position, token string) {
-	throw(pos, "unexpected token: "+token)
+	throw(CodeUnexpectedToken, pos, "unexpected token: "+token)
 }
 
 func newPos(begin, end int) Position {
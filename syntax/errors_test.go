@@ -0,0 +1,70 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		pattern string
+		opts    ParserOptions
+		want    string
+	}{
+		{`*`, ParserOptions{}, "RE1001"},
+		{`(a`, ParserOptions{}, "RE1002"},
+		{`[a`, ParserOptions{}, "RE1003"},
+		{`(?P<x`, ParserOptions{}, "RE1004"},
+		{`a\`, ParserOptions{}, "RE1005"},
+		{`\p`, ParserOptions{}, "RE1006"},
+		{`\x{41`, ParserOptions{}, "RE1007"},
+		{`\p{Foo}`, ParserOptions{UnicodeClasses: UnicodeClassesGo}, "RE1008"},
+		{`(a)\1`, ParserOptions{Backrefs: BackrefsRE2}, "RE1009"},
+		{`\x{D800}`, ParserOptions{Surrogates: EscapeReject}, "RE1101"},
+		{`\xFF`, ParserOptions{HighByteEscapes: EscapeReject}, "RE1102"},
+		{`(?P<x>)(?P<x>)`, ParserOptions{DupNames: DupNamesReject}, "RE1103"},
+		{`((a))`, ParserOptions{Limits: Limits{MaxDepth: 2}}, "RE1201"},
+		{`abc`, ParserOptions{Limits: Limits{MaxNodes: 2}}, "RE1202"},
+		{`(a{10}){10}`, ParserOptions{Limits: Limits{MaxRepeatProduct: 50}}, "RE1203"},
+		{`a{10}`, ParserOptions{Limits: Limits{MaxRepeatCount: 5}}, "RE1204"},
+		{strings.Repeat(`a`, 1<<16), ParserOptions{}, "RE1205"},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		_, err := NewParser(&opts).Parse(test.pattern)
+		var have string
+		switch err := err.(type) {
+		case ParseError:
+			have = err.Code.String()
+		case LimitError:
+			have = err.Code().String()
+		default:
+			t.Errorf("parse(%q): unexpected error: %v", test.pattern, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("parse(%q) code:\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestWarningCodes(t *testing.T) {
+	opts := &ParserOptions{
+		Surrogates:      EscapeWarn,
+		HighByteEscapes: EscapeWarn,
+		DupNames:        DupNamesWarn,
+	}
+	re, err := NewParser(opts).Parse(`\x{D800}\xFF(?P<x>)(?P<x>)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, w := range re.Warnings {
+		have = append(have, w.Code.String()+" "+w.Severity.String()+" "+w.Confidence.String())
+	}
+	want := []string{"RE1101 warning high", "RE1102 warning medium", "RE1103 warning high"}
+	if len(have) != len(want) || have[0] != want[0] || have[1] != want[1] || have[2] != want[2] {
+		t.Errorf("warnings:\nhave: %v\nwant: %v", have, want)
+	}
+}
@@ -0,0 +1,82 @@
+package explain
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Capture describes a named capture group.
+type Capture struct {
+	Name string
+
+	// Index is a group number, see syntax.Regexp.GroupIndexes.
+	Index int
+
+	// Body is the enclosed pattern text.
+	Body string
+
+	// Matches is the enclosed expression description, see Describe.
+	Matches string
+
+	// Doc is the text of the comment that is adjacent to the group.
+	// It's empty if the group is not documented.
+	Doc string
+}
+
+// Captures returns the named capture groups of re along with their docs,
+// in the order of their opening parentheses.
+//
+// The group doc is a `(?#...)` comment that follows the group opening
+// or its closing parenthesis. Where the `x` flag is active, a `#` comment
+// on the same line is accepted as well, like in the `(?P<id>\d+) # user ID`.
+//
+// flags describes the flags that are enabled outside of the pattern.
+func Captures(re *syntax.Regexp, flags syntax.Flags) []Capture {
+	var list []Capture
+	index := 0
+	syntax.WalkFlags(re.Expr, syntax.DialectPCRE, flags, func(e syntax.Expr, flags syntax.Flags) {
+		switch e.Op {
+		case syntax.OpCapture:
+			index++
+		case syntax.OpNamedCapture:
+			index++
+			extended := flags&syntax.FlagExtended != 0
+			body := e.Args[0]
+			doc := commentAt(re.Pattern, int(body.Begin()), extended)
+			if doc == "" {
+				doc = commentAt(re.Pattern, int(e.End()), extended)
+			}
+			list = append(list, Capture{
+				Name:    e.Args[1].Value,
+				Index:   index,
+				Body:    re.Pattern[body.Begin():body.End()],
+				Matches: Describe(body),
+				Doc:     doc,
+			})
+		}
+	})
+	return list
+}
+
+// commentAt returns the text of the comment that starts at the offset.
+func commentAt(pattern string, offset int, extended bool) string {
+	s := pattern[offset:]
+	if extended {
+		s = strings.TrimLeft(s, " \t")
+	}
+	switch {
+	case strings.HasPrefix(s, "(?#"):
+		end := strings.IndexByte(s, ')')
+		if end == -1 {
+			return ""
+		}
+		return strings.TrimSpace(s[len("(?#"):end])
+	case extended && strings.HasPrefix(s, "#"):
+		if end := strings.IndexByte(s, '\n'); end != -1 {
+			s = s[:end]
+		}
+		return strings.TrimSpace(s[len("#"):])
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package explain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCaptures(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   syntax.Flags
+		want    []string
+	}{
+		{`(\w+)(?P<x>a)`, 0, []string{`2 x "a" ""`}},
+		{`(?P<user>(?#user name)\w+):(?P<id>\d+)(?#numeric ID)`, 0, []string{
+			`1 user "(?#user name)\\w+" "user name"`,
+			`2 id "\\d+" "numeric ID"`,
+		}},
+		{"(?x)\n(?<ts>  # timestamp\n  \\d+\n)\n(?<msg>.*)  # message\n", 0, []string{
+			`1 ts "  # timestamp\n  \\d+\n" "timestamp"`,
+			`2 msg ".*" "message"`,
+		}},
+		{"(?<a>x) # not a comment", 0, []string{`1 a "x" ""`}},
+		{"(?<a>x) # comment", syntax.FlagExtended, []string{`1 a "x" "comment"`}},
+		{"(?-x:(?<a>x) # not a comment)", syntax.FlagExtended, []string{`1 a "x" ""`}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var have []string
+		for _, c := range Captures(re, test.flags) {
+			have = append(have, fmt.Sprintf("%d %s %q %q", c.Index, c.Name, c.Body, c.Doc))
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("Captures(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
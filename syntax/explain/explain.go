@@ -0,0 +1,192 @@
+// Package explain describes regexp expressions in plain English.
+package explain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Describe returns a short description of e, like `one or more digits`.
+//
+// Only the e itself is described: for groups and alternations the
+// enclosed expressions are not included, for quantifiers the description
+// of a simple repeated expression is included.
+// The description doesn't take the active flags into account.
+func Describe(e syntax.Expr) string {
+	switch e.Op {
+	case syntax.OpChar, syntax.OpLiteral:
+		return "literal " + strconv.Quote(e.Value)
+	case syntax.OpQuote:
+		return "literal " + strconv.Quote(e.QuotedLiteral())
+	case syntax.OpEscapeMeta:
+		return "literal " + strconv.Quote(e.Args[0].Value)
+	case syntax.OpString:
+		return strconv.Quote(e.Value)
+	case syntax.OpDot:
+		return "any char"
+	case syntax.OpCaret:
+		return "start anchor"
+	case syntax.OpDollar:
+		return "end anchor"
+	case syntax.OpEmptyMatch:
+		return "empty match"
+	case syntax.OpConcat:
+		return "sequence"
+	case syntax.OpAlt:
+		return fmt.Sprintf("alternation of %d branches", len(e.Args))
+
+	case syntax.OpEscapeChar:
+		return describeEscape(e)
+	case syntax.OpEscapeOctal, syntax.OpEscapeHex:
+		if e.Form == syntax.FormEscapeBackref {
+			return "backreference to group " + e.Args[0].Value
+		}
+		base := 16
+		if e.Op == syntax.OpEscapeOctal {
+			base = 8
+		}
+		digits := e.Args[0].Value
+		if digits == "" {
+			digits = "0"
+		}
+		code, err := strconv.ParseUint(digits, base, 32)
+		if err != nil {
+			return "char " + e.Value
+		}
+		return fmt.Sprintf("char U+%04X", code)
+	case syntax.OpEscapeUni:
+		name := e.Args[0].Value
+		if strings.HasPrefix(e.Value, `\P`) {
+			return "char not in Unicode class " + name
+		}
+		return "char in Unicode class " + name
+	case syntax.OpPosixClass:
+		name := strings.TrimSuffix(strings.TrimPrefix(e.Value, "[:"), ":]")
+		if e.Form == syntax.FormPosixUnicode {
+			return "Unicode POSIX class " + name
+		}
+		return "POSIX class " + name
+	case syntax.OpCharClass:
+		return "one of " + e.Value
+	case syntax.OpNegCharClass:
+		return "none of " + strings.Replace(e.Value, "[^", "[", 1)
+	case syntax.OpCharRange:
+		return "range " + e.Value
+
+	case syntax.OpStar:
+		return "zero or more of " + describeRepeated(e.Args[0])
+	case syntax.OpPlus:
+		return "one or more of " + describeRepeated(e.Args[0])
+	case syntax.OpQuestion:
+		return "optional " + describeRepeated(e.Args[0])
+	case syntax.OpRepeat:
+		return describeRepeat(e)
+	case syntax.OpNonGreedy:
+		return Describe(e.Args[0]) + ", lazy"
+	case syntax.OpPossessive:
+		return Describe(e.Args[0]) + ", possessive"
+
+	case syntax.OpCapture:
+		return "capture group"
+	case syntax.OpNamedCapture:
+		return "capture group " + strconv.Quote(e.Args[1].Value)
+	case syntax.OpGroup:
+		return "group"
+	case syntax.OpGroupWithFlags:
+		return "group with flags " + e.Args[1].Value
+	case syntax.OpAtomicGroup:
+		return "atomic group"
+	case syntax.OpScriptRun:
+		if e.Form == syntax.FormScriptRunAtomic {
+			return "atomic script run"
+		}
+		return "script run"
+	case syntax.OpPositiveLookahead:
+		return "lookahead"
+	case syntax.OpNegativeLookahead:
+		return "negative lookahead"
+	case syntax.OpPositiveLookbehind:
+		return "lookbehind"
+	case syntax.OpNegativeLookbehind:
+		return "negative lookbehind"
+	case syntax.OpFlagOnlyGroup:
+		return "set flags " + e.Args[0].Value
+	case syntax.OpComment:
+		return "comment"
+	case syntax.OpPlaceholder:
+		return "placeholder " + e.Value
+	default:
+		return e.Op.String()
+	}
+}
+
+func describeRepeated(e syntax.Expr) string {
+	switch e.Op {
+	case syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun:
+		return "the " + Describe(e)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat,
+		syntax.OpNonGreedy, syntax.OpPossessive:
+		return "(" + Describe(e) + ")"
+	default:
+		return Describe(e)
+	}
+}
+
+func describeRepeat(e syntax.Expr) string {
+	s := strings.TrimSuffix(strings.TrimPrefix(e.Args[1].Value, "{"), "}")
+	x := describeRepeated(e.Args[0])
+	comma := strings.IndexByte(s, ',')
+	switch {
+	case comma == -1:
+		return x + ", exactly " + s + " times"
+	case comma == len(s)-1:
+		return x + ", at least " + s[:comma] + " times"
+	case comma == 0:
+		return x + ", at most " + s[1:] + " times"
+	default:
+		return x + ", " + s[:comma] + " to " + s[comma+1:] + " times"
+	}
+}
+
+func describeEscape(e syntax.Expr) string {
+	if d, ok := escapeDescriptions[e.Value]; ok {
+		return d
+	}
+	return "literal " + strconv.Quote(e.Args[0].Value)
+}
+
+var escapeDescriptions = map[string]string{
+	`\d`: "digit",
+	`\D`: "non-digit",
+	`\w`: "word char",
+	`\W`: "non-word char",
+	`\s`: "whitespace",
+	`\S`: "non-whitespace",
+	`\h`: "horizontal whitespace",
+	`\H`: "non-horizontal whitespace",
+	`\v`: "vertical whitespace",
+	`\V`: "non-vertical whitespace",
+	`\R`: "line break",
+	`\X`: "extended grapheme cluster",
+	`\C`: "single byte",
+	`\N`: "non-newline",
+	`\b`: "word boundary",
+	`\B`: "non-word boundary",
+	`\A`: "start of text",
+	`\z`: "end of text",
+	`\Z`: "end of text or final newline",
+	`\G`: "end of the previous match",
+	`\K`: "match start reset",
+	`\a`: "bell",
+	`\e`: "escape",
+	`\f`: "form feed",
+	`\n`: "newline",
+	`\r`: "carriage return",
+	`\t`: "tab",
+	`\k`: "named backreference",
+	`\g`: "backreference",
+}
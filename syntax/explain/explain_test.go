@@ -0,0 +1,54 @@
+package explain
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `literal "abc"`},
+		{`\Qa.b\E`, `literal "a.b"`},
+		{`\.`, `literal "."`},
+		{`\d`, `digit`},
+		{`\-`, `literal "-"`},
+		{`\x41`, `char U+0041`},
+		{`\101`, `char U+0041`},
+		{`\pL`, `char in Unicode class L`},
+		{`\P{Greek}`, `char not in Unicode class Greek`},
+		{`[a-z]`, `one of [a-z]`},
+		{`[^a-z]`, `none of [a-z]`},
+		{`\d+`, `one or more of digit`},
+		{`x*?`, `zero or more of literal "x", lazy`},
+		{`(?:ab)?`, `optional the group`},
+		{`a{3}`, `literal "a", exactly 3 times`},
+		{`a{2,}`, `literal "a", at least 2 times`},
+		{`a{2,5}`, `literal "a", 2 to 5 times`},
+		{`(a+){2}`, `the capture group, exactly 2 times`},
+		{`(?P<year>x)`, `capture group "year"`},
+		{`(?i:x)`, `group with flags i`},
+		{`(?<!x)`, `negative lookbehind`},
+		{`(*sr:\d+)`, `script run`},
+		{`(*atomic_script_run:\d+)`, `atomic script run`},
+		{`a|b|c`, `alternation of 3 branches`},
+		{`(?m)`, `set flags m`},
+		{``, `empty match`},
+		{`${name}`, `placeholder ${name}`},
+		{`%d+`, `one or more of placeholder %d`},
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Placeholders: syntax.PlaceholdersAll})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if have := Describe(re.Expr); have != test.want {
+			t.Errorf("Describe(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package extract
+
+import (
+	"strings"
+)
+
+var envQuotes = quoteRules{escape: envEscape, plain: true}
+
+// envEscapes are the dotenv escapes inside the double quotes.
+var envEscapes = map[byte]string{
+	'\\': "\\", '\'': "'", '"': `"`,
+	'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+}
+
+// envEscape decodes a dotenv escape.
+// Unknown escapes are kept as is, so `"a\d+"` is `a\d+`.
+func envEscape(s string) (string, int, bool) {
+	if len(s) >= 2 {
+		if value, ok := envEscapes[s[1]]; ok {
+			return value, 2, true
+		}
+	}
+	return "\\", 1, true
+}
+
+func (c *collector) scanEnv() {
+	c.lines(func(line string, offset int) {
+		body := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(body, "export ") {
+			body = strings.TrimLeft(body[len("export "):], " \t")
+		}
+		eq := strings.IndexByte(body, '=')
+		if body == "" || body[0] == '#' || eq <= 0 {
+			return
+		}
+		base := offset + len(line) - len(body)
+		key := strings.TrimSpace(body[:eq])
+		if value, ok := readString(body, eq+1, envQuotes, ""); ok {
+			c.addString([]string{key}, value, base)
+		}
+	})
+}
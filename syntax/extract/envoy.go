@@ -0,0 +1,65 @@
+package extract
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// RuleFullMatchAnchor is a rule ID of the compat.FullMatchAnchors issues.
+const RuleFullMatchAnchor = "full-match-anchor"
+
+// Envoy extracts the RegexMatcher patterns of an envoy config
+// in FormatJSON or FormatYAML: the route and header `safe_regex`,
+// the `safe_regex_match` and the `regex_rewrite` patterns,
+// including the rate limit actions ones.
+func Envoy(data []byte, format Format) ([]Pattern, error) {
+	c := collector{data: data, match: isEnvoyRegex}
+	switch format {
+	case FormatJSON:
+		if err := c.scanJSON(); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		c.scanYAML()
+	default:
+		return nil, fmt.Errorf("%s is not an envoy config format", format)
+	}
+	return c.patterns, nil
+}
+
+// isEnvoyRegex matches the RegexMatcher message `regex` fields.
+func isEnvoyRegex(path []string) bool {
+	if len(path) < 2 || path[len(path)-1] != "regex" {
+		return false
+	}
+	switch path[len(path)-2] {
+	case "safe_regex", "safe_regex_match":
+		return true
+	case "pattern":
+		return len(path) >= 3 && path[len(path)-3] == "regex_rewrite"
+	default:
+		return false
+	}
+}
+
+// CheckEnvoy lints the Envoy patterns of the uri config.
+//
+// The patterns are checked against the compat.Envoy profile
+// in addition to the opts profiles, and the compat.FullMatchAnchors
+// issues are reported as the RuleFullMatchAnchor warnings.
+func CheckEnvoy(uri string, data []byte, format Format, opts *LintOptions) ([]sarif.Result, error) {
+	patterns, err := Envoy(data, format)
+	if err != nil {
+		return nil, err
+	}
+	lintOpts := LintOptions{Profiles: []*compat.Profile{compat.Envoy}}
+	if opts != nil {
+		lintOpts.Parser = opts.Parser
+		lintOpts.Profiles = append(lintOpts.Profiles, opts.Profiles...)
+	}
+	results := lintIssues(uri, patterns, &lintOpts, RuleFullMatchAnchor, compat.FullMatchAnchors)
+	sarif.Locate(results, data)
+	return results, nil
+}
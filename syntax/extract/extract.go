@@ -0,0 +1,668 @@
+// Package extract finds the regexp patterns inside the config files
+// and the Go sources, so they can be checked by the linters.
+//
+// The config values are selected by their key names, see Options.Keys.
+// Only the string values are extracted, including the string
+// array elements. The Go patterns are the literal arguments of the
+// sink functions, see Options.Sinks.
+package extract
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// Format is a config file format.
+type Format byte
+
+const (
+	FormatJSON Format = iota + 1
+
+	// FormatYAML supports the block mappings and sequences, and the
+	// single-line flow sequences. Block scalars (`|` and `>`), multi-line
+	// quoted strings and flow mappings are skipped.
+	FormatYAML
+
+	// FormatTOML supports the tables, dotted keys and single-line arrays.
+	// Multi-line strings and inline tables are skipped.
+	FormatTOML
+
+	// FormatEnv is a dotenv file with `KEY=value` lines.
+	FormatEnv
+
+	// FormatPrometheus is a Prometheus YAML config. Only the `regex`
+	// fields of the relabel configs are extracted, Options.Keys are ignored.
+	// Prometheus anchors these patterns at both ends.
+	FormatPrometheus
+
+	// FormatGo is a Go source file. The string literal arguments of
+	// DefaultSinks and Options.Sinks calls and the Options.Tags struct
+	// tag values are extracted, Options.Keys are ignored.
+	FormatGo
+
+	// FormatHTML is an HTML template or a JSX file.
+	// The `pattern` attribute values are extracted, see HTML.
+	FormatHTML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	case FormatTOML:
+		return "TOML"
+	case FormatEnv:
+		return "dotenv"
+	case FormatPrometheus:
+		return "Prometheus"
+	case FormatGo:
+		return "Go"
+	case FormatHTML:
+		return "HTML"
+	default:
+		return "?"
+	}
+}
+
+// DetectFormat selects the format by the file name.
+// Prometheus configs are detected as FormatYAML.
+func DetectFormat(filename string) (Format, bool) {
+	base := filepath.Base(filename)
+	if base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env") {
+		return FormatEnv, true
+	}
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".json":
+		return FormatJSON, true
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".toml":
+		return FormatTOML, true
+	case ".go":
+		return FormatGo, true
+	case ".html", ".htm", ".gohtml", ".tmpl", ".vue", ".svelte", ".jsx", ".tsx":
+		return FormatHTML, true
+	default:
+		return 0, false
+	}
+}
+
+// Pattern is a pattern string found in a config file.
+type Pattern struct {
+	// Pattern is the decoded string value.
+	Pattern string
+
+	// Key is a dotted path of the value key, like `rules.regex`.
+	// Array indexes are not included.
+	Key string
+
+	// Line and Column locate the value text inside the file, 1-based.
+	// For the quoted strings, it's the first char after the quote.
+	Line   int
+	Column int
+
+	// Offset and Size locate the value text inside the file.
+	Offset int
+	Size   int
+
+	// Exact reports whether the value text is the pattern verbatim,
+	// so the pattern positions can be mapped to the file positions
+	// by adding the Offset. It's false for the strings with escapes,
+	// use FileOffset to map their positions.
+	Exact bool
+
+	// offsets map the pattern bytes to the value text offsets.
+	// It's nil for the Exact patterns and the unmapped escapes.
+	offsets []int
+}
+
+// FileOffset maps the pattern byte offset to the file offset.
+// If the pattern escapes can't be mapped, it returns the value start.
+func (pat Pattern) FileOffset(i int) int {
+	switch {
+	case pat.Exact:
+		return pat.Offset + i
+	case i >= 0 && i < len(pat.offsets):
+		return pat.Offset + pat.offsets[i]
+	default:
+		return pat.Offset
+	}
+}
+
+// DefaultKeys are used if Options.Keys are empty.
+var DefaultKeys = []string{"regex", "regexp", "pattern"}
+
+// Options configure the extraction.
+type Options struct {
+	// Keys select the values that hold the patterns.
+	// A key matches if its lowercased name ends with one of the Keys,
+	// possibly in plural: `log_regex` and `Patterns` match too.
+	Keys []string
+
+	// Sinks are the Go functions that take the patterns,
+	// in addition to the DefaultSinks.
+	Sinks []Sink
+
+	// Tags are the Go struct tags that hold the patterns.
+	Tags []TagSink
+}
+
+// File extracts the patterns from the file contents,
+// the format is selected by DetectFormat.
+// Files of unknown formats have no patterns.
+func File(filename string, data []byte, opts *Options) ([]Pattern, error) {
+	format, ok := DetectFormat(filename)
+	if !ok {
+		return nil, nil
+	}
+	return Extract(data, format, opts)
+}
+
+// Extract returns the patterns found in data, in the file order.
+//
+// Only the JSON and Go syntax errors and the invalid escapes of
+// the pattern values are reported, other formats skip the lines
+// they can't parse.
+func Extract(data []byte, format Format, opts *Options) ([]Pattern, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	keys := DefaultKeys
+	if len(opts.Keys) != 0 {
+		keys = opts.Keys
+	}
+	c := collector{data: data, match: func(path []string) bool {
+		return isPatternKey(path[len(path)-1], keys)
+	}}
+	switch format {
+	case FormatJSON:
+		if err := c.scanJSON(); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		c.scanYAML()
+	case FormatPrometheus:
+		c.match = isRelabelRegex
+		c.scanYAML()
+	case FormatTOML:
+		c.scanTOML()
+	case FormatEnv:
+		c.scanEnv()
+	case FormatGo:
+		sinks := append(append([]Sink(nil), DefaultSinks...), opts.Sinks...)
+		if err := c.scanGo(sinks, opts.Tags); err != nil {
+			return nil, err
+		}
+	case FormatHTML:
+		c.scanHTML()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.patterns, nil
+}
+
+// RuleCompat is a rule ID of the engine compatibility issues.
+const RuleCompat = "compat"
+
+// LintOptions configure Lint.
+type LintOptions struct {
+	Parser *syntax.ParserOptions
+
+	// Profiles are the engines the patterns are checked against.
+	Profiles []*compat.Profile
+}
+
+// Lint parses the patterns and returns their parse errors,
+// warnings and compat issues as the results for the uri file.
+//
+// The results of the patterns that can't be mapped to
+// the file are located at the whole pattern value.
+// Use sarif.Locate to set the results lines.
+func Lint(uri string, patterns []Pattern, opts *LintOptions) []sarif.Result {
+	if opts == nil {
+		opts = &LintOptions{}
+	}
+	p := syntax.NewParser(opts.Parser)
+	var results []sarif.Result
+	for _, pat := range patterns {
+		re, err := p.Parse(pat.Pattern)
+		if err != nil {
+			if r, ok := sarif.ParseErrorResult(uri, pat.Offset, err); ok {
+				r.Pos = pat.filePos(r.Pos)
+				results = append(results, r)
+			}
+			continue
+		}
+		for _, w := range re.Warnings {
+			r := sarif.WarningResult(uri, pat.Offset, w)
+			r.Pos = pat.filePos(r.Pos)
+			results = append(results, r)
+		}
+		for _, profile := range opts.Profiles {
+			for _, issue := range profile.Issues(re) {
+				results = append(results, sarif.Result{
+					RuleID:  RuleCompat,
+					Level:   sarif.LevelError,
+					Message: issue.Message,
+					URI:     uri,
+					Offset:  pat.Offset,
+					Pos:     pat.filePos(issue.Pos),
+				})
+			}
+		}
+	}
+	return results
+}
+
+// lintIssues is like Lint, but the issues returned by the check
+// are also reported, as the rule warnings.
+func lintIssues(uri string, patterns []Pattern, opts *LintOptions, rule string, check func(re *syntax.Regexp) []compat.Issue) []sarif.Result {
+	p := syntax.NewParser(opts.Parser)
+	var results []sarif.Result
+	for _, pat := range patterns {
+		results = append(results, Lint(uri, []Pattern{pat}, opts)...)
+		re, err := p.Parse(pat.Pattern)
+		if err != nil {
+			continue
+		}
+		for _, issue := range check(re) {
+			results = append(results, sarif.Result{
+				RuleID:  rule,
+				Level:   sarif.LevelWarning,
+				Message: issue.Message,
+				URI:     uri,
+				Offset:  pat.Offset,
+				Pos:     pat.filePos(issue.Pos),
+			})
+		}
+	}
+	return results
+}
+
+func (pat Pattern) filePos(pos syntax.Position) syntax.Position {
+	if !pat.Exact && pat.offsets == nil {
+		return syntax.Position{End: uint16(pat.Size)}
+	}
+	return syntax.Position{
+		Begin: uint16(pat.FileOffset(int(pos.Begin)) - pat.Offset),
+		End:   uint16(pat.FileOffset(int(pos.End)) - pat.Offset),
+	}
+}
+
+func isPatternKey(key string, keys []string) bool {
+	key = strings.ToLower(key)
+	for _, k := range keys {
+		if strings.HasSuffix(key, k) || strings.HasSuffix(key, k+"s") || strings.HasSuffix(key, k+"es") {
+			return true
+		}
+	}
+	return false
+}
+
+// isRelabelRegex matches the `regex` fields of the Prometheus
+// relabel_configs, metric_relabel_configs and write_relabel_configs.
+func isRelabelRegex(path []string) bool {
+	if path[len(path)-1] != "regex" {
+		return false
+	}
+	for _, key := range path[:len(path)-1] {
+		if strings.HasSuffix(key, "relabel_configs") {
+			return true
+		}
+	}
+	return false
+}
+
+type collector struct {
+	data  []byte
+	match func(path []string) bool
+
+	// matchKey selects the mappings which key names are patterns,
+	// by their paths. Can be nil.
+	matchKey func(path []string) bool
+
+	// templates makes scanGo collect the fmt.Sprintf format
+	// arguments of the sink calls instead of the string literals.
+	templates bool
+
+	patterns []Pattern
+
+	// err is the first value decoding error.
+	err error
+}
+
+// add adds the value if its key path matches.
+// offset is the value text location inside the file.
+func (c *collector) add(path []string, value string, offset, size int) {
+	if len(path) != 0 && c.match(path) {
+		c.addValue(path, value, offset, size)
+	}
+}
+
+// addString adds the string value if its key path matches.
+// base is the line offset inside the file.
+func (c *collector) addString(path []string, value str, base int) {
+	if len(path) == 0 || !c.match(path) {
+		return
+	}
+	if value.err != nil {
+		if c.err == nil {
+			offset := base + value.begin + value.err.offset
+			line, column := c.position(offset)
+			c.err = fmt.Errorf("%d:%d: %v", line, column, value.err)
+		}
+		return
+	}
+	c.addMapped(path, value.value, base+value.begin, value.offsets, value.end-value.begin)
+}
+
+// addValue is like add, but the path is not checked.
+func (c *collector) addValue(path []string, value string, offset, size int) {
+	var offsets []int
+	if decoded, m, ok := unquote(string(c.data[offset:offset+size]), '"'); ok && decoded == value {
+		offsets = m
+	}
+	c.addMapped(path, value, offset, offsets, size)
+}
+
+// addKey adds the key name if its parent path matches the matchKey.
+// offset is the key text location inside the file.
+func (c *collector) addKey(parent []string, key string, offset, size int) {
+	if c.matchKey == nil || !c.matchKey(parent) {
+		return
+	}
+	c.addValue(append(parent[:len(parent):len(parent)], key), key, offset, size)
+}
+
+// addMapped adds the value with its text mapped by the
+// offsets relative to the base, see unquote.
+// If offsets are nil, the value text is the size bytes at base.
+func (c *collector) addMapped(path []string, value string, base int, offsets []int, size int) {
+	offset := base
+	if offsets != nil {
+		first := offsets[0]
+		offset = base + first
+		size = offsets[len(offsets)-1] - first
+		for i := range offsets {
+			offsets[i] -= first
+		}
+	}
+	pat := Pattern{
+		Pattern: value,
+		Key:     strings.Join(path, "."),
+		Offset:  offset,
+		Size:    size,
+		Exact:   string(c.data[offset:offset+size]) == value,
+	}
+	if !pat.Exact {
+		pat.offsets = offsets
+	}
+	pat.Line, pat.Column = c.position(offset)
+	c.patterns = append(c.patterns, pat)
+}
+
+// position returns the 1-based line and column of the data offset.
+func (c *collector) position(offset int) (line, column int) {
+	before := string(c.data[:offset])
+	return strings.Count(before, "\n") + 1, offset - strings.LastIndexByte(before, '\n')
+}
+
+// unquote decodes the Go escapes of the s quoted string body.
+// The offsets map every value byte to its s offset, the last
+// offset is len(s). All bytes of an escape map to its `\`.
+func unquote(s string, quote byte) (value string, offsets []int, ok bool) {
+	buf := make([]byte, 0, len(s))
+	offsets = make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			if s[i] == quote {
+				return "", nil, false
+			}
+			buf = append(buf, s[i])
+			offsets = append(offsets, i)
+			i++
+			continue
+		}
+		ch, multibyte, tail, err := strconv.UnquoteChar(s[i:], quote)
+		if err != nil {
+			return "", nil, false
+		}
+		n := len(buf)
+		if ch < utf8.RuneSelf || !multibyte {
+			buf = append(buf, byte(ch))
+		} else {
+			var enc [utf8.UTFMax]byte
+			buf = append(buf, enc[:utf8.EncodeRune(enc[:], ch)]...)
+		}
+		for ; n < len(buf); n++ {
+			offsets = append(offsets, i)
+		}
+		i = len(s) - len(tail)
+	}
+	offsets = append(offsets, len(s))
+	return string(buf), offsets, true
+}
+
+// escapeFunc decodes the escape at the s start, s[0] is `\`.
+// It returns the decoded text and the escape length.
+type escapeFunc func(s string) (value string, size int, ok bool)
+
+// escapeError is an escape that can't be decoded.
+type escapeError struct {
+	escape string
+
+	// offset is the escape location inside the string body.
+	offset int
+}
+
+func (e *escapeError) Error() string {
+	return "unknown escape sequence " + e.escape
+}
+
+// decodeEscapes decodes the s quoted string body with the escape rules.
+// The offsets map every value byte to its s offset, see unquote.
+func decodeEscapes(s string, escape escapeFunc) (value string, offsets []int, err *escapeError) {
+	buf := make([]byte, 0, len(s))
+	offsets = make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			buf = append(buf, s[i])
+			offsets = append(offsets, i)
+			i++
+			continue
+		}
+		decoded, size, ok := escape(s[i:])
+		if !ok {
+			_, n := utf8.DecodeRuneInString(s[i+1:])
+			return "", nil, &escapeError{escape: s[i : i+1+n], offset: i}
+		}
+		buf = append(buf, decoded...)
+		for n := len(offsets); n < len(buf); n++ {
+			offsets = append(offsets, i)
+		}
+		i += size
+	}
+	offsets = append(offsets, len(s))
+	return string(buf), offsets, nil
+}
+
+// unescapeQuotes decodes the doubled quotes of the single-quoted
+// string body s. The offsets are like the decodeEscapes ones.
+func unescapeQuotes(s string) (value string, offsets []int) {
+	buf := make([]byte, 0, len(s))
+	offsets = make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); i++ {
+		buf = append(buf, s[i])
+		offsets = append(offsets, i)
+		if s[i] == '\'' {
+			i++
+		}
+	}
+	offsets = append(offsets, len(s))
+	return string(buf), offsets
+}
+
+// hexEscape decodes the escape at the s start that is
+// followed by n hex digits, like `\u00e9`.
+func hexEscape(s string, n int) (value string, size int, ok bool) {
+	if len(s) < 2+n {
+		return "", 0, false
+	}
+	ch, err := strconv.ParseUint(s[2:2+n], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(ch)) {
+		return "", 0, false
+	}
+	return string(rune(ch)), 2 + n, true
+}
+
+// remap maps the offsets through the outer offsets.
+func remap(offsets, outer []int) []int {
+	mapped := make([]int, len(offsets))
+	for i, offset := range offsets {
+		mapped[i] = outer[offset]
+	}
+	return mapped
+}
+
+// lines calls fn for every data line with its offset.
+func (c *collector) lines(fn func(line string, offset int)) {
+	data := string(c.data)
+	offset := 0
+	for offset < len(data) {
+		line := data[offset:]
+		size := len(line)
+		if i := strings.IndexByte(line, '\n'); i != -1 {
+			line = line[:i]
+			size = i + 1
+		}
+		fn(strings.TrimSuffix(line, "\r"), offset)
+		offset += size
+	}
+}
+
+// quoteRules describe how the quoted strings are decoded.
+type quoteRules struct {
+	// escape decodes the escapes inside the double quotes.
+	escape escapeFunc
+
+	// singleEscape enables the YAML `''` escape inside the single quotes.
+	singleEscape bool
+
+	// plain allows the unquoted strings.
+	plain bool
+}
+
+// str is a string value found in a line.
+type str struct {
+	value string
+
+	// offsets map the value bytes to the value text offsets,
+	// see unquote. It's nil if value is the text verbatim.
+	offsets []int
+
+	// err is the escape decoding error, the value is empty then.
+	err *escapeError
+
+	// begin and end locate the value text, without the quotes.
+	begin int
+	end   int
+
+	// next is the offset after the value and its closing quote.
+	next int
+}
+
+// readString reads a string that starts at s[i].
+// A plain string ends before any of the stop chars or a ` #` comment.
+func readString(s string, i int, rules quoteRules, stop string) (str, bool) {
+	if i >= len(s) {
+		return str{}, false
+	}
+	switch s[i] {
+	case '"':
+		j := i + 1
+		for j < len(s) && s[j] != '"' {
+			if s[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) {
+			return str{}, false
+		}
+		value, offsets, err := decodeEscapes(s[i+1:j], rules.escape)
+		return str{value: value, offsets: offsets, err: err, begin: i + 1, end: j, next: j + 1}, true
+
+	case '\'':
+		j := i + 1
+		for {
+			k := strings.IndexByte(s[j:], '\'')
+			if k == -1 {
+				return str{}, false
+			}
+			j += k
+			if !rules.singleEscape || j+1 >= len(s) || s[j+1] != '\'' {
+				break
+			}
+			j += 2
+		}
+		value := str{value: s[i+1 : j], begin: i + 1, end: j, next: j + 1}
+		if rules.singleEscape && strings.Contains(value.value, "''") {
+			value.value, value.offsets = unescapeQuotes(s[i+1 : j])
+		}
+		return value, true
+
+	default:
+		if !rules.plain {
+			return str{}, false
+		}
+		j := i
+		for j < len(s) && !strings.ContainsRune(stop, rune(s[j])) {
+			if s[j] == '#' && j > i && s[j-1] == ' ' {
+				break
+			}
+			j++
+		}
+		end := i + len(strings.TrimRight(s[i:j], " \t"))
+		if end == i {
+			return str{}, false
+		}
+		return str{value: s[i:end], begin: i, end: end, next: j}, true
+	}
+}
+
+// splitFlow calls fn for every string element of the `[x, y]`
+// flow sequence that starts at s[i].
+func splitFlow(s string, i int, rules quoteRules, fn func(elem str)) {
+	i++ // Skip `[`
+	for i < len(s) {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) || s[i] == ']' {
+			return
+		}
+		elem, ok := readString(s, i, rules, ",]")
+		if !ok {
+			return
+		}
+		fn(elem)
+		i = elem.next
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) || s[i] != ',' {
+			return
+		}
+		i++
+	}
+}
@@ -0,0 +1,591 @@
+package extract
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/compat"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		want   []string
+	}{
+		{
+			format: FormatJSON,
+			data: `{
+  "name": "x",
+  "regex": "^\\d+$",
+  "rules": [{"pattern": "a|b"}, {"match": "c"}],
+  "skip_patterns": ["x*", "y+"]
+}`,
+			want: []string{
+				`3:13 regex ^\d+$ (escaped)`,
+				`4:26 rules.pattern a|b`,
+				`5:22 skip_patterns x*`,
+				`5:28 skip_patterns y+`,
+			},
+		},
+
+		{
+			format: FormatYAML,
+			data: `# config
+server:
+  host: example.com
+  path_regex: ^/api/.*   # comment
+  routes:
+    - name: a
+      pattern: "a\\.b"
+    - name: b
+      pattern: 'it''s'
+ignore_patterns:
+- x+
+- 'y'
+other: [a]
+regexes: [a, "b"]
+url_regex: "\/api\/\x41\u00e9"
+script: |
+  regex: not-a-key
+`,
+			want: []string{
+				`4:15 server.path_regex ^/api/.*`,
+				`7:17 server.routes.pattern a\.b (escaped)`,
+				`9:17 server.routes.pattern it's (escaped)`,
+				`11:3 ignore_patterns x+`,
+				`12:4 ignore_patterns y`,
+				`14:11 regexes a`,
+				`14:15 regexes b`,
+				`15:13 url_regex /api/Aé (escaped)`,
+			},
+		},
+
+		{
+			format: FormatPrometheus,
+			data: `scrape_configs:
+  - job_name: node
+    relabel_configs:
+      - source_labels: [__address__]
+        regex: '(.*):\d+'
+        target_label: instance
+    metric_relabel_configs:
+      - regex: go_.*
+        action: drop
+regex: unrelated
+`,
+			want: []string{
+				`5:17 scrape_configs.relabel_configs.regex (.*):\d+`,
+				`8:16 scrape_configs.metric_relabel_configs.regex go_.*`,
+			},
+		},
+
+		{
+			format: FormatTOML,
+			data: `regex = "top"
+
+[filter]
+name = "x"
+pattern = 'a\d'
+"path.regex" = "\\w+"
+extra.patterns = ["p1", 'p2']
+doc = """
+regex = "skipped"
+"""
+
+[[rules]]
+regexp = "r"
+name_regex = "caf\u00e9"
+`,
+			want: []string{
+				`1:10 regex top`,
+				`5:12 filter.pattern a\d`,
+				`6:17 filter.path.regex \w+ (escaped)`,
+				`7:20 filter.extra.patterns p1`,
+				`7:26 filter.extra.patterns p2`,
+				`13:11 rules.regexp r`,
+				`14:15 rules.name_regex café (escaped)`,
+			},
+		},
+
+		{
+			format: FormatEnv,
+			data: `# env
+HOST=localhost
+LOG_FILTER_REGEX=^debug # comment
+export SKIP_PATTERN="a\tb"
+URL_PATTERN='x\d'
+DIGITS_REGEX="a\d+\"\n"
+`,
+			want: []string{
+				`3:18 LOG_FILTER_REGEX ^debug`,
+				`4:22 SKIP_PATTERN a	b (escaped)`,
+				`5:14 URL_PATTERN x\d`,
+				`6:15 DIGITS_REGEX a\d+"
+ (escaped)`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		patterns, err := Extract([]byte(test.data), test.format, nil)
+		if err != nil {
+			t.Errorf("%s: %v", test.format, err)
+			continue
+		}
+		var have []string
+		for _, p := range patterns {
+			s := fmt.Sprintf("%d:%d %s %s", p.Line, p.Column, p.Key, p.Pattern)
+			if !p.Exact {
+				s += " (escaped)"
+			}
+			have = append(have, s)
+			raw := test.data[p.Offset : p.Offset+p.Size]
+			if p.Exact && raw != p.Pattern {
+				t.Errorf("%s: %s: offset points to %q", test.format, p.Key, raw)
+			}
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s:\nhave:\n%s\nwant:\n%s", test.format,
+				strings.Join(have, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+}
+
+func TestExtractKeys(t *testing.T) {
+	data := []byte(`{"regex": "a", "filter": "b", "url_filters": ["c"]}`)
+	patterns, err := Extract(data, FormatJSON, &Options{Keys: []string{"filter"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, p := range patterns {
+		have = append(have, p.Pattern)
+	}
+	if strings.Join(have, " ") != "b c" {
+		t.Errorf("patterns:\nhave: %v\nwant: [b c]", have)
+	}
+}
+
+func TestExtractJSONError(t *testing.T) {
+	_, err := Extract([]byte("{\n  \"regex\": \"a\",\n}"), FormatJSON, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "2:15: ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExtractEscapeError(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		want   string
+	}{
+		{FormatYAML, "a: \"\\d\"\nregex: \"x\\d\"\n", `2:10: unknown escape sequence \d`},
+		{FormatYAML, "regexes: [a, \"\\u12\"]\n", `1:15: unknown escape sequence \u`},
+		{FormatTOML, "a = \"\\d\"\nregex = \"\\/\"\n", `2:10: unknown escape sequence \/`},
+	}
+
+	for _, test := range tests {
+		_, err := Extract([]byte(test.data), test.format, nil)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%s: Extract(%q):\nhave: %v\nwant: %s", test.format, test.data, err, test.want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     Format
+	}{
+		{"config.json", FormatJSON},
+		{"deploy/prometheus.yml", FormatYAML},
+		{"app.YAML", FormatYAML},
+		{"Cargo.toml", FormatTOML},
+		{".env", FormatEnv},
+		{".env.local", FormatEnv},
+		{"prod.env", FormatEnv},
+		{"main.go", FormatGo},
+		{"web/form.tsx", FormatHTML},
+		{"README.md", 0},
+	}
+
+	for _, test := range tests {
+		have, _ := DetectFormat(test.filename)
+		if have != test.want {
+			t.Errorf("DetectFormat(%q):\nhave: %s\nwant: %s", test.filename, have, test.want)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	data := "rules:\n  - regex: a(b\n  - regex: \"x(\\\\d\"\n  - regex: ok\n"
+	patterns, err := File("rules.yaml", []byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := Lint("rules.yaml", patterns, nil)
+	if len(results) != 2 {
+		t.Fatalf("results:\nhave: %d\nwant: 2", len(results))
+	}
+	for i, p := range patterns[:2] {
+		r := results[i]
+		if r.RuleID != syntax.CodeExpectedToken.String() || r.Offset != p.Offset {
+			t.Errorf("%s: unexpected result %+v", p.Pattern, r)
+		}
+		if int(r.Pos.End) > p.Size {
+			t.Errorf("%s: position %v is out of the value bounds", p.Pattern, r.Pos)
+		}
+	}
+}
+
+func TestExtractGo(t *testing.T) {
+	data := `package main
+
+import (
+	"regexp"
+	"github.com/gorilla/mux"
+	v "github.com/example/validator/v2"
+)
+
+type Form struct {
+	Name  string ` + "`" + `validate:"required,regexp=^[a-z]+$" json:"name"` + "`" + `
+	Email string "pattern:\"\\\\w+@\""
+}
+
+func main() {
+	regexp.MustCompile("a\\d+")
+	regexp.MatchString(` + "`" + `b+` + "`" + `, "bbb")
+	r := mux.NewRouter()
+	r.HandleFunc("/users/{id:[0-9]+}", nil)
+	v.Check("x", "c*")
+	other.MustCompile("skipped")
+}
+`
+	opts := &Options{
+		Sinks: []Sink{
+			{Func: "HandleFunc"},
+			{Path: "github.com/example/validator/v2", Func: "Check", Arg: 1},
+		},
+		Tags: []TagSink{
+			{Key: "validate", Option: "regexp"},
+			{Key: "pattern"},
+		},
+	}
+	patterns, err := Extract([]byte(data), FormatGo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`10:42 validate:regexp ^[a-z]+$`,
+		`11:26 pattern \w+@ (escaped)`,
+		`15:22 regexp.MustCompile a\d+ (escaped)`,
+		`16:22 regexp.MatchString b+`,
+		`18:16 HandleFunc /users/{id:[0-9]+}`,
+		`19:16 validator.Check c*`,
+	}
+	var have []string
+	for _, p := range patterns {
+		s := fmt.Sprintf("%d:%d %s %s", p.Line, p.Column, p.Key, p.Pattern)
+		if !p.Exact {
+			s += " (escaped)"
+		}
+		have = append(have, s)
+		if raw := data[p.Offset : p.Offset+p.Size]; p.Exact && raw != p.Pattern {
+			t.Errorf("%s: offset points to %q", p.Key, raw)
+		}
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("patterns:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := Extract([]byte("package"), FormatGo, nil); err == nil {
+		t.Errorf("expected a Go syntax error")
+	}
+}
+
+func TestCheckStructTags(t *testing.T) {
+	data := "package models\n\n" +
+		"type User struct {\n" +
+		"\tName string `validate:\"required,regexp=^[a-z]+0x2C[a-z]+$\" json:\"name\"`\n" +
+		"\tCode string \"binding:\\\"regexp=a\\\\\\\\d[\\\"\"\n" +
+		"\tTag  string `pattern:\"(?<=a)b\"`\n" +
+		"}\n"
+
+	patterns, err := StructTags([]byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, p := range patterns {
+		have = append(have, p.Key+" "+p.Pattern)
+	}
+	want := []string{
+		`validate:regexp ^[a-z]+,[a-z]+$`,
+		`binding:regexp a\d[`,
+		`pattern (?<=a)b`,
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("patterns:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+	if i := strings.Index(data, "0x2C"); patterns[0].FileOffset(len("^[a-z]+")) != i {
+		t.Errorf("0x2C offset:\nhave: %d\nwant: %d", patterns[0].FileOffset(len("^[a-z]+")), i)
+	}
+
+	results, err := CheckStructTags("models.go", []byte(data), nil, &LintOptions{
+		Profiles: []*compat.Profile{compat.GoRegexp},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantResults := []struct {
+		rule string
+		text string
+	}{
+		{syntax.CodeUnexpectedToken.String(), "["},
+		{RuleCompat, "(?<=a)"},
+	}
+	if len(results) != len(wantResults) {
+		t.Fatalf("results:\nhave: %d\nwant: %d", len(results), len(wantResults))
+	}
+	for i, want := range wantResults {
+		r := results[i]
+		text := data[r.Offset+int(r.Pos.Begin) : r.Offset+int(r.Pos.End)]
+		if r.RuleID != want.rule || text != want.text {
+			t.Errorf("result %d:\nhave: %s %q\nwant: %s %q", i, r.RuleID, text, want.rule, want.text)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		want   []string
+	}{
+		{
+			format: FormatJSON,
+			data: `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "string", "pattern": "^[a-z]+$"},
+    "pattern": {"type": "string", "examples": [{"pattern": "skipped"}]}
+  },
+  "patternProperties": {"^x-\\w+": {}},
+  "default": {"pattern": "skipped"}
+}`,
+			want: []string{
+				`4:42 properties.id.pattern ^[a-z]+$`,
+				`7:26 patternProperties.^x-\w+ ^x-\w+ (escaped)`,
+			},
+		},
+
+		{
+			format: FormatYAML,
+			data: `openapi: 3.0.0
+components:
+  schemas:
+    User:
+      properties:
+        name:
+          type: string
+          pattern: '^[A-Z]'
+      patternProperties:
+        "^meta_":
+          type: string
+      example:
+        pattern: skipped
+`,
+			want: []string{
+				`8:21 components.schemas.User.properties.name.pattern ^[A-Z]`,
+				`10:10 components.schemas.User.patternProperties.^meta_ ^meta_`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		patterns, err := Schema([]byte(test.data), test.format)
+		if err != nil {
+			t.Errorf("%s: %v", test.format, err)
+			continue
+		}
+		var have []string
+		for _, p := range patterns {
+			s := fmt.Sprintf("%d:%d %s %s", p.Line, p.Column, p.Key, p.Pattern)
+			if !p.Exact {
+				s += " (escaped)"
+			}
+			have = append(have, s)
+		}
+		if strings.Join(have, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s:\nhave:\n%s\nwant:\n%s", test.format,
+				strings.Join(have, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+
+	if _, err := Schema(nil, FormatTOML); err == nil {
+		t.Errorf("expected an error for TOML schema")
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	data := `{"properties": {"a": {"pattern": "^(?<x>\\d)$"}}}`
+	results, err := CheckSchema("schema.json", []byte(data), FormatJSON, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`compat (?<x>\\d)`,
+		`schema-portability \\d`,
+		`schema-portability $`,
+	}
+	var have []string
+	for _, r := range results {
+		have = append(have, r.RuleID+" "+data[r.Offset+int(r.Pos.Begin):r.Offset+int(r.Pos.End)])
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("results:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckEnvoy(t *testing.T) {
+	data := `static_resources:
+  listeners:
+  - filter_chains:
+    - filters:
+      - typed_config:
+          route_config:
+            virtual_hosts:
+            - routes:
+              - match:
+                  safe_regex:
+                    regex: "^/api/v[0-9]+"
+                route:
+                  regex_rewrite:
+                    pattern:
+                      regex: /old/(.*)
+                    substitution: /new/\\1
+              - match:
+                  headers:
+                  - name: x-id
+                    safe_regex_match:
+                      regex: '[a-f0-9]{128}(?=x)'
+                  prefix: /
+`
+	patterns, err := Envoy([]byte(data), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 3 {
+		t.Fatalf("patterns:\nhave: %d\nwant: 3", len(patterns))
+	}
+
+	results, err := CheckEnvoy("envoy.yaml", []byte(data), FormatYAML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`11:29 full-match-anchor ^`,
+		`11:36 full-match-anchor [0-9]+`,
+		`21:31 compat [a-f0-9]{128}(?=x)`,
+		`21:44 compat (?=x)`,
+	}
+	var have []string
+	for _, r := range results {
+		have = append(have, fmt.Sprintf("%d:%d %s %s", r.Line, r.Column, r.RuleID,
+			data[r.Offset+int(r.Pos.Begin):r.Offset+int(r.Pos.End)]))
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("results:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckHTML(t *testing.T) {
+	data := `<form>
+  <input name="zip" pattern="\d{5}">
+  <input name="q" pattern='^[a-z]+'>
+  <input name="tag" pattern="[a&amp;b(]">
+  <input name=x pattern=[\p{L}--[a-z]]+>
+</form>
+<Field pattern={"\\w+\\A"} />
+`
+	patterns, err := File("form.jsx", []byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, p := range patterns {
+		have = append(have, fmt.Sprintf("%d:%d %s", p.Line, p.Column, p.Pattern))
+	}
+	want := []string{
+		`2:30 \d{5}`,
+		`3:28 ^[a-z]+`,
+		`4:30 [a&b(]`,
+		`5:25 [\p{L}--[a-z]]+`,
+		`7:18 \w+\A`,
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("patterns:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+
+	results := CheckHTML("form.jsx", []byte(data), nil)
+	wantResults := []string{
+		`full-match-anchor ^`,
+		`full-match-anchor [a-z]+`,
+		`compat (`,
+		`compat \\A`,
+	}
+	have = nil
+	for _, r := range results {
+		have = append(have, r.RuleID+" "+data[r.Offset+int(r.Pos.Begin):r.Offset+int(r.Pos.End)])
+	}
+	if strings.Join(have, "\n") != strings.Join(wantResults, "\n") {
+		t.Errorf("results:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(wantResults, "\n"))
+	}
+}
+
+func TestCheckInjection(t *testing.T) {
+	data := `package main
+
+import (
+	"fmt"
+	re "regexp"
+)
+
+var (
+	userRE  = re.MustCompile(fmt.Sprintf("^%s@%s$", user, host))
+	idRE    = re.MustCompile(fmt.Sprintf("^id-%d$", id))
+	sepRE   = re.MustCompile(fmt.Sprintf("[%s]+|x%s*", seps, sep))
+	plainRE = re.MustCompile("^%s$")
+	otherRE = re.MustCompile(strings.Repeat("a", 2))
+)
+`
+	patterns, err := Templates([]byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 3 {
+		t.Fatalf("patterns:\nhave: %d\nwant: 3", len(patterns))
+	}
+
+	results, err := CheckInjection("main.go", []byte(data), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`pattern-injection %s@: %s is not quoted`,
+		`pattern-injection %s$: %s is not quoted`,
+		`pattern-injection %s]: %s is inside a char class`,
+		`pattern-injection %s*: %s is quantified`,
+	}
+	var have []string
+	for _, r := range results {
+		text := data[r.Offset+int(r.Pos.Begin) : r.Offset+int(r.Pos.End)+1]
+		have = append(have, r.RuleID+" "+text+": "+strings.SplitN(r.Message, ":", 2)[0])
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("results:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,283 @@
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// Sink is a Go function that takes a pattern argument.
+type Sink struct {
+	// Path is the function package import path, like `regexp`.
+	// An empty Path matches the methods named Func of any type,
+	// the receiver types are not checked.
+	Path string
+
+	Func string
+
+	// Arg is the pattern argument index.
+	Arg int
+}
+
+// TagSink is a struct tag key that holds the patterns.
+type TagSink struct {
+	Key string
+
+	// Option selects the `option=pattern` element of a comma-separated
+	// tag value, like in `validate:"required,regexp=^\d+$"`.
+	// If empty, the whole tag value is a pattern.
+	Option string
+}
+
+// DefaultSinks are the regexp package functions, they're always
+// checked by Extract.
+var DefaultSinks = []Sink{
+	{Path: "regexp", Func: "Compile"},
+	{Path: "regexp", Func: "CompilePOSIX"},
+	{Path: "regexp", Func: "MustCompile"},
+	{Path: "regexp", Func: "MustCompilePOSIX"},
+	{Path: "regexp", Func: "Match"},
+	{Path: "regexp", Func: "MatchReader"},
+	{Path: "regexp", Func: "MatchString"},
+}
+
+// DefaultTags are the go-playground/validator and gin binding
+// `regexp=` options and the `pattern` tags.
+var DefaultTags = []TagSink{
+	{Key: "validate", Option: "regexp"},
+	{Key: "binding", Option: "regexp"},
+	{Key: "pattern"},
+}
+
+// StructTags extracts the patterns from the Go struct tags only.
+// If tags are empty, DefaultTags are used.
+//
+// Both the Go string literal and the tag value escapes are decoded,
+// the positions of such patterns are mapped by Pattern.FileOffset.
+func StructTags(data []byte, tags []TagSink) ([]Pattern, error) {
+	if len(tags) == 0 {
+		tags = DefaultTags
+	}
+	c := collector{data: data}
+	if err := c.scanGo(nil, tags); err != nil {
+		return nil, err
+	}
+	return c.patterns, nil
+}
+
+// CheckStructTags lints the StructTags patterns of the uri Go file.
+func CheckStructTags(uri string, data []byte, tags []TagSink, opts *LintOptions) ([]sarif.Result, error) {
+	patterns, err := StructTags(data, tags)
+	if err != nil {
+		return nil, err
+	}
+	results := Lint(uri, patterns, opts)
+	sarif.Locate(results, data)
+	return results, nil
+}
+
+// scanGo extracts the string literal arguments of the sink calls
+// and the tag sink values.
+// The Go patterns are keyed by the sink names, like `regexp.MustCompile`
+// or `validate:regexp`.
+func (c *collector) scanGo(sinks []Sink, tags []TagSink) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", c.data, 0)
+	if err != nil {
+		return err
+	}
+
+	imports := make(map[string]string)
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	addLit := func(key string, lit *ast.BasicLit) {
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return
+		}
+		offset := fset.Position(lit.Pos()).Offset + 1
+		c.addValue([]string{key}, value, offset, len(lit.Value)-2)
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			path := ""
+			if x, ok := sel.X.(*ast.Ident); ok {
+				path = imports[x.Name]
+			}
+			for _, sink := range sinks {
+				if sink.Func != sel.Sel.Name || sink.Path != path || sink.Arg >= len(n.Args) {
+					continue
+				}
+				arg := n.Args[sink.Arg]
+				if c.templates {
+					arg = sprintfFormat(arg, imports)
+				}
+				if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					key := sink.Func
+					if path != "" {
+						key = importName(path) + "." + key
+					}
+					addLit(key, lit)
+				}
+				break
+			}
+
+		case *ast.Field:
+			if n.Tag != nil && len(tags) != 0 {
+				c.addTags(tags, n.Tag.Value, fset.Position(n.Tag.Pos()).Offset)
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// addTags adds the tag sink values of the lit struct tag literal.
+func (c *collector) addTags(tags []TagSink, lit string, litOffset int) {
+	// The tag offsets are relative to the literal body.
+	body := lit[1 : len(lit)-1]
+	tag, tagOffsets, ok := body, []int(nil), true
+	if lit[0] == '`' {
+		tagOffsets = make([]int, len(body)+1)
+		for i := range tagOffsets {
+			tagOffsets[i] = i
+		}
+	} else {
+		tag, tagOffsets, ok = unquote(body, '"')
+		if !ok {
+			return
+		}
+	}
+
+	// Scan the `key:"value"` pairs like reflect.StructTag.Lookup does.
+	for i := 0; i < len(tag); {
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		colon := strings.IndexByte(tag[i:], ':')
+		if colon == -1 || i+colon+1 >= len(tag) || tag[i+colon+1] != '"' {
+			return
+		}
+		key := tag[i : i+colon]
+		begin := i + colon + 2
+		end := begin
+		for end < len(tag) && tag[end] != '"' {
+			if tag[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(tag) {
+			return
+		}
+		i = end + 1
+		value, valueOffsets, ok := unquote(tag[begin:end], '"')
+		if !ok {
+			return
+		}
+		for j := range valueOffsets {
+			valueOffsets[j] += begin
+		}
+
+		for _, sink := range tags {
+			if sink.Key != key {
+				continue
+			}
+			name := sink.Key
+			pattern, offsets := value, valueOffsets
+			if sink.Option != "" {
+				name += ":" + sink.Option
+				pattern, offsets, ok = tagOption(value, valueOffsets, sink.Option)
+				if !ok {
+					continue
+				}
+			}
+			c.addMapped([]string{name}, pattern, litOffset+1, remap(offsets, tagOffsets), 0)
+		}
+	}
+}
+
+// tagOption returns the `option=value` element value of a comma-separated
+// list, with its offsets mapped by the list offsets.
+// The validator `0x2C` and `0x7C` escapes are decoded to `,` and `|`.
+func tagOption(s string, offsets []int, option string) (string, []int, bool) {
+	prefix := option + "="
+	for begin := 0; begin <= len(s); {
+		end := strings.IndexByte(s[begin:], ',')
+		if end == -1 {
+			end = len(s)
+		} else {
+			end += begin
+		}
+		if !strings.HasPrefix(s[begin:end], prefix) {
+			begin = end + 1
+			continue
+		}
+		var buf []byte
+		var elemOffsets []int
+		for i := begin + len(prefix); i < end; {
+			ch := s[i]
+			size := 1
+			switch {
+			case strings.HasPrefix(s[i:end], "0x2C"):
+				ch, size = ',', 4
+			case strings.HasPrefix(s[i:end], "0x7C"):
+				ch, size = '|', 4
+			}
+			buf = append(buf, ch)
+			elemOffsets = append(elemOffsets, offsets[i])
+			i += size
+		}
+		elemOffsets = append(elemOffsets, offsets[end])
+		return string(buf), elemOffsets, true
+	}
+	return "", nil, false
+}
+
+// importName returns the default package name for the import path,
+// the `/vN` major version suffixes are skipped.
+func importName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return name
+}
+
+// sprintfFormat returns the format argument of the e fmt.Sprintf call
+// or nil if e is not such call.
+func sprintfFormat(e ast.Expr, imports map[string]string) ast.Expr {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Sprintf" {
+		return nil
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || imports[x.Name] != "fmt" {
+		return nil
+	}
+	return call.Args[0]
+}
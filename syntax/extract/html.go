@@ -0,0 +1,164 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// HTML extracts the `pattern` attribute values of an HTML template
+// or a JSX file. The quoted and unquoted HTML values and the JSX
+// `{"..."}` string expressions are supported.
+func HTML(data []byte) []Pattern {
+	c := collector{data: data}
+	c.scanHTML()
+	return c.patterns
+}
+
+func (c *collector) scanHTML() {
+	s := string(c.data)
+	for i := 0; ; {
+		j := strings.Index(strings.ToLower(s[i:]), "pattern=")
+		if j == -1 {
+			return
+		}
+		i += j
+		attr := i
+		i += len("pattern=")
+		if attr == 0 || !isSpace(s[attr-1]) || i == len(s) {
+			continue
+		}
+
+		switch s[i] {
+		case '"', '\'':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end == -1 {
+				continue
+			}
+			value, offsets := decodeEntities(s[i+1 : i+1+end])
+			c.addMapped([]string{"pattern"}, value, i+1, offsets, end)
+		case '{':
+			// A JSX string expression.
+			k := i + 1
+			for k < len(s) && isSpace(s[k]) {
+				k++
+			}
+			if k == len(s) || strings.IndexByte("\"'`", s[k]) == -1 {
+				continue
+			}
+			end := k + 1
+			for end < len(s) && s[end] != s[k] {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				continue
+			}
+			if value, _, ok := unquote(s[k+1:end], s[k]); ok {
+				c.addValue([]string{"pattern"}, value, k+1, end-k-1)
+			}
+		default:
+			end := i
+			for end < len(s) && !isSpace(s[end]) && s[end] != '>' {
+				end++
+			}
+			value, offsets := decodeEntities(s[i:end])
+			c.addMapped([]string{"pattern"}, value, i, offsets, end-i)
+		}
+	}
+}
+
+// htmlEntities are the character references that can appear in the patterns.
+var htmlEntities = map[string]string{
+	"&amp;":  "&",
+	"&lt;":   "<",
+	"&gt;":   ">",
+	"&quot;": `"`,
+	"&apos;": "'",
+	"&#39;":  "'",
+}
+
+// decodeEntities decodes the htmlEntities of s.
+// The offsets are nil if there are none, see unquote.
+func decodeEntities(s string) (string, []int) {
+	if !strings.Contains(s, "&") {
+		return s, nil
+	}
+	var buf []byte
+	var offsets []int
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' {
+			if end := strings.IndexByte(s[i:], ';'); end != -1 {
+				if ch, ok := htmlEntities[s[i:i+end+1]]; ok {
+					buf = append(buf, ch...)
+					offsets = append(offsets, i)
+					i += end
+					continue
+				}
+			}
+		}
+		buf = append(buf, s[i])
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(s))
+	return string(buf), offsets
+}
+
+// CheckHTML lints the HTML patterns of the uri file.
+//
+// The patterns are checked with compat.CheckHTMLPattern and the
+// analysis.TargetHTMLPattern anchoring issues are reported as
+// the RuleFullMatchAnchor warnings. Only the opts parser
+// options are used.
+func CheckHTML(uri string, data []byte, opts *LintOptions) []sarif.Result {
+	if opts == nil {
+		opts = &LintOptions{}
+	}
+	p := syntax.NewParser(opts.Parser)
+	var results []sarif.Result
+	for _, pat := range HTML(data) {
+		issues, err := compat.CheckHTMLPattern(p, pat.Pattern)
+		if err != nil {
+			if r, ok := sarif.ParseErrorResult(uri, pat.Offset, err); ok {
+				r.Pos = pat.filePos(r.Pos)
+				results = append(results, r)
+			}
+			continue
+		}
+		for _, issue := range issues {
+			results = append(results, sarif.Result{
+				RuleID:  RuleCompat,
+				Level:   sarif.LevelError,
+				Message: issue.Message,
+				URI:     uri,
+				Offset:  pat.Offset,
+				Pos:     pat.filePos(issue.Pos),
+			})
+		}
+		re, err := p.Parse(pat.Pattern)
+		if len(issues) != 0 || err != nil {
+			continue
+		}
+		for _, issue := range analysis.CheckAnchoring(re, analysis.TargetHTMLPattern) {
+			results = append(results, sarif.Result{
+				RuleID:  RuleFullMatchAnchor,
+				Level:   sarif.LevelWarning,
+				Message: issue.Message,
+				URI:     uri,
+				Offset:  pat.Offset,
+				Pos:     pat.filePos(issue.Expr.Pos),
+			})
+		}
+	}
+	sarif.Locate(results, data)
+	return results
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
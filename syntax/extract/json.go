@@ -0,0 +1,91 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonFrame is an open JSON object or array.
+type jsonFrame struct {
+	object bool
+
+	// key is the current object key.
+	key string
+
+	// expectKey reports whether the next object string is a key.
+	expectKey bool
+}
+
+func (c *collector) scanJSON() error {
+	dec := json.NewDecoder(bytes.NewReader(c.data))
+	var stack []jsonFrame
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF && len(stack) == 0 {
+				return nil
+			}
+			offset := int(dec.InputOffset())
+			before := string(c.data[:offset])
+			line := strings.Count(before, "\n") + 1
+			column := offset - strings.LastIndexByte(before, '\n')
+			return fmt.Errorf("%d:%d: %v", line, column, err)
+		}
+
+		var top *jsonFrame
+		if len(stack) != 0 {
+			top = &stack[len(stack)-1]
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				stack = append(stack, jsonFrame{object: tok == '{', expectKey: tok == '{'})
+				continue
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			end := int(dec.InputOffset())
+			start := bytes.LastIndexByte(c.data[:end-1], '"')
+			for isEscaped(c.data, start) {
+				start = bytes.LastIndexByte(c.data[:start], '"')
+			}
+			if top != nil && top.object && top.expectKey {
+				top.key = tok
+				top.expectKey = false
+				c.addKey(jsonPath(stack[:len(stack)-1]), tok, start+1, end-start-2)
+				continue
+			}
+			c.add(jsonPath(stack), tok, start+1, end-start-2)
+		}
+
+		// A value was completed, the next object string is a key.
+		if len(stack) != 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+}
+
+func jsonPath(stack []jsonFrame) []string {
+	var path []string
+	for _, frame := range stack {
+		if frame.object {
+			path = append(path, frame.key)
+		}
+	}
+	return path
+}
+
+// isEscaped reports whether the data[i] char is escaped by `\`.
+func isEscaped(data []byte, i int) bool {
+	slashes := 0
+	for i > 0 && data[i-1] == '\\' {
+		slashes++
+		i--
+	}
+	return slashes%2 == 1
+}
@@ -0,0 +1,71 @@
+package extract
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// RuleSchemaPortability is a rule ID of the JSON Schema pattern
+// parts that are matched differently by the validators.
+const RuleSchemaPortability = "schema-portability"
+
+// Schema extracts the `pattern` values and the `patternProperties` keys
+// of a JSON Schema or OpenAPI document in FormatJSON or FormatYAML.
+//
+// The examples, defaults, enums and consts are skipped,
+// as their values are instances, not schemas.
+func Schema(data []byte, format Format) ([]Pattern, error) {
+	c := collector{
+		data: data,
+		match: func(path []string) bool {
+			return path[len(path)-1] == "pattern" && isSchemaPath(path[:len(path)-1])
+		},
+		matchKey: func(path []string) bool {
+			return len(path) != 0 && path[len(path)-1] == "patternProperties" && isSchemaPath(path)
+		},
+	}
+	switch format {
+	case FormatJSON:
+		if err := c.scanJSON(); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		c.scanYAML()
+	default:
+		return nil, fmt.Errorf("%s is not a schema format", format)
+	}
+	return c.patterns, nil
+}
+
+// isSchemaPath reports whether the path can lead to a schema.
+func isSchemaPath(path []string) bool {
+	for _, key := range path {
+		switch key {
+		case "example", "examples", "default", "enum", "const":
+			return false
+		}
+	}
+	return true
+}
+
+// CheckSchema lints the Schema patterns of the uri document.
+//
+// The patterns are checked against the compat.JSONSchema profile
+// in addition to the opts profiles, and the compat.JSONSchemaPortability
+// issues are reported as the RuleSchemaPortability warnings.
+func CheckSchema(uri string, data []byte, format Format, opts *LintOptions) ([]sarif.Result, error) {
+	patterns, err := Schema(data, format)
+	if err != nil {
+		return nil, err
+	}
+	lintOpts := LintOptions{Profiles: []*compat.Profile{compat.JSONSchema}}
+	if opts != nil {
+		lintOpts.Parser = opts.Parser
+		lintOpts.Profiles = append(lintOpts.Profiles, opts.Profiles...)
+	}
+	results := lintIssues(uri, patterns, &lintOpts, RuleSchemaPortability, compat.JSONSchemaPortability)
+	sarif.Locate(results, data)
+	return results, nil
+}
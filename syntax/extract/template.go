@@ -0,0 +1,57 @@
+package extract
+
+import (
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/sarif"
+)
+
+// RuleInjection is a rule ID of the analysis.CheckInjection risks.
+const RuleInjection = "pattern-injection"
+
+// Templates extracts the fmt.Sprintf format strings that are passed
+// to the sinks of a Go file, like in `regexp.MustCompile(fmt.Sprintf("^%s$", name))`.
+// DefaultSinks are always checked.
+//
+// The patterns are keyed by the sink names, like the Extract Go patterns.
+func Templates(data []byte, sinks []Sink) ([]Pattern, error) {
+	c := collector{data: data, templates: true}
+	if err := c.scanGo(append(append([]Sink(nil), DefaultSinks...), sinks...), nil); err != nil {
+		return nil, err
+	}
+	return c.patterns, nil
+}
+
+// CheckInjection lints the Templates patterns of the uri Go file.
+//
+// The patterns are parsed with the fmt verbs as placeholders,
+// the analysis.CheckInjection risks are reported as the
+// RuleInjection warnings.
+func CheckInjection(uri string, data []byte, sinks []Sink, opts *LintOptions) ([]sarif.Result, error) {
+	patterns, err := Templates(data, sinks)
+	if err != nil {
+		return nil, err
+	}
+	var lintOpts LintOptions
+	var parserOpts syntax.ParserOptions
+	if opts != nil {
+		lintOpts = *opts
+		if opts.Parser != nil {
+			parserOpts = *opts.Parser
+		}
+	}
+	parserOpts.Placeholders |= syntax.PlaceholdersPrintf
+	lintOpts.Parser = &parserOpts
+	results := lintIssues(uri, patterns, &lintOpts, RuleInjection, injectionIssues)
+	sarif.Locate(results, data)
+	return results, nil
+}
+
+func injectionIssues(re *syntax.Regexp) []compat.Issue {
+	var issues []compat.Issue
+	for _, risk := range analysis.CheckInjection(re) {
+		issues = append(issues, compat.Issue{Pos: risk.Expr.Pos, Message: risk.Message})
+	}
+	return issues
+}
@@ -0,0 +1,113 @@
+package extract
+
+import (
+	"strings"
+)
+
+var tomlQuotes = quoteRules{escape: tomlEscape}
+
+// tomlEscapes are the basic string escapes, except the `\u` and `\U` ones.
+var tomlEscapes = map[byte]string{
+	'\\': "\\", '"': `"`, 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t",
+}
+
+// tomlEscape decodes a TOML basic string escape.
+func tomlEscape(s string) (string, int, bool) {
+	if len(s) < 2 {
+		return "", 0, false
+	}
+	switch s[1] {
+	case 'u':
+		return hexEscape(s, 4)
+	case 'U':
+		return hexEscape(s, 8)
+	}
+	value, ok := tomlEscapes[s[1]]
+	return value, 2, ok
+}
+
+func (c *collector) scanTOML() {
+	var table []string
+
+	// closing is the multi-line string delimiter
+	// if the line is inside of such a string.
+	closing := ""
+
+	c.lines(func(line string, offset int) {
+		if closing != "" {
+			if strings.Contains(line, closing) {
+				closing = ""
+			}
+			return
+		}
+		body := strings.TrimLeft(line, " \t")
+		if body == "" || body[0] == '#' {
+			return
+		}
+		if body[0] == '[' {
+			// A `[table]` or an `[[array.of.tables]]` header.
+			header := strings.TrimPrefix(body[1:], "[")
+			if end := strings.IndexByte(header, ']'); end != -1 {
+				table = tomlKey(header[:end])
+			}
+			return
+		}
+		eq := strings.IndexByte(body, '=')
+		if eq == -1 {
+			return
+		}
+		base := offset + len(line) - len(body)
+		path := append(append([]string(nil), table...), tomlKey(body[:eq])...)
+		i := eq + 1
+		for i < len(body) && (body[i] == ' ' || body[i] == '\t') {
+			i++
+		}
+		rest := body[i:]
+		switch {
+		case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, `'''`):
+			// Multi-line strings are not supported.
+			if !strings.Contains(rest[3:], rest[:3]) {
+				closing = rest[:3]
+			}
+		case strings.HasPrefix(rest, "["):
+			splitFlow(body, i, tomlQuotes, func(elem str) {
+				c.addString(path, elem, base)
+			})
+		default:
+			if value, ok := readString(body, i, tomlQuotes, ""); ok {
+				c.addString(path, value, base)
+			}
+		}
+	})
+}
+
+// tomlKey splits a dotted key, like `a."b.c".d`.
+func tomlKey(s string) []string {
+	var parts []string
+	for {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return parts
+		}
+		if s[0] == '"' || s[0] == '\'' {
+			key, ok := readString(s, 0, tomlQuotes, "")
+			if !ok || key.err != nil {
+				return parts
+			}
+			parts = append(parts, key.value)
+			s = s[key.next:]
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end == -1 {
+				end = len(s)
+			}
+			parts = append(parts, strings.TrimSpace(s[:end]))
+			s = s[end:]
+		}
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, ".") {
+			return parts
+		}
+		s = s[1:]
+	}
+}
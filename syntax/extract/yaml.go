@@ -0,0 +1,168 @@
+package extract
+
+import (
+	"strings"
+)
+
+// yamlKey is an open YAML mapping key.
+type yamlKey struct {
+	indent int
+	name   string
+}
+
+var yamlQuotes = quoteRules{escape: yamlEscape, singleEscape: true, plain: true}
+
+// yamlEscapes are the double-quoted scalar escapes,
+// except the `\x`, `\u` and `\U` ones.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v",
+	'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': `"`, '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// yamlEscape decodes a YAML double-quoted scalar escape.
+func yamlEscape(s string) (string, int, bool) {
+	if len(s) < 2 {
+		return "", 0, false
+	}
+	switch s[1] {
+	case 'x':
+		return hexEscape(s, 2)
+	case 'u':
+		return hexEscape(s, 4)
+	case 'U':
+		return hexEscape(s, 8)
+	}
+	value, ok := yamlEscapes[s[1]]
+	return value, 2, ok
+}
+
+func (c *collector) scanYAML() {
+	var stack []yamlKey
+
+	// blockIndent is the indentation of a block scalar owner,
+	// the more indented lines belong to the scalar.
+	blockIndent := -1
+
+	c.lines(func(line string, offset int) {
+		body := strings.TrimLeft(line, " ")
+		indent := len(line) - len(body)
+		if blockIndent != -1 {
+			if body == "" || indent > blockIndent {
+				return
+			}
+			blockIndent = -1
+		}
+		if body == "" || body[0] == '#' || body == "---" || body == "..." {
+			return
+		}
+
+		item := false
+		dashIndent := indent
+		for body == "-" || strings.HasPrefix(body, "- ") {
+			item = true
+			rest := strings.TrimLeft(body[1:], " ")
+			indent += len(body) - len(rest)
+			body = rest
+		}
+		for len(stack) != 0 {
+			top := stack[len(stack)-1]
+			if item && top.indent <= dashIndent || !item && top.indent < indent {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		if body == "" {
+			return
+		}
+		base := offset + len(line) - len(body)
+
+		path := make([]string, 0, len(stack)+1)
+		for _, key := range stack {
+			path = append(path, key.name)
+		}
+		key, i, ok := yamlSplitKey(body)
+		if !ok {
+			if item {
+				c.addYAMLValue(path, body, 0, base, dashIndent, &blockIndent)
+			}
+			return
+		}
+		name := key.value
+		c.addKey(path, name, base+key.begin, key.end-key.begin)
+		path = append(path, name)
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+		// Skip the anchors and tags.
+		for i < len(body) && (body[i] == '&' || body[i] == '!') {
+			for i < len(body) && body[i] != ' ' {
+				i++
+			}
+			for i < len(body) && body[i] == ' ' {
+				i++
+			}
+		}
+		if i == len(body) || body[i] == '#' {
+			stack = append(stack, yamlKey{indent: indent, name: name})
+			return
+		}
+		c.addYAMLValue(path, body, i, base, indent, &blockIndent)
+	})
+}
+
+// addYAMLValue adds the value that starts at body[i].
+// indent is the value owner indentation.
+func (c *collector) addYAMLValue(path []string, body string, i, base, indent int, blockIndent *int) {
+	switch body[i] {
+	case '|', '>':
+		*blockIndent = indent
+	case '{':
+		// Flow mappings are not supported.
+	case '[':
+		splitFlow(body, i, yamlQuotes, func(elem str) {
+			c.addString(path, elem, base)
+		})
+	default:
+		if value, ok := readString(body, i, yamlQuotes, ""); ok {
+			c.addString(path, value, base)
+		}
+	}
+}
+
+// yamlSplitKey splits the `key: value` mapping entry.
+// It returns the key and the value offset.
+func yamlSplitKey(body string) (key str, next int, ok bool) {
+	i := 0
+	if body[0] == '"' || body[0] == '\'' {
+		key, ok = readString(body, 0, yamlQuotes, "")
+		if !ok || key.err != nil {
+			return str{}, 0, false
+		}
+		i = key.next
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+		if i == len(body) || body[i] != ':' {
+			return str{}, 0, false
+		}
+	} else {
+		for ; i < len(body); i++ {
+			if body[i] == '#' && i > 0 && body[i-1] == ' ' {
+				return str{}, 0, false
+			}
+			if body[i] == ':' && (i+1 == len(body) || body[i+1] == ' ' || body[i+1] == '\t') {
+				break
+			}
+		}
+		if i == len(body) {
+			return str{}, 0, false
+		}
+		name := strings.TrimRight(body[:i], " \t")
+		key = str{value: name, end: len(name)}
+	}
+	if i+1 < len(body) && body[i+1] != ' ' && body[i+1] != '\t' {
+		return str{}, 0, false
+	}
+	return key, i + 1, true
+}
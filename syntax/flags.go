@@ -0,0 +1,230 @@
+package syntax
+
+import (
+	"strings"
+)
+
+// Dialect identifies a regexp syntax flavor.
+type Dialect byte
+
+const (
+	// DialectRE2 is the Go regexp and RE2 syntax.
+	DialectRE2 Dialect = iota
+
+	// DialectPCRE is the PCRE syntax.
+	DialectPCRE
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectRE2:
+		return "RE2"
+	case DialectPCRE:
+		return "PCRE"
+	default:
+		return "?"
+	}
+}
+
+// Flags is a set of regexp flags.
+type Flags uint16
+
+const (
+	// FlagCaseInsensitive is `i`.
+	FlagCaseInsensitive Flags = 1 << iota
+
+	// FlagMultiline is `m`: `^` and `$` match at line boundaries.
+	FlagMultiline
+
+	// FlagDotAll is `s`: `.` matches a newline.
+	FlagDotAll
+
+	// FlagUngreedy is `U`: swaps the meaning of `x*` and `x*?`.
+	FlagUngreedy
+
+	// FlagExtended is `x`: whitespace and `#` comments are ignored. PCRE-only.
+	FlagExtended
+
+	// FlagNoAutoCapture is `n`: plain `(...)` groups don't capture. PCRE-only.
+	FlagNoAutoCapture
+
+	// FlagDupNames is `J`: duplicated group names are allowed. PCRE-only.
+	FlagDupNames
+)
+
+var flagChars = []struct {
+	ch      byte
+	flag    Flags
+	dialect Dialect // The least permissive dialect that supports the flag
+}{
+	{'i', FlagCaseInsensitive, DialectRE2},
+	{'m', FlagMultiline, DialectRE2},
+	{'s', FlagDotAll, DialectRE2},
+	{'U', FlagUngreedy, DialectRE2},
+	{'x', FlagExtended, DialectPCRE},
+	{'n', FlagNoAutoCapture, DialectPCRE},
+	{'J', FlagDupNames, DialectPCRE},
+}
+
+// String returns the flags letters, like `ims`.
+func (f Flags) String() string {
+	var b strings.Builder
+	for _, info := range flagChars {
+		if f&info.flag != 0 {
+			b.WriteByte(info.ch)
+		}
+	}
+	return b.String()
+}
+
+// FlagErrorKind describes a flags string problem.
+type FlagErrorKind byte
+
+const (
+	// FlagUnknown is a flag that is not supported by the dialect.
+	FlagUnknown FlagErrorKind = iota + 1
+
+	// FlagDuplicate is a flag that is mentioned more than once.
+	FlagDuplicate
+
+	// FlagConflict is a flag that is both enabled and disabled.
+	FlagConflict
+
+	// FlagMisplacedDash is an extra `-` or a `-` without any flags after it.
+	FlagMisplacedDash
+)
+
+// FlagError is a flags string parsing error.
+type FlagError struct {
+	Kind FlagErrorKind
+
+	// Offset is a problematic char offset inside the flags string.
+	Offset int
+
+	// Char is a problematic flags string char.
+	Char byte
+}
+
+func (e *FlagError) Error() string {
+	switch e.Kind {
+	case FlagUnknown:
+		return "unknown flag: " + string(e.Char)
+	case FlagDuplicate:
+		return "duplicated flag: " + string(e.Char)
+	case FlagConflict:
+		return "flag is both enabled and disabled: " + string(e.Char)
+	default:
+		return "misplaced '-' in flags"
+	}
+}
+
+// ParseFlags parses a flags string, like `i-ms`.
+//
+// The string may start with `?` and end with `:`,
+// so OpFlagOnlyGroup and OpGroupWithFlags flags can be passed as is.
+// The result contains flags that are enabled and disabled by s.
+func ParseFlags(s string, d Dialect) (enable, disable Flags, err error) {
+	offset := 0
+	if strings.HasPrefix(s, "?") {
+		offset = 1
+	}
+	s = strings.TrimSuffix(s, ":")
+
+	negated := false
+	for i := offset; i < len(s); i++ {
+		ch := s[i]
+		if ch == '-' {
+			if negated || i == len(s)-1 {
+				return 0, 0, &FlagError{Kind: FlagMisplacedDash, Offset: i, Char: ch}
+			}
+			negated = true
+			continue
+		}
+		flag := Flags(0)
+		for _, info := range flagChars {
+			if info.ch == ch && info.dialect <= d {
+				flag = info.flag
+				break
+			}
+		}
+		if flag == 0 {
+			return 0, 0, &FlagError{Kind: FlagUnknown, Offset: i, Char: ch}
+		}
+		if (enable|disable)&flag != 0 {
+			kind := FlagDuplicate
+			if (enable&flag != 0) == negated {
+				kind = FlagConflict
+			}
+			return 0, 0, &FlagError{Kind: kind, Offset: i, Char: ch}
+		}
+		if negated {
+			disable |= flag
+		} else {
+			enable |= flag
+		}
+	}
+	return enable, disable, nil
+}
+
+// WalkFlags calls visit for every e sub-expression along with
+// the flags that are active at its location.
+//
+// flags describes the flags that are enabled at the pattern start,
+// like the ones that are passed to the engine outside of the pattern.
+// The expressions are visited in the pattern text order.
+//
+// `(?flags)` changes the flags until the end of the enclosing group,
+// including the alternation branches that follow it; this is how
+// both RE2 and PCRE handle it. Flags strings that can't be parsed
+// in the dialect d are ignored.
+func WalkFlags(e Expr, d Dialect, flags Flags, visit func(e Expr, flags Flags)) {
+	walkFlags(e, d, flags, visit)
+}
+
+// walkFlags returns the flags that are active after e.
+func walkFlags(e Expr, d Dialect, flags Flags, visit func(Expr, Flags)) Flags {
+	visit(e, flags)
+
+	switch e.Op {
+	case OpConcat, OpAlt:
+		for _, a := range e.Args {
+			flags = walkFlags(a, d, flags, visit)
+		}
+		return flags
+
+	case OpFlagOnlyGroup:
+		visit(e.Args[0], flags)
+		return applyFlags(e.Args[0].Value, d, flags)
+
+	case OpGroupWithFlags:
+		inner := applyFlags(e.Args[1].Value, d, flags)
+		visit(e.Args[1], flags)
+		walkFlags(e.Args[0], d, inner, visit)
+		return flags
+
+	case OpNamedCapture:
+		visit(e.Args[1], flags)
+		walkFlags(e.Args[0], d, flags, visit)
+		return flags
+
+	case OpCapture, OpGroup, OpAtomicGroup, OpScriptRun,
+		OpPositiveLookahead, OpNegativeLookahead,
+		OpPositiveLookbehind, OpNegativeLookbehind:
+		walkFlags(e.Args[0], d, flags, visit)
+		return flags
+
+	default:
+		for _, a := range e.Args {
+			flags = walkFlags(a, d, flags, visit)
+		}
+		return flags
+	}
+}
+
+func applyFlags(s string, d Dialect, flags Flags) Flags {
+	enable, disable, err := ParseFlags(s, d)
+	if err != nil {
+		return flags
+	}
+	return (flags | enable) &^ disable
+}
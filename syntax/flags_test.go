@@ -0,0 +1,102 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		flags   string
+		dialect Dialect
+		enable  string
+		disable string
+		err     string
+	}{
+		{``, DialectRE2, ``, ``, ``},
+		{`i`, DialectRE2, `i`, ``, ``},
+		{`?i-ms:`, DialectRE2, `i`, `ms`, ``},
+		{`-U`, DialectRE2, ``, `U`, ``},
+		{`imsU`, DialectRE2, `imsU`, ``, ``},
+		{`xnJ`, DialectPCRE, `xnJ`, ``, ``},
+
+		{`x`, DialectRE2, ``, ``, `0: unknown flag: x`},
+		{`iq`, DialectPCRE, ``, ``, `1: unknown flag: q`},
+		{`?imi`, DialectRE2, ``, ``, `3: duplicated flag: i`},
+		{`-ss`, DialectRE2, ``, ``, `2: duplicated flag: s`},
+		{`i-i`, DialectRE2, ``, ``, `2: flag is both enabled and disabled: i`},
+		{`i-`, DialectRE2, ``, ``, `1: misplaced '-' in flags`},
+		{`i-m-s`, DialectRE2, ``, ``, `3: misplaced '-' in flags`},
+	}
+
+	for _, test := range tests {
+		enable, disable, err := ParseFlags(test.flags, test.dialect)
+		if err != nil {
+			ferr := err.(*FlagError)
+			have := fmt.Sprintf("%d: %s", ferr.Offset, ferr.Error())
+			if have != test.err {
+				t.Errorf("parseFlags(%q, %s) error:\nhave: %s\nwant: %s", test.flags, test.dialect, have, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("parseFlags(%q, %s): expected %q error", test.flags, test.dialect, test.err)
+			continue
+		}
+		if enable.String() != test.enable || disable.String() != test.disable {
+			t.Errorf("parseFlags(%q, %s):\nhave: +%s -%s\nwant: +%s -%s",
+				test.flags, test.dialect, enable, disable, test.enable, test.disable)
+		}
+	}
+}
+
+func TestParseFlagsFromAST(t *testing.T) {
+	p := NewParser(nil)
+	re, err := p.Parse(`(?i-s)x(?m:y)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enable, disable, err := ParseFlags(re.Expr.Args[0].Args[0].Value, DialectRE2)
+	if err != nil || enable != FlagCaseInsensitive || disable != FlagDotAll {
+		t.Errorf("(?i-s): have +%s -%s %v", enable, disable, err)
+	}
+	enable, disable, err = ParseFlags(re.Expr.Args[2].Args[1].Value, DialectRE2)
+	if err != nil || enable != FlagMultiline || disable != 0 {
+		t.Errorf("(?m:y): have +%s -%s %v", enable, disable, err)
+	}
+}
+
+func TestWalkFlags(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a`, `a:`},
+		{`(?i)a`, `a:i`},
+		{`a(?i)b`, `a: b:i`},
+		{`(?i:a)b`, `a:i b:`},
+		{`(?i)a(?-i:b)c`, `a:i b: c:i`},
+		{`(a(?i)b|c)d`, `a: b:i c:i d:`},
+		{`(?s)(?P<x>(?m)a)b`, `a:ms b:s`},
+		{`(?z)a`, `a:`},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var parts []string
+		WalkFlags(re.Expr, DialectRE2, 0, func(e Expr, flags Flags) {
+			if e.Op == OpChar {
+				parts = append(parts, e.Value+":"+flags.String())
+			}
+		})
+		have := strings.Join(parts, " ")
+		if have != test.want {
+			t.Errorf("WalkFlags(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
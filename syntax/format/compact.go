@@ -0,0 +1,230 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Compact returns the free-spacing pattern rewritten without
+// the `x` flag, so it can be used with the engines that don't support it.
+//
+// flags describes the flags that are enabled outside of the pattern,
+// the FlagExtended is the only one that is taken into account.
+// Whitespace and `#` comments are removed from the parts of the pattern
+// where the `x` flag is active, `(?#...)` comments are removed everywhere.
+// The `x` flag is removed from the flag groups, escaped whitespace
+// and `#` chars are unescaped. Char classes and `\Q...\E` quotes
+// are copied as is.
+//
+// The pattern text is processed without parsing, so invalid
+// patterns are rewritten on the best effort basis.
+func Compact(pattern string, flags syntax.Flags) string {
+	c := compactor{
+		pattern:  pattern,
+		extended: flags&syntax.FlagExtended != 0,
+	}
+	c.run()
+	return c.out.String()
+}
+
+type compactor struct {
+	pattern  string
+	pos      int
+	extended bool
+	stack    []bool
+	out      strings.Builder
+
+	// mergeable reports whether the char can't follow the
+	// last written token without changing its meaning,
+	// like a digit after the `\1` backreference.
+	mergeable func(ch byte) bool
+}
+
+func (c *compactor) write(s string) {
+	if c.mergeable != nil && s != "" && c.mergeable(s[0]) {
+		c.out.WriteString("(?:)")
+	}
+	c.mergeable = nil
+	c.out.WriteString(s)
+}
+
+func (c *compactor) run() {
+	for c.pos < len(c.pattern) {
+		ch := c.pattern[c.pos]
+		switch {
+		case c.extended && isSpace(ch):
+			c.pos++
+		case c.extended && ch == '#':
+			end := strings.IndexByte(c.pattern[c.pos:], '\n')
+			if end == -1 {
+				c.pos = len(c.pattern)
+			} else {
+				c.pos += end + 1
+			}
+		case ch == '\\':
+			c.escape()
+		case ch == '[':
+			c.charClass()
+		case ch == '(':
+			c.group()
+		case ch == ')':
+			if n := len(c.stack); n != 0 {
+				c.extended = c.stack[n-1]
+				c.stack = c.stack[:n-1]
+			}
+			c.write(")")
+			c.pos++
+		default:
+			c.write(c.pattern[c.pos : c.pos+1])
+			c.pos++
+		}
+	}
+}
+
+func (c *compactor) escape() {
+	begin := c.pos
+	c.pos++
+	if c.pos == len(c.pattern) {
+		c.write(`\`)
+		return
+	}
+	ch := c.pattern[c.pos]
+	c.pos++
+	switch {
+	case ch == 'Q':
+		end := strings.Index(c.pattern[c.pos:], `\E`)
+		if end == -1 {
+			c.pos = len(c.pattern)
+		} else {
+			c.pos += end + len(`\E`)
+		}
+		c.write(c.pattern[begin:c.pos])
+	case c.extended && (isSpace(ch) || ch == '#'):
+		c.write(string(ch))
+	case isDigit(ch):
+		for c.pos < len(c.pattern) && c.pos-begin < 4 && isDigit(c.pattern[c.pos]) {
+			c.pos++
+		}
+		c.write(c.pattern[begin:c.pos])
+		c.mergeable = isDigit
+	case ch == 'x' && (c.pos == len(c.pattern) || c.pattern[c.pos] != '{'):
+		for c.pos < len(c.pattern) && c.pos-begin < 4 && isHexDigit(c.pattern[c.pos]) {
+			c.pos++
+		}
+		c.write(c.pattern[begin:c.pos])
+		if c.pos-begin < 4 {
+			c.mergeable = isHexDigit
+		}
+	default:
+		c.pos--
+		c.skipRune()
+		c.write(c.pattern[begin:c.pos])
+	}
+}
+
+func (c *compactor) charClass() {
+	begin := c.pos
+	c.pos++
+	if c.pos < len(c.pattern) && c.pattern[c.pos] == '^' {
+		c.pos++
+	}
+	if c.pos < len(c.pattern) && c.pattern[c.pos] == ']' {
+		c.pos++
+	}
+	for c.pos < len(c.pattern) {
+		switch {
+		case c.pattern[c.pos] == ']':
+			c.pos++
+			c.write(c.pattern[begin:c.pos])
+			return
+		case c.pattern[c.pos] == '\\':
+			c.pos++
+			c.skipRune()
+		case strings.HasPrefix(c.pattern[c.pos:], "[:"):
+			end := strings.Index(c.pattern[c.pos:], ":]")
+			if end == -1 {
+				c.pos++
+			} else {
+				c.pos += end + len(":]")
+			}
+		default:
+			c.pos++
+		}
+	}
+	c.write(c.pattern[begin:])
+}
+
+func (c *compactor) group() {
+	rest := c.pattern[c.pos:]
+	if strings.HasPrefix(rest, "(?#") {
+		end := strings.IndexByte(rest, ')')
+		if end == -1 {
+			c.pos = len(c.pattern)
+		} else {
+			c.pos += end + 1
+		}
+		return
+	}
+
+	flagsEnd := len("(?")
+	for flagsEnd < len(rest) && isFlagChar(rest[flagsEnd]) {
+		flagsEnd++
+	}
+	isFlags := strings.HasPrefix(rest, "(?") && flagsEnd < len(rest) &&
+		(rest[flagsEnd] == ')' || rest[flagsEnd] == ':')
+	if !isFlags {
+		c.stack = append(c.stack, c.extended)
+		c.write("(")
+		c.pos++
+		return
+	}
+
+	flags := rest[len("(?"):flagsEnd]
+	onlyFlags := rest[flagsEnd] == ')'
+	c.pos += flagsEnd + 1
+	if !onlyFlags {
+		c.stack = append(c.stack, c.extended)
+	}
+	if !strings.Contains(flags, "x") {
+		c.write(rest[:flagsEnd+1])
+		return
+	}
+
+	c.extended = strings.IndexByte(flags, 'x') < strings.IndexByte(flags+"-", '-')
+	flags = strings.Replace(flags, "x", "", -1)
+	flags = strings.TrimSuffix(flags, "-")
+	switch {
+	case !onlyFlags:
+		c.write("(?" + flags + ":")
+	case flags != "":
+		c.write("(?" + flags + ")")
+	}
+}
+
+func (c *compactor) skipRune() {
+	c.pos++
+	for c.pos < len(c.pattern) && c.pattern[c.pos]&0xC0 == 0x80 {
+		c.pos++
+	}
+}
+
+func isSpace(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+func isFlagChar(ch byte) bool {
+	return ch == '-' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
@@ -0,0 +1,60 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   syntax.Flags
+		want    string
+	}{
+		{`a b # c`, 0, `a b # c`},
+		{`a b # c`, syntax.FlagExtended, `ab`},
+		{"(?x) a b # c\n d", 0, `abd`},
+		{"(?ix) a | b", 0, `(?i)a|b`},
+		{"x y (?x: a b ) x y", 0, `x y (?:ab) x y`},
+		{"(?x) a (?-x) b c", 0, `a b c`},
+		{"(?x) a (?i-x: b c ) d e", 0, `a(?i: b c )de`},
+		{"(?x) ( a (?-x) b ) c d", 0, `(a b )cd`},
+		{`(?x) [ a ] \ \# \Q a b \E`, 0, `[ a ] #\Q a b \E`},
+		{`(?x) [] #] \[ # \]`, 0, `[] #]\[`},
+		{`(?x) a(?#comment) b`, 0, `ab`},
+		{`(?x) (a) \1 0 \12 3`, 0, `(a)\1(?:)0\12(?:)3`},
+		{`(?x) \x4 1 \x41 b \x{41} 1`, 0, `\x4(?:)1\x41b\x{41}1`},
+		{`(?x) (?P<name> a ) (?R)`, 0, `(?P<name>a)(?R)`},
+	}
+
+	for _, test := range tests {
+		have := Compact(test.pattern, test.flags)
+		if have != test.want {
+			t.Errorf("Compact(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestCompactFreeSpacing(t *testing.T) {
+	patterns := []string{
+		`^(?P<year>\d{4})-(a b|#)+$`,
+		`x(?:)|\Q a\E`,
+		`(?i)[# ]+|(?:a|(b c))?`,
+	}
+
+	p := syntax.NewParser(nil)
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		spaced, err := FreeSpacing(re)
+		if err != nil {
+			t.Fatalf("FreeSpacing(%q): %v", pattern, err)
+		}
+		if have := Compact(spaced, 0); have != pattern {
+			t.Errorf("Compact(FreeSpacing(%q)):\nhave: %s\nwant: %s", pattern, have, pattern)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package format implements the regexp pattern formatting.
+package format
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// NamedGroupStyle selects the named capture group syntax.
+type NamedGroupStyle byte
+
+const (
+	// NamedGroupsAsIs keeps the named groups syntax unchanged.
+	NamedGroupsAsIs NamedGroupStyle = iota
+
+	// NamedGroupsP is `(?P<name>re)`, it's supported by all engines.
+	NamedGroupsP
+
+	// NamedGroupsAngle is `(?<name>re)`.
+	NamedGroupsAngle
+
+	// NamedGroupsQuote is `(?'name're)`.
+	NamedGroupsQuote
+)
+
+// HexCase selects the hex escapes letters case.
+type HexCase byte
+
+const (
+	// HexAsIs keeps the hex digits unchanged.
+	HexAsIs HexCase = iota
+
+	// HexUpper is `\xFF`.
+	HexUpper
+
+	// HexLower is `\xff`.
+	HexLower
+)
+
+// StyleOptions describe the preferred pattern style.
+// The zero value keeps the pattern unchanged.
+type StyleOptions struct {
+	NamedGroups NamedGroupStyle
+
+	HexCase HexCase
+
+	// ShortHex rewrites `\x{41}` as `\x41` when the code fits into 2 digits.
+	ShortHex bool
+
+	// ControlEscapes rewrites the hex escapes of `\t`, `\n`, `\r`
+	// and `\f` using their letter forms.
+	ControlEscapes bool
+
+	// RemoveRedundantEscapes removes `\` before the punctuation
+	// chars that have no special meaning in their context, like `\-`
+	// outside of a char class or `\.` inside it.
+	RemoveRedundantEscapes bool
+}
+
+// Canonical returns re.Pattern rewritten in the specified style.
+//
+// Only the cosmetic aspects are changed, the pattern meaning
+// is preserved. Parts of the pattern that are not affected by
+// the style options are kept as is.
+func Canonical(re *syntax.Regexp, style StyleOptions) string {
+	f := formatter{style: style}
+	f.walk(re.Expr, false)
+	if len(f.edits) == 0 {
+		return re.Pattern
+	}
+	sort.SliceStable(f.edits, func(i, j int) bool {
+		return f.edits[i].begin < f.edits[j].begin
+	})
+	var b strings.Builder
+	offset := 0
+	for _, e := range f.edits {
+		b.WriteString(re.Pattern[offset:e.begin])
+		b.WriteString(e.text)
+		offset = e.end
+	}
+	b.WriteString(re.Pattern[offset:])
+	return b.String()
+}
+
+type edit struct {
+	begin int
+	end   int
+	text  string
+}
+
+type formatter struct {
+	style StyleOptions
+	edits []edit
+}
+
+func (f *formatter) replace(e syntax.Expr, text string) {
+	if text != e.Value {
+		f.edits = append(f.edits, edit{begin: int(e.Begin()), end: int(e.End()), text: text})
+	}
+}
+
+func (f *formatter) walk(e syntax.Expr, insideClass bool) {
+	switch e.Op {
+	case syntax.OpCharClass, syntax.OpNegCharClass:
+		insideClass = true
+	case syntax.OpNamedCapture:
+		f.formatNamedCapture(e)
+	case syntax.OpEscapeHex:
+		f.formatHex(e)
+		return
+	case syntax.OpEscapeChar:
+		if f.style.RemoveRedundantEscapes && isRedundantEscape(e.Args[0].Value, insideClass) {
+			f.replace(e, e.Args[0].Value)
+		}
+		return
+	}
+	for _, a := range e.Args {
+		f.walk(a, insideClass)
+	}
+}
+
+func (f *formatter) formatNamedCapture(e syntax.Expr) {
+	name := e.Args[1]
+	var open, close string
+	switch f.style.NamedGroups {
+	case NamedGroupsP:
+		open, close = "(?P<", ">"
+	case NamedGroupsAngle:
+		open, close = "(?<", ">"
+	case NamedGroupsQuote:
+		open, close = "(?'", "'"
+	default:
+		return
+	}
+	f.edits = append(f.edits,
+		edit{begin: int(e.Begin()), end: int(name.Begin()), text: open},
+		edit{begin: int(name.End()), end: int(name.End()) + len(close), text: close})
+}
+
+func (f *formatter) formatHex(e syntax.Expr) {
+	digits := e.Args[0].Value
+	if digits == "" {
+		return
+	}
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return
+	}
+
+	if f.style.ControlEscapes {
+		if s, ok := controlEscapes[code]; ok {
+			f.replace(e, s)
+			return
+		}
+	}
+
+	full := e.Form == syntax.FormEscapeHexFull
+	if f.style.ShortHex && full && code <= 0xFF {
+		full = false
+		digits = strconv.FormatUint(code, 16)
+		if len(digits) == 1 {
+			digits = "0" + digits
+		}
+		if strings.ToUpper(e.Args[0].Value) == e.Args[0].Value {
+			digits = strings.ToUpper(digits)
+		}
+	}
+	switch f.style.HexCase {
+	case HexUpper:
+		digits = strings.ToUpper(digits)
+	case HexLower:
+		digits = strings.ToLower(digits)
+	}
+	if full {
+		f.replace(e, `\x{`+digits+`}`)
+	} else {
+		f.replace(e, `\x`+digits)
+	}
+}
+
+var controlEscapes = map[uint64]string{
+	'\t': `\t`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\f': `\f`,
+}
+
+// isRedundantEscape reports whether the escaped char
+// can be written without a `\` in the given context.
+//
+// Some chars are never unescaped even if it's safe in most cases:
+// `,` can form a repetition like `x{1\,2}` => `x{1,2}`, `:`, `.` and `=`
+// can form a POSIX class, `#` and whitespace are special under the `x` flag.
+func isRedundantEscape(ch string, insideClass bool) bool {
+	if len(ch) != 1 {
+		return false
+	}
+	if insideClass {
+		return strings.Contains(`+*?(){}|$/"'!@%&~;_<>`+"`", ch)
+	}
+	return strings.Contains(`-/"'!@%&~;_=<>:`+"`", ch)
+}
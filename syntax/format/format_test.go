@@ -0,0 +1,79 @@
+package format
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		pattern string
+		style   StyleOptions
+		want    string
+	}{
+		{`(?<x>a)(?'y'b)(?P<z>c)`, StyleOptions{}, `(?<x>a)(?'y'b)(?P<z>c)`},
+		{`(?<x>a)(?'y'b)(?P<z>c)`, StyleOptions{NamedGroups: NamedGroupsP}, `(?P<x>a)(?P<y>b)(?P<z>c)`},
+		{`(?<x>a)(?'y'b)(?P<z>c)`, StyleOptions{NamedGroups: NamedGroupsAngle}, `(?<x>a)(?<y>b)(?<z>c)`},
+		{`(?P<x>(?<y>))`, StyleOptions{NamedGroups: NamedGroupsQuote}, `(?'x'(?'y'))`},
+
+		{`\xfa\x{aB}`, StyleOptions{HexCase: HexUpper}, `\xFA\x{AB}`},
+		{`\xFA\x{aB}`, StyleOptions{HexCase: HexLower}, `\xfa\x{ab}`},
+		{`\x{41}\x{4}\x{FF}\x{100}`, StyleOptions{ShortHex: true}, `\x41\x04\xFF\x{100}`},
+		{`\x{a}\x09[\x0D]\x0c\x0b`, StyleOptions{ControlEscapes: true}, `\n\t[\r]\f\x0b`},
+
+		{`a\-b\/c\.\:`, StyleOptions{RemoveRedundantEscapes: true}, `a-b/c\.:`},
+		{`[\.\-\]\^\/\:]`, StyleOptions{RemoveRedundantEscapes: true}, `[\.\-\]\^/\:]`},
+		{`[\(\)\*\|\$]\(`, StyleOptions{RemoveRedundantEscapes: true}, `[()*|$]\(`},
+		{`x{1\,2}\#\ \d\n`, StyleOptions{RemoveRedundantEscapes: true}, `x{1\,2}\#\ \d\n`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := Canonical(re, test.style)
+		if have != test.want {
+			t.Errorf("Canonical(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+		if _, err := p.Parse(have); err != nil {
+			t.Errorf("parse(%q): %v", have, err)
+		}
+	}
+}
+
+func TestCanonicalEquivalent(t *testing.T) {
+	style := StyleOptions{
+		NamedGroups:            NamedGroupsP,
+		HexCase:                HexUpper,
+		ShortHex:               true,
+		ControlEscapes:         true,
+		RemoveRedundantEscapes: true,
+	}
+	tests := []struct {
+		pattern string
+		inputs  []string
+	}{
+		{`(?P<x>\-\x{41}+)\x0A[\$\.]`, []string{"-AA\n$", "-A\n.", "-A\nx", "A\n$"}},
+		{`\/\x{7e}[\{\}]`, []string{"/~{", "/~}", "/~x"}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		formatted := Canonical(re, style)
+		want := regexp.MustCompile(test.pattern)
+		have := regexp.MustCompile(formatted)
+		for _, input := range test.inputs {
+			if want.MatchString(input) != have.MatchString(input) {
+				t.Errorf("%q and %q disagree on %q", test.pattern, formatted, input)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/explain"
+)
+
+// FreeSpacing returns re.Pattern rewritten in the `(?x)` free-spacing form.
+//
+// Every logical unit, like a literal, a quantified atom or a group
+// delimiter, is placed on its own line, alternation branches are
+// separated by `|` lines. Groups contents are indented.
+// Every line gets a `#` comment that describes it, see explain.Describe.
+//
+// Whitespace and `#` chars inside the literals are escaped,
+// so the result matches the same strings. The free-spacing
+// mode is PCRE-only.
+//
+// An error is returned if re already changes the `x` flag.
+func FreeSpacing(re *syntax.Regexp) (string, error) {
+	if changesExtended(re.Expr) {
+		return "", errors.New("pattern already changes the x flag")
+	}
+	p := spacingPrinter{pattern: re.Pattern}
+	p.emit(re.Expr, 0)
+	return p.String(), nil
+}
+
+func changesExtended(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpFlagOnlyGroup:
+		return strings.Contains(e.Args[0].Value, "x")
+	case syntax.OpGroupWithFlags:
+		if strings.Contains(e.Args[1].Value, "x") {
+			return true
+		}
+	}
+	for _, a := range e.Args {
+		if changesExtended(a) {
+			return true
+		}
+	}
+	return false
+}
+
+type spacingLine struct {
+	code    string
+	comment string
+}
+
+type spacingPrinter struct {
+	pattern string
+	lines   []spacingLine
+}
+
+func (p *spacingPrinter) String() string {
+	width := 0
+	for _, l := range p.lines {
+		if n := utf8.RuneCountInString(l.code); n > width {
+			width = n
+		}
+	}
+	var b strings.Builder
+	b.WriteString("(?x)\n")
+	for _, l := range p.lines {
+		b.WriteString(l.code)
+		if l.comment != "" {
+			b.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(l.code)+2))
+			b.WriteString("# ")
+			b.WriteString(l.comment)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (p *spacingPrinter) add(depth int, code, comment string) {
+	// Comments end with a newline, so they can't have their own.
+	comment = strings.Replace(comment, "\n", `\n`, -1)
+	p.lines = append(p.lines, spacingLine{
+		code:    strings.Repeat("  ", depth) + code,
+		comment: comment,
+	})
+}
+
+func (p *spacingPrinter) text(begin, end uint16) string {
+	return p.pattern[begin:end]
+}
+
+func (p *spacingPrinter) emit(e syntax.Expr, depth int) {
+	switch e.Op {
+	case syntax.OpConcat:
+		for _, a := range e.Args {
+			p.emit(a, depth)
+		}
+
+	case syntax.OpAlt:
+		for i, a := range e.Args {
+			if i != 0 {
+				p.add(depth, "|", "")
+			}
+			p.emit(a, depth+1)
+		}
+
+	case syntax.OpEmptyMatch:
+		// Nothing to print.
+
+	case syntax.OpComment:
+		p.add(depth, e.Value, "")
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat,
+		syntax.OpNonGreedy, syntax.OpPossessive:
+		x := e
+		for isQuantifier(x.Op) {
+			x = x.Args[0]
+		}
+		suffix := p.text(x.End(), e.End())
+		if isGroup(x.Op) {
+			p.emitGroup(x, depth, suffix, explain.Describe(e))
+		} else {
+			p.add(depth, p.atom(x)+suffix, explain.Describe(e))
+		}
+
+	default:
+		if isGroup(e.Op) {
+			p.emitGroup(e, depth, "", "")
+		} else {
+			p.add(depth, p.atom(e), explain.Describe(e))
+		}
+	}
+}
+
+func (p *spacingPrinter) emitGroup(g syntax.Expr, depth int, suffix, comment string) {
+	body := g.Args[0]
+	if body.IsEmptyMatch() {
+		if comment == "" {
+			comment = explain.Describe(g)
+		}
+		p.add(depth, p.text(g.Begin(), g.End())+suffix, comment)
+		return
+	}
+	p.add(depth, p.text(g.Begin(), body.Begin()), explain.Describe(g))
+	p.emit(body, depth+1)
+	p.add(depth, p.text(body.End(), g.End())+suffix, comment)
+}
+
+// atom returns the e text with whitespace and `#` escaped.
+func (p *spacingPrinter) atom(e syntax.Expr) string {
+	if e.Op == syntax.OpQuote && e.Form == syntax.FormQuoteUnclosed {
+		// The padding and the comment would be quoted otherwise.
+		return p.text(e.Begin(), e.End()) + `\E`
+	}
+	if e.Op != syntax.OpChar && e.Op != syntax.OpLiteral {
+		return p.text(e.Begin(), e.End())
+	}
+	var b strings.Builder
+	for _, ch := range e.Value {
+		switch ch {
+		case ' ', '#':
+			b.WriteByte('\\')
+			b.WriteRune(ch)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\v':
+			b.WriteString(`\x0B`)
+		default:
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+func isQuantifier(op syntax.Operation) bool {
+	switch op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat,
+		syntax.OpNonGreedy, syntax.OpPossessive:
+		return true
+	}
+	return false
+}
+
+func isGroup(op syntax.Operation) bool {
+	switch op {
+	case syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpAtomicGroup,
+		syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind,
+		syntax.OpScriptRun:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestFreeSpacing(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`^(?P<year>\d{4})-(a b|#)+$`, `(?x)
+^          # start anchor
+(?P<year>  # capture group "year"
+  \d{4}    # digit, exactly 4 times
+)
+-          # literal "-"
+(          # capture group
+    a\ b   # literal "a b"
+  |
+    \#     # literal "#"
+)+         # one or more of the capture group
+$          # end anchor
+`},
+		{`x(?:)|\Q a\E`, `(?x)
+  x       # literal "x"
+  (?:)    # group
+|
+  \Q a\E  # literal " a"
+`},
+		{`x|\Q a`, `(?x)
+  x       # literal "x"
+|
+  \Q a\E  # literal " a"
+`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, err := FreeSpacing(re)
+		if err != nil {
+			t.Fatalf("FreeSpacing(%q): %v", test.pattern, err)
+		}
+		if have != test.want {
+			t.Errorf("FreeSpacing(%q):\nhave:\n%s\nwant:\n%s", test.pattern, have, test.want)
+		}
+	}
+
+	re, err := p.Parse(`a(?x:b)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FreeSpacing(re); err == nil {
+		t.Errorf("FreeSpacing(%q): expected an error", re.Pattern)
+	}
+}
@@ -0,0 +1,333 @@
+// Package grep maps the grep-like command lines to the pattern semantics.
+package grep
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/sed"
+)
+
+// Syntax is a pattern syntax selected by the command flags.
+type Syntax byte
+
+const (
+	// SyntaxBRE is the grep default, POSIX basic regexps.
+	SyntaxBRE Syntax = iota
+
+	// SyntaxERE is `grep -E` and egrep, POSIX extended regexps.
+	SyntaxERE
+
+	// SyntaxPCRE is `grep -P` and `rg -P`.
+	SyntaxPCRE
+
+	// SyntaxFixed is `-F` and fgrep, patterns are literal strings.
+	SyntaxFixed
+
+	// SyntaxRust is the ripgrep default, the rust regex syntax.
+	SyntaxRust
+)
+
+func (s Syntax) String() string {
+	switch s {
+	case SyntaxBRE:
+		return "BRE"
+	case SyntaxERE:
+		return "ERE"
+	case SyntaxPCRE:
+		return "PCRE"
+	case SyntaxFixed:
+		return "fixed"
+	case SyntaxRust:
+		return "rust"
+	default:
+		return "?"
+	}
+}
+
+// Mode describes how a grep-like command interprets its patterns.
+type Mode struct {
+	Syntax Syntax
+
+	// IgnoreCase is `-i`.
+	IgnoreCase bool
+
+	// WordRegexp is `-w`: the match must form a whole word.
+	WordRegexp bool
+
+	// LineRegexp is `-x`: the match must form a whole line.
+	LineRegexp bool
+}
+
+// Dialect returns the syntax dialect that should be used for the patterns.
+func (m Mode) Dialect() syntax.Dialect {
+	if m.Syntax == SyntaxPCRE {
+		return syntax.DialectPCRE
+	}
+	return syntax.DialectRE2
+}
+
+// Effective returns a single pattern that matches the same lines
+// as the command patterns, written in the syntax accepted by the syntax package.
+//
+// BRE patterns are converted to the ERE form, fixed strings are quoted.
+// Several patterns are joined into an alternation, the case-insensitivity
+// and the implicit anchoring of `-w` and `-x` are made explicit.
+// `-w` is approximated with `\b` assertions.
+func (m Mode) Effective(patterns ...string) string {
+	parts := make([]string, len(patterns))
+	for i, p := range patterns {
+		switch m.Syntax {
+		case SyntaxBRE:
+			p = sed.ConvertBRE(p)
+		case SyntaxFixed:
+			p = syntax.QuoteMeta(p, m.Dialect())
+		}
+		parts[i] = p
+	}
+
+	result := strings.Join(parts, "|")
+	if len(parts) > 1 || m.WordRegexp || m.LineRegexp {
+		result = "(?:" + result + ")"
+	}
+	if m.WordRegexp {
+		result = `\b` + result + `\b`
+	}
+	if m.LineRegexp {
+		result = "^" + result + "$"
+	}
+	if m.IgnoreCase {
+		result = "(?i)" + result
+	}
+	return result
+}
+
+// PatternFileError is returned by FromArgs when some patterns
+// are read from the `-f` files.
+type PatternFileError struct {
+	// Files are the `-f` arguments.
+	Files []string
+}
+
+func (e PatternFileError) Error() string {
+	return "patterns are read from " + strings.Join(e.Files, ", ")
+}
+
+// FromArgs parses the command line args of grep, egrep, fgrep or rg (ripgrep).
+// program is the command name, it can be a path.
+//
+// It returns the command mode and the patterns, either the `-e` arguments
+// or the first positional argument. The unrelated flags are skipped.
+// The later flags override the earlier ones, like `-E -F` selects SyntaxFixed.
+//
+// The `-f` pattern files are not read: if there are any, the mode and
+// the `-e` patterns are returned together with PatternFileError.
+func FromArgs(program string, args []string) (Mode, []string, error) {
+	var mode Mode
+	var valueFlags string
+	var valueOptions []string
+	switch path.Base(program) {
+	case "grep":
+		valueFlags, valueOptions = grepValueFlags, grepValueOptions
+	case "egrep":
+		mode.Syntax = SyntaxERE
+		valueFlags, valueOptions = grepValueFlags, grepValueOptions
+	case "fgrep":
+		mode.Syntax = SyntaxFixed
+		valueFlags, valueOptions = grepValueFlags, grepValueOptions
+	case "rg":
+		mode.Syntax = SyntaxRust
+		valueFlags, valueOptions = rgValueFlags, rgValueOptions
+	default:
+		return mode, nil, errors.New("unknown program: " + program)
+	}
+	ripgrep := mode.Syntax == SyntaxRust
+
+	var patterns []string
+	var files []string
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+
+		case strings.HasPrefix(arg, "--"):
+			name := arg[2:]
+			value := ""
+			if eq := strings.IndexByte(name, '='); eq != -1 {
+				name, value = name[:eq], name[eq+1:]
+			} else if hasOption(valueOptions, name) {
+				if i+1 == len(args) {
+					return mode, nil, errors.New("missing --" + name + " argument")
+				}
+				i++
+				value = args[i]
+			}
+			switch name {
+			case "regexp":
+				patterns = append(patterns, value)
+			case "file":
+				files = append(files, value)
+			case "basic-regexp":
+				mode.Syntax = SyntaxBRE
+			case "extended-regexp":
+				mode.Syntax = SyntaxERE
+			case "perl-regexp", "pcre2":
+				mode.Syntax = SyntaxPCRE
+			case "engine":
+				if value == "pcre2" {
+					mode.Syntax = SyntaxPCRE
+				}
+			case "fixed-strings":
+				mode.Syntax = SyntaxFixed
+			case "ignore-case":
+				mode.IgnoreCase = true
+			case "word-regexp":
+				mode.WordRegexp = true
+			case "line-regexp":
+				mode.LineRegexp = true
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for j := 1; j < len(arg); j++ {
+				ch := arg[j]
+				if strings.IndexByte(valueFlags, ch) != -1 {
+					value := arg[j+1:]
+					if value == "" {
+						if i+1 == len(args) {
+							return mode, nil, errors.New("missing -" + string(ch) + " argument")
+						}
+						i++
+						value = args[i]
+					}
+					switch ch {
+					case 'e':
+						patterns = append(patterns, value)
+					case 'f':
+						files = append(files, value)
+					}
+					break
+				}
+				switch ch {
+				case 'G':
+					mode.Syntax = SyntaxBRE
+				case 'E':
+					mode.Syntax = SyntaxERE
+				case 'P':
+					mode.Syntax = SyntaxPCRE
+				case 'F':
+					mode.Syntax = SyntaxFixed
+				case 'i':
+					mode.IgnoreCase = true
+				case 'w':
+					mode.WordRegexp = true
+				case 'x':
+					mode.LineRegexp = true
+				}
+			}
+
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if ripgrep && mode.Syntax == SyntaxBRE {
+		// ripgrep has no BRE syntax.
+		mode.Syntax = SyntaxRust
+	}
+	if len(patterns) == 0 && len(files) == 0 {
+		if len(positional) == 0 {
+			return mode, nil, errors.New("no pattern")
+		}
+		patterns = positional[:1]
+	}
+
+	// grep treats the newlines as pattern separators.
+	var result []string
+	for _, p := range patterns {
+		result = append(result, strings.Split(p, "\n")...)
+	}
+	if len(files) != 0 {
+		return mode, result, PatternFileError{Files: files}
+	}
+	return mode, result, nil
+}
+
+func hasOption(options []string, name string) bool {
+	for _, o := range options {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The short flags that take a value.
+const (
+	grepValueFlags = "ABCDdefm"
+	rgValueFlags   = "ABCEefgjMmrTt"
+)
+
+// The long options that take a value.
+// The options with an optional value, like grep `--color`,
+// can only have it after the `=`, so they're not listed.
+var (
+	grepValueOptions = []string{
+		"after-context",
+		"before-context",
+		"binary-files",
+		"context",
+		"devices",
+		"directories",
+		"exclude",
+		"exclude-dir",
+		"exclude-from",
+		"file",
+		"group-separator",
+		"include",
+		"label",
+		"max-count",
+		"regexp",
+	}
+
+	rgValueOptions = []string{
+		"after-context",
+		"before-context",
+		"color",
+		"colors",
+		"context",
+		"context-separator",
+		"dfa-size-limit",
+		"encoding",
+		"engine",
+		"field-context-separator",
+		"field-match-separator",
+		"file",
+		"glob",
+		"hostname-bin",
+		"hyperlink-format",
+		"iglob",
+		"ignore-file",
+		"max-columns",
+		"max-count",
+		"max-depth",
+		"max-filesize",
+		"path-separator",
+		"pre",
+		"pre-glob",
+		"regex-size-limit",
+		"regexp",
+		"replace",
+		"sort",
+		"sortr",
+		"threads",
+		"type",
+		"type-add",
+		"type-clear",
+		"type-not",
+	}
+)
@@ -0,0 +1,119 @@
+package grep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestFromArgs(t *testing.T) {
+	tests := []struct {
+		program  string
+		args     []string
+		mode     Mode
+		patterns []string
+	}{
+		{"grep", []string{"-n", "a\\+", "file.txt"}, Mode{}, []string{`a\+`}},
+		{"/bin/grep", []string{"-iEw", "-A", "3", "x|y"}, Mode{Syntax: SyntaxERE, IgnoreCase: true, WordRegexp: true}, []string{"x|y"}},
+		{"egrep", []string{"-x", "-e", "a", "-eb", "--regexp=c", "file"}, Mode{Syntax: SyntaxERE, LineRegexp: true}, []string{"a", "b", "c"}},
+		{"fgrep", []string{"--", "-v"}, Mode{Syntax: SyntaxFixed}, []string{"-v"}},
+		{"grep", []string{"-E", "-F", "--ignore-case", "a.b\nc"}, Mode{Syntax: SyntaxFixed, IgnoreCase: true}, []string{"a.b", "c"}},
+		{"grep", []string{"-Pm1", "\\d+"}, Mode{Syntax: SyntaxPCRE}, []string{`\d+`}},
+		{"rg", []string{"-E", "utf-8", "-G", "-w", "foo"}, Mode{Syntax: SyntaxRust, WordRegexp: true}, []string{"foo"}},
+		{"rg", []string{"--engine=pcre2", "-g", "*.go", "(?<=x)y"}, Mode{Syntax: SyntaxPCRE}, []string{"(?<=x)y"}},
+		{"grep", []string{"--max-count", "1", "foo", "file"}, Mode{}, []string{"foo"}},
+		{"grep", []string{"--context", "2", "--label=x", "--exclude", "*.o", "-E", "a+"}, Mode{Syntax: SyntaxERE}, []string{"a+"}},
+		{"rg", []string{"--engine", "pcre2", "--type", "go", "--max-depth", "3", "\\d"}, Mode{Syntax: SyntaxPCRE}, []string{`\d`}},
+		{"rg", []string{"--glob", "*.go", "--replace", "$1", "(x)"}, Mode{Syntax: SyntaxRust}, []string{"(x)"}},
+	}
+
+	for _, test := range tests {
+		mode, patterns, err := FromArgs(test.program, test.args)
+		if err != nil {
+			t.Errorf("FromArgs(%s, %q): %v", test.program, test.args, err)
+			continue
+		}
+		if mode != test.mode {
+			t.Errorf("FromArgs(%s, %q) mode:\nhave: %+v\nwant: %+v", test.program, test.args, mode, test.mode)
+		}
+		if !reflect.DeepEqual(patterns, test.patterns) {
+			t.Errorf("FromArgs(%s, %q) patterns:\nhave: %q\nwant: %q", test.program, test.args, patterns, test.patterns)
+		}
+	}
+}
+
+func TestFromArgsErrors(t *testing.T) {
+	tests := []struct {
+		program string
+		args    []string
+		want    string
+	}{
+		{"ag", []string{"x"}, "unknown program: ag"},
+		{"grep", []string{"-i"}, "no pattern"},
+		{"grep", []string{"-e"}, "missing -e argument"},
+		{"grep", []string{"--regexp"}, "missing --regexp argument"},
+		{"grep", []string{"-i", "--max-count"}, "missing --max-count argument"},
+	}
+
+	for _, test := range tests {
+		_, _, err := FromArgs(test.program, test.args)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("FromArgs(%s, %q):\nhave: %v\nwant: %s", test.program, test.args, err, test.want)
+		}
+	}
+}
+
+func TestFromArgsPatternFiles(t *testing.T) {
+	tests := []struct {
+		program  string
+		args     []string
+		patterns []string
+		files    []string
+	}{
+		{"grep", []string{"-f", "pats.txt", "file"}, nil, []string{"pats.txt"}},
+		{"grep", []string{"--file", "pats.txt", "file"}, nil, []string{"pats.txt"}},
+		{"grep", []string{"-e", "x", "--file=a.txt", "-fb.txt", "file"}, []string{"x"}, []string{"a.txt", "b.txt"}},
+		{"rg", []string{"--file", "pats.txt", "dir"}, nil, []string{"pats.txt"}},
+	}
+
+	for _, test := range tests {
+		_, patterns, err := FromArgs(test.program, test.args)
+		fileErr, ok := err.(PatternFileError)
+		if !ok {
+			t.Errorf("FromArgs(%s, %q): unexpected error: %v", test.program, test.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(patterns, test.patterns) {
+			t.Errorf("FromArgs(%s, %q) patterns:\nhave: %q\nwant: %q", test.program, test.args, patterns, test.patterns)
+		}
+		if !reflect.DeepEqual(fileErr.Files, test.files) {
+			t.Errorf("FromArgs(%s, %q) files:\nhave: %q\nwant: %q", test.program, test.args, fileErr.Files, test.files)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		patterns []string
+		want     string
+	}{
+		{Mode{}, []string{`a\(b\)\{2\}+`}, `a(b){2}\+`},
+		{Mode{Syntax: SyntaxERE}, []string{`a(b){2}+`}, `a(b){2}+`},
+		{Mode{Syntax: SyntaxFixed}, []string{`a.b`, `c`}, `(?:a\.b|c)`},
+		{Mode{Syntax: SyntaxPCRE, IgnoreCase: true, WordRegexp: true}, []string{`\w+`}, `(?i)\b(?:\w+)\b`},
+		{Mode{Syntax: SyntaxRust, LineRegexp: true}, []string{`x`}, `^(?:x)$`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		have := test.mode.Effective(test.patterns...)
+		if have != test.want {
+			t.Errorf("Effective(%q):\nhave: %s\nwant: %s", test.patterns, have, test.want)
+		}
+		if _, err := p.Parse(have); err != nil {
+			t.Errorf("parse(%q): %v", have, err)
+		}
+	}
+}
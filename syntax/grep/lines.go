@@ -0,0 +1,85 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// MaxLineSize is the max line length supported by ScanLines.
+const MaxLineSize = 16 << 20
+
+// Matcher finds the leftmost match inside a line.
+// *regexp.Regexp implements it.
+type Matcher interface {
+	// FindSubmatchIndex returns the match and submatch locations,
+	// or nil if there is no match.
+	FindSubmatchIndex(b []byte) []int
+}
+
+// LineMatch is a line that contains a match.
+type LineMatch struct {
+	// Line is the line number, starting from 1.
+	Line int
+
+	// Offset is the input offset of the line start.
+	Offset int
+
+	// Submatches are the locations inside the line,
+	// as returned by the Matcher.
+	Submatches []int
+}
+
+// MatchLines returns the data lines that contain a match.
+//
+// The lines are matched in place, without the line terminators
+// ("\n" or "\r\n"), so no per-line allocations are made.
+func MatchLines(data []byte, m Matcher) []LineMatch {
+	var matches []LineMatch
+	offset := 0
+	for number := 1; offset < len(data); number++ {
+		line := data[offset:]
+		size := len(line)
+		if i := bytes.IndexByte(line, '\n'); i != -1 {
+			line = bytes.TrimSuffix(line[:i], []byte{'\r'})
+			size = i + 1
+		}
+		if match, ok := matchLine(m, line, number, offset); ok {
+			matches = append(matches, match)
+		}
+		offset += size
+	}
+	return matches
+}
+
+// ScanLines is like MatchLines, but it reads the lines from r
+// and calls fn for every matched line until it returns false.
+//
+// The line slice is only valid until fn returns.
+// Lines longer than MaxLineSize result in bufio.ErrTooLong.
+func ScanLines(r io.Reader, m Matcher, fn func(line []byte, match LineMatch) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	offset, next := 0, 0
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		next += advance
+		return advance, token, err
+	})
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Bytes()
+		if match, ok := matchLine(m, line, number, offset); ok && !fn(line, match) {
+			return nil
+		}
+		offset = next
+	}
+	return scanner.Err()
+}
+
+func matchLine(m Matcher, line []byte, number, offset int) (LineMatch, bool) {
+	loc := m.FindSubmatchIndex(line)
+	if loc == nil {
+		return LineMatch{}, false
+	}
+	return LineMatch{Line: number, Offset: offset, Submatches: loc}, true
+}
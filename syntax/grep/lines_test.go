@@ -0,0 +1,60 @@
+package grep
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMatchLines(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    string
+	}{
+		{`error`, "ok\nerror 1\r\nok\nerror 2", `[{2 3 [0 5]} {4 15 [0 5]}]`},
+		{`^$`, "a\n\nb\n", `[{2 2 [0 0]}]`},
+		{`(\w+)=(\d+)`, "x=1 y=2\nz\n", `[{1 0 [0 3 0 1 2 3]}]`},
+		{`a$`, "a\r\nba\r\n", `[{1 0 [0 1]} {2 3 [1 2]}]`},
+		{`x`, "", `[]`},
+	}
+
+	for _, test := range tests {
+		re := regexp.MustCompile(test.pattern)
+		have := fmt.Sprint(MatchLines([]byte(test.input), re))
+		if have != test.want {
+			t.Errorf("MatchLines(%q, %q):\nhave: %s\nwant: %s", test.pattern, test.input, have, test.want)
+		}
+
+		var scanned []LineMatch
+		r := iotest.OneByteReader(strings.NewReader(test.input))
+		err := ScanLines(r, re, func(line []byte, match LineMatch) bool {
+			scanned = append(scanned, match)
+			return true
+		})
+		if err != nil {
+			t.Errorf("ScanLines(%q, %q): %v", test.pattern, test.input, err)
+			continue
+		}
+		if have := fmt.Sprint(scanned); have != test.want {
+			t.Errorf("ScanLines(%q, %q):\nhave: %s\nwant: %s", test.pattern, test.input, have, test.want)
+		}
+	}
+}
+
+func TestScanLinesStop(t *testing.T) {
+	re := regexp.MustCompile(`\d`)
+	var lines []string
+	err := ScanLines(strings.NewReader("1\na\n2\n3\n"), re, func(line []byte, match LineMatch) bool {
+		lines = append(lines, string(line))
+		return len(lines) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := fmt.Sprint(lines); have != "[1 2]" {
+		t.Errorf("unexpected lines: %s", have)
+	}
+}
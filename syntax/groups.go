@@ -0,0 +1,74 @@
+package syntax
+
+// DupNamesPolicy controls how the parser treats duplicated group names.
+type DupNamesPolicy byte
+
+const (
+	// DupNamesAccept accepts duplicated names silently.
+	// This is how Ruby and .NET handle them.
+	DupNamesAccept DupNamesPolicy = iota
+
+	// DupNamesReject reports duplicated names as a parse error,
+	// unless the PCRE `J` flag is active at the duplicate location.
+	// This is how Go regexp and PCRE handle them.
+	DupNamesReject
+
+	// DupNamesWarn accepts duplicated names, but adds a Regexp warning.
+	DupNamesWarn
+)
+
+// GroupIndexes returns a mapping from the capture group names
+// to their indexes. Groups are numbered from 1 in the order
+// of their opening parentheses, unnamed groups are counted too.
+//
+// A name is mapped to several indexes if it's duplicated.
+func (re *Regexp) GroupIndexes() map[string][]int {
+	indexes := make(map[string][]int)
+	index := 0
+	walkCaptures(re.Expr, func(e Expr) {
+		index++
+		if e.Op == OpNamedCapture {
+			name := e.Args[1].Value
+			indexes[name] = append(indexes[name], index)
+		}
+	})
+	return indexes
+}
+
+// checkDupNames applies the duplicated group names policy.
+func (p *Parser) checkDupNames(e Expr) {
+	if p.opts.DupNames == DupNamesAccept {
+		return
+	}
+	seen := make(map[string]bool)
+	WalkFlags(e, DialectPCRE, 0, func(e Expr, flags Flags) {
+		if e.Op != OpNamedCapture {
+			return
+		}
+		name := e.Args[1].Value
+		if !seen[name] || flags&FlagDupNames != 0 {
+			seen[name] = true
+			return
+		}
+		message := "duplicated group name: " + name
+		if p.opts.DupNames == DupNamesReject {
+			throw(CodeDuplicateGroupName, e.Args[1].Pos, message)
+		}
+		p.out.Warnings = append(p.out.Warnings, Warning{
+			Pos:        e.Args[1].Pos,
+			Message:    message,
+			Code:       CodeDuplicateGroupName,
+			Severity:   SeverityWarning,
+			Confidence: ConfidenceHigh,
+		})
+	})
+}
+
+func walkCaptures(e Expr, visit func(Expr)) {
+	if e.Op == OpCapture || e.Op == OpNamedCapture {
+		visit(e)
+	}
+	for _, a := range e.Args {
+		walkCaptures(a, visit)
+	}
+}
@@ -0,0 +1,66 @@
+package syntax
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupIndexes(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    map[string][]int
+	}{
+		{`abc`, map[string][]int{}},
+		{`(a)(?P<x>b)`, map[string][]int{"x": {2}}},
+		{`(?<x>a)|(?'x'b)((?P<y>c))`, map[string][]int{"x": {1, 2}, "y": {4}}},
+		{`(?:a)(?=(?P<z>b))`, map[string][]int{"z": {1}}},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := re.GroupIndexes()
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("GroupIndexes(%q):\nhave: %v\nwant: %v", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestParserDupNames(t *testing.T) {
+	tests := []struct {
+		pattern string
+		policy  DupNamesPolicy
+		want    string
+	}{
+		{`(?P<x>a)(?P<x>b)`, DupNamesAccept, ``},
+		{`(?P<x>a)(?P<x>b)`, DupNamesReject, `error: duplicated group name: x`},
+		{`(?P<x>a)(?P<x>b)`, DupNamesWarn, `warning 12-13: duplicated group name: x`},
+		{`(?J)(?P<x>a)(?P<x>b)`, DupNamesReject, ``},
+		{`(?J:(?P<x>a))(?P<x>b)`, DupNamesReject, `error: duplicated group name: x`},
+		{`(?P<x>a)(?P<y>b)`, DupNamesReject, ``},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&ParserOptions{DupNames: test.policy})
+		re, err := p.Parse(test.pattern)
+		var have string
+		switch {
+		case err != nil:
+			have = "error: " + err.Error()
+		case len(re.Warnings) != 0:
+			var parts []string
+			for _, w := range re.Warnings {
+				parts = append(parts, fmt.Sprintf("warning %d-%d: %s", w.Pos.Begin, w.Pos.End, w.Message))
+			}
+			have = strings.Join(parts, "; ")
+		}
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
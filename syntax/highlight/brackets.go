@@ -0,0 +1,45 @@
+package highlight
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// MatchingBrackets returns a map of the paired delimiter positions.
+//
+// For every group, char class and closed `\Q...\E` quote it maps
+// the opening delimiter offset to the closing delimiter offset and vice versa.
+// A group opening delimiter can be longer than one byte, like `(?P<`,
+// its first byte offset is used. The same is true for `[^` and `\E`.
+func MatchingBrackets(re *syntax.Regexp) map[int]int {
+	pairs := make(map[int]int)
+	collectBrackets(pairs, re.Expr)
+	return pairs
+}
+
+func collectBrackets(pairs map[int]int, e syntax.Expr) {
+	open := int(e.Begin())
+	switch e.Op {
+	case syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup,
+		syntax.OpGroupWithFlags, syntax.OpFlagOnlyGroup, syntax.OpAtomicGroup, syntax.OpScriptRun,
+		syntax.OpPositiveLookahead, syntax.OpNegativeLookahead,
+		syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind,
+		syntax.OpComment, syntax.OpCharClass, syntax.OpNegCharClass:
+		closing := int(e.End()) - len(")")
+		pairs[open] = closing
+		pairs[closing] = open
+	case syntax.OpQuote:
+		if e.Form != syntax.FormQuoteUnclosed {
+			closing := int(e.End()) - len(`\E`)
+			pairs[open] = closing
+			pairs[closing] = open
+		}
+		return
+	case syntax.OpEscapeChar, syntax.OpEscapeMeta, syntax.OpEscapeOctal,
+		syntax.OpEscapeHex, syntax.OpEscapeUni, syntax.OpPosixClass:
+		return
+	}
+
+	for _, a := range e.Args {
+		collectBrackets(pairs, a)
+	}
+}
@@ -0,0 +1,36 @@
+package highlight
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestMatchingBrackets(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    map[int]int
+	}{
+		{`abc`, map[int]int{}},
+		{`(a)`, map[int]int{0: 2, 2: 0}},
+		{`((?:a)[^b])`, map[int]int{0: 10, 10: 0, 1: 5, 5: 1, 6: 9, 9: 6}},
+		{`(?P<x>[)])`, map[int]int{0: 9, 9: 0, 6: 8, 8: 6}},
+		{`[[:alpha:]]`, map[int]int{0: 10, 10: 0}},
+		{`\Q(a\E(?i)`, map[int]int{0: 4, 4: 0, 6: 9, 9: 6}},
+		{`x\Q(a`, map[int]int{}},
+		{`\((?#c)\)`, map[int]int{2: 6, 6: 2}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := MatchingBrackets(re)
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("brackets(%q):\nhave: %v\nwant: %v", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package highlight classifies pattern bytes for the syntax highlighting.
+package highlight
+
+import (
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Class is a highlighting class of a pattern part.
+type Class byte
+
+const (
+	// ClassLiteral is a char that matches itself.
+	ClassLiteral Class = iota
+
+	// ClassMeta is a metachar, like `.`, `|`, `^` or a char range `-`.
+	ClassMeta
+
+	// ClassEscape is an escape sequence, like `\d`, `\x41` or `\Q`.
+	ClassEscape
+
+	// ClassGroup is a group delimiter, like `(`, `(?:`, `(?=` or `)`.
+	ClassGroup
+
+	// ClassGroupName is a named capture group name.
+	ClassGroupName
+
+	// ClassFlags is a group flags string, like `i-s`.
+	ClassFlags
+
+	// ClassQuantifier is a repetition operator, like `*`, `+?` or `{2,3}`.
+	ClassQuantifier
+
+	// ClassCharClass is a char class bracket or a POSIX class, like `[^` or `[:alpha:]`.
+	ClassCharClass
+
+	// ClassComment is a `(?#...)` comment.
+	ClassComment
+
+	// ClassPlaceholder is a template placeholder, like `%s` or `${name}`.
+	ClassPlaceholder
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassLiteral:
+		return "literal"
+	case ClassMeta:
+		return "meta"
+	case ClassEscape:
+		return "escape"
+	case ClassGroup:
+		return "group"
+	case ClassGroupName:
+		return "group-name"
+	case ClassFlags:
+		return "flags"
+	case ClassQuantifier:
+		return "quantifier"
+	case ClassCharClass:
+		return "char-class"
+	case ClassComment:
+		return "comment"
+	case ClassPlaceholder:
+		return "placeholder"
+	default:
+		return "?"
+	}
+}
+
+// Span is a pattern bytes range of the same class.
+type Span struct {
+	Begin int
+	End   int
+	Class Class
+}
+
+// Spans classifies every re.Pattern byte.
+//
+// The classification follows the re AST, so it matches the
+// parser interpretation of the pattern: for example, `-` is
+// only a meta char when it forms a char range.
+// The spans are sorted, they don't overlap and they cover
+// the whole pattern. Adjacent spans have different classes.
+func Spans(re *syntax.Regexp) []Span {
+	classes := make([]Class, len(re.Pattern))
+	markExpr(classes, re.Expr)
+
+	var spans []Span
+	for i, c := range classes {
+		if len(spans) != 0 && spans[len(spans)-1].Class == c {
+			spans[len(spans)-1].End = i + 1
+			continue
+		}
+		spans = append(spans, Span{Begin: i, End: i + 1, Class: c})
+	}
+	return spans
+}
+
+func mark(classes []Class, e syntax.Expr, c Class) {
+	for i := e.Begin(); i < e.End(); i++ {
+		classes[i] = c
+	}
+}
+
+// markExpr marks e bytes, nested expressions override the parent classes.
+func markExpr(classes []Class, e syntax.Expr) {
+	switch e.Op {
+	case syntax.OpConcat:
+		// Only children are marked.
+	case syntax.OpChar, syntax.OpLiteral, syntax.OpString:
+		mark(classes, e, ClassLiteral)
+	case syntax.OpDot, syntax.OpCaret, syntax.OpDollar, syntax.OpAlt, syntax.OpCharRange:
+		mark(classes, e, ClassMeta)
+	case syntax.OpEscapeChar, syntax.OpEscapeMeta, syntax.OpEscapeOctal,
+		syntax.OpEscapeHex, syntax.OpEscapeUni:
+		mark(classes, e, ClassEscape)
+		return
+	case syntax.OpQuote:
+		mark(classes, e, ClassEscape)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion,
+		syntax.OpNonGreedy, syntax.OpPossessive:
+		mark(classes, e, ClassQuantifier)
+	case syntax.OpRepeat:
+		mark(classes, e, ClassQuantifier)
+		markExpr(classes, e.Args[0])
+		return
+	case syntax.OpCharClass, syntax.OpNegCharClass, syntax.OpPosixClass:
+		mark(classes, e, ClassCharClass)
+	case syntax.OpComment:
+		mark(classes, e, ClassComment)
+		return
+	case syntax.OpPlaceholder:
+		mark(classes, e, ClassPlaceholder)
+		return
+	case syntax.OpNamedCapture:
+		mark(classes, e, ClassGroup)
+		mark(classes, e.Args[1], ClassGroupName)
+		markExpr(classes, e.Args[0])
+		return
+	case syntax.OpGroupWithFlags:
+		mark(classes, e, ClassGroup)
+		mark(classes, e.Args[1], ClassFlags)
+		markExpr(classes, e.Args[0])
+		return
+	case syntax.OpFlagOnlyGroup:
+		mark(classes, e, ClassGroup)
+		mark(classes, e.Args[0], ClassFlags)
+		return
+	default:
+		// Capture, non-capturing groups and lookarounds.
+		mark(classes, e, ClassGroup)
+	}
+
+	for _, a := range e.Args {
+		markExpr(classes, a)
+	}
+}
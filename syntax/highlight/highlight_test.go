@@ -0,0 +1,48 @@
+package highlight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{``, ``},
+		{`abc`, `literal(abc)`},
+		{`^a.b$`, `meta(^) literal(a) meta(.) literal(b) meta($)`},
+		{`a|bc`, `literal(a) meta(|) literal(bc)`},
+		{`a+b*?c{2,3}`, `literal(a) quantifier(+) literal(b) quantifier(*?) literal(c) quantifier({2,3})`},
+		{`\d\.\x41x`, `escape(\d\.\x41) literal(x)`},
+		{`\Qa.b\E`, `escape(\Q) literal(a.b) escape(\E)`},
+		{`[^a-z_]`, `char-class([^) literal(a) meta(-) literal(z_) char-class(])`},
+		{`[-a[:digit:]]`, `char-class([) literal(-a) char-class([:digit:]])`},
+		{`(a)(?:b)`, `group(() literal(a) group()(?:) literal(b) group())`},
+		{`(?P<name>x)`, `group((?P<) group-name(name) group(>) literal(x) group())`},
+		{`(?i:x)(?-s)`, `group((?) flags(i) group(:) literal(x) group()(?) flags(-s) group())`},
+		{`a(?#note)`, `literal(a) comment((?#note))`},
+		{`(?=a+)`, `group((?=) literal(a) quantifier(+) group())`},
+		{`[%s]+{{.X}}`, `char-class([) placeholder(%s) char-class(]) quantifier(+) placeholder({{.X}})`},
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Placeholders: syntax.PlaceholdersAll})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		var parts []string
+		for _, span := range Spans(re) {
+			parts = append(parts, fmt.Sprintf("%s(%s)", span.Class, test.pattern[span.Begin:span.End]))
+		}
+		have := strings.Join(parts, " ")
+		if have != test.want {
+			t.Errorf("spans(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package highlight
+
+import (
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// TokenTypes is a semantic token types legend for SemanticTokens.
+//
+// The token type is an index inside this slice, it's
+// identical to the corresponding Class value.
+var TokenTypes = []string{
+	ClassLiteral:     ClassLiteral.String(),
+	ClassMeta:        ClassMeta.String(),
+	ClassEscape:      ClassEscape.String(),
+	ClassGroup:       ClassGroup.String(),
+	ClassGroupName:   ClassGroupName.String(),
+	ClassFlags:       ClassFlags.String(),
+	ClassQuantifier:  ClassQuantifier.String(),
+	ClassCharClass:   ClassCharClass.String(),
+	ClassComment:     ClassComment.String(),
+	ClassPlaceholder: ClassPlaceholder.String(),
+}
+
+// SemanticTokens returns re spans encoded as LSP semantic tokens.
+//
+// Every token is described by 5 integers: deltaLine, deltaStart,
+// length, tokenType and tokenModifiers. See TokenTypes for the legend;
+// modifiers are always 0. Columns and lengths are measured in UTF-16
+// code units, a span that contains newlines is split into several tokens.
+//
+// The positions are relative to the pattern start: a language server
+// that embeds the result into the document tokens only needs
+// to shift the first token position.
+func SemanticTokens(re *syntax.Regexp) []uint32 {
+	var enc semanticEncoder
+	for _, span := range Spans(re) {
+		s := re.Pattern[span.Begin:span.End]
+		for s != "" {
+			size := 0
+			length := 0
+			for size < len(s) && s[size] != '\n' {
+				ch, n := utf8.DecodeRuneInString(s[size:])
+				size += n
+				length += utf16Len(ch)
+			}
+			enc.push(length, span.Class)
+			enc.col += length
+			if size < len(s) {
+				// Skip the '\n'.
+				size++
+				enc.line++
+				enc.col = 0
+			}
+			s = s[size:]
+		}
+	}
+	return enc.data
+}
+
+type semanticEncoder struct {
+	data []uint32
+
+	line     int
+	col      int
+	prevLine int
+	prevCol  int
+}
+
+func (enc *semanticEncoder) push(length int, c Class) {
+	if length == 0 {
+		return
+	}
+	deltaStart := enc.col
+	if enc.line == enc.prevLine {
+		deltaStart -= enc.prevCol
+	}
+	enc.data = append(enc.data,
+		uint32(enc.line-enc.prevLine), uint32(deltaStart), uint32(length), uint32(c), 0)
+	enc.prevLine = enc.line
+	enc.prevCol = enc.col
+}
+
+func utf16Len(ch rune) int {
+	if ch >= 0x10000 && ch <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,46 @@
+package highlight
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []uint32
+	}{
+		{``, nil},
+		{`a+`, []uint32{
+			0, 0, 1, uint32(ClassLiteral), 0,
+			0, 1, 1, uint32(ClassQuantifier), 0,
+		}},
+		{"é\U0001F600.", []uint32{
+			0, 0, 3, uint32(ClassLiteral), 0,
+			0, 3, 1, uint32(ClassMeta), 0,
+		}},
+		{"(?x)a\nb\n\n(?#c\nd)", []uint32{
+			0, 0, 2, uint32(ClassGroup), 0,
+			0, 2, 1, uint32(ClassFlags), 0,
+			0, 1, 1, uint32(ClassGroup), 0,
+			0, 1, 1, uint32(ClassLiteral), 0,
+			1, 0, 1, uint32(ClassLiteral), 0,
+			2, 0, 4, uint32(ClassComment), 0,
+			1, 0, 2, uint32(ClassComment), 0,
+		}},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := SemanticTokens(re)
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("tokens(%q):\nhave: %v\nwant: %v", test.pattern, have, test.want)
+		}
+	}
+}
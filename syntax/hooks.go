@@ -0,0 +1,55 @@
+package syntax
+
+import (
+	"time"
+)
+
+// ParseStats describes a finished Parse call.
+type ParseStats struct {
+	// PatternLen is the pattern length in bytes.
+	PatternLen int
+
+	// Nodes is the number of the result AST nodes.
+	// It's 0 if the pattern can't be parsed.
+	Nodes int
+
+	Duration time.Duration
+
+	// Err is the Parse call error, if any.
+	Err error
+}
+
+// SetParseHook makes p call hook after every Parse call.
+// A nil hook disables the instrumentation.
+//
+// The hook is called synchronously, so it should be cheap,
+// like a metrics counter update.
+func (p *Parser) SetParseHook(hook func(ParseStats)) {
+	p.parseHook = hook
+}
+
+// SetParseHook sets the hook for all pooled parsers, see Parser.SetParseHook.
+// It must be called before the pool is used.
+func (pp *ParserPool) SetParseHook(hook func(ParseStats)) {
+	pp.parseHook = hook
+}
+
+func (p *Parser) runParseHook(pattern string, start time.Time, result **Regexp, err *error) {
+	stats := ParseStats{
+		PatternLen: len(pattern),
+		Duration:   time.Since(start),
+		Err:        *err,
+	}
+	if *err == nil {
+		stats.Nodes = countNodes((*result).Expr)
+	}
+	p.parseHook(stats)
+}
+
+func countNodes(e Expr) int {
+	n := 1
+	for _, a := range e.Args {
+		n += countNodes(a)
+	}
+	return n
+}
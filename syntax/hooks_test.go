@@ -0,0 +1,47 @@
+package syntax
+
+import (
+	"testing"
+)
+
+func TestParseHook(t *testing.T) {
+	var stats []ParseStats
+	p := NewParser(nil)
+	p.SetParseHook(func(s ParseStats) {
+		stats = append(stats, s)
+	})
+
+	if _, err := p.Parse(`a(b)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Parse(`a(`); err == nil {
+		t.Fatal("expected a parse error")
+	}
+	p.SetParseHook(nil)
+	_, _ = p.Parse(`x`)
+
+	if len(stats) != 2 {
+		t.Fatalf("hook calls:\nhave: %d\nwant: 2", len(stats))
+	}
+	// Concat(Char, Capture(Char)).
+	if s := stats[0]; s.PatternLen != 4 || s.Nodes != 4 || s.Err != nil || s.Duration < 0 {
+		t.Errorf("stats for a valid pattern: %+v", s)
+	}
+	if s := stats[1]; s.PatternLen != 2 || s.Nodes != 0 || s.Err == nil {
+		t.Errorf("stats for an invalid pattern: %+v", s)
+	}
+}
+
+func TestParserPoolHook(t *testing.T) {
+	calls := 0
+	pp := NewParserPool(nil)
+	pp.SetParseHook(func(ParseStats) { calls++ })
+	for i := 0; i < 3; i++ {
+		if _, err := pp.Parse(`x+`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("hook calls:\nhave: %d\nwant: 3", calls)
+	}
+}
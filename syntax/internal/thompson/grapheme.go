@@ -0,0 +1,152 @@
+package thompson
+
+import (
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// The `\X` grapheme cluster char categories.
+//
+// They're a regular approximation of the Unicode text segmentation
+// (UAX #29) Grapheme_Cluster_Break property: the unicode package has
+// no such tables, so the general categories are used instead.
+// Extended_Pictographic is approximated by the `\p{So}` symbols.
+var (
+	graphemeControl = charset.FromTable(unicode.Cc).
+			Union(charset.FromTable(unicode.Zl)).
+			Union(charset.FromTable(unicode.Zp))
+
+	graphemeExtend = charset.FromTable(unicode.M).
+			Union(charset.Of('\u200C', '\u200D')).
+			Union(charset.New(charset.Range{Lo: 0x1F3FB, Hi: 0x1F3FF})).
+			Union(charset.New(charset.Range{Lo: 0xE0020, Hi: 0xE007F}))
+
+	graphemeRegional = charset.New(charset.Range{Lo: 0x1F1E6, Hi: 0x1F1FF})
+
+	graphemePictographic = charset.FromTable(unicode.So).Subtract(graphemeRegional)
+
+	graphemeZWJ = charset.Of('\u200D')
+
+	graphemeExtendNoZWJ = graphemeExtend.Subtract(graphemeZWJ)
+
+	graphemeHangulL = charset.New(
+		charset.Range{Lo: 0x1100, Hi: 0x115F},
+		charset.Range{Lo: 0xA960, Hi: 0xA97C})
+	graphemeHangulV = charset.New(
+		charset.Range{Lo: 0x1160, Hi: 0x11A7},
+		charset.Range{Lo: 0xD7B0, Hi: 0xD7C6})
+	graphemeHangulT = charset.New(
+		charset.Range{Lo: 0x11A8, Hi: 0x11FF},
+		charset.Range{Lo: 0xD7CB, Hi: 0xD7FB})
+	graphemeHangulLV  = hangulLV()
+	graphemeHangulLVT = charset.New(charset.Range{Lo: 0xAC00, Hi: 0xD7A3}).Subtract(graphemeHangulLV)
+)
+
+// hangulLV returns the precomposed Hangul syllables without a trailing consonant,
+// every 28th syllable starting from U+AC00.
+func hangulLV() charset.RuneSet {
+	var ranges []charset.Range
+	for ch := rune(0xAC00); ch <= 0xD7A3; ch += 28 {
+		ranges = append(ranges, charset.Range{Lo: ch, Hi: ch})
+	}
+	return charset.New(ranges...)
+}
+
+// fragment builds a part of the automaton and returns its entry and exit states.
+type fragment func() (in, out int)
+
+// buildGrapheme builds an extended grapheme cluster:
+//
+//	\r\n | control | (RI RI | hangul | emoji | other) extend*
+//
+// where hangul is a Hangul syllable sequence and emoji is a ZWJ sequence:
+//
+//	L* (V+ | LV V* | LVT) T* | L+ | T+
+//	pictographic ((extend - ZWJ)* ZWJ pictographic)*
+//
+// Unlike PCRE, the cluster is not atomic: a shorter cluster can
+// be matched if the rest of the pattern requires it.
+func (b *Builder) buildGrapheme() (in, out int) {
+	set := func(runes charset.RuneSet) fragment {
+		return func() (int, int) { return b.buildSet(runes) }
+	}
+	vowels := b.alt(
+		b.plus(set(graphemeHangulV)),
+		b.seq(set(graphemeHangulLV), b.star(set(graphemeHangulV))),
+		set(graphemeHangulLVT))
+	hangul := b.alt(
+		b.seq(b.star(set(graphemeHangulL)), vowels, b.star(set(graphemeHangulT))),
+		b.plus(set(graphemeHangulL)),
+		b.plus(set(graphemeHangulT)))
+	emoji := b.seq(
+		set(graphemePictographic),
+		b.star(b.seq(b.star(set(graphemeExtendNoZWJ)), set(graphemeZWJ), set(graphemePictographic))))
+	core := b.alt(
+		b.seq(set(graphemeRegional), set(graphemeRegional)),
+		hangul,
+		emoji,
+		set(graphemeControl.Negate()))
+	cluster := b.alt(
+		func() (int, int) { return b.buildString("\r\n") },
+		set(graphemeControl),
+		b.seq(core, b.star(set(graphemeExtend))))
+	return cluster()
+}
+
+func (b *Builder) buildSet(runes charset.RuneSet) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	b.AddEdge(in, Edge{Kind: EdgeRunes, Runes: runes, To: out})
+	return in, out
+}
+
+// alt returns an alternation fragment, the first parts are preferred.
+func (b *Builder) alt(parts ...fragment) fragment {
+	return func() (in, out int) {
+		in = b.newState()
+		out = b.newState()
+		for _, part := range parts {
+			partIn, partOut := part()
+			b.AddEdge(in, Edge{To: partIn})
+			b.AddEdge(partOut, Edge{To: out})
+		}
+		return in, out
+	}
+}
+
+// seq returns a concatenation fragment.
+func (b *Builder) seq(parts ...fragment) fragment {
+	return func() (in, out int) {
+		in = b.newState()
+		out = in
+		for _, part := range parts {
+			partIn, partOut := part()
+			b.AddEdge(out, Edge{To: partIn})
+			out = partOut
+		}
+		return in, out
+	}
+}
+
+// star returns a greedy `x*` fragment.
+func (b *Builder) star(part fragment) fragment {
+	return func() (in, out int) {
+		in = b.newState()
+		out = b.newState()
+		bodyIn, bodyOut := part()
+		b.addChoice(in, bodyIn, out, true)
+		b.AddEdge(bodyOut, Edge{To: in})
+		return in, out
+	}
+}
+
+// plus returns a greedy `x+` fragment.
+func (b *Builder) plus(part fragment) fragment {
+	return func() (in, out int) {
+		in, bodyOut := part()
+		out = b.newState()
+		b.addChoice(bodyOut, in, out, true)
+		return in, out
+	}
+}
@@ -0,0 +1,295 @@
+// Package thompson builds the Thompson automata for the regular
+// subset of the syntax. It's shared by the dfa matcher and the
+// automaton-based analysis.
+package thompson
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/charset"
+)
+
+// MaxStates limits the automaton size.
+const MaxStates = 10000
+
+// ErrTooBig is returned when the automaton exceeds MaxStates.
+var ErrTooBig = errors.New("automaton is too big")
+
+// UnsupportedError is returned for the expressions that can't be
+// expressed by the automaton.
+type UnsupportedError struct {
+	// Expr is the unsupported expression source text.
+	Expr string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Expr + ": unsupported by the automaton"
+}
+
+// EdgeKind is an automaton edge kind.
+type EdgeKind byte
+
+const (
+	EdgeEpsilon EdgeKind = iota
+	EdgeRunes
+	EdgeBegin // Taken only at the beginning of the input
+	EdgeEnd   // Taken only at the end of the input
+)
+
+// Edge is an automaton transition.
+// Runes are only set for the EdgeRunes edges.
+type Edge struct {
+	Kind  EdgeKind
+	Runes charset.RuneSet
+	To    int
+}
+
+// Builder adds the pattern states to an automaton.
+type Builder struct {
+	// States are the automaton states. Every state is a list
+	// of its outgoing edges, ordered by priority.
+	States [][]Edge
+
+	// Newline is a line terminator convention for `.` and `\R`.
+	Newline syntax.Newline
+}
+
+type bailout struct {
+	err error
+}
+
+// NewState adds a state without edges and returns its index.
+func (b *Builder) NewState() int {
+	b.States = append(b.States, nil)
+	return len(b.States) - 1
+}
+
+// AddEdge appends e to the from state edges.
+func (b *Builder) AddEdge(from int, e Edge) {
+	b.States[from] = append(b.States[from], e)
+}
+
+// Build adds the e states and returns its entry and exit states.
+//
+// Backreferences, lookarounds, atomic groups, possessive quantifiers,
+// flags and word boundaries result in an UnsupportedError.
+// `$` matches only at the end of the input.
+func (b *Builder) Build(e syntax.Expr) (in, out int, err error) {
+	defer func() {
+		r := recover()
+		if r, ok := r.(bailout); ok {
+			in, out = -1, -1
+			err = r.err
+			return
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+	in, out = b.build(e)
+	return in, out, nil
+}
+
+func (b *Builder) newState() int {
+	if len(b.States) >= MaxStates {
+		panic(bailout{err: ErrTooBig})
+	}
+	return b.NewState()
+}
+
+func (b *Builder) build(e syntax.Expr) (in, out int) {
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral, syntax.OpEmptyMatch:
+		in = b.newState()
+		out = in
+		for _, a := range e.Args {
+			argIn, argOut := b.build(a)
+			b.AddEdge(out, Edge{To: argIn})
+			out = argOut
+		}
+		return in, out
+
+	case syntax.OpAlt:
+		in = b.newState()
+		out = b.newState()
+		for _, a := range e.Args {
+			branchIn, branchOut := b.build(a)
+			b.AddEdge(in, Edge{To: branchIn})
+			b.AddEdge(branchOut, Edge{To: out})
+		}
+		return in, out
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat:
+		return b.buildQuantifier(e, true)
+	case syntax.OpNonGreedy:
+		return b.buildQuantifier(e.Args[0], false)
+
+	case syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup:
+		// Captures don't affect the match bounds.
+		return b.build(e.Args[0])
+
+	case syntax.OpComment:
+		in = b.newState()
+		return in, in
+
+	case syntax.OpDot:
+		return b.buildSet(charset.Dot(b.Newline))
+
+	case syntax.OpCaret:
+		return b.buildAssert(EdgeBegin)
+	case syntax.OpDollar:
+		return b.buildAssert(EdgeEnd)
+
+	case syntax.OpQuote:
+		return b.buildString(e.QuotedLiteral())
+	}
+
+	switch e.Value {
+	case `\A`:
+		return b.buildAssert(EdgeBegin)
+	case `\z`:
+		return b.buildAssert(EdgeEnd)
+	case `\X`:
+		return b.buildGrapheme()
+	case `\R`:
+		// The longer line breaks go first, so `\r\n` is preferred over `\r`.
+		in = b.newState()
+		out = b.newState()
+		for _, s := range b.Newline.LineBreaks() {
+			breakIn, breakOut := b.buildString(s)
+			b.AddEdge(in, Edge{To: breakIn})
+			b.AddEdge(breakOut, Edge{To: out})
+		}
+		return in, out
+	}
+	runes, ok := charset.FromExpr(e)
+	if !ok {
+		panic(bailout{err: &UnsupportedError{Expr: e.Value}})
+	}
+	return b.buildSet(runes)
+}
+
+// buildQuantifier builds a star, plus, question or repeat expression.
+//
+// The edges of every state are ordered by priority: the greedy
+// quantifiers prefer to repeat their body, the non-greedy ones
+// prefer to leave it.
+func (b *Builder) buildQuantifier(e syntax.Expr, greedy bool) (in, out int) {
+	switch e.Op {
+	case syntax.OpStar:
+		return b.buildStar(e.Args[0], greedy)
+	case syntax.OpPlus:
+		return b.buildPlus(e.Args[0], greedy)
+	case syntax.OpQuestion:
+		return b.buildOptional(e.Args[0], greedy)
+	default:
+		return b.buildRepeat(e, greedy)
+	}
+}
+
+// addChoice adds the from->preferred and from->other edges,
+// greedy selects which one is tried first.
+func (b *Builder) addChoice(from, body, exit int, greedy bool) {
+	if greedy {
+		b.AddEdge(from, Edge{To: body})
+		b.AddEdge(from, Edge{To: exit})
+	} else {
+		b.AddEdge(from, Edge{To: exit})
+		b.AddEdge(from, Edge{To: body})
+	}
+}
+
+func (b *Builder) buildStar(e syntax.Expr, greedy bool) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	bodyIn, bodyOut := b.build(e)
+	b.addChoice(in, bodyIn, out, greedy)
+	b.AddEdge(bodyOut, Edge{To: in})
+	return in, out
+}
+
+func (b *Builder) buildPlus(e syntax.Expr, greedy bool) (in, out int) {
+	in, bodyOut := b.build(e)
+	out = b.newState()
+	b.addChoice(bodyOut, in, out, greedy)
+	return in, out
+}
+
+func (b *Builder) buildOptional(e syntax.Expr, greedy bool) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	bodyIn, bodyOut := b.build(e)
+	b.addChoice(in, bodyIn, out, greedy)
+	b.AddEdge(bodyOut, Edge{To: out})
+	return in, out
+}
+
+func (b *Builder) buildRepeat(e syntax.Expr, greedy bool) (in, out int) {
+	min, max := RepeatBounds(e.Args[1].Value, MaxStates)
+	in = b.newState()
+	out = in
+	appendPart := func(partIn, partOut int) {
+		b.AddEdge(out, Edge{To: partIn})
+		out = partOut
+	}
+	for i := 0; i < min; i++ {
+		appendPart(b.build(e.Args[0]))
+	}
+	if max == -1 {
+		appendPart(b.buildStar(e.Args[0], greedy))
+		return in, out
+	}
+	for i := min; i < max; i++ {
+		appendPart(b.buildOptional(e.Args[0], greedy))
+	}
+	return in, out
+}
+
+// buildString builds a sequence of the s chars.
+func (b *Builder) buildString(s string) (in, out int) {
+	in = b.newState()
+	out = in
+	for _, ch := range s {
+		next := b.newState()
+		b.AddEdge(out, Edge{Kind: EdgeRunes, Runes: charset.Of(ch), To: next})
+		out = next
+	}
+	return in, out
+}
+
+func (b *Builder) buildAssert(kind EdgeKind) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	b.AddEdge(in, Edge{Kind: kind, To: out})
+	return in, out
+}
+
+// RepeatBounds parses {min,max} repeat count string.
+// For {min,} form max is -1. The counts above limit
+// are saturated to limit.
+func RepeatBounds(s string, limit int) (min, max int) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	comma := strings.IndexByte(s, ',')
+	if comma == -1 {
+		n := atoi(s, limit)
+		return n, n
+	}
+	min = atoi(s[:comma], limit)
+	if comma == len(s)-1 {
+		return min, -1
+	}
+	return min, atoi(s[comma+1:], limit)
+}
+
+func atoi(s string, limit int) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		n = n*10 + int(s[i]-'0')
+		if n > limit {
+			return limit
+		}
+	}
+	return n
+}
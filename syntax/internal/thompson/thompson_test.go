@@ -0,0 +1,54 @@
+package thompson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestRepeatBounds(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{`{3}`, `3 3`},
+		{`{2,}`, `2 -1`},
+		{`{0,5}`, `0 5`},
+		{`{,5}`, `0 5`},
+		{`{100}`, `10 10`},
+		{`{1,99999999999999999999}`, `1 10`},
+	}
+	for _, test := range tests {
+		min, max := RepeatBounds(test.s, 10)
+		if have := fmt.Sprint(min, max); have != test.want {
+			t.Errorf("RepeatBounds(%q):\nhave: %s\nwant: %s", test.s, have, test.want)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a(?=b)`, `(?=b): unsupported by the automaton`},
+		{`a\b`, `\b: unsupported by the automaton`},
+		{`(?:a{1000}){1000}`, ErrTooBig.Error()},
+	}
+	for _, test := range tests {
+		re, err := syntax.NewParser(nil).Parse(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b Builder
+		_, _, err = b.Build(re.Expr)
+		have := "<nil>"
+		if err != nil {
+			have = err.Error()
+		}
+		if have != test.want {
+			t.Errorf("Build(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
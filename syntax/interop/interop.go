@@ -0,0 +1,25 @@
+// Package interop converts patterns between this package
+// and the Go standard library regexp/syntax package.
+package interop
+
+import (
+	stdsyntax "regexp/syntax"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// FromStd converts a regexp/syntax tree into this package AST.
+//
+// The source text is synthesized from the std tree with its String
+// method, so the result Pattern is not the text that was originally
+// parsed, but it describes the same regexp: `[a-cb]` becomes `[a-c]`,
+// `(?i)ab` becomes `(?i:AB)`.
+//
+// opts are used to parse the synthesized text, nil means the default options.
+func FromStd(re *stdsyntax.Regexp, opts *syntax.ParserOptions) (*syntax.Regexp, error) {
+	result, err := syntax.NewParser(opts).Parse(re.String())
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
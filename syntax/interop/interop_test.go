@@ -0,0 +1,51 @@
+package interop
+
+import (
+	stdsyntax "regexp/syntax"
+	"testing"
+)
+
+func TestFromStd(t *testing.T) {
+	patterns := []string{
+		`abc`,
+		`a+?|b*|c??`,
+		`(?i)hello`,
+		`[a-cb]\d\W`,
+		`^(?P<year>\d{4})-(\d{2})$`,
+		`(?s).(?-s).`,
+		`(?m)^x$`,
+		`\Afoo\z`,
+		`\pL\p{Greek}\PN`,
+		`[[:alpha:]\x{10FFFF}]`,
+		`x{2,}y{0,3}z{5}`,
+		`(?U)a+b*?`,
+		`\bword\B`,
+		``,
+		`a|`,
+		`[^\n]`,
+	}
+
+	for _, pattern := range patterns {
+		std, err := stdsyntax.Parse(pattern, stdsyntax.Perl)
+		if err != nil {
+			t.Fatalf("std parse(%q): %v", pattern, err)
+		}
+		re, err := FromStd(std, nil)
+		if err != nil {
+			t.Errorf("FromStd(%q): %v", pattern, err)
+			continue
+		}
+		if re.Pattern != std.String() {
+			t.Errorf("FromStd(%q) pattern:\nhave: %s\nwant: %s", pattern, re.Pattern, std.String())
+		}
+		// The synthesized text must describe the same std tree.
+		std2, err := stdsyntax.Parse(re.Pattern, stdsyntax.Perl)
+		if err != nil {
+			t.Errorf("std parse(%q): %v", re.Pattern, err)
+			continue
+		}
+		if std2.String() != std.String() {
+			t.Errorf("FromStd(%q) round trip:\nhave: %s\nwant: %s", pattern, std2, std)
+		}
+	}
+}
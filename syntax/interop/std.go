@@ -0,0 +1,179 @@
+package interop
+
+import (
+	"fmt"
+	stdsyntax "regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// horizontalSpaces is the PCRE `\h` set written as char class contents.
+const horizontalSpaces = `\t \x{A0}\x{1680}\x{180E}\x{2000}-\x{200A}\x{202F}\x{205F}\x{3000}`
+
+// verticalSpaces is the PCRE `\v` set, RE2 `\v` is a vertical tab.
+const verticalSpaces = `\n-\r\x{85}\x{2028}\x{2029}`
+
+// pcreSpaces is the PCRE `\s` set, it includes a vertical tab unlike the RE2 one.
+const pcreSpaces = `\t-\r `
+
+// ToStdSyntaxString returns re.Pattern rewritten in the regexp/syntax form.
+//
+// The pattern is treated as a PCRE one. The forms that have RE2
+// equivalents are normalized: named groups use the `(?P<name>re)` syntax,
+// comments are removed, `\Q...\E` quotes are escaped, the `\e`, `\h`,
+// `\N`, `\v` and `\s` escapes are expanded to the PCRE char sets.
+// If flags have no stdsyntax.OneLine, `^` outside of the `m` flag
+// becomes `\A`.
+//
+// An error is returned for the features that RE2 doesn't support,
+// like lookarounds and backreferences. The `$` outside of the `m`
+// flag is rejected too: PCRE matches it before a final newline,
+// use `\z` or `(?m)$` instead.
+//
+// The result is parsed by regexp/syntax.Parse with the specified
+// flags and simplified, like `x{2,3}` becomes `xxx?`.
+func ToStdSyntaxString(re *syntax.Regexp, flags stdsyntax.Flags) (string, error) {
+	c := stdConverter{pattern: re.Pattern}
+	var anchorErr error
+	syntax.WalkFlags(re.Expr, syntax.DialectPCRE, 0, func(e syntax.Expr, f syntax.Flags) {
+		if f&syntax.FlagMultiline != 0 || anchorErr != nil {
+			return
+		}
+		switch {
+		case e.Op == syntax.OpDollar:
+			anchorErr = c.unsupported(e, "end anchor before a final newline")
+		case e.Op == syntax.OpCaret && flags&stdsyntax.OneLine == 0:
+			c.replace(e.Begin(), e.End(), `\A`)
+		}
+	})
+	if anchorErr != nil {
+		return "", anchorErr
+	}
+	if err := c.walk(re.Expr, false); err != nil {
+		return "", err
+	}
+
+	sort.SliceStable(c.edits, func(i, j int) bool {
+		return c.edits[i].begin < c.edits[j].begin
+	})
+	var b strings.Builder
+	offset := 0
+	for _, e := range c.edits {
+		b.WriteString(re.Pattern[offset:e.begin])
+		b.WriteString(e.text)
+		offset = e.end
+	}
+	b.WriteString(re.Pattern[offset:])
+	std, err := stdsyntax.Parse(b.String(), flags)
+	if err != nil {
+		return "", err
+	}
+	return std.Simplify().String(), nil
+}
+
+type stdEdit struct {
+	begin int
+	end   int
+	text  string
+}
+
+type stdConverter struct {
+	pattern string
+	edits   []stdEdit
+}
+
+func (c *stdConverter) replace(begin, end uint16, text string) {
+	c.edits = append(c.edits, stdEdit{begin: int(begin), end: int(end), text: text})
+}
+
+func (c *stdConverter) unsupported(e syntax.Expr, what string) error {
+	return fmt.Errorf("%s at %d: %s is not supported by RE2", e.Value, e.Begin(), what)
+}
+
+func (c *stdConverter) walk(e syntax.Expr, insideClass bool) error {
+	switch e.Op {
+	case syntax.OpCharClass, syntax.OpNegCharClass:
+		insideClass = true
+
+	case syntax.OpComment:
+		c.replace(e.Begin(), e.End(), "")
+		return nil
+
+	case syntax.OpQuote:
+		c.replace(e.Begin(), e.End(), syntax.QuoteMeta(e.QuotedLiteral(), syntax.DialectRE2))
+		return nil
+
+	case syntax.OpNamedCapture:
+		body := e.Args[0]
+		c.replace(e.Begin(), body.Begin(), "(?P<"+e.Args[1].Value+">")
+		return c.walk(body, insideClass)
+
+	case syntax.OpEscapeChar:
+		return c.escape(e, insideClass)
+
+	case syntax.OpEscapeOctal, syntax.OpEscapeHex:
+		if e.Form == syntax.FormEscapeBackref {
+			return c.unsupported(e, "backreference")
+		}
+
+	case syntax.OpPossessive:
+		return c.unsupported(e, "possessive quantifier")
+	case syntax.OpAtomicGroup:
+		return c.unsupported(e, "atomic group")
+	case syntax.OpScriptRun:
+		return c.unsupported(e, "script run")
+	case syntax.OpPositiveLookahead, syntax.OpNegativeLookahead:
+		return c.unsupported(e, "lookahead")
+	case syntax.OpPositiveLookbehind, syntax.OpNegativeLookbehind:
+		return c.unsupported(e, "lookbehind")
+	}
+
+	for _, a := range e.Args {
+		if err := c.walk(a, insideClass); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *stdConverter) escape(e syntax.Expr, insideClass bool) error {
+	switch e.Value {
+	case `\e`:
+		c.replace(e.Begin(), e.End(), `\x1B`)
+	case `\h`, `\H`:
+		return c.class(e, insideClass, horizontalSpaces)
+	case `\v`, `\V`:
+		return c.class(e, insideClass, verticalSpaces)
+	case `\s`, `\S`:
+		return c.class(e, insideClass, pcreSpaces)
+	case `\Z`:
+		return c.unsupported(e, "end anchor before a final newline")
+	case `\N`:
+		if insideClass {
+			return c.unsupported(e, "negated class inside a class")
+		}
+		c.replace(e.Begin(), e.End(), `[^\n]`)
+	case `\k`, `\g`:
+		return c.unsupported(e, "backreference")
+	}
+	return nil
+}
+
+// class replaces the e escape with a class of the set chars,
+// the uppercase escapes are negated.
+func (c *stdConverter) class(e syntax.Expr, insideClass bool, set string) error {
+	negated := e.Value[1] >= 'A' && e.Value[1] <= 'Z'
+	switch {
+	case negated && insideClass:
+		return c.unsupported(e, "negated class inside a class")
+	case negated:
+		c.replace(e.Begin(), e.End(), "[^"+set+"]")
+	case insideClass:
+		c.replace(e.Begin(), e.End(), set)
+	default:
+		c.replace(e.Begin(), e.End(), "["+set+"]")
+	}
+	return nil
+}
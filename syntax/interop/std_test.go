@@ -0,0 +1,88 @@
+package interop
+
+import (
+	stdsyntax "regexp/syntax"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestToStdSyntaxString(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `abc`},
+		{`(?<x>a)(?'y'b)(?P<z>c)`, `(?P<x>a)(?P<y>b)(?P<z>c)`},
+		{`a(?#comment)b`, `ab`},
+		{`\Qa.b\E+`, `a\.b+`},
+		{`\e\N`, `\x1b[^\n]`},
+		{`\h[\hx]`, `[\t \xa0\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]` +
+			`[\t x\xa0\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]`},
+		{`(?i)\v\x{41}\101`, `(?i:[\n-\r\x85\x{2028}\x{2029}]AA)`},
+		{`\s[^\s]\S`, `[\t-\r ][^\t-\r ][^\t-\r ]`},
+		{`x{2,3}(?:ab){2}`, `xxx?abab`},
+		{`^a\z|(?m)^b$`, `(?m:\Aa\z|^b$)`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, err := ToStdSyntaxString(re, stdsyntax.Perl)
+		if err != nil {
+			t.Errorf("ToStdSyntaxString(%q): %v", test.pattern, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("ToStdSyntaxString(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestToStdSyntaxStringNoOneLine(t *testing.T) {
+	re, err := syntax.NewParser(nil).Parse(`^a|(?m)^b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := ToStdSyntaxString(re, stdsyntax.PerlX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `(?m:\Aa|^b)`; have != want {
+		t.Errorf("ToStdSyntaxString(%q):\nhave: %s\nwant: %s", re.Pattern, have, want)
+	}
+}
+
+func TestToStdSyntaxStringErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a++`, `a++ at 0: possessive quantifier is not supported by RE2`},
+		{`x(?>y)`, `(?>y) at 1: atomic group is not supported by RE2`},
+		{`(?=x)`, `(?=x) at 0: lookahead is not supported by RE2`},
+		{`(?<!x)`, `(?<!x) at 0: lookbehind is not supported by RE2`},
+		{`(a)\k<x>`, `\k at 3: backreference is not supported by RE2`},
+		{`[\H]`, `\H at 1: negated class inside a class is not supported by RE2`},
+		{`[a\S]`, `\S at 2: negated class inside a class is not supported by RE2`},
+		{`^a$`, `$ at 2: end anchor before a final newline is not supported by RE2`},
+		{`(?m:a$)b$`, `$ at 8: end anchor before a final newline is not supported by RE2`},
+		{`a\Z`, `\Z at 1: end anchor before a final newline is not supported by RE2`},
+		{`(?x)a`, "error parsing regexp: invalid or unsupported Perl syntax: `(?x`"},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = ToStdSyntaxString(re, stdsyntax.Perl)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("ToStdSyntaxString(%q):\nhave: %v\nwant: %s", test.pattern, err, test.want)
+		}
+	}
+}
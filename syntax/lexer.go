@@ -33,6 +33,7 @@ const (
 	tokEscapeHex
 	tokEscapeHexFull
 	tokComment
+	tokPlaceholder
 
 	tokQ                        // \Q
 	tokMinus                    // -
@@ -56,6 +57,7 @@ const (
 	tokLparenPositiveLookbehind // (?<=
 	tokLparenNegativeLookahead  // (?!
 	tokLparenNegativeLookbehind // (?<!
+	tokLparenScriptRun          // (*sr:
 	tokRparen                   // )
 )
 
@@ -85,6 +87,13 @@ type lexer struct {
 	tokens []token
 	pos    int
 	input  string
+
+	// latin1 makes every input byte a separate char.
+	latin1 bool
+
+	// placeholders selects the template markers that are
+	// scanned as tokPlaceholder.
+	placeholders PlaceholderSyntax
 }
 
 func (l *lexer) HasMoreTokens() bool {
@@ -109,10 +118,13 @@ func (l *lexer) Peek() token {
 
 func (l *lexer) scan() {
 	for l.pos < len(l.input) {
+		if l.tryScanPlaceholder() {
+			l.maybeInsertConcat()
+			continue
+		}
 		ch := l.input[l.pos]
 		if ch >= utf8.RuneSelf {
-			_, size := utf8.DecodeRuneInString(l.input[l.pos:])
-			l.pushTok(tokChar, size)
+			l.pushTok(tokChar, l.charSize(l.pos))
 			l.maybeInsertConcat()
 			continue
 		}
@@ -160,10 +172,10 @@ func (l *lexer) scan() {
 					} else if l.tryScanGroupName(l.pos + 2) {
 					} else if l.tryScanGroupFlags(l.pos + 2) {
 					} else {
-						throw(newPos(l.pos, l.pos+1), "group token is incomplete")
+						throw(CodeIncompleteGroup, newPos(l.pos, l.pos+1), "group token is incomplete")
 					}
 				}
-			} else {
+			} else if !l.tryScanScriptRun(l.pos + 1) {
 				l.pushTok(tokLparen, 1)
 			}
 		case '{':
@@ -188,10 +200,12 @@ func (l *lexer) scanCharClass() {
 	}
 
 	for l.pos < len(l.input) {
+		if l.tryScanPlaceholder() {
+			continue
+		}
 		ch := l.input[l.pos]
 		if ch >= utf8.RuneSelf {
-			_, size := utf8.DecodeRuneInString(l.input[l.pos:])
-			l.pushTok(tokChar, size)
+			l.pushTok(tokChar, l.charSize(l.pos))
 			continue
 		}
 		switch ch {
@@ -223,17 +237,17 @@ func (l *lexer) scanCharClass() {
 func (l *lexer) scanEscape(insideCharClass bool) {
 	s := l.input
 	if l.pos+1 >= len(s) {
-		throw(newPos(l.pos, l.pos+1), `unexpected end of pattern: trailing '\'`)
+		throw(CodeTrailingBackslash, newPos(l.pos, l.pos+1), `unexpected end of pattern: trailing '\'`)
 	}
 	switch {
 	case s[l.pos+1] == 'p' || s[l.pos+1] == 'P':
 		if l.pos+2 >= len(s) {
-			throw(newPos(l.pos, l.pos+2), "unexpected end of pattern: expected uni-class-short or '{'")
+			throw(CodeIncompleteEscape, newPos(l.pos, l.pos+2), "unexpected end of pattern: expected uni-class-short or '{'")
 		}
 		if s[l.pos+2] == '{' {
 			j := strings.IndexByte(s[l.pos+2:], '}')
 			if j < 0 {
-				throw(newPos(l.pos, l.pos+2), "can't find closing '}'")
+				throw(CodeUnclosedBrace, newPos(l.pos, l.pos+2), "can't find closing '}'")
 			}
 			l.pushTok(tokEscapeUniFull, len(`\p{`)+j)
 		} else {
@@ -241,12 +255,12 @@ func (l *lexer) scanEscape(insideCharClass bool) {
 		}
 	case s[l.pos+1] == 'x':
 		if l.pos+2 >= len(s) {
-			throw(newPos(l.pos, l.pos+2), "unexpected end of pattern: expected hex-digit or '{'")
+			throw(CodeIncompleteEscape, newPos(l.pos, l.pos+2), "unexpected end of pattern: expected hex-digit or '{'")
 		}
 		if s[l.pos+2] == '{' {
 			j := strings.IndexByte(s[l.pos+2:], '}')
 			if j < 0 {
-				throw(newPos(l.pos, l.pos+2), "can't find closing '}'")
+				throw(CodeUnclosedBrace, newPos(l.pos, l.pos+2), "can't find closing '}'")
 			}
 			l.pushTok(tokEscapeHexFull, len(`\x{`)+j)
 		} else {
@@ -277,8 +291,7 @@ func (l *lexer) scanEscape(insideCharClass bool) {
 	default:
 		ch := l.byteAt(l.pos + 1)
 		if ch >= utf8.RuneSelf {
-			_, size := utf8.DecodeRuneInString(l.input[l.pos+1:])
-			l.pushTok(tokEscapeChar, len(`\`)+size)
+			l.pushTok(tokEscapeChar, len(`\`)+l.charSize(l.pos+1))
 			return
 		}
 		kind := tokEscapeChar
@@ -304,6 +317,15 @@ func (l *lexer) maybeInsertConcat() {
 	}
 }
 
+// charSize returns the size of a non-ASCII char that starts at pos.
+func (l *lexer) charSize(pos int) int {
+	if l.latin1 {
+		return 1
+	}
+	_, size := utf8.DecodeRuneInString(l.input[pos:])
+	return size
+}
+
 func (l *lexer) Init(s string) {
 	l.pos = 0
 	l.tokens = l.tokens[:0]
@@ -325,6 +347,11 @@ func (l *lexer) tryScanGroupName(pos int) bool {
 	case '<':
 		tok = tokLparenNameAngle
 	case 'P':
+		// `(?P=name)` and `(?P>name)` are not captures, the name
+		// end search should not reach the next group `>`.
+		if l.byteAt(pos+1) != '<' {
+			return false
+		}
 		offset = 2
 	default:
 		return false
@@ -354,6 +381,38 @@ func (l *lexer) tryScanGroupFlags(pos int) bool {
 	return true
 }
 
+// scriptRunPrefixes are the PCRE2 script run group prefixes, without the `(`.
+var scriptRunPrefixes = []string{
+	"*sr:",
+	"*script_run:",
+	"*asr:",
+	"*atomic_script_run:",
+}
+
+func (l *lexer) tryScanScriptRun(pos int) bool {
+	if l.byteAt(pos) != '*' {
+		return false
+	}
+	for _, prefix := range scriptRunPrefixes {
+		if strings.HasPrefix(l.input[pos:], prefix) {
+			l.pushTok(tokLparenScriptRun, len("(")+len(prefix))
+			return true
+		}
+	}
+	return false
+}
+
+func (l *lexer) tryScanPlaceholder() bool {
+	if l.placeholders == 0 {
+		return false
+	}
+	if n := l.placeholderWidth(l.pos); n != 0 {
+		l.pushTok(tokPlaceholder, n)
+		return true
+	}
+	return false
+}
+
 func (l *lexer) tryScanComment(pos int) bool {
 	if l.byteAt(pos) != '#' {
 		return false
@@ -444,6 +503,7 @@ var concatTable = [256]byte{
 	tokLparenPositiveLookbehind: concatX,
 	tokLparenNegativeLookahead:  concatX,
 	tokLparenNegativeLookbehind: concatX,
+	tokLparenScriptRun:          concatX,
 
 	tokRparen:   concatY,
 	tokRbracket: concatY,
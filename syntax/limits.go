@@ -0,0 +1,179 @@
+package syntax
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Limits restricts the size of the parsed patterns.
+//
+// It's intended for the services that parse untrusted patterns:
+// the AST consumers (like analysis and transform packages) are
+// recursive, so deep or enormous trees can be too expensive to process.
+// Zero values mean "no limit".
+type Limits struct {
+	// MaxDepth is a max expressions nesting level.
+	// `a` has depth 1, `(a)` has depth 2.
+	MaxDepth int
+
+	// MaxNodes is a max number of AST nodes, including OpString nodes.
+	MaxNodes int
+
+	// MaxRepeatProduct is a max product of the nested repetition counts.
+	// `(a{10}){20}` has the product of 200, `a*` and `a+` are counted as 1,
+	// `a{n,}` is counted as n.
+	MaxRepeatProduct int
+
+	// MaxRepeatCount is a max `{n,m}` repetition bound.
+	// RE2 and Go regexp use 1000.
+	MaxRepeatCount int
+}
+
+// LimitKind identifies the exceeded limit.
+type LimitKind byte
+
+const (
+	// LimitDepth is Limits.MaxDepth.
+	LimitDepth LimitKind = iota + 1
+
+	// LimitNodes is Limits.MaxNodes.
+	LimitNodes
+
+	// LimitRepeatProduct is Limits.MaxRepeatProduct.
+	LimitRepeatProduct
+
+	// LimitRepeatCount is Limits.MaxRepeatCount.
+	LimitRepeatCount
+
+	// LimitLength is a max pattern length, it's not configurable.
+	// Patterns longer than 64KiB can't be addressed by the Position.
+	LimitLength
+)
+
+// maxPatternLen is the LimitLength value.
+const maxPatternLen = 1<<16 - 1
+
+func (k LimitKind) String() string {
+	switch k {
+	case LimitDepth:
+		return "nesting depth"
+	case LimitNodes:
+		return "number of nodes"
+	case LimitRepeatProduct:
+		return "repetition product"
+	case LimitRepeatCount:
+		return "repetition count"
+	case LimitLength:
+		return "length"
+	default:
+		return "?"
+	}
+}
+
+// LimitError is returned by the Parse when the pattern exceeds one of the Limits.
+type LimitError struct {
+	Kind LimitKind
+
+	// Limit is the exceeded limit value.
+	Limit int
+
+	// Pos is a location of the expression that exceeded the limit.
+	Pos Position
+}
+
+// Code returns the diagnostic code for the exceeded limit.
+func (e LimitError) Code() Code {
+	return CodeLimitDepth + Code(e.Kind-LimitDepth)
+}
+
+func (e LimitError) Error() string {
+	return "pattern " + e.Kind.String() + " exceeds the limit of " + strconv.Itoa(e.Limit)
+}
+
+// checkDepth panics with LimitError if the current parsing depth
+// exceeds the MaxDepth limit.
+//
+// The parseExpr nesting never exceeds the resulting AST depth,
+// so it can be checked before the recursive AST passes run.
+func (p *Parser) checkDepth(pos Position) {
+	limit := p.opts.Limits.MaxDepth
+	if limit != 0 && p.depth > limit {
+		panic(LimitError{Kind: LimitDepth, Limit: limit, Pos: pos})
+	}
+}
+
+// checkNodes panics with LimitError if the number of allocated
+// expressions exceeds the MaxNodes limit.
+//
+// Merging chars into literals can only add nodes, so the parsing
+// time count is a lower bound of the final AST size.
+func (p *Parser) checkNodes(pos Position) {
+	limit := p.opts.Limits.MaxNodes
+	if limit != 0 && p.exprPool.total > limit {
+		panic(LimitError{Kind: LimitNodes, Limit: limit, Pos: pos})
+	}
+}
+
+// checkLimits panics with LimitError if e exceeds the parser limits.
+//
+// The depth and nodes limits are also checked during the parsing,
+// this pass does the exact accounting of the final AST.
+func (p *Parser) checkLimits(e Expr) {
+	limits := p.opts.Limits
+	if limits == (Limits{}) {
+		return
+	}
+	nodes := 0
+	var walk func(e Expr, depth, product int)
+	walk = func(e Expr, depth, product int) {
+		nodes++
+		if limits.MaxNodes != 0 && nodes > limits.MaxNodes {
+			panic(LimitError{Kind: LimitNodes, Limit: limits.MaxNodes, Pos: e.Pos})
+		}
+		if limits.MaxDepth != 0 && depth > limits.MaxDepth {
+			panic(LimitError{Kind: LimitDepth, Limit: limits.MaxDepth, Pos: e.Pos})
+		}
+		if e.Op == OpRepeat {
+			count := repeatCount(e.Args[1].Value)
+			if limits.MaxRepeatCount != 0 && count > limits.MaxRepeatCount {
+				panic(LimitError{Kind: LimitRepeatCount, Limit: limits.MaxRepeatCount, Pos: e.Args[1].Pos})
+			}
+			product = mulRepeatProduct(product, count)
+			if limits.MaxRepeatProduct != 0 && product > limits.MaxRepeatProduct {
+				panic(LimitError{Kind: LimitRepeatProduct, Limit: limits.MaxRepeatProduct, Pos: e.Pos})
+			}
+		}
+		for _, a := range e.Args {
+			walk(a, depth+1, product)
+		}
+	}
+	walk(e, 1, 1)
+}
+
+// repeatCount returns the max repetitions count for the `{n,m}` string.
+// For `{n,}` it returns n.
+func repeatCount(s string) int {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if comma := strings.IndexByte(s, ','); comma != -1 {
+		if comma == len(s)-1 {
+			s = s[:comma]
+		} else {
+			s = s[comma+1:]
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return maxRepeatProduct
+	}
+	return n
+}
+
+// maxRepeatProduct is a saturation limit for the repeat products.
+const maxRepeatProduct = 1 << 30
+
+func mulRepeatProduct(x, y int) int {
+	if x != 0 && y > maxRepeatProduct/x {
+		return maxRepeatProduct
+	}
+	return x * y
+}
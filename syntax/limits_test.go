@@ -0,0 +1,48 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParserLimits(t *testing.T) {
+	tests := []struct {
+		pattern string
+		limits  Limits
+		want    string
+	}{
+		{`((a))`, Limits{MaxDepth: 3}, ``},
+		{`((a))`, Limits{MaxDepth: 2}, `pattern nesting depth exceeds the limit of 2 at 2-3`},
+		{`abc`, Limits{MaxNodes: 4}, ``},
+		{`abcd`, Limits{MaxNodes: 4}, `pattern number of nodes exceeds the limit of 4 at 3-4`},
+		{`(a{10}){20}`, Limits{MaxRepeatProduct: 200}, ``},
+		{`(a{10}){20}`, Limits{MaxRepeatProduct: 199}, `pattern repetition product exceeds the limit of 199 at 1-6`},
+		{`(a{2,}b{0,5})*`, Limits{MaxRepeatProduct: 5}, ``},
+		{`a{1000}b{0,1000}c{1000,}`, Limits{MaxRepeatCount: 1000}, ``},
+		{`a{1,1001}`, Limits{MaxRepeatCount: 1000}, `pattern repetition count exceeds the limit of 1000 at 1-9`},
+		{`a{99999999999999999999}`, Limits{MaxRepeatProduct: 1000}, `pattern repetition product exceeds the limit of 1000 at 0-23`},
+
+		// The limits are checked before the recursive AST passes.
+		{strings.Repeat(`(`, 20000) + strings.Repeat(`)`, 20000), Limits{MaxDepth: 50, MaxNodes: 1000}, `pattern nesting depth exceeds the limit of 50 at 50-51`},
+		{strings.Repeat(`(a`, 20000) + strings.Repeat(`)`, 20000), Limits{MaxNodes: 1000}, `pattern number of nodes exceeds the limit of 1000 at 2001-2002`},
+		{strings.Repeat(`a`, 1<<16-1), Limits{}, ``},
+		{strings.Repeat(`(`, 100000) + strings.Repeat(`)`, 100000), Limits{MaxDepth: 50, MaxNodes: 1000}, `pattern length exceeds the limit of 65535 at 0-0`},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&ParserOptions{Limits: test.limits})
+		_, err := p.Parse(test.pattern)
+		have := ""
+		if err != nil {
+			limitErr, ok := err.(LimitError)
+			if !ok {
+				t.Fatalf("parse(%q): unexpected error type: %T", test.pattern, err)
+			}
+			have = fmt.Sprintf("%v at %d-%d", err, limitErr.Pos.Begin, limitErr.Pos.End)
+		}
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package syntax
+
+// Newline is a line terminator convention.
+//
+// It defines what `.` doesn't match, where the multiline `^` and `$`
+// match and what the `\R` escape matches. The conventions mirror
+// the PCRE `(*LF)`, `(*CR)`, `(*CRLF)`, `(*ANYCRLF)` and `(*ANY)` verbs.
+type Newline byte
+
+const (
+	// NewlineDefault is the Go regexp and PCRE default: lines are
+	// terminated by `\n`, while `\R` matches any Unicode line break.
+	NewlineDefault Newline = iota
+
+	// NewlineLF terminates lines by `\n`, `\R` matches only `\n`.
+	NewlineLF
+
+	// NewlineCR terminates lines by `\r`, like the classic Mac OS.
+	NewlineCR
+
+	// NewlineCRLF terminates lines by the `\r\n` sequence, like Windows.
+	NewlineCRLF
+
+	// NewlineAnyCRLF terminates lines by `\r\n`, `\n` or `\r`.
+	NewlineAnyCRLF
+
+	// NewlineAny terminates lines by any Unicode line break:
+	// `\r\n`, `\n`, `\v`, `\f`, `\r`, NEL (U+0085), LS (U+2028) and PS (U+2029).
+	NewlineAny
+)
+
+func (nl Newline) String() string {
+	switch nl {
+	case NewlineDefault:
+		return "default"
+	case NewlineLF:
+		return "LF"
+	case NewlineCR:
+		return "CR"
+	case NewlineCRLF:
+		return "CRLF"
+	case NewlineAnyCRLF:
+		return "ANYCRLF"
+	case NewlineAny:
+		return "ANY"
+	default:
+		return "?"
+	}
+}
+
+// Terminators returns the line terminators, longer sequences go first.
+func (nl Newline) Terminators() []string {
+	switch nl {
+	case NewlineCR:
+		return []string{"\r"}
+	case NewlineCRLF:
+		return []string{"\r\n"}
+	case NewlineAnyCRLF:
+		return []string{"\r\n", "\n", "\r"}
+	case NewlineAny:
+		return []string{"\r\n", "\n", "\v", "\f", "\r", "\u0085", "\u2028", "\u2029"}
+	default:
+		return []string{"\n"}
+	}
+}
+
+// LineBreaks returns the sequences matched by `\R`, longer sequences go first.
+func (nl Newline) LineBreaks() []string {
+	if nl == NewlineDefault {
+		return NewlineAny.Terminators()
+	}
+	return nl.Terminators()
+}
+
+// IsTerminator reports whether ch is a line terminator or its part.
+//
+// The `\r` and `\n` chars are both reported for NewlineCRLF,
+// so the char-level consumers that treat `.` as a chars set
+// approximate it as not matching a lone `\r` or `\n` either.
+func (nl Newline) IsTerminator(ch rune) bool {
+	for _, t := range nl.Terminators() {
+		for _, tch := range t {
+			if ch == tch {
+				return true
+			}
+		}
+	}
+	return false
+}
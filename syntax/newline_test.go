@@ -0,0 +1,36 @@
+package syntax
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewline(t *testing.T) {
+	tests := []struct {
+		newline     Newline
+		terminators string
+		lineBreaks  string
+	}{
+		{NewlineDefault, `["\n"]`, `["\r\n" "\n" "\v" "\f" "\r" "\u0085" "\u2028" "\u2029"]`},
+		{NewlineLF, `["\n"]`, `["\n"]`},
+		{NewlineCR, `["\r"]`, `["\r"]`},
+		{NewlineCRLF, `["\r\n"]`, `["\r\n"]`},
+		{NewlineAnyCRLF, `["\r\n" "\n" "\r"]`, `["\r\n" "\n" "\r"]`},
+	}
+
+	for _, test := range tests {
+		if have := fmt.Sprintf("%+q", test.newline.Terminators()); have != test.terminators {
+			t.Errorf("%s terminators:\nhave: %s\nwant: %s", test.newline, have, test.terminators)
+		}
+		if have := fmt.Sprintf("%+q", test.newline.LineBreaks()); have != test.lineBreaks {
+			t.Errorf("%s line breaks:\nhave: %s\nwant: %s", test.newline, have, test.lineBreaks)
+		}
+	}
+
+	if !NewlineCRLF.IsTerminator('\r') || !NewlineCRLF.IsTerminator('\n') {
+		t.Errorf("CRLF: expected both \\r and \\n to be terminator chars")
+	}
+	if NewlineCR.IsTerminator('\n') {
+		t.Errorf("CR: unexpected \\n terminator char")
+	}
+}
@@ -0,0 +1,75 @@
+package syntax
+
+import (
+	"strings"
+)
+
+// IsSuppressed reports whether the diagnostic with the specified rule
+// is suppressed at pos by a `(?#nolint)` comment.
+//
+// `(?#nolint)` suppresses all diagnostics, `(?#nolint:rule1,rule2)`
+// suppresses only the listed rules. The comment affects the innermost
+// group that contains it, or the whole pattern if it's not inside a group.
+// The rule is usually a Code string, like "RE1103", but the
+// external linters can use their own rule names.
+//
+// The parser drops the suppressed warnings from Regexp.Warnings.
+func (re *Regexp) IsSuppressed(pos Position, rule string) bool {
+	suppressed := false
+	walkNolint(re.Expr, re.Expr.Pos, func(scope Position, rules []string) {
+		if pos.Begin < scope.Begin || pos.End > scope.End {
+			return
+		}
+		if len(rules) == 0 {
+			suppressed = true
+			return
+		}
+		for _, r := range rules {
+			if r == rule {
+				suppressed = true
+			}
+		}
+	})
+	return suppressed
+}
+
+// walkNolint calls visit for every nolint comment along with its scope.
+func walkNolint(e Expr, scope Position, visit func(scope Position, rules []string)) {
+	switch e.Op {
+	case OpComment:
+		text := strings.TrimSuffix(strings.TrimPrefix(e.Value, "(?#"), ")")
+		switch {
+		case text == "nolint":
+			visit(scope, nil)
+		case strings.HasPrefix(text, "nolint:"):
+			rules := strings.Split(text[len("nolint:"):], ",")
+			for i := range rules {
+				rules[i] = strings.TrimSpace(rules[i])
+			}
+			visit(scope, rules)
+		}
+		return
+	case OpCapture, OpNamedCapture, OpGroup, OpGroupWithFlags, OpAtomicGroup, OpScriptRun,
+		OpPositiveLookahead, OpNegativeLookahead,
+		OpPositiveLookbehind, OpNegativeLookbehind:
+		scope = e.Pos
+	}
+	for _, a := range e.Args {
+		walkNolint(a, scope, visit)
+	}
+}
+
+// dropSuppressedWarnings removes the warnings that are suppressed
+// by the `(?#nolint)` comments.
+func (p *Parser) dropSuppressedWarnings() {
+	if len(p.out.Warnings) == 0 {
+		return
+	}
+	warnings := p.out.Warnings[:0]
+	for _, w := range p.out.Warnings {
+		if !p.out.IsSuppressed(w.Pos, w.Code.String()) {
+			warnings = append(warnings, w)
+		}
+	}
+	p.out.Warnings = warnings
+}
@@ -0,0 +1,64 @@
+package syntax
+
+import (
+	"testing"
+)
+
+func TestIsSuppressed(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+		rule    string
+		want    bool
+	}{
+		{`ab`, 0, "x", false},
+		{`a(?#nolint)b`, 0, "x", true},
+		{`a(?#nolint:x)b`, 12, "x", true},
+		{`a(?#nolint:x, y)b`, 0, "y", true},
+		{`a(?#nolint:x)b`, 0, "y", false},
+		{`a(b(?#nolint))c`, 2, "x", true},
+		{`a(b(?#nolint))c`, 0, "x", false},
+		{`a(b(?#nolint))c`, 14, "x", false},
+		{`(?:a|(?#nolint)b)c`, 3, "x", true},
+		{`(?#nolintx)a`, 11, "x", false},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		pos := Position{Begin: uint16(test.offset), End: uint16(test.offset + 1)}
+		if have := re.IsSuppressed(pos, test.rule); have != test.want {
+			t.Errorf("IsSuppressed(%q, %d, %s):\nhave: %v\nwant: %v",
+				test.pattern, test.offset, test.rule, have, test.want)
+		}
+	}
+}
+
+func TestSuppressedWarnings(t *testing.T) {
+	opts := &ParserOptions{DupNames: DupNamesWarn, HighByteEscapes: EscapeWarn}
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{`(?P<x>)(?P<x>)\xFF`, 2},
+		{`(?#nolint)(?P<x>)(?P<x>)\xFF`, 0},
+		{`(?#nolint:RE1103)(?P<x>)(?P<x>)\xFF`, 1},
+		{`(?P<x>(?#nolint:RE1103))(?P<x>)\xFF`, 2},
+		{`(?P<x>)(?P<x>(?#nolint:RE1103))\xFF`, 1},
+		{`(?P<x>)(?P<x>)(?:\xFF(?#nolint:RE1102))`, 1},
+	}
+
+	p := NewParser(opts)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if len(re.Warnings) != test.want {
+			t.Errorf("parse(%q) warnings:\nhave: %v\nwant: %d warnings", test.pattern, re.Warnings, test.want)
+		}
+	}
+}
@@ -5,11 +5,11 @@ const (
 	OpNone Operation = iota
 
 	// OpConcat is a concatenation of ops.
-	// Examples: `xy` `abc\d` ``
+	// Examples: `xy` `abc\d`
 	// Args - concatenated ops
 	//
-	// As a special case, OpConcat with 0 Args is used for "empty"
-	// set of operations.
+	// The parser never produces OpConcat with 0 Args, OpEmptyMatch is used
+	// instead (unless ParserOptions.EmptyConcat is set).
 	OpConcat
 
 	// OpDot is a '.' wildcard.
@@ -66,11 +66,12 @@ const (
 	// OpQuote is a \Q...\E enclosed literal.
 	// Examples: `\Q.?\E` `\Q?q[]=1`
 	// FormQuoteUnclosed: `\Qabc`
-	// Args[0] - literal value (OpString)
+	// Args[0] - literal value without delimiters (OpString), see Expr.QuotedLiteral
 	OpQuote
 
 	// OpEscapeChar is a single char escape.
 	// Examples: `\d` `\a` `\n`
+	// FormEscapeBackref: `\8` (see ParserOptions.Backrefs)
 	// Args[0] - escaped value (OpString)
 	OpEscapeChar
 
@@ -81,6 +82,7 @@ const (
 
 	// OpEscapeOctal is an octal char code escape (up to 3 digits).
 	// Examples: `\123` `\12`
+	// FormEscapeBackref: `\1` (see ParserOptions.Backrefs)
 	// Args[0] - escaped value (OpString)
 	OpEscapeOctal
 
@@ -112,8 +114,10 @@ const (
 	// Args[1] - range upper bound
 	OpCharRange
 
-	// OpPosixClass is a named ASCII char set inside a char class.
+	// OpPosixClass is a named char set inside a char class.
 	// Examples: `[:alpha:]` `[:blank:]`
+	// FormPosixUnicode: the set is resolved against the Unicode
+	// categories instead of ASCII (see ParserOptions.UnicodePosixClasses)
 	OpPosixClass
 
 	// OpRepeat is a {min,max} repetition quantifier.
@@ -131,44 +135,44 @@ const (
 	// Examples: `(?P<foo>abc)` `(?P<name>x|y)`
 	// FormNamedCaptureAngle examples: `(?<foo>abc)` `(?<name>x|y)`
 	// FormNamedCaptureQuote examples: `(?'foo'abc)` `(?'name'x|y)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	// Args[1] - group name (OpString)
 	OpNamedCapture
 
 	// OpGroup is `(?:re)` non-capturing group.
 	// Examples: `(?:abc)` `(?:x|y)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpGroup
 
 	// OpGroupWithFlags is `(?flags:re)` non-capturing group.
 	// Examples: `(?i:abc)` `(?i:x|y)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	// Args[1] - flags (OpString)
 	OpGroupWithFlags
 
 	// OpAtomicGroup is `(?>re)` non-capturing group without backtracking.
 	// Examples: `(?>foo)` `(?>)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpAtomicGroup
 
 	// OpPositiveLookahead is `(?=re)` asserts that following text matches re.
 	// Examples: `(?=foo)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpPositiveLookahead
 
 	// OpNegativeLookahead is `(?!re)` asserts that following text doesn't match re.
 	// Examples: `(?!foo)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpNegativeLookahead
 
 	// OpPositiveLookbehind is `(?<=re)` asserts that preceding text matches re.
 	// Examples: `(?<=foo)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpPositiveLookbehind
 
 	// OpNegativeLookbehind is `(?=re)` asserts that preceding text doesn't match re.
 	// Examples: `(?<!foo)`
-	// Args[0] - enclosed expression (OpConcat with 0 args for empty group)
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
 	OpNegativeLookbehind
 
 	// OpFlagOnlyGroup is `(?flags)` form that affects current group flags.
@@ -180,6 +184,25 @@ const (
 	// Examples: `(?#text)` `(?#)`
 	OpComment
 
+	// OpEmptyMatch is an empty expression that matches an empty string.
+	// Examples: `` `()` `x|`
+	OpEmptyMatch
+
+	// OpScriptRun is `(*sr:re)` PCRE2 script run group: re only matches
+	// if all its chars belong to the same script.
+	// Examples: `(*sr:\w+)` `(*script_run:\d+)` `(*asr:\w+)`
+	// Args[0] - enclosed expression (OpEmptyMatch for empty group)
+	//
+	// The atomic `(*asr:re)` and `(*atomic_script_run:re)` groups
+	// have FormScriptRunAtomic form.
+	OpScriptRun
+
+	// OpPlaceholder is a template placeholder that stands for
+	// an unknown pattern part, it can be substituted with anything.
+	// Placeholders are only recognized with ParserOptions.Placeholders.
+	// Examples: `%s` `%[1]d` `%%` `${name}` `{{.Var}}`
+	OpPlaceholder
+
 	// OpNone2 is a sentinel value that is never part of the AST.
 	// OpNone and OpNone2 can be used to cover all ops in a range.
 	OpNone2
@@ -192,4 +215,7 @@ const (
 	FormNamedCaptureAngle
 	FormNamedCaptureQuote
 	FormQuoteUnclosed
+	FormEscapeBackref
+	FormPosixUnicode
+	FormScriptRunAtomic
 )
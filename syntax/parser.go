@@ -1,42 +1,116 @@
 package syntax
 
 import (
-	"errors"
 	"strings"
+	"time"
 )
 
 type ParserOptions struct {
 	// NoLiterals disables OpChar merging into OpLiteral.
 	NoLiterals bool
+
+	// UnicodeClasses enables `\p{Name}` class name validation.
+	// Unknown names are reported as parse errors.
+	UnicodeClasses UnicodeClassCheck
+
+	// Surrogates controls `\x{D800}`-`\x{DFFF}` escapes handling.
+	// UTF-16 based engines (like JS) accept them, while UTF-8
+	// engines (like RE2 and PCRE) reject them.
+	Surrogates EscapePolicy
+
+	// Latin1 makes the parser interpret the pattern as Latin-1 text
+	// instead of UTF-8, so every byte is a separate char.
+	//
+	// This mode mirrors the regexp/syntax Latin1 flag and the byte-oriented
+	// engines, like Rust bytes::Regex. Use charset.RE2Latin1 tables to
+	// interpret the char classes of such patterns.
+	Latin1 bool
+
+	// HighByteEscapes controls `\x80`-`\xFF` and `\200`-`\377` escapes handling.
+	// Depending on the engine mode, they match either a single byte
+	// or a UTF-8 encoded code point.
+	HighByteEscapes EscapePolicy
+
+	// EmptyConcat makes the parser represent empty expressions
+	// as OpConcat with 0 Args instead of OpEmptyMatch.
+	//
+	// It's intended for the code that was written before OpEmptyMatch
+	// was introduced. Use Expr.IsEmptyMatch to handle both forms.
+	EmptyConcat bool
+
+	// Backrefs selects the `\N` backreferences disambiguation rules.
+	// Resolved backreferences have FormEscapeBackref form.
+	Backrefs BackrefRules
+
+	// DupNames controls duplicated group names handling.
+	// Use Regexp.GroupIndexes to get the resolved group indexes.
+	DupNames DupNamesPolicy
+
+	// UnicodePosixClasses makes POSIX classes like `[:alpha:]` match
+	// the Unicode categories instead of the ASCII chars, like the
+	// POSIX tools do in the UTF-8 locales and PCRE does with UCP.
+	// Such classes have FormPosixUnicode form.
+	UnicodePosixClasses bool
+
+	// Placeholders enables the template placeholders recognition.
+	// The recognized markers are parsed as OpPlaceholder nodes,
+	// so the patterns built with fmt.Sprintf or the template
+	// engines can be analyzed.
+	Placeholders PlaceholderSyntax
+
+	// Limits restricts the parsed patterns size.
+	// A pattern that exceeds the limits is rejected with LimitError.
+	Limits Limits
 }
 
 func NewParser(opts *ParserOptions) *Parser {
 	return newParser(opts)
 }
 
+// Parser is a reusable regexp parser.
+//
+// The zero value is a ready to use parser with default options.
+// All internal buffers are reused between the Parse calls, so it's
+// advised to keep a parser around instead of creating a new one
+// for every pattern.
+//
+// A Parser must not be copied after the first use.
+// It's not safe for concurrent use.
 type Parser struct {
 	out      Regexp
 	lexer    lexer
-	exprPool []Expr
+	exprPool exprArena
 
 	prefixParselets [256]prefixParselet
 	infixParselets  [256]infixParselet
 
 	charClass []Expr
-	allocated uint
+
+	// depth is a current parseExpr recursion depth.
+	depth int
 
 	opts ParserOptions
+
+	parseHook func(ParseStats)
 }
 
 // ParsePCRE parses PHP-style pattern with delimiters.
 // An example of such pattern is `/foo/i`.
+//
+// The delimiters and modifiers errors are reported as ParseError
+// with a position inside the pattern source.
 func (p *Parser) ParsePCRE(pattern string) (*RegexpPCRE, error) {
 	pcre, err := p.newPCRE(pattern)
 	if err != nil {
 		return nil, err
 	}
-	if pcre.HasModifier('x') {
-		return nil, errors.New("'x' modifier is not supported")
+	if i := strings.IndexByte(pcre.Modifiers, 'x'); i != -1 {
+		offset := len(pattern) - len(pcre.Modifiers) + i
+		return nil, ParseError{
+			Code:    CodeUnsupportedModifier,
+			Pos:     newPos(offset, offset+1),
+			Message: "'x' modifier is not supported",
+		}
 	}
 	re, err := p.Parse(pcre.Pattern)
 	if re != nil {
@@ -45,24 +119,61 @@ func (p *Parser) ParsePCRE(pattern string) (*RegexpPCRE, error) {
 	return pcre, err
 }
 
+// ParseBytes is like Parse, but accepts a pattern as a byte slice.
+//
+// The pattern is not copied: the returned Regexp Pattern and all
+// expression values refer to the pattern memory directly.
+// The caller must not modify the pattern while the result is in use.
+func (p *Parser) ParseBytes(pattern []byte) (*Regexp, error) {
+	return p.Parse(bytesToString(pattern))
+}
+
+// Parse parses the pattern and returns its AST.
+//
+// All AST nodes are allocated from the parser-owned arena that is
+// reused by the subsequent Parse calls. This makes the returned Regexp,
+// its Expr tree and all Args slices valid only until the next parsing
+// is performed by the same parser. Use Regexp.Clone to get a copy
+// that outlives the next Parse call.
 func (p *Parser) Parse(pattern string) (result *Regexp, err error) {
+	if p.parseHook != nil {
+		// Deferred before the recover, so it sees the parse error.
+		defer p.runParseHook(pattern, time.Now(), &result, &err)
+	}
 	defer func() {
 		r := recover()
 		if r == nil {
 			return
 		}
-		if err2, ok := r.(ParseError); ok {
-			err = err2
+		switch r := r.(type) {
+		case ParseError:
+			err = r
+			return
+		case LimitError:
+			err = r
 			return
 		}
 		panic(r)
 	}()
 
+	if p.prefixParselets[tokChar] == nil {
+		p.init()
+	}
+
+	if len(pattern) > maxPatternLen {
+		// Position can't address the bytes past this length.
+		panic(LimitError{Kind: LimitLength, Limit: maxPatternLen})
+	}
+
+	p.lexer.latin1 = p.opts.Latin1
+	p.lexer.placeholders = p.opts.Placeholders
 	p.lexer.Init(pattern)
-	p.allocated = 0
+	p.exprPool.reset()
+	p.depth = 0
 	p.out.Pattern = pattern
+	p.out.Warnings = p.out.Warnings[:0]
 	if pattern == "" {
-		p.out.Expr = *p.newExpr(OpConcat, Position{})
+		p.out.Expr = *p.newEmpty(0)
 	} else {
 		p.out.Expr = *p.parseExpr(0)
 	}
@@ -71,6 +182,10 @@ func (p *Parser) Parse(pattern string) (result *Regexp, err error) {
 		p.mergeChars(&p.out.Expr)
 	}
 	p.setValues(&p.out.Expr)
+	p.resolveBackrefs(&p.out.Expr)
+	p.checkDupNames(p.out.Expr)
+	p.checkLimits(p.out.Expr)
+	p.dropSuppressedWarnings()
 
 	return &p.out, nil
 }
@@ -79,14 +194,32 @@ type prefixParselet func(token) *Expr
 
 type infixParselet func(*Expr, token) *Expr
 
+// Reset discards the last parsing results while keeping the
+// allocated internal buffers for the reuse.
+//
+// It's not required to call Reset between the Parse calls.
+// It can be used to make the parser drop all references to
+// the previously parsed pattern.
+func (p *Parser) Reset() {
+	p.out = Regexp{}
+	p.lexer.Init("")
+	p.exprPool.release()
+	for i := range p.charClass {
+		p.charClass[i] = Expr{}
+	}
+	p.charClass = p.charClass[:0]
+}
+
 func newParser(opts *ParserOptions) *Parser {
 	var p Parser
-
 	if opts != nil {
 		p.opts = *opts
 	}
-	p.exprPool = make([]Expr, 256)
+	p.init()
+	return &p
+}
 
+func (p *Parser) init() {
 	for tok, op := range tok2op {
 		if op != 0 {
 			p.prefixParselets[tokenKind(tok)] = p.parsePrefixElementary
@@ -110,6 +243,7 @@ func newParser(opts *ParserOptions) *Parser {
 		litPos.Begin += uint16(len(`\x{`))
 		litPos.End -= uint16(len(`}`))
 		lit := p.newExpr(OpString, litPos)
+		p.checkCodeEscape(tok.pos, p.exprValue(lit), 16, false)
 		return p.newExprForm(OpEscapeHex, FormEscapeHexFull, tok.pos, lit)
 	}
 	p.prefixParselets[tokEscapeUniFull] = func(tok token) *Expr {
@@ -117,14 +251,27 @@ func newParser(opts *ParserOptions) *Parser {
 		litPos.Begin += uint16(len(`\p{`))
 		litPos.End -= uint16(len(`}`))
 		lit := p.newExpr(OpString, litPos)
+		p.checkUnicodeClass(tok.pos, p.exprValue(lit))
 		return p.newExprForm(OpEscapeUni, FormEscapeUniFull, tok.pos, lit)
 	}
 
-	p.prefixParselets[tokEscapeHex] = func(tok token) *Expr { return p.parseEscape(OpEscapeHex, `\x`, tok) }
-	p.prefixParselets[tokEscapeOctal] = func(tok token) *Expr { return p.parseEscape(OpEscapeOctal, `\`, tok) }
+	p.prefixParselets[tokEscapeHex] = func(tok token) *Expr {
+		e := p.parseEscape(OpEscapeHex, `\x`, tok)
+		p.checkCodeEscape(tok.pos, p.exprValue(&e.Args[0]), 16, true)
+		return e
+	}
+	p.prefixParselets[tokEscapeOctal] = func(tok token) *Expr {
+		e := p.parseEscape(OpEscapeOctal, `\`, tok)
+		p.checkCodeEscape(tok.pos, p.exprValue(&e.Args[0]), 8, true)
+		return e
+	}
 	p.prefixParselets[tokEscapeChar] = func(tok token) *Expr { return p.parseEscape(OpEscapeChar, `\`, tok) }
 	p.prefixParselets[tokEscapeMeta] = func(tok token) *Expr { return p.parseEscape(OpEscapeMeta, `\`, tok) }
-	p.prefixParselets[tokEscapeUni] = func(tok token) *Expr { return p.parseEscape(OpEscapeUni, `\p`, tok) }
+	p.prefixParselets[tokEscapeUni] = func(tok token) *Expr {
+		e := p.parseEscape(OpEscapeUni, `\p`, tok)
+		p.checkUnicodeClass(tok.pos, p.exprValue(&e.Args[0]))
+		return e
+	}
 
 	p.prefixParselets[tokLparen] = func(tok token) *Expr { return p.parseGroup(OpCapture, tok) }
 	p.prefixParselets[tokLparenAtomic] = func(tok token) *Expr { return p.parseGroup(OpAtomicGroup, tok) }
@@ -133,6 +280,14 @@ func newParser(opts *ParserOptions) *Parser {
 	p.prefixParselets[tokLparenPositiveLookbehind] = func(tok token) *Expr { return p.parseGroup(OpPositiveLookbehind, tok) }
 	p.prefixParselets[tokLparenNegativeLookbehind] = func(tok token) *Expr { return p.parseGroup(OpNegativeLookbehind, tok) }
 
+	p.prefixParselets[tokLparenScriptRun] = func(tok token) *Expr {
+		e := p.parseGroup(OpScriptRun, tok)
+		if strings.HasPrefix(p.tokenValue(tok), "(*a") {
+			e.Form = FormScriptRunAtomic
+		}
+		return e
+	}
+
 	p.prefixParselets[tokLparenName] = func(tok token) *Expr {
 		return p.parseNamedCapture(FormDefault, tok)
 	}
@@ -147,8 +302,7 @@ func newParser(opts *ParserOptions) *Parser {
 
 	p.prefixParselets[tokPipe] = func(tok token) *Expr {
 		// We need prefix pipe parselet to handle `(|x)` syntax.
-		right := p.parseExpr(1)
-		return p.newExpr(OpAlt, tok.pos, p.newEmpty(tok.pos), right)
+		return p.parseAlt(p.newEmpty(tok.pos.Begin), tok)
 	}
 	p.prefixParselets[tokLbracket] = func(tok token) *Expr {
 		return p.parseCharClass(OpCharClass, tok)
@@ -177,8 +331,6 @@ func newParser(opts *ParserOptions) *Parser {
 	p.infixParselets[tokMinus] = p.parseMinus
 	p.infixParselets[tokPlus] = p.parsePlus
 	p.infixParselets[tokQuestion] = p.parseQuestion
-
-	return &p
 }
 
 func (p *Parser) setValues(e *Expr) {
@@ -233,8 +385,13 @@ func (p *Parser) mergeChars(e *Expr) {
 	}
 }
 
-func (p *Parser) newEmpty(pos Position) *Expr {
-	return p.newExpr(OpConcat, pos)
+// newEmpty returns a zero-width empty expression located at the offset.
+func (p *Parser) newEmpty(offset uint16) *Expr {
+	pos := Position{Begin: offset, End: offset}
+	if p.opts.EmptyConcat {
+		return p.newExpr(OpConcat, pos)
+	}
+	return p.newExpr(OpEmptyMatch, pos)
 }
 
 func (p *Parser) newExprForm(op Operation, form Form, pos Position, args ...*Expr) *Expr {
@@ -257,12 +414,7 @@ func (p *Parser) newExpr(op Operation, pos Position, args ...*Expr) *Expr {
 }
 
 func (p *Parser) allocExpr() *Expr {
-	i := p.allocated
-	if i < uint(len(p.exprPool)) {
-		p.allocated++
-		return &p.exprPool[i]
-	}
-	return &Expr{}
+	return p.exprPool.alloc()
 }
 
 func (p *Parser) expect(kind tokenKind) Position {
@@ -279,19 +431,28 @@ func (p *Parser) parseExpr(precedence int) *Expr {
 	if prefix == nil {
 		throwUnexpectedToken(tok.pos, tok.String())
 	}
+	p.depth++
+	p.checkDepth(tok.pos)
 	left := prefix(tok)
+	p.checkNodes(left.Pos)
 
 	for precedence < p.precedenceOf(p.lexer.Peek()) {
 		tok := p.lexer.NextToken()
 		infix := p.infixParselets[tok.kind]
 		left = infix(left, tok)
+		p.checkNodes(left.Pos)
 	}
 
+	p.depth--
 	return left
 }
 
 func (p *Parser) parsePrefixElementary(tok token) *Expr {
-	return p.newExpr(tok2op[tok.kind], tok.pos)
+	e := p.newExpr(tok2op[tok.kind], tok.pos)
+	if e.Op == OpPosixClass && p.opts.UnicodePosixClasses {
+		e.Form = FormPosixUnicode
+	}
+	return e
 }
 
 func (p *Parser) parseCharClass(op Operation, tok token) *Expr {
@@ -306,7 +467,7 @@ func (p *Parser) parseCharClass(op Operation, tok token) *Expr {
 			break
 		}
 		if next.kind == tokNone {
-			throw(tok.pos, "unterminated '['")
+			throw(CodeUnterminatedClass, tok.pos, "unterminated '['")
 		}
 	}
 
@@ -360,9 +521,9 @@ func (p *Parser) parseQuestion(left *Expr, tok token) *Expr {
 func (p *Parser) parseAlt(left *Expr, tok token) *Expr {
 	var right *Expr
 	switch p.lexer.Peek().kind {
-	case tokRparen, tokNone:
-		// This is needed to handle `(x|)` syntax.
-		right = p.newEmpty(tok.pos)
+	case tokRparen, tokNone, tokPipe:
+		// This is needed to handle `(x|)` and `x||y` syntax.
+		right = p.newEmpty(tok.pos.End)
 	default:
 		right = p.parseExpr(1)
 	}
@@ -377,7 +538,7 @@ func (p *Parser) parseAlt(left *Expr, tok token) *Expr {
 func (p *Parser) parseGroupItem(tok token) *Expr {
 	if p.lexer.Peek().kind == tokRparen {
 		// This is needed to handle `() syntax.`
-		return p.newEmpty(tok.pos)
+		return p.newEmpty(tok.pos.End)
 	}
 	return p.parseExpr(0)
 }
@@ -451,8 +612,12 @@ func (p *Parser) precedenceOf(tok token) int {
 
 func (p *Parser) newPCRE(source string) (*RegexpPCRE, error) {
 	if source == "" {
-		return nil, errors.New("empty pattern: can't find delimiters")
+		return nil, ParseError{Code: CodeInvalidDelimiter, Message: "empty pattern: can't find delimiters"}
+	}
+	if len(source) > maxPatternLen {
+		return nil, LimitError{Kind: LimitLength, Limit: maxPatternLen}
 	}
+	delimPos := Position{Begin: 0, End: 1}
 
 	delim := source[0]
 	endDelim := delim
@@ -466,20 +631,20 @@ func (p *Parser) newPCRE(source string) (*RegexpPCRE, error) {
 	case '<':
 		endDelim = '>'
 	case '\\':
-		return nil, errors.New("'\\' is not a valid delimiter")
+		return nil, ParseError{Code: CodeInvalidDelimiter, Pos: delimPos, Message: "'\\' is not a valid delimiter"}
 	default:
 		if isSpace(delim) {
-			return nil, errors.New("whitespace is not a valid delimiter")
+			return nil, ParseError{Code: CodeInvalidDelimiter, Pos: delimPos, Message: "whitespace is not a valid delimiter"}
 		}
 		if isAlphanumeric(delim) {
-			return nil, errors.New("'" + string(delim) + "' is not a valid delimiter")
+			return nil, ParseError{Code: CodeInvalidDelimiter, Pos: delimPos, Message: "'" + string(delim) + "' is not a valid delimiter"}
 		}
 	}
 
 	const delimLen = 1
 	j := strings.LastIndexByte(source[delimLen:], endDelim)
 	if j == -1 {
-		return nil, errors.New("can't find '" + string(endDelim) + "' ending delimiter")
+		return nil, ParseError{Code: CodeMissingDelimiter, Pos: delimPos, Message: "can't find '" + string(endDelim) + "' ending delimiter"}
 	}
 	j += delimLen
 
@@ -493,11 +658,12 @@ func (p *Parser) newPCRE(source string) (*RegexpPCRE, error) {
 }
 
 var tok2op = [256]Operation{
-	tokDollar:     OpDollar,
-	tokCaret:      OpCaret,
-	tokDot:        OpDot,
-	tokChar:       OpChar,
-	tokMinus:      OpChar,
-	tokPosixClass: OpPosixClass,
-	tokComment:    OpComment,
+	tokDollar:      OpDollar,
+	tokCaret:       OpCaret,
+	tokDot:         OpDot,
+	tokChar:        OpChar,
+	tokMinus:       OpChar,
+	tokPosixClass:  OpPosixClass,
+	tokComment:     OpComment,
+	tokPlaceholder: OpPlaceholder,
 }
@@ -59,7 +59,7 @@ func writeExpr(t *testing.T, w *strings.Builder, re *Regexp, e Expr) {
 	}
 
 	switch e.Op {
-	case OpChar, OpString, OpPosixClass, OpDot, OpCaret, OpDollar, OpComment:
+	case OpChar, OpString, OpPosixClass, OpDot, OpCaret, OpDollar, OpComment, OpPlaceholder:
 		w.WriteString(e.Value)
 
 	case OpQuote:
@@ -144,6 +144,12 @@ func writeExpr(t *testing.T, w *strings.Builder, re *Regexp, e Expr) {
 		writeExpr(t, w, re, e.Args[0])
 		w.WriteByte(')')
 
+	case OpScriptRun:
+		assertEndPos(e, e.Args[0].End()+1)
+		w.WriteString(re.Pattern[e.Begin():e.Args[0].Begin()])
+		writeExpr(t, w, re, e.Args[0])
+		w.WriteByte(')')
+
 	case OpCapture, OpGroup, OpAtomicGroup, OpPositiveLookahead, OpNegativeLookahead, OpPositiveLookbehind, OpNegativeLookbehind:
 		assertEndPos(e, e.Args[0].End()+1)
 		w.WriteByte('(')
@@ -181,6 +187,9 @@ func writeExpr(t *testing.T, w *strings.Builder, re *Regexp, e Expr) {
 		writeExpr(t, w, re, e.Args[0])
 		writeExpr(t, w, re, e.Args[1])
 
+	case OpEmptyMatch:
+		assertEndPos(e, e.Begin())
+
 	case OpConcat:
 		assertBeginPos(e, e.Begin())
 		if len(e.Args) > 0 {
@@ -227,6 +236,8 @@ func TestWriteExpr(t *testing.T) {
 		pat string
 		o1  Operation
 		o2  Operation
+
+		placeholders bool
 	}{
 		{pat: `(?#?#)$`, o1: OpDollar, o2: OpComment},
 		{pat: `(foobar|baz)*+(?#the comment)`, o1: OpPossessive, o2: OpComment},
@@ -266,6 +277,12 @@ func TestWriteExpr(t *testing.T) {
 		{pat: `(?:(?>g2)g1(?=))`, o1: OpAtomicGroup, o2: OpPositiveLookahead},
 		{pat: `(?<=a)|(<!)`, o1: OpPositiveLookbehind, o2: OpNegativeLookbehind},
 		{pat: `(?<=)|(<!a)`, o1: OpPositiveLookbehind, o2: OpNegativeLookbehind},
+		{pat: `(*sr:\d+)x`, o1: OpScriptRun, o2: OpPlus},
+		{pat: `(*atomic_script_run:)|y`, o1: OpScriptRun, o2: OpAlt},
+		{pat: `(|x)`, o1: OpEmptyMatch, o2: OpCapture},
+		{pat: `x||y(?:)`, o1: OpEmptyMatch, o2: OpGroup},
+		{pat: `%s\.%[1]d`, o1: OpPlaceholder, o2: OpEscapeMeta, placeholders: true},
+		{pat: `[${chars}]{{.Suffix}}+`, o1: OpPlaceholder, o2: OpCharClass, placeholders: true},
 		{pat: `\s*\{weight=(\d+)\}\s(?!\s)*`, o1: OpNegativeLookahead},
 		{pat: `(?!x)[.?,!;:@#$%^&*()]+`, o1: OpNegativeLookahead},
 		{pat: `--(?<var_name>[\\w-]+?):\\s+?(?'var_val'.+?);`, o1: OpNamedCapture},
@@ -290,9 +307,14 @@ func TestWriteExpr(t *testing.T) {
 	}
 
 	p := NewParser(nil)
+	templateParser := NewParser(&ParserOptions{Placeholders: PlaceholdersAll})
 	for _, test := range tests {
 		pattern := "_" + test.pat + "_"
-		re, err := p.Parse(pattern)
+		parser := p
+		if test.placeholders {
+			parser = templateParser
+		}
+		re, err := parser.Parse(pattern)
 		if err != nil {
 			t.Fatalf("parse(%q): %v", test.pat, err)
 		}
@@ -390,11 +412,20 @@ func TestParser(t *testing.T) {
 		{`x(?P<x1>ab)y`, `{x (capture ab x1) y}`},
 		{`x(?<x12>ab)y`, `{x (capture ab x12) y}`},
 		{`x(?'x12'ab)y`, `{x (capture ab x12) y}`},
+		{`(?P=x)(?P<y>)`, `{(flags ?P=x) (capture {} y)}`},
+		{`(?P>x)(?P<y>)`, `{(flags ?P>x) (capture {} y)}`},
 
 		// Atomic groups. PCRE-only.
 		{`(?>)`, `(atomic {})`},
 		{`(?>foo)`, `(atomic foo)`},
 
+		// Script runs. PCRE-only.
+		{`(*sr:)`, `(script-run {})`},
+		{`(*sr:\d+)`, `(script-run (+ \d))`},
+		{`(*script_run:a|b)`, `(script-run (or a b))`},
+		{`(*asr:\w+)`, `(atomic-script-run (+ \w))`},
+		{`(*atomic_script_run:x)y`, `{(atomic-script-run x) y}`},
+
 		// Comments. PCRE-only.
 		{`a(?#)b`, `{a /*(?#)*/ b}`},
 		{`a(?#foo\)b`, `{a /*(?#foo\)*/ b}`},
@@ -540,6 +571,10 @@ func TestParser(t *testing.T) {
 		{`|x`, `(or {} x)`},
 		{`(|x|y)`, `(capture (or {} x y))`},
 		{`(?:|x)`, `(group (or {} x))`},
+		{`x||y`, `(or x {} y)`},
+		{`||x`, `(or {} {} x)`},
+		{`(a||)`, `(capture (or a {} {}))`},
+		{`(|)`, `(capture (or {} {}))`},
 
 		// More tests for char merging.
 		{`xy+`, `{x (+ y)}`},
@@ -566,6 +601,90 @@ func TestParser(t *testing.T) {
 	}
 }
 
+func TestParseBytes(t *testing.T) {
+	patterns := []string{
+		``,
+		`abc`,
+		`(?P<x>[a-z]+)|\d{2,}`,
+		`\Qa.b\E✓`,
+	}
+
+	p := NewParser(nil)
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		want := formatSyntax(re)
+
+		buf := []byte(pattern)
+		re, err = p.ParseBytes(buf)
+		if err != nil {
+			t.Fatalf("parseBytes(%q): %v", pattern, err)
+		}
+		if re.Pattern != pattern {
+			t.Errorf("parseBytes(%q): pattern mismatch: %q", pattern, re.Pattern)
+		}
+		have := formatSyntax(re)
+		if have != want {
+			t.Errorf("parseBytes(%q):\nhave: %s\nwant: %s", pattern, have, want)
+		}
+	}
+}
+
+func TestParserArena(t *testing.T) {
+	// Make the pattern big enough to require several arena chunks.
+	pattern := strings.Repeat(`(a|[b-c]+)`, 200)
+
+	p := NewParser(nil)
+	re, err := p.Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := formatSyntax(re)
+	clone := re.Clone()
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := p.Parse(pattern); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("parsing with a warmed up parser allocates: %v allocs", allocs)
+	}
+
+	if _, err := p.Parse(`x`); err != nil {
+		t.Fatal(err)
+	}
+	if have := formatSyntax(clone); have != want {
+		t.Errorf("cloned regexp is changed after reparse:\nhave: %s\nwant: %s", have, want)
+	}
+	re, err = p.Parse(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := formatSyntax(re); have != want {
+		t.Errorf("arena reuse result mismatch:\nhave: %s\nwant: %s", have, want)
+	}
+}
+
+func TestParserZeroValue(t *testing.T) {
+	var p Parser
+	for i := 0; i < 2; i++ {
+		re, err := p.Parse(`a(b|c)+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := formatSyntax(re), `{a (+ (capture (or b c)))}`; have != want {
+			t.Errorf("parse result mismatch:\nhave: %s\nwant: %s", have, want)
+		}
+		p.Reset()
+		if p.out.Pattern != "" || p.lexer.input != "" {
+			t.Errorf("Reset() did not clear pattern references")
+		}
+	}
+}
+
 func formatSyntax(re *Regexp) string {
 	return formatExprSyntax(re, re.Expr)
 }
@@ -601,6 +720,8 @@ func formatExprSyntax(re *Regexp, e Expr) string {
 		return fmt.Sprintf("[^%s]", formatArgsSyntax(re, e.Args))
 	case OpConcat:
 		return fmt.Sprintf("{%s}", formatArgsSyntax(re, e.Args))
+	case OpEmptyMatch:
+		return "{}"
 	case OpAlt:
 		return fmt.Sprintf("(or %s)", formatArgsSyntax(re, e.Args))
 	case OpCapture:
@@ -611,6 +732,11 @@ func formatExprSyntax(re *Regexp, e Expr) string {
 		return fmt.Sprintf("(group %s)", formatExprSyntax(re, e.Args[0]))
 	case OpAtomicGroup:
 		return fmt.Sprintf("(atomic %s)", formatExprSyntax(re, e.Args[0]))
+	case OpScriptRun:
+		if e.Form == FormScriptRunAtomic {
+			return fmt.Sprintf("(atomic-script-run %s)", formatExprSyntax(re, e.Args[0]))
+		}
+		return fmt.Sprintf("(script-run %s)", formatExprSyntax(re, e.Args[0]))
 	case OpGroupWithFlags:
 		return fmt.Sprintf("(group %s ?%s)", formatExprSyntax(re, e.Args[0]), e.Args[1].Value)
 	case OpFlagOnlyGroup:
@@ -635,6 +761,8 @@ func formatExprSyntax(re *Regexp, e Expr) string {
 		return fmt.Sprintf("(possessive %s)", formatExprSyntax(re, e.Args[0]))
 	case OpComment:
 		return fmt.Sprintf("/*%s*/", e.Value)
+	case OpPlaceholder:
+		return fmt.Sprintf("<%s>", e.Value)
 	default:
 		return fmt.Sprintf("<op=%d>", e.Op)
 	}
@@ -692,3 +820,61 @@ func BenchmarkParserStdlib(b *testing.B) {
 		})
 	}
 }
+
+func TestParserLatin1(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"\xe9x", `{` + "\xe9" + ` x}`},
+		{"é", `{` + "\xc3 \xa9" + `}`},
+		{"[\xff-\xfe]", `[` + "\xff-\xfe" + `]`},
+		{"\\\xff+", `(+ \` + "\xff" + `)`},
+	}
+
+	p := NewParser(&ParserOptions{Latin1: true, NoLiterals: true})
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := formatSyntax(re)
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %q\nwant: %q", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestParserPlaceholders(t *testing.T) {
+	tests := []struct {
+		syntax  PlaceholderSyntax
+		pattern string
+		want    string
+	}{
+		{PlaceholdersPrintf, `^%s$`, `{^ <%s> $}`},
+		{PlaceholdersPrintf, `%-8.3f|%[2]*d|%+v`, `(or <%-8.3f> <%[2]*d> <%+v>)`},
+		{PlaceholdersPrintf, `100%%`, `{100 <%%>}`},
+		{PlaceholdersPrintf, `[%s\d]+`, `(+ [<%s> \d])`},
+		{PlaceholdersPrintf, `(%q)?`, `(? (capture <%q>))`},
+		{PlaceholdersPrintf, `5%`, `5%`},
+		{PlaceholdersPrintf, `%y`, `%y`},
+		{PlaceholdersPrintf, `${x}{{x}}`, `{$ {x}{{x}}}`},
+		{PlaceholdersDollar, `${name}\.${ext}`, `{<${name}> \. <${ext}>}`},
+		{PlaceholdersDollar, `a${}`, `{a $ {}}`},
+		{PlaceholdersTemplate, `{{.Host}}(:\d+)?`, `{<{{.Host}}> (? (capture {: (+ \d)}))}`},
+		{PlaceholdersTemplate, `x{{`, `x{{`},
+		{PlaceholdersAll, `%s/${a}/{{b}}`, `{<%s> / <${a}> / <{{b}}>}`},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&ParserOptions{Placeholders: test.syntax})
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := formatSyntax(re)
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -10,19 +10,21 @@ func TestParserErrorsPCRE(t *testing.T) {
 		pattern string
 		want    string
 	}{
-		{``, `empty pattern: can't find delimiters`},
-		{`aba`, `'a' is not a valid delimiter`},
-		{` aa `, `whitespace is not a valid delimiter`},
-		{`/abc`, `can't find '/' ending delimiter`},
-		{`#abc`, `can't find '#' ending delimiter`},
+		{``, `RE1010 at 0-0: empty pattern: can't find delimiters`},
+		{`aba`, `RE1010 at 0-1: 'a' is not a valid delimiter`},
+		{` aa `, `RE1010 at 0-1: whitespace is not a valid delimiter`},
+		{`\a\`, `RE1010 at 0-1: '\' is not a valid delimiter`},
+		{`/abc`, `RE1011 at 0-1: can't find '/' ending delimiter`},
+		{`#abc`, `RE1011 at 0-1: can't find '#' ending delimiter`},
+		{`/a b/ix`, `RE1012 at 6-7: 'x' modifier is not supported`},
 	}
 
 	p := NewParser(nil)
 	for _, test := range tests {
 		_, err := p.ParsePCRE(test.pattern)
 		have := "<nil>"
-		if err != nil {
-			have = err.Error()
+		if err, ok := err.(ParseError); ok {
+			have = fmt.Sprintf("%s at %d-%d: %v", err.Code, err.Pos.Begin, err.Pos.End, err)
 		}
 		if have != test.want {
 			t.Errorf("parse(%q):\nhave: %s\nwant: %s",
@@ -0,0 +1,402 @@
+package pikevm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/charset"
+	"github.com/quasilyte/regex/syntax/internal/thompson"
+)
+
+// ErrTooBig is returned for the patterns which program is too big.
+var ErrTooBig = errors.New("pattern program is too big")
+
+// maxStates limits the compiled program size.
+const maxStates = 10000
+
+// BacktrackingError is returned for the patterns that
+// can't be matched without backtracking.
+type BacktrackingError struct {
+	Expr    syntax.Expr
+	Feature analysis.Feature
+}
+
+func (e *BacktrackingError) Error() string {
+	return e.Expr.Value + ": " + e.Feature.String() + " requires a backtracking engine"
+}
+
+type edgeKind byte
+
+const (
+	edgeEpsilon edgeKind = iota
+	edgeRunes
+	edgeSave   // Records the current position in the slot
+	edgeAssert // Taken only if the cond holds at the current position
+)
+
+type edge struct {
+	kind  edgeKind
+	runes charset.RuneSet
+	slot  int
+	cond  cond
+	to    int
+}
+
+// cond is a set of the empty-width conditions.
+type cond byte
+
+const (
+	condBeginText cond = 1 << iota
+	condEndText
+	condBeginLine
+	condEndLine
+	condWordBoundary
+	condNoWordBoundary
+)
+
+// program is a Thompson automaton with the capture slots.
+//
+// The edges of every state are ordered by priority.
+// A state with a runes edge has no other edges,
+// such states and the match state are the thread states.
+type program struct {
+	states [][]edge
+	start  int
+	match  int
+	slots  int
+}
+
+type bailout struct {
+	err error
+}
+
+func compile(re *syntax.Regexp, newline syntax.Newline) (prog *program, err error) {
+	b := builder{
+		prog:     &program{},
+		newline:  newline,
+		captures: make(map[syntax.Position]int),
+	}
+	defer func() {
+		r := recover()
+		if r, ok := r.(bailout); ok {
+			prog = nil
+			err = r.err
+			return
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	index := 0
+	walkCaptures(re.Expr, func(e syntax.Expr) {
+		index++
+		b.captures[e.Pos] = index
+	})
+
+	prog = b.prog
+	prog.slots = 2 * (index + 1)
+	prog.match = b.newState()
+	in, out, _ := b.build(re.Expr, 0)
+	prog.start = b.newState()
+	b.addEdge(prog.start, edge{kind: edgeSave, slot: 0, to: in})
+	b.addEdge(out, edge{kind: edgeSave, slot: 1, to: prog.match})
+	return prog, nil
+}
+
+func walkCaptures(e syntax.Expr, visit func(syntax.Expr)) {
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		visit(e)
+	}
+	for _, a := range e.Args {
+		walkCaptures(a, visit)
+	}
+}
+
+type builder struct {
+	prog     *program
+	newline  syntax.Newline
+	captures map[syntax.Position]int
+}
+
+func (b *builder) newState() int {
+	if len(b.prog.states) >= maxStates {
+		panic(bailout{err: ErrTooBig})
+	}
+	b.prog.states = append(b.prog.states, nil)
+	return len(b.prog.states) - 1
+}
+
+func (b *builder) addEdge(from int, e edge) {
+	b.prog.states[from] = append(b.prog.states[from], e)
+}
+
+// build adds the e states to the program and returns its entry and exit states.
+// flags are the flags that are active at e, the flags that are active
+// after e are returned.
+func (b *builder) build(e syntax.Expr, flags syntax.Flags) (in, out int, after syntax.Flags) {
+	if f, ok := analysis.ExprFeature(e); ok {
+		switch f {
+		case analysis.FeatureBackreference, analysis.FeatureRecursion,
+			analysis.FeatureLookahead, analysis.FeatureLookbehind,
+			analysis.FeatureAtomicGroup, analysis.FeaturePossessive:
+			panic(bailout{err: &BacktrackingError{Expr: e, Feature: f}})
+		}
+	}
+
+	switch e.Op {
+	case syntax.OpConcat, syntax.OpLiteral, syntax.OpEmptyMatch:
+		in = b.newState()
+		out = in
+		for _, a := range e.Args {
+			argIn, argOut, argFlags := b.build(a, flags)
+			b.addEdge(out, edge{to: argIn})
+			out = argOut
+			flags = argFlags
+		}
+		return in, out, flags
+
+	case syntax.OpAlt:
+		in = b.newState()
+		out = b.newState()
+		for _, a := range e.Args {
+			branchIn, branchOut, branchFlags := b.build(a, flags)
+			b.addEdge(in, edge{to: branchIn})
+			b.addEdge(branchOut, edge{to: out})
+			flags = branchFlags
+		}
+		return in, out, flags
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat:
+		in, out = b.buildQuantifier(e, flags, flags&syntax.FlagUngreedy == 0)
+		return in, out, flags
+	case syntax.OpNonGreedy:
+		in, out = b.buildQuantifier(e.Args[0], flags, flags&syntax.FlagUngreedy != 0)
+		return in, out, flags
+
+	case syntax.OpCapture, syntax.OpNamedCapture:
+		slot := 2 * b.captures[e.Pos]
+		bodyIn, bodyOut, _ := b.build(e.Args[0], flags)
+		in = b.newState()
+		out = b.newState()
+		b.addEdge(in, edge{kind: edgeSave, slot: slot, to: bodyIn})
+		b.addEdge(bodyOut, edge{kind: edgeSave, slot: slot + 1, to: out})
+		return in, out, flags
+	case syntax.OpGroup:
+		in, out, _ = b.build(e.Args[0], flags)
+		return in, out, flags
+	case syntax.OpGroupWithFlags:
+		in, out, _ = b.build(e.Args[0], b.applyFlags(e.Args[1], flags))
+		return in, out, flags
+	case syntax.OpFlagOnlyGroup:
+		in = b.newState()
+		return in, in, b.applyFlags(e.Args[0], flags)
+
+	case syntax.OpComment:
+		in = b.newState()
+		return in, in, flags
+
+	case syntax.OpDot:
+		runes := charset.Dot(b.newline)
+		if flags&syntax.FlagDotAll != 0 {
+			runes = charset.Any
+		}
+		in, out = b.buildRunes(runes)
+		return in, out, flags
+
+	case syntax.OpCaret:
+		if flags&syntax.FlagMultiline != 0 {
+			in, out = b.buildAssert(condBeginLine)
+		} else {
+			in, out = b.buildAssert(condBeginText)
+		}
+		return in, out, flags
+	case syntax.OpDollar:
+		if flags&syntax.FlagMultiline != 0 {
+			in, out = b.buildAssert(condEndLine)
+		} else {
+			in, out = b.buildAssert(condEndText)
+		}
+		return in, out, flags
+
+	case syntax.OpQuote:
+		in = b.newState()
+		out = in
+		for _, ch := range e.QuotedLiteral() {
+			runes := charset.Of(ch)
+			if flags&syntax.FlagCaseInsensitive != 0 {
+				runes = runes.Fold()
+			}
+			chIn, chOut := b.buildRunes(runes)
+			b.addEdge(out, edge{to: chIn})
+			out = chOut
+		}
+		return in, out, flags
+	}
+
+	switch e.Value {
+	case `\A`:
+		in, out = b.buildAssert(condBeginText)
+		return in, out, flags
+	case `\z`:
+		in, out = b.buildAssert(condEndText)
+		return in, out, flags
+	case `\b`:
+		in, out = b.buildAssert(condWordBoundary)
+		return in, out, flags
+	case `\B`:
+		in, out = b.buildAssert(condNoWordBoundary)
+		return in, out, flags
+	}
+	fold := charset.FoldNone
+	if flags&syntax.FlagCaseInsensitive != 0 {
+		fold = charset.FoldSimple
+	}
+	runes, ok := charset.RE2.FromExprFold(e, fold)
+	if !ok {
+		panic(bailout{err: fmt.Errorf("%s: unsupported by the Pike VM", e.Value)})
+	}
+	in, out = b.buildRunes(runes)
+	return in, out, flags
+}
+
+// applyFlags returns the flags changed by the flags string expression.
+func (b *builder) applyFlags(e syntax.Expr, flags syntax.Flags) syntax.Flags {
+	enable, disable, err := syntax.ParseFlags(e.Value, syntax.DialectRE2)
+	if err != nil {
+		panic(bailout{err: fmt.Errorf("%s: %v", e.Value, err)})
+	}
+	return (flags | enable) &^ disable
+}
+
+// buildQuantifier builds a star, plus, question or repeat expression.
+//
+// The greedy quantifiers prefer to repeat their body,
+// the non-greedy ones prefer to leave it.
+func (b *builder) buildQuantifier(e syntax.Expr, flags syntax.Flags, greedy bool) (in, out int) {
+	switch e.Op {
+	case syntax.OpStar:
+		return b.buildStar(e.Args[0], flags, greedy)
+	case syntax.OpPlus:
+		return b.buildPlus(e.Args[0], flags, greedy)
+	case syntax.OpQuestion:
+		return b.buildOptional(e.Args[0], flags, greedy)
+	default:
+		return b.buildRepeat(e, flags, greedy)
+	}
+}
+
+// addChoice adds the from->body and from->exit edges,
+// greedy selects which one is tried first.
+func (b *builder) addChoice(from, body, exit int, greedy bool) {
+	if greedy {
+		b.addEdge(from, edge{to: body})
+		b.addEdge(from, edge{to: exit})
+	} else {
+		b.addEdge(from, edge{to: exit})
+		b.addEdge(from, edge{to: body})
+	}
+}
+
+func (b *builder) buildStar(e syntax.Expr, flags syntax.Flags, greedy bool) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	bodyIn, bodyOut, _ := b.build(e, flags)
+	b.addChoice(in, bodyIn, out, greedy)
+	if b.nullable(bodyIn, bodyOut) {
+		// Like Go regexp, build x* as (x+)? to get the right
+		// captures when the body matches an empty string.
+		b.addChoice(bodyOut, bodyIn, out, greedy)
+		return in, out
+	}
+	b.addEdge(bodyOut, edge{to: in})
+	return in, out
+}
+
+func (b *builder) buildPlus(e syntax.Expr, flags syntax.Flags, greedy bool) (in, out int) {
+	in, bodyOut, _ := b.build(e, flags)
+	out = b.newState()
+	b.addChoice(bodyOut, in, out, greedy)
+	return in, out
+}
+
+func (b *builder) buildOptional(e syntax.Expr, flags syntax.Flags, greedy bool) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	bodyIn, bodyOut, _ := b.build(e, flags)
+	b.addChoice(in, bodyIn, out, greedy)
+	b.addEdge(bodyOut, edge{to: out})
+	return in, out
+}
+
+func (b *builder) buildRepeat(e syntax.Expr, flags syntax.Flags, greedy bool) (in, out int) {
+	// Too big counts are saturated to maxStates,
+	// so they're rejected by the builder.
+	min, max := thompson.RepeatBounds(e.Args[1].Value, maxStates)
+	in = b.newState()
+	out = in
+	appendPart := func(partIn, partOut int) {
+		b.addEdge(out, edge{to: partIn})
+		out = partOut
+	}
+	copies := min
+	if max == -1 && min != 0 {
+		// x{2,} is xx+, like Go regexp simplifies it.
+		copies--
+	}
+	for i := 0; i < copies; i++ {
+		partIn, partOut, _ := b.build(e.Args[0], flags)
+		appendPart(partIn, partOut)
+	}
+	switch {
+	case max == -1 && min == 0:
+		appendPart(b.buildStar(e.Args[0], flags, greedy))
+	case max == -1:
+		appendPart(b.buildPlus(e.Args[0], flags, greedy))
+	default:
+		for i := min; i < max; i++ {
+			appendPart(b.buildOptional(e.Args[0], flags, greedy))
+		}
+	}
+	return in, out
+}
+
+// nullable reports whether the out state is reachable
+// from the in state without consuming the input.
+func (b *builder) nullable(in, out int) bool {
+	seen := make(map[int]bool)
+	stack := []int{in}
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if s == out {
+			return true
+		}
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		for _, e := range b.prog.states[s] {
+			if e.kind != edgeRunes {
+				stack = append(stack, e.to)
+			}
+		}
+	}
+	return false
+}
+
+func (b *builder) buildRunes(runes charset.RuneSet) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	b.addEdge(in, edge{kind: edgeRunes, runes: runes, to: out})
+	return in, out
+}
+
+func (b *builder) buildAssert(c cond) (in, out int) {
+	in = b.newState()
+	out = b.newState()
+	b.addEdge(in, edge{kind: edgeAssert, cond: c, to: out})
+	return in, out
+}
@@ -0,0 +1,258 @@
+// Package pikevm implements a Pike VM submatch engine.
+//
+// The Pike VM simulates the pattern automaton for all threads at once,
+// every thread carries its own capture positions. The threads are kept
+// in the priority order, so the results follow the leftmost-first
+// (Perl) semantics, like in Go regexp. The matching takes
+// O(len(s) * program size) time and never backtracks.
+//
+// Only the RE2-compatible subset of the syntax is supported, see Compile.
+// Use New to get a backtracking engine for the other patterns.
+package pikevm
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Options configure the Machine.
+type Options struct {
+	// Newline is a line terminator convention for `.`.
+	// See syntax.Newline and charset.Dot for details.
+	// The `m` flag `^` and `$` only recognize `\n`, like RE2 does.
+	Newline syntax.Newline
+}
+
+// Engine finds the leftmost-first match along with its submatches.
+type Engine interface {
+	// FindStringSubmatchIndex returns the match and the capture groups
+	// locations, like regexp.Regexp.FindStringSubmatchIndex does.
+	FindStringSubmatchIndex(s string) []int
+}
+
+// Backtracker compiles the patterns that the Pike VM doesn't support.
+type Backtracker func(re *syntax.Regexp) (Engine, error)
+
+// New returns a Machine for re if it can be matched without
+// backtracking, otherwise re is compiled by the backtracker.
+//
+// If the backtracker is nil, the Compile error is returned.
+// Errors that are not a BacktrackingError are always returned.
+func New(re *syntax.Regexp, opts *Options, backtracker Backtracker) (Engine, error) {
+	m, err := Compile(re, opts)
+	if err == nil {
+		return m, nil
+	}
+	if _, ok := err.(*BacktrackingError); ok && backtracker != nil {
+		return backtracker(re)
+	}
+	return nil, err
+}
+
+// Machine is a compiled Pike VM program.
+//
+// It's safe for concurrent use.
+type Machine struct {
+	prog *program
+	pool sync.Pool
+}
+
+// Compile returns a Machine for re.
+//
+// The RE2 flags `imsU` are supported. `^` and `$` match at the input
+// boundaries, `\b` and `\B` use the ASCII word chars.
+// Backreferences, lookarounds, atomic groups, possessive quantifiers
+// and recursion are rejected with a BacktrackingError. Other unsupported
+// constructions, like `\G` or the script runs, are rejected with a plain error.
+func Compile(re *syntax.Regexp, opts *Options) (*Machine, error) {
+	var newline syntax.Newline
+	if opts != nil {
+		newline = opts.Newline
+	}
+	prog, err := compile(re, newline)
+	if err != nil {
+		return nil, err
+	}
+	return &Machine{prog: prog}, nil
+}
+
+// NumSubexp returns the number of the capture groups.
+func (m *Machine) NumSubexp() int {
+	return m.prog.slots/2 - 1
+}
+
+// MatchString reports whether s contains a match.
+func (m *Machine) MatchString(s string) bool {
+	return m.run(s, false) != nil
+}
+
+// FindStringSubmatchIndex returns the leftmost-first match location
+// followed by the capture groups locations. The groups that didn't
+// participate in the match have -1 locations. If there is no match,
+// the result is nil.
+func (m *Machine) FindStringSubmatchIndex(s string) []int {
+	return m.run(s, true)
+}
+
+// queue is a sparse set of the threads, ordered by priority.
+type queue struct {
+	sparse []int
+	dense  []int
+	caps   [][]int
+}
+
+func newQueue(size int) *queue {
+	return &queue{
+		sparse: make([]int, size),
+		dense:  make([]int, 0, size),
+		caps:   make([][]int, size),
+	}
+}
+
+func (q *queue) contains(state int) bool {
+	i := q.sparse[state]
+	return i < len(q.dense) && q.dense[i] == state
+}
+
+func (q *queue) insert(state int) {
+	q.sparse[state] = len(q.dense)
+	q.dense = append(q.dense, state)
+}
+
+func (q *queue) clear() {
+	q.dense = q.dense[:0]
+}
+
+// vm is the matching state, it's reused between the runs.
+type vm struct {
+	clist *queue
+	nlist *queue
+	caps  []int
+}
+
+func (m *Machine) getVM() *vm {
+	if v, ok := m.pool.Get().(*vm); ok {
+		return v
+	}
+	size := len(m.prog.states)
+	return &vm{
+		clist: newQueue(size),
+		nlist: newQueue(size),
+		caps:  make([]int, m.prog.slots),
+	}
+}
+
+// run returns the leftmost-first match slots or nil.
+// If submatches is false, it stops at the first found match.
+func (m *Machine) run(s string, submatches bool) []int {
+	v := m.getVM()
+	defer m.pool.Put(v)
+	v.clist.clear()
+	v.nlist.clear()
+
+	var matched []int
+	prev := rune(-1)
+	for pos := 0; ; {
+		if matched == nil {
+			for i := range v.caps {
+				v.caps[i] = -1
+			}
+			m.add(v.clist, m.prog.start, pos, v.caps, conds(prev, s, pos))
+		}
+		if len(v.clist.dense) == 0 {
+			break
+		}
+
+		ch, size := rune(-1), 0
+		if pos < len(s) {
+			ch, size = utf8.DecodeRuneInString(s[pos:])
+		}
+		next := conds(ch, s, pos+size)
+		for _, state := range v.clist.dense {
+			caps := v.clist.caps[state]
+			if state == m.prog.match {
+				matched = append(matched[:0], caps...)
+				if !submatches {
+					return matched
+				}
+				// The rest of the threads have a lower priority.
+				break
+			}
+			e := m.prog.states[state][0]
+			if ch != -1 && e.runes.Contains(ch) {
+				m.add(v.nlist, e.to, pos+size, caps, next)
+			}
+		}
+		if pos >= len(s) {
+			break
+		}
+		v.clist, v.nlist = v.nlist, v.clist
+		v.nlist.clear()
+		prev = ch
+		pos += size
+	}
+	return matched
+}
+
+// add adds the state thread and the threads that are reachable
+// from it without consuming the input, in the priority order.
+func (m *Machine) add(q *queue, state, pos int, caps []int, c cond) {
+	if q.contains(state) {
+		return
+	}
+	q.insert(state)
+	edges := m.prog.states[state]
+	if state == m.prog.match || (len(edges) != 0 && edges[0].kind == edgeRunes) {
+		q.caps[state] = append(q.caps[state][:0], caps...)
+		return
+	}
+	for _, e := range edges {
+		switch e.kind {
+		case edgeEpsilon:
+			m.add(q, e.to, pos, caps, c)
+		case edgeAssert:
+			if c&e.cond != 0 {
+				m.add(q, e.to, pos, caps, c)
+			}
+		case edgeSave:
+			old := caps[e.slot]
+			caps[e.slot] = pos
+			m.add(q, e.to, pos, caps, c)
+			caps[e.slot] = old
+		}
+	}
+}
+
+// conds returns the conditions that hold between the prev rune
+// and the rune at s[pos:]. A -1 prev means the input start.
+func conds(prev rune, s string, pos int) cond {
+	next := rune(-1)
+	if pos < len(s) {
+		next, _ = utf8.DecodeRuneInString(s[pos:])
+	}
+	var c cond
+	if prev == -1 {
+		c |= condBeginText | condBeginLine
+	}
+	if prev == '\n' {
+		c |= condBeginLine
+	}
+	if next == -1 {
+		c |= condEndText | condEndLine
+	}
+	if next == '\n' {
+		c |= condEndLine
+	}
+	if isWordChar(prev) != isWordChar(next) {
+		c |= condWordBoundary
+	} else {
+		c |= condNoWordBoundary
+	}
+	return c
+}
+
+func isWordChar(ch rune) bool {
+	return ch == '_' || ('0' <= ch && ch <= '9') || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
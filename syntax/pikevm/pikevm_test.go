@@ -0,0 +1,162 @@
+package pikevm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/regextest"
+)
+
+func TestFindStringSubmatchIndex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		inputs  []string
+	}{
+		{`a`, []string{``, `a`, `ba`, `bbb`}},
+		{`(a+)(b*)`, []string{`aab`, `xaabbb`, `b`}},
+		{`(a|ab)(c|bcd)(d*)`, []string{`abcd`, `abcdd`}},
+		{`(a*)*`, []string{`b`, `aaa`}},
+		{`(a*)+b`, []string{`aab`, `b`}},
+		{`(a|b)*?c`, []string{`abac`, `c`}},
+		{`x(?P<name>\d{2,3})y?`, []string{`x1234y`, `x12y`, `x1y`}},
+		{`^(\w+)\s*=\s*(.*)$`, []string{`key = value`, ` key=v`, "k=v\n"}},
+		{`(?m)^(\d+)$`, []string{"a\n12\nb", "12"}},
+		{`(?i)(K+)(é)`, []string{`xkKÉ`, "\u212ak\u00e9"}},
+		{`(?s)a.b|a.c`, []string{"a\nb", "a\nc"}},
+		{`(?U)(a+)(a*)`, []string{`aaaa`}},
+		{`(?U)(a+?)(a*)`, []string{`aaaa`}},
+		{`\bfoo\b|(\Bbar)`, []string{`foobar`, `a foo`, `xbar`}},
+		{`(a)|(b)|(c)`, []string{`c`, `b`, `zz`}},
+		{`(\d{2}){2}`, []string{`12345`}},
+		{`\Q.+\E(x)`, []string{`a.+x`, `aax`}},
+		{`[^\d\s](?:-|_)?`, []string{`1 -a-`, `_`}},
+		{`(a){0}b`, []string{`ab`}},
+		{``, []string{``, `abc`}},
+		{`(|a)+`, []string{`aa`}},
+		{`(a?)((ab)?)(b?)`, []string{`ab`}},
+	}
+
+	for _, test := range tests {
+		re, err := syntax.NewParser(nil).Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		m, err := Compile(re, nil)
+		if err != nil {
+			t.Fatalf("compile(%q): %v", test.pattern, err)
+		}
+		std := regexp.MustCompile(test.pattern)
+		if m.NumSubexp() != std.NumSubexp() {
+			t.Errorf("%q: NumSubexp:\nhave: %d\nwant: %d", test.pattern, m.NumSubexp(), std.NumSubexp())
+		}
+		for _, s := range test.inputs {
+			have := fmt.Sprint(m.FindStringSubmatchIndex(s))
+			want := fmt.Sprint(std.FindStringSubmatchIndex(s))
+			if have != want {
+				t.Errorf("%q on %q:\nhave: %s\nwant: %s", test.pattern, s, have, want)
+			}
+			if m.MatchString(s) != std.MatchString(s) {
+				t.Errorf("%q on %q: MatchString mismatch", test.pattern, s)
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		pattern      string
+		backtracking bool
+	}{
+		{`(a)\1`, true},
+		{`a(?=b)`, true},
+		{`(?<!a)b`, true},
+		{`(?>a+)`, true},
+		{`a++`, true},
+		{`(?R)`, true},
+		{`\Ga`, false},
+		{`(*sr:\w+)`, false},
+		{`(?x)a`, false},
+	}
+
+	for _, test := range tests {
+		re, err := syntax.NewParser(nil).Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = Compile(re, nil)
+		if err == nil {
+			t.Errorf("compile(%q): expected an error", test.pattern)
+			continue
+		}
+		if _, ok := err.(*BacktrackingError); ok != test.backtracking {
+			t.Errorf("compile(%q): %v: have backtracking=%v, want %v", test.pattern, err, ok, test.backtracking)
+		}
+	}
+}
+
+type fakeEngine struct{}
+
+func (fakeEngine) FindStringSubmatchIndex(s string) []int { return nil }
+
+func TestNew(t *testing.T) {
+	backtracker := func(re *syntax.Regexp) (Engine, error) {
+		return fakeEngine{}, nil
+	}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(a)b`, `*pikevm.Machine`},
+		{`(a)\1`, `pikevm.fakeEngine`},
+		{`\Ga`, `error`},
+	}
+
+	for _, test := range tests {
+		re, err := syntax.NewParser(nil).Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have := "error"
+		if e, err := New(re, nil, backtracker); err == nil {
+			have = fmt.Sprintf("%T", e)
+		}
+		if have != test.want {
+			t.Errorf("New(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+
+	re, _ := syntax.NewParser(nil).Parse(`(a)\1`)
+	if _, err := New(re, nil, nil); err == nil {
+		t.Errorf("New without a backtracker: expected an error")
+	}
+}
+
+func TestGeneratedPatterns(t *testing.T) {
+	inputs := []string{``, `a`, `ab`, `abc`, `aab1`, "x\ny", `A_b 9-`, `ёж`}
+	g := regextest.NewGenerator(1, regextest.RE2)
+	p := syntax.NewParser(nil)
+	for i := 0; i < 2000; i++ {
+		pattern := g.Pattern()
+		std, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		re, err := p.Parse(pattern)
+		if err != nil {
+			continue
+		}
+		m, err := Compile(re, nil)
+		if err != nil {
+			continue
+		}
+		for _, s := range inputs {
+			have := fmt.Sprint(m.FindStringSubmatchIndex(s))
+			want := fmt.Sprint(std.FindStringSubmatchIndex(s))
+			if have != want {
+				t.Errorf("%q on %q:\nhave: %s\nwant: %s", pattern, s, have, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package syntax
+
+import (
+	"strings"
+)
+
+// PlaceholderSyntax is a set of template placeholder syntaxes.
+//
+// Patterns are often built with fmt.Sprintf or a template engine,
+// so their source text contains markers that are substituted
+// before the pattern is compiled. The recognized markers are
+// parsed as OpPlaceholder nodes, so the rest of the pattern
+// can still be analyzed.
+type PlaceholderSyntax byte
+
+const (
+	// PlaceholdersPrintf recognizes fmt.Sprintf verbs,
+	// like `%s`, `%d`, `%-8v`, `%[2]q` and `%%`.
+	PlaceholdersPrintf PlaceholderSyntax = 1 << iota
+
+	// PlaceholdersDollar recognizes `${name}` interpolations
+	// of the shell, JS template literals and alike.
+	PlaceholdersDollar
+
+	// PlaceholdersTemplate recognizes `{{.Var}}` actions
+	// of text/template, Mustache, Jinja and alike.
+	PlaceholdersTemplate
+
+	// PlaceholdersAll recognizes all supported syntaxes.
+	PlaceholdersAll = PlaceholdersPrintf | PlaceholdersDollar | PlaceholdersTemplate
+)
+
+// printfVerbs lists the fmt verb letters.
+const printfVerbs = "bcdeEfFgGoOpqstTUvxX"
+
+// placeholderWidth returns the size of a placeholder that starts
+// at pos or 0 if there is no placeholder.
+func (l *lexer) placeholderWidth(pos int) int {
+	s := l.input[pos:]
+	switch {
+	case s[0] == '%' && l.placeholders&PlaceholdersPrintf != 0:
+		return printfVerbWidth(s)
+	case strings.HasPrefix(s, "${") && l.placeholders&PlaceholdersDollar != 0:
+		if j := strings.IndexByte(s, '}'); j > len("${") {
+			return j + len("}")
+		}
+	case strings.HasPrefix(s, "{{") && l.placeholders&PlaceholdersTemplate != 0:
+		if j := strings.Index(s[len("{{"):], "}}"); j > 0 {
+			return len("{{") + j + len("}}")
+		}
+	}
+	return 0
+}
+
+// printfVerbWidth returns the size of a fmt verb at the s start,
+// like `%s`, `%-8.3f` or `%[2]*d`. It returns 0 for a lone `%`.
+func printfVerbWidth(s string) int {
+	if strings.HasPrefix(s, "%%") {
+		return len("%%")
+	}
+	i := len("%")
+	for i < len(s) && strings.IndexByte("+-# 0", s[i]) != -1 {
+		i++
+	}
+	i = skipPrintfNumber(s, i)
+	if i < len(s) && s[i] == '.' {
+		i = skipPrintfNumber(s, i+len("."))
+	}
+	i = skipPrintfArgIndex(s, i)
+	if i < len(s) && strings.IndexByte(printfVerbs, s[i]) != -1 {
+		return i + 1
+	}
+	return 0
+}
+
+// skipPrintfNumber skips a width or a precision: digits or `*`,
+// optionally preceded by an explicit argument index.
+func skipPrintfNumber(s string, i int) int {
+	i = skipPrintfArgIndex(s, i)
+	if i < len(s) && s[i] == '*' {
+		return i + 1
+	}
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// skipPrintfArgIndex skips an explicit `[n]` argument index.
+func skipPrintfArgIndex(s string, i int) int {
+	if i >= len(s) || s[i] != '[' {
+		return i
+	}
+	j := i + len("[")
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	if j == i+len("[") || j >= len(s) || s[j] != ']' {
+		return i
+	}
+	return j + len("]")
+}
@@ -0,0 +1,178 @@
+// Package playground bundles the pattern analyses into a single
+// JSON-based entry point, suitable for the WASM builds.
+package playground
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/explain"
+)
+
+// Options are decoded from the Analyze options JSON.
+type Options struct {
+	// Dialect is a compatibility check target, "RE2" (default) or "PCRE".
+	Dialect string `json:"dialect"`
+
+	// Flags are enabled outside of the pattern, like `ix`.
+	Flags string `json:"flags"`
+}
+
+// Result is encoded as the Analyze result JSON.
+type Result struct {
+	// Error is set if the pattern can't be parsed,
+	// other fields are empty in this case.
+	Error *Diagnostic `json:"error,omitempty"`
+
+	Tree       *Node        `json:"tree,omitempty"`
+	Warnings   []Diagnostic `json:"warnings,omitempty"`
+	Captures   []Capture    `json:"captures,omitempty"`
+	Complexity int          `json:"complexity,omitempty"`
+	Compat     *Compat      `json:"compat,omitempty"`
+}
+
+// Diagnostic is a message about the pattern[Begin:End] part.
+type Diagnostic struct {
+	// Code is a syntax.Code string, like "RE1001".
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Begin   int    `json:"begin"`
+	End     int    `json:"end"`
+}
+
+// Node is a pattern AST node.
+type Node struct {
+	Op          string  `json:"op"`
+	Begin       int     `json:"begin"`
+	End         int     `json:"end"`
+	Value       string  `json:"value"`
+	Description string  `json:"description,omitempty"`
+	Args        []*Node `json:"args,omitempty"`
+}
+
+// Capture is a named capture group, see explain.Capture.
+type Capture struct {
+	Name    string `json:"name"`
+	Index   int    `json:"index"`
+	Matches string `json:"matches"`
+	Doc     string `json:"doc,omitempty"`
+}
+
+// Compat describes the pattern compatibility with the target dialect.
+type Compat struct {
+	Dialect string `json:"dialect"`
+
+	// Unsupported lists the used features that the dialect lacks.
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// Analyze parses the pattern and returns the JSON-encoded Result.
+//
+// optionsJSON is a JSON-encoded Options, it can be empty.
+// Pattern errors are reported inside the Result,
+// an error is returned only for the invalid options.
+func Analyze(pattern, optionsJSON string) (string, error) {
+	var opts Options
+	if optionsJSON != "" {
+		if err := json.Unmarshal([]byte(optionsJSON), &opts); err != nil {
+			return "", fmt.Errorf("decode options: %v", err)
+		}
+	}
+	result, err := Inspect(pattern, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(result); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Inspect is like Analyze, but it works with the decoded values.
+func Inspect(pattern string, opts Options) (*Result, error) {
+	dialect, flags, err := opts.decode()
+	if err != nil {
+		return nil, err
+	}
+	return analyze(pattern, dialect, flags), nil
+}
+
+func (opts Options) decode() (syntax.Dialect, syntax.Flags, error) {
+	dialect := syntax.DialectRE2
+	switch opts.Dialect {
+	case "", "RE2":
+	case "PCRE":
+		dialect = syntax.DialectPCRE
+	default:
+		return 0, 0, fmt.Errorf("unknown dialect: %s", opts.Dialect)
+	}
+	flags, _, err := syntax.ParseFlags(opts.Flags, syntax.DialectPCRE)
+	if err != nil {
+		return 0, 0, fmt.Errorf("flags: %v", err)
+	}
+	return dialect, flags, nil
+}
+
+func analyze(pattern string, dialect syntax.Dialect, flags syntax.Flags) *Result {
+	p := syntax.NewParser(nil)
+	re, err := p.Parse(pattern)
+	if err != nil {
+		d := &Diagnostic{Message: err.Error(), End: len(pattern)}
+		if err, ok := err.(syntax.ParseError); ok {
+			d.Code = err.Code.String()
+			d.Begin, d.End = int(err.Pos.Begin), int(err.Pos.End)
+		}
+		return &Result{Error: d}
+	}
+
+	result := &Result{
+		Tree:       newNode(re.Expr),
+		Complexity: analysis.Complexity(re).Total,
+		Compat:     &Compat{Dialect: dialect.String()},
+	}
+	for _, w := range re.Warnings {
+		result.Warnings = append(result.Warnings, Diagnostic{
+			Code:    w.Code.String(),
+			Message: w.Message,
+			Begin:   int(w.Pos.Begin),
+			End:     int(w.Pos.End),
+		})
+	}
+	for _, c := range explain.Captures(re, flags) {
+		result.Captures = append(result.Captures, Capture{
+			Name:    c.Name,
+			Index:   c.Index,
+			Matches: c.Matches,
+			Doc:     c.Doc,
+		})
+	}
+	for _, f := range analysis.Features(re) {
+		if f.Dialect() > dialect {
+			result.Compat.Unsupported = append(result.Compat.Unsupported, f.String())
+		}
+	}
+	return result
+}
+
+func newNode(e syntax.Expr) *Node {
+	n := &Node{
+		Op:    e.Op.String(),
+		Begin: int(e.Begin()),
+		End:   int(e.End()),
+		Value: e.Value,
+	}
+	if e.Op != syntax.OpString {
+		n.Description = explain.Describe(e)
+	}
+	for _, a := range e.Args {
+		n.Args = append(n.Args, newNode(a))
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package playground
+
+import (
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		pattern string
+		options string
+		want    string
+	}{
+		{`a(`, ``, `{"error":{"code":"RE1001","message":"unexpected token: None","begin":0,"end":0}}`},
+		{`(?=y)`, ``, `{"tree":{"op":"PositiveLookahead","begin":0,"end":5,"value":"(?=y)","description":"lookahead","args":[` +
+			`{"op":"Char","begin":3,"end":4,"value":"y","description":"literal \"y\""}]},` +
+			`"complexity":3,"compat":{"dialect":"RE2","unsupported":["lookahead"]}}`},
+		{`(?=y)`, `{"dialect":"PCRE"}`, `{"tree":{"op":"PositiveLookahead","begin":0,"end":5,"value":"(?=y)","description":"lookahead","args":[` +
+			`{"op":"Char","begin":3,"end":4,"value":"y","description":"literal \"y\""}]},` +
+			`"complexity":3,"compat":{"dialect":"PCRE"}}`},
+		{`(?<x>\d)#ID`, `{"flags":"x"}`, `{"tree":{"op":"Concat","begin":0,"end":11,"value":"(?<x>\\d)#ID","description":"sequence","args":[` +
+			`{"op":"NamedCapture","begin":0,"end":8,"value":"(?<x>\\d)","description":"capture group \"x\"","args":[` +
+			`{"op":"EscapeChar","begin":5,"end":7,"value":"\\d","description":"digit","args":[{"op":"String","begin":6,"end":7,"value":"d"}]},` +
+			`{"op":"String","begin":3,"end":4,"value":"x"}]},` +
+			`{"op":"Literal","begin":8,"end":11,"value":"#ID","description":"literal \"#ID\"","args":[` +
+			`{"op":"Char","begin":8,"end":9,"value":"#","description":"literal \"#\""},` +
+			`{"op":"Char","begin":9,"end":10,"value":"I","description":"literal \"I\""},` +
+			`{"op":"Char","begin":10,"end":11,"value":"D","description":"literal \"D\""}]}]},` +
+			`"captures":[{"name":"x","index":1,"matches":"digit","doc":"ID"}],"complexity":12,"compat":{"dialect":"RE2"}}`},
+	}
+
+	for _, test := range tests {
+		have, err := Analyze(test.pattern, test.options)
+		if err != nil {
+			t.Fatalf("Analyze(%q, %q): %v", test.pattern, test.options, err)
+		}
+		if have != test.want {
+			t.Errorf("Analyze(%q, %q):\nhave: %s\nwant: %s", test.pattern, test.options, have, test.want)
+		}
+	}
+}
+
+func TestAnalyzeOptionErrors(t *testing.T) {
+	tests := []struct {
+		options string
+		want    string
+	}{
+		{`{`, "decode options: unexpected end of JSON input"},
+		{`{"dialect":"JS"}`, "unknown dialect: JS"},
+		{`{"flags":"q"}`, "flags: unknown flag: q"},
+	}
+
+	for _, test := range tests {
+		_, err := Analyze("x", test.options)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Analyze(%q):\nhave: %v\nwant: %s", test.options, err, test.want)
+		}
+	}
+}
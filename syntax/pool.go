@@ -0,0 +1,53 @@
+package syntax
+
+import (
+	"sync"
+)
+
+// ParserPool is a concurrent-safe parser pool.
+//
+// It amortizes the parser allocations across goroutines.
+// The zero value is a pool of parsers with default options.
+type ParserPool struct {
+	opts ParserOptions
+	pool sync.Pool
+
+	parseHook func(ParseStats)
+}
+
+// NewParserPool returns a pool of parsers that are created with opts.
+func NewParserPool(opts *ParserOptions) *ParserPool {
+	pp := &ParserPool{}
+	if opts != nil {
+		pp.opts = *opts
+	}
+	return pp
+}
+
+// Parse parses the pattern using one of the pooled parsers.
+//
+// Unlike Parser.Parse, the result is not owned by the parser,
+// so it remains valid indefinitely.
+func (pp *ParserPool) Parse(pattern string) (*Regexp, error) {
+	p, ok := pp.pool.Get().(*Parser)
+	if !ok {
+		p = newParser(&pp.opts)
+	}
+	p.parseHook = pp.parseHook
+	re, err := p.Parse(pattern)
+	if err == nil {
+		re = re.Clone()
+	}
+	pp.pool.Put(p)
+	return re, err
+}
+
+var defaultParserPool ParserPool
+
+// ParsePooled parses the pattern with a default options parser
+// taken from the package-level pool.
+//
+// It's safe for concurrent use. See ParserPool.Parse.
+func ParsePooled(pattern string) (*Regexp, error) {
+	return defaultParserPool.Parse(pattern)
+}
@@ -0,0 +1,51 @@
+package syntax
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestParsePooled(t *testing.T) {
+	var wg sync.WaitGroup
+	errors := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pattern := fmt.Sprintf(`x%d(a|b)`, i*1000+j)
+				re, err := ParsePooled(pattern)
+				if err != nil {
+					errors <- err
+					return
+				}
+				want := fmt.Sprintf(`{x%d (capture (or a b))}`, i*1000+j)
+				if have := formatSyntax(re); have != want {
+					errors <- fmt.Errorf("%s: result mismatch: %s", pattern, have)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errors)
+	for err := range errors {
+		t.Error(err)
+	}
+
+	if _, err := ParsePooled(`(x`); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestParserPoolOptions(t *testing.T) {
+	pp := NewParserPool(&ParserOptions{NoLiterals: true})
+	re, err := pp.Parse(`abc`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.Expr.Op != OpConcat {
+		t.Errorf("NoLiterals option is not respected: got %s", re.Expr.Op)
+	}
+}
@@ -0,0 +1,168 @@
+package syntax
+
+import (
+	"strings"
+)
+
+// QuoteContext describes where the escaped text is going to be inserted.
+type QuoteContext byte
+
+const (
+	// QuoteOutsideClass is a top-level pattern context.
+	QuoteOutsideClass QuoteContext = iota
+
+	// QuoteInsideClass is a char class context, like `[...]`.
+	QuoteInsideClass
+)
+
+// QuoteMeta returns a pattern that matches the literal text s in the dialect d.
+//
+// For DialectRE2 the result is identical to regexp.QuoteMeta.
+// For DialectPCRE it also escapes `#`, whitespace and NUL,
+// so the result stays valid under the `x` flag and in C strings.
+func QuoteMeta(s string, d Dialect) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case d == DialectPCRE && ch == 0:
+			b.WriteString(`\x00`)
+			continue
+		case quoteMetachar[ch]:
+			b.WriteByte('\\')
+		case d == DialectPCRE && (ch == '#' || isSpace(ch)):
+			b.WriteByte('\\')
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// MinimalEscape escapes only the chars of s that have a special
+// meaning in the given context.
+//
+// Inside a char class `^` is escaped only at the beginning of s;
+// `-` and `[` are always escaped, so the result can be safely
+// concatenated with other class elements.
+func MinimalEscape(s string, ctx QuoteContext) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ctx == QuoteInsideClass {
+			if classQuoteMetachar[ch] || (ch == '^' && i == 0) {
+				b.WriteByte('\\')
+			}
+		} else if quoteMetachar[ch] && ch != ']' && ch != '}' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// Unquote returns re.Pattern with all `\Q...\E` regions
+// replaced by their escaped contents.
+//
+// Quotes inside char classes are escaped using the char class rules.
+// Empty quotes are removed.
+//
+// A literal `{` that starts an unfinished repetition before a quote,
+// like in `x{2\Q}\E`, is escaped, so it's not completed by the
+// unquoted text.
+func Unquote(re *Regexp) string {
+	var u unquoter
+	u.walk(re.Expr, QuoteOutsideClass)
+	if len(u.quotes) == 0 {
+		return re.Pattern
+	}
+	b := make([]byte, 0, len(re.Pattern))
+	offset := 0
+	for _, q := range u.quotes {
+		b = append(b, re.Pattern[offset:q.expr.Begin()]...)
+		if q.ctx == QuoteOutsideClass {
+			b = escapeOpenRepeat(b)
+		}
+		b = append(b, MinimalEscape(q.expr.QuotedLiteral(), q.ctx)...)
+		offset = int(q.expr.End())
+	}
+	b = append(b, re.Pattern[offset:]...)
+	return string(b)
+}
+
+// escapeOpenRepeat escapes the `{` if b ends with `{digits[,digits]`.
+func escapeOpenRepeat(b []byte) []byte {
+	i := len(b)
+	for i > 0 && isDigit(b[i-1]) {
+		i--
+	}
+	if i > 0 && b[i-1] == ',' {
+		i--
+		for i > 0 && isDigit(b[i-1]) {
+			i--
+		}
+	}
+	if i == 0 || b[i-1] != '{' {
+		return b
+	}
+	brace := i - 1
+	slashes := 0
+	for j := brace - 1; j >= 0 && b[j] == '\\'; j-- {
+		slashes++
+	}
+	if slashes%2 != 0 {
+		return b
+	}
+	b = append(b, 0)
+	copy(b[brace+1:], b[brace:])
+	b[brace] = '\\'
+	return b
+}
+
+type unquoter struct {
+	quotes []quoteInfo
+}
+
+type quoteInfo struct {
+	expr Expr
+	ctx  QuoteContext
+}
+
+func (u *unquoter) walk(e Expr, ctx QuoteContext) {
+	switch e.Op {
+	case OpQuote:
+		u.quotes = append(u.quotes, quoteInfo{expr: e, ctx: ctx})
+		return
+	case OpCharClass, OpNegCharClass:
+		ctx = QuoteInsideClass
+	}
+	for _, a := range e.Args {
+		u.walk(a, ctx)
+	}
+}
+
+// quoteMetachar is a table of chars escaped by regexp.QuoteMeta.
+var quoteMetachar = [256]bool{
+	'\\': true,
+	'.':  true,
+	'+':  true,
+	'*':  true,
+	'?':  true,
+	'(':  true,
+	')':  true,
+	'|':  true,
+	'[':  true,
+	']':  true,
+	'{':  true,
+	'}':  true,
+	'^':  true,
+	'$':  true,
+}
+
+// classQuoteMetachar is a table of chars that need to be
+// escaped inside a char class, except the leading `^`.
+var classQuoteMetachar = [256]bool{
+	'\\': true,
+	'[':  true,
+	']':  true,
+	'-':  true,
+}
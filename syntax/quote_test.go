@@ -0,0 +1,93 @@
+package syntax
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestQuoteMeta(t *testing.T) {
+	tests := []struct {
+		s    string
+		re2  string
+		pcre string
+	}{
+		{``, ``, ``},
+		{`abc`, `abc`, `abc`},
+		{`a.b*c`, `a\.b\*c`, `a\.b\*c`},
+		{`[x]{1}`, `\[x\]\{1\}`, `\[x\]\{1\}`},
+		{`# a`, `# a`, `\#\ a`},
+		{"a\x00", "a\x00", `a\x00`},
+		{`привет`, `привет`, `привет`},
+	}
+
+	for _, test := range tests {
+		if have := QuoteMeta(test.s, DialectRE2); have != test.re2 {
+			t.Errorf("QuoteMeta(%q, RE2):\nhave: %s\nwant: %s", test.s, have, test.re2)
+		}
+		if have := regexp.QuoteMeta(test.s); have != test.re2 {
+			t.Errorf("regexp.QuoteMeta(%q):\nhave: %s\nwant: %s", test.s, have, test.re2)
+		}
+		if have := QuoteMeta(test.s, DialectPCRE); have != test.pcre {
+			t.Errorf("QuoteMeta(%q, PCRE):\nhave: %s\nwant: %s", test.s, have, test.pcre)
+		}
+	}
+}
+
+func TestMinimalEscape(t *testing.T) {
+	tests := []struct {
+		s       string
+		outside string
+		inside  string
+	}{
+		{`abc`, `abc`, `abc`},
+		{`a.b]c}`, `a\.b]c}`, `a.b\]c}`},
+		{`^a^`, `\^a\^`, `\^a^`},
+		{`a-z[`, `a-z\[`, `a\-z\[`},
+		{`\(|)$`, `\\\(\|\)\$`, `\\(|)$`},
+	}
+
+	for _, test := range tests {
+		if have := MinimalEscape(test.s, QuoteOutsideClass); have != test.outside {
+			t.Errorf("MinimalEscape(%q, outside):\nhave: %s\nwant: %s", test.s, have, test.outside)
+		}
+		if have := MinimalEscape(test.s, QuoteInsideClass); have != test.inside {
+			t.Errorf("MinimalEscape(%q, inside):\nhave: %s\nwant: %s", test.s, have, test.inside)
+		}
+		if _, err := regexp.Compile(MinimalEscape(test.s, QuoteOutsideClass)); err != nil {
+			t.Errorf("compile outside %q: %v", test.s, err)
+		}
+		if _, err := regexp.Compile("[" + MinimalEscape(test.s, QuoteInsideClass) + "]"); err != nil {
+			t.Errorf("compile inside %q: %v", test.s, err)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `abc`},
+		{`\Qa.b\E+`, `a\.b+`},
+		{`x\Q\Ey`, `xy`},
+		{`[\Q^]-\E]`, `[\^\]\-]`},
+		{`a\Q(b`, `a\(b`},
+		{`\Q$\E|[a\Q\\E]`, `\$|[a\\]`},
+		{`x{2\Q}\E`, `x\{2}`},
+		{`.*a{1\Q,4}`, `.*a\{1,4}`},
+		{`x{2\Q\E}`, `x\{2}`},
+		{`x\{2\Q}\E`, `x\{2}`},
+		{`x{2}\Qa\E`, `x{2}a`},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		if have := Unquote(re); have != test.want {
+			t.Errorf("Unquote(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
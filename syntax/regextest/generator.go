@@ -0,0 +1,208 @@
+package regextest
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Dialect selects the syntax subset that is used by the Generator.
+type Dialect int
+
+const (
+	// RE2 patterns are accepted by both this package and Go regexp/syntax.
+	RE2 Dialect = iota
+
+	// PCRE patterns can also contain PCRE-only constructs like
+	// lookarounds, atomic groups, possessive quantifiers and comments.
+	PCRE
+)
+
+// Generator produces random valid patterns.
+type Generator struct {
+	// MaxDepth limits the generated patterns nesting level.
+	MaxDepth int
+
+	// MaxWidth limits the number of concatenated or alternated elements.
+	MaxWidth int
+
+	dialect Dialect
+	rand    *rand.Rand
+	buf     strings.Builder
+	groups  int
+}
+
+// NewGenerator returns a pattern generator for the specified dialect.
+// Generators with the same seed produce the same patterns sequence.
+func NewGenerator(seed int64, d Dialect) *Generator {
+	return &Generator{
+		MaxDepth: 4,
+		MaxWidth: 4,
+		dialect:  d,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Pattern returns the next random pattern.
+func (g *Generator) Pattern() string {
+	g.buf.Reset()
+	g.groups = 0
+	g.genAlt(0)
+	return g.buf.String()
+}
+
+func (g *Generator) genAlt(depth int) {
+	n := 1
+	if g.rand.Intn(4) == 0 {
+		n += g.rand.Intn(g.MaxWidth)
+	}
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			g.buf.WriteByte('|')
+		}
+		g.genConcat(depth)
+	}
+}
+
+func (g *Generator) genConcat(depth int) {
+	n := g.rand.Intn(g.MaxWidth + 1)
+	for i := 0; i < n; i++ {
+		g.genQuantified(depth)
+	}
+}
+
+func (g *Generator) genQuantified(depth int) {
+	if g.genAtom(depth) {
+		return
+	}
+	switch g.rand.Intn(8) {
+	case 0:
+		g.buf.WriteByte('*')
+	case 1:
+		g.buf.WriteByte('+')
+	case 2:
+		g.buf.WriteByte('?')
+	case 3:
+		min := g.rand.Intn(4)
+		switch g.rand.Intn(3) {
+		case 0:
+			g.buf.WriteString("{" + strconv.Itoa(min) + "}")
+		case 1:
+			g.buf.WriteString("{" + strconv.Itoa(min) + ",}")
+		default:
+			max := min + g.rand.Intn(4)
+			g.buf.WriteString("{" + strconv.Itoa(min) + "," + strconv.Itoa(max) + "}")
+		}
+	default:
+		return
+	}
+	switch g.rand.Intn(4) {
+	case 0:
+		g.buf.WriteByte('?')
+	case 1:
+		if g.dialect == PCRE {
+			g.buf.WriteByte('+')
+		}
+	}
+}
+
+var (
+	literalChars = []string{"a", "b", "x", "0", "7", "_", "-", " ", "✓", "ж", `\.`, `\+`, `\(`, `\[`, `\\`}
+
+	classItems = []string{"a", "z", "0-9", "a-f", `\d`, `\w`, `\s`, `\-`, `\]`, `[:alpha:]`, `[:^digit:]`, "_", "✓"}
+
+	escapes = []string{`\d`, `\D`, `\w`, `\W`, `\s`, `\S`, `\b`, `\B`, `\A`, `\z`, `\pL`, `\PN`, `\p{Greek}`, `\x41`, `\x{263a}`, `\n`, `\t`}
+
+	pcreEscapes = []string{`\012`, `\h`, `\R`, `\Q.+\E`, `\Qa|b\E`}
+
+	flags = []string{"i", "s", "m", "U", "i-s", "-m", "is"}
+)
+
+// genAtom writes a single atom to the buffer.
+// If the written atom can't be quantified, it returns true.
+func (g *Generator) genAtom(depth int) (unquantifiable bool) {
+	if depth < g.MaxDepth && g.rand.Intn(4) == 0 {
+		return g.genGroup(depth + 1)
+	}
+	switch g.rand.Intn(10) {
+	case 0:
+		g.buf.WriteByte('.')
+	case 1:
+		g.genCharClass()
+	case 2:
+		g.pick(escapes)
+	case 3:
+		if g.dialect == PCRE {
+			g.pick(pcreEscapes)
+		} else {
+			g.pick(literalChars)
+		}
+	case 4:
+		if g.rand.Intn(2) == 0 {
+			g.buf.WriteByte('^')
+		} else {
+			g.buf.WriteByte('$')
+		}
+		return true
+	default:
+		g.pick(literalChars)
+	}
+	return false
+}
+
+func (g *Generator) genGroup(depth int) (unquantifiable bool) {
+	kinds := 5
+	if g.dialect == PCRE {
+		kinds = 12
+	}
+	switch g.rand.Intn(kinds) {
+	case 0:
+		g.buf.WriteString("(?:")
+	case 1:
+		g.groups++
+		g.buf.WriteString("(?P<g" + strconv.Itoa(g.groups) + ">")
+	case 2:
+		g.buf.WriteString("(?" + flags[g.rand.Intn(len(flags))] + ":")
+	case 3:
+		g.buf.WriteString("(?" + flags[g.rand.Intn(len(flags))] + ")")
+		return true
+	case 5:
+		g.buf.WriteString("(?=")
+	case 6:
+		g.buf.WriteString("(?!")
+	case 7:
+		g.buf.WriteString("(?<=")
+	case 8:
+		g.buf.WriteString("(?<!")
+	case 9:
+		g.buf.WriteString("(?>")
+	case 10:
+		g.groups++
+		g.buf.WriteString("(?<g" + strconv.Itoa(g.groups) + ">")
+	case 11:
+		g.buf.WriteString("(?#comment)")
+		return true
+	default:
+		g.groups++
+		g.buf.WriteString("(")
+	}
+	g.genAlt(depth)
+	g.buf.WriteByte(')')
+	return false
+}
+
+func (g *Generator) genCharClass() {
+	g.buf.WriteByte('[')
+	if g.rand.Intn(3) == 0 {
+		g.buf.WriteByte('^')
+	}
+	n := 1 + g.rand.Intn(g.MaxWidth)
+	for i := 0; i < n; i++ {
+		g.pick(classItems)
+	}
+	g.buf.WriteByte(']')
+}
+
+func (g *Generator) pick(list []string) {
+	g.buf.WriteString(list[g.rand.Intn(len(list))])
+}
@@ -0,0 +1,114 @@
+package regextest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+var updateGolden = flag.Bool("regextest.update", false, "update regextest golden files")
+
+// Dump renders re AST in a stable and diff-friendly format.
+//
+// Every expression is printed on its own line, with its
+// children indented below it:
+//
+//	Concat 0-4 "a(b)"
+//	  Char 0-1 "a"
+//	  Capture 1-4 "(b)"
+//	    Char 2-3 "b"
+func Dump(re *syntax.Regexp) string {
+	var b strings.Builder
+	dumpExpr(&b, re.Expr, 0)
+	return b.String()
+}
+
+func dumpExpr(b *strings.Builder, e syntax.Expr, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(e.Op.String())
+	if e.Form != syntax.FormDefault {
+		b.WriteString("[" + formatForm(e.Form) + "]")
+	}
+	fmt.Fprintf(b, " %d-%d %q\n", e.Begin(), e.End(), e.Value)
+	for _, a := range e.Args {
+		dumpExpr(b, a, depth+1)
+	}
+}
+
+func formatForm(f syntax.Form) string {
+	switch f {
+	case syntax.FormEscapeHexFull:
+		return "EscapeHexFull"
+	case syntax.FormEscapeUniFull:
+		return "EscapeUniFull"
+	case syntax.FormNamedCaptureAngle:
+		return "NamedCaptureAngle"
+	case syntax.FormNamedCaptureQuote:
+		return "NamedCaptureQuote"
+	case syntax.FormQuoteUnclosed:
+		return "QuoteUnclosed"
+	case syntax.FormEscapeBackref:
+		return "EscapeBackref"
+	case syntax.FormPosixUnicode:
+		return "PosixUnicode"
+	case syntax.FormScriptRunAtomic:
+		return "ScriptRunAtomic"
+	default:
+		return fmt.Sprintf("Form%d", f)
+	}
+}
+
+// CheckGolden compares the re dump with the golden file contents.
+//
+// When tests are executed with -regextest.update flag,
+// the golden file is (re-)written instead.
+func CheckGolden(t testing.TB, filename string, re *syntax.Regexp) {
+	t.Helper()
+
+	have := "# " + re.Pattern + "\n" + Dump(re)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("create golden file dir: %v", err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(have), 0644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run with -regextest.update to create it)", err)
+	}
+	want := string(data)
+	if have != want {
+		t.Errorf("%s: AST mismatch:\n%s", filename, lineDiff(want, have))
+	}
+}
+
+// lineDiff returns a simple line-based diff between want and have.
+func lineDiff(want, have string) string {
+	wantLines := strings.Split(want, "\n")
+	haveLines := strings.Split(have, "\n")
+	var b strings.Builder
+	for i := 0; i < len(wantLines) || i < len(haveLines); i++ {
+		var w, h string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(haveLines) {
+			h = haveLines[i]
+		}
+		if w == h {
+			continue
+		}
+		fmt.Fprintf(&b, "line %d:\n-%s\n+%s\n", i+1, w, h)
+	}
+	return b.String()
+}
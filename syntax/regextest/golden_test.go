@@ -0,0 +1,76 @@
+package regextest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestDump(t *testing.T) {
+	p := syntax.NewParser(nil)
+	re, err := p.Parse(`a(?<x>b)+\x{F}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Concat 0-14 "a(?<x>b)+\\x{F}"
+  Char 0-1 "a"
+  Plus 1-9 "(?<x>b)+"
+    NamedCapture[NamedCaptureAngle] 1-8 "(?<x>b)"
+      Char 6-7 "b"
+      String 4-5 "x"
+  EscapeHex[EscapeHexFull] 9-14 "\\x{F}"
+    String 12-13 "F"
+`
+	if have := Dump(re); have != want {
+		t.Errorf("dump mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regextest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "testdata", "x.golden")
+
+	p := syntax.NewParser(nil)
+	re, err := p.Parse(`x|y`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	*updateGolden = true
+	CheckGolden(t, filename, re)
+	*updateGolden = false
+
+	tb := &recordingTB{TB: t}
+	CheckGolden(tb, filename, re)
+	if len(tb.errors) != 0 {
+		t.Errorf("unexpected golden mismatch: %v", tb.errors)
+	}
+
+	re, err = p.Parse(`x|z`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckGolden(tb, filename, re)
+	if len(tb.errors) != 1 {
+		t.Errorf("expected a golden mismatch to be reported")
+	}
+}
@@ -0,0 +1,68 @@
+// Package regextest provides utilities for testing the code
+// that is built on top of the syntax package.
+package regextest
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Fuzz is a go-fuzz compatible entry point.
+//
+// It parses data as a pattern and checks the resulting AST invariants,
+// panicking if any of them is violated. It returns 1 for the inputs
+// that were parsed successfully, so the fuzzer gives them a priority.
+func Fuzz(data []byte) int {
+	p := syntax.NewParser(nil)
+	re, err := p.Parse(string(data))
+	if err != nil {
+		var pos syntax.Position
+		switch err := err.(type) {
+		case syntax.ParseError:
+			pos = err.Pos
+		case syntax.LimitError:
+			pos = err.Pos
+		default:
+			panic(fmt.Sprintf("unexpected error type %T: %v", err, err))
+		}
+		if int(pos.Begin) > len(data) || int(pos.End) > len(data) {
+			panic(fmt.Sprintf("error position %v is out of the pattern bounds", pos))
+		}
+		return 0
+	}
+	if err := CheckInvariants(re); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// CheckInvariants verifies that re AST is consistent with its pattern.
+//
+// For every expression it checks that its position is inside the
+// pattern and parent expression bounds and that its Value
+// matches the pattern text at that position.
+func CheckInvariants(re *syntax.Regexp) error {
+	return checkExpr(re, re.Expr, syntax.Position{End: uint16(len(re.Pattern))})
+}
+
+func checkExpr(re *syntax.Regexp, e syntax.Expr, parent syntax.Position) error {
+	if e.Begin() > e.End() {
+		return fmt.Errorf("%q: %s: begin=%d is greater than end=%d",
+			re.Pattern, e.Op, e.Begin(), e.End())
+	}
+	if e.Begin() < parent.Begin || e.End() > parent.End {
+		return fmt.Errorf("%q: %s: position %v is outside of the parent %v",
+			re.Pattern, e.Op, e.Pos, parent)
+	}
+	if want := re.Pattern[e.Begin():e.End()]; e.Value != want {
+		return fmt.Errorf("%q: %s: value mismatch: have %q, want %q",
+			re.Pattern, e.Op, e.Value, want)
+	}
+	for _, a := range e.Args {
+		if err := checkExpr(re, a, e.Pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package regextest
+
+import (
+	stdsyntax "regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestGeneratorRE2(t *testing.T) {
+	g := NewGenerator(1, RE2)
+	p := syntax.NewParser(nil)
+	for i := 0; i < 2000; i++ {
+		pattern := g.Pattern()
+		if _, err := stdsyntax.Parse(pattern, stdsyntax.Perl); err != nil {
+			t.Fatalf("regexp/syntax rejected %q: %v", pattern, err)
+		}
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		if err := CheckInvariants(re); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGeneratorPCRE(t *testing.T) {
+	g := NewGenerator(1, PCRE)
+	for i := 0; i < 2000; i++ {
+		pattern := g.Pattern()
+		if Fuzz([]byte(pattern)) != 1 {
+			t.Fatalf("parse(%q) failed", pattern)
+		}
+	}
+}
+
+func TestGeneratorDeterminism(t *testing.T) {
+	g1 := NewGenerator(42, PCRE)
+	g2 := NewGenerator(42, PCRE)
+	for i := 0; i < 100; i++ {
+		if p1, p2 := g1.Pattern(), g2.Pattern(); p1 != p2 {
+			t.Fatalf("patterns mismatch: %q vs %q", p1, p2)
+		}
+	}
+}
+
+func TestFuzzInvalid(t *testing.T) {
+	inputs := []string{
+		`(`,
+		`[`,
+		`\`,
+		`(?`,
+		`x{1,2`,
+		`\p{`,
+		"\xff\xfe",
+		strings.Repeat("a", 1<<16),
+	}
+	for _, input := range inputs {
+		Fuzz([]byte(input))
+	}
+}
@@ -0,0 +1,92 @@
+package regextest
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Shrink returns a minimal version of the pattern for which keep is still true.
+//
+// keep should return true for the original pattern; it's called with
+// smaller and smaller candidates until none of them can be accepted.
+// Candidates are produced in two ways: by the AST transformations
+// (removing a node or replacing it with one of its children, like
+// `(a|b)+` => `a|b` => `a`) and by removing byte chunks of the pattern,
+// so invalid patterns can be shrunk too.
+//
+// The result is only locally minimal: removing any single node
+// or chunk from it makes keep false.
+func Shrink(pattern string, keep func(pattern string) bool) string {
+	p := syntax.NewParser(nil)
+	for {
+		shrunk, ok := shrinkStep(p, pattern, keep)
+		if !ok {
+			return pattern
+		}
+		pattern = shrunk
+	}
+}
+
+func shrinkStep(p *syntax.Parser, pattern string, keep func(string) bool) (string, bool) {
+	for _, candidate := range shrinkCandidates(p, pattern) {
+		if keep(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// shrinkCandidates returns the pattern reductions, the shortest ones come first.
+func shrinkCandidates(p *syntax.Parser, pattern string) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	add := func(s string) {
+		if len(s) < len(pattern) && !seen[s] {
+			seen[s] = true
+			candidates = append(candidates, s)
+		}
+	}
+
+	if re, err := p.Parse(pattern); err == nil {
+		walkShrinkable(re.Expr, func(e syntax.Expr) {
+			splice := func(s string) string {
+				return pattern[:e.Begin()] + s + pattern[e.End():]
+			}
+			add(splice(""))
+			for _, a := range e.Args {
+				if a.Op != syntax.OpString {
+					add(splice(pattern[a.Begin():a.End()]))
+				}
+			}
+		})
+	}
+
+	for size := len(pattern) / 2; size >= 1; size /= 2 {
+		for i := 0; i+size <= len(pattern); i += size {
+			if isRuneBoundary(pattern, i) && isRuneBoundary(pattern, i+size) {
+				add(pattern[:i] + pattern[i+size:])
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i]) < len(candidates[j])
+	})
+	return candidates
+}
+
+func walkShrinkable(e syntax.Expr, visit func(syntax.Expr)) {
+	if e.Op == syntax.OpString {
+		return
+	}
+	visit(e)
+	for _, a := range e.Args {
+		walkShrinkable(a, visit)
+	}
+}
+
+func isRuneBoundary(s string, i int) bool {
+	return i == len(s) || utf8.RuneStart(s[i])
+}
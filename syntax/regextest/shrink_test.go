@@ -0,0 +1,52 @@
+package regextest
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestShrink(t *testing.T) {
+	tests := []struct {
+		pattern string
+		keep    func(string) bool
+		want    string
+	}{
+		{
+			pattern: `^(foo|bar)+[0-9]{2,}x*$`,
+			keep:    func(s string) bool { return strings.Contains(s, "x") },
+			want:    `x`,
+		},
+		{
+			pattern: `abc(?:(d+)+|e)f`,
+			keep: func(s string) bool {
+				_, err := regexp.Compile(s)
+				return err == nil && strings.Contains(s, ")+")
+			},
+			want: `()+`,
+		},
+		{
+			pattern: `\d+\.\d+(?:e[+-]?\d+)?`,
+			keep: func(s string) bool {
+				re, err := regexp.Compile(s)
+				return err == nil && re.MatchString("1.5e10") && !re.MatchString("15")
+			},
+			want: `\.`,
+		},
+		{
+			pattern: `ab(c`,
+			keep:    func(s string) bool { return strings.Contains(s, "(") },
+			want:    `(`,
+		},
+	}
+
+	for _, test := range tests {
+		if !test.keep(test.pattern) {
+			t.Fatalf("%q: keep is false for the original pattern", test.pattern)
+		}
+		have := Shrink(test.pattern, test.keep)
+		if have != test.want {
+			t.Errorf("Shrink(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
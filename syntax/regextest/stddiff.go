@@ -0,0 +1,194 @@
+package regextest
+
+import (
+	"errors"
+	"fmt"
+	stdsyntax "regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// DiffStd parses the pattern with both syntax package and Go regexp/syntax
+// and reports the first semantic disagreement found.
+//
+// Both trees are normalized before the comparison: the syntax AST is
+// printed back structurally (so every grouping and precedence decision
+// made by the parser is preserved), then both patterns are simplified
+// by regexp/syntax.
+//
+// Patterns that are rejected by regexp/syntax are outside of the RE2
+// compatible subset and are not compared.
+func DiffStd(pattern string) error {
+	stdRe, stdErr := stdsyntax.Parse(pattern, stdsyntax.Perl)
+	re, err := syntax.NewParser(nil).Parse(pattern)
+	switch {
+	case stdErr != nil:
+		return nil
+	case err != nil:
+		return fmt.Errorf("%q: rejected by syntax (%v), but accepted by regexp/syntax", pattern, err)
+	}
+
+	var b strings.Builder
+	if err := writeStdPattern(&b, re.Expr); err != nil {
+		return fmt.Errorf("%q: %v", pattern, err)
+	}
+	normalized := b.String()
+	ourRe, err := stdsyntax.Parse(normalized, stdsyntax.Perl)
+	if err != nil {
+		return fmt.Errorf("%q: normalized form %q is rejected by regexp/syntax: %v", pattern, normalized, err)
+	}
+
+	have := formatStd(ourRe.Simplify())
+	want := formatStd(stdRe.Simplify())
+	if have != want {
+		return fmt.Errorf("%q: parse trees mismatch:\nsyntax:        %s\nregexp/syntax: %s", pattern, have, want)
+	}
+	return nil
+}
+
+// formatStd prints re in a canonical form that doesn't depend on
+// the nested concatenations and alternations layout.
+func formatStd(re *stdsyntax.Regexp) string {
+	var b strings.Builder
+	writeStd(&b, re)
+	return b.String()
+}
+
+func writeStd(b *strings.Builder, re *stdsyntax.Regexp) {
+	switch re.Op {
+	case stdsyntax.OpConcat, stdsyntax.OpAlternate:
+		b.WriteString(re.Op.String() + "{")
+		writeStdFlattened(b, re.Op, re)
+		b.WriteString("}")
+	case stdsyntax.OpLiteral:
+		for _, r := range re.Rune {
+			fmt.Fprintf(b, "lit{%q fold=%v}", r, re.Flags&stdsyntax.FoldCase != 0)
+		}
+	case stdsyntax.OpCharClass:
+		switch fmt.Sprint(re.Rune) {
+		case fmt.Sprint([]rune{0, unicode.MaxRune}):
+			b.WriteString(stdsyntax.OpAnyChar.String())
+		case fmt.Sprint([]rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}):
+			b.WriteString(stdsyntax.OpAnyCharNotNL.String())
+		default:
+			fmt.Fprintf(b, "class{%v}", re.Rune)
+		}
+	case stdsyntax.OpCapture:
+		fmt.Fprintf(b, "capture{%d %s ", re.Cap, re.Name)
+		writeStd(b, re.Sub[0])
+		b.WriteString("}")
+	case stdsyntax.OpStar, stdsyntax.OpPlus, stdsyntax.OpQuest, stdsyntax.OpRepeat:
+		fmt.Fprintf(b, "%s{%d,%d nongreedy=%v ", re.Op, re.Min, re.Max, re.Flags&stdsyntax.NonGreedy != 0)
+		writeStd(b, re.Sub[0])
+		b.WriteString("}")
+	default:
+		b.WriteString(re.Op.String())
+	}
+}
+
+func writeStdFlattened(b *strings.Builder, op stdsyntax.Op, re *stdsyntax.Regexp) {
+	for _, sub := range re.Sub {
+		if sub.Op == op {
+			writeStdFlattened(b, op, sub)
+			continue
+		}
+		writeStd(b, sub)
+		b.WriteString(" ")
+	}
+}
+
+// writeStdPattern prints e in a way that makes its structure explicit.
+func writeStdPattern(b *strings.Builder, e syntax.Expr) error {
+	switch e.Op {
+	case syntax.OpEmptyMatch:
+		// Nothing to write.
+
+	case syntax.OpConcat:
+		for _, a := range e.Args {
+			if err := writeStdPattern(b, a); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpAlt:
+		for i, a := range e.Args {
+			if i != 0 {
+				b.WriteByte('|')
+			}
+			if err := writeStdPattern(b, a); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuestion, syntax.OpRepeat:
+		b.WriteString("(?:")
+		if err := writeStdPattern(b, e.Args[0]); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+		switch e.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		case syntax.OpQuestion:
+			b.WriteByte('?')
+		case syntax.OpRepeat:
+			b.WriteString(e.Args[1].Value)
+		}
+
+	case syntax.OpNonGreedy:
+		if err := writeStdPattern(b, e.Args[0]); err != nil {
+			return err
+		}
+		b.WriteByte('?')
+
+	case syntax.OpCapture, syntax.OpNamedCapture, syntax.OpGroup, syntax.OpGroupWithFlags:
+		switch e.Op {
+		case syntax.OpCapture:
+			b.WriteString("(")
+		case syntax.OpNamedCapture:
+			b.WriteString("(?P<" + e.Args[1].Value + ">")
+		case syntax.OpGroup:
+			b.WriteString("(?:")
+		case syntax.OpGroupWithFlags:
+			b.WriteString("(?" + e.Args[1].Value + ":")
+		}
+		if err := writeStdPattern(b, e.Args[0]); err != nil {
+			return err
+		}
+		b.WriteByte(')')
+
+	case syntax.OpFlagOnlyGroup:
+		b.WriteString("(?" + e.Args[0].Value + ")")
+
+	case syntax.OpCharClass, syntax.OpNegCharClass:
+		b.WriteByte('[')
+		if e.Op == syntax.OpNegCharClass {
+			b.WriteByte('^')
+		}
+		for _, a := range e.Args {
+			if a.Op == syntax.OpCharRange {
+				b.WriteString(a.Args[0].Value + "-" + a.Args[1].Value)
+			} else {
+				b.WriteString(a.Value)
+			}
+		}
+		b.WriteByte(']')
+
+	case syntax.OpComment:
+		// Not supported by regexp/syntax, but doesn't affect the semantics.
+
+	case syntax.OpLiteral, syntax.OpChar, syntax.OpDot, syntax.OpCaret, syntax.OpDollar,
+		syntax.OpQuote, syntax.OpEscapeChar, syntax.OpEscapeMeta, syntax.OpEscapeOctal,
+		syntax.OpEscapeHex, syntax.OpEscapeUni:
+		b.WriteString(e.Value)
+
+	default:
+		return errors.New("can't express " + e.Op.String() + " in regexp/syntax")
+	}
+
+	return nil
+}
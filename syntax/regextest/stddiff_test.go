@@ -0,0 +1,50 @@
+package regextest
+
+import (
+	"testing"
+)
+
+func TestDiffStd(t *testing.T) {
+	patterns := []string{
+		``,
+		`abc`,
+		`a|b|`,
+		`x||y`,
+		`(a|b)+?c{2,3}`,
+		`(?i)ab(?-i:c)`,
+		`a(?i)b|c`,
+		`[]a-z\d[:alpha:]]`,
+		`[^\x00-\x{10FFFF}]`,
+		`(?P<name>x)(?:y)*`,
+		`^\pL\p{Greek}$`,
+
+		// Not supported by regexp/syntax: skipped.
+		`(?=x)`,
+		`a++`,
+	}
+	for _, pattern := range patterns {
+		if err := DiffStd(pattern); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Known disagreements.
+	mismatching := []string{
+		// regexp/syntax applies the quantifier to the last quoted char.
+		`\Qa.b\E+`,
+	}
+	for _, pattern := range mismatching {
+		if err := DiffStd(pattern); err == nil {
+			t.Errorf("%q: expected a disagreement to be reported", pattern)
+		}
+	}
+}
+
+func TestDiffStdGenerated(t *testing.T) {
+	g := NewGenerator(2, RE2)
+	for i := 0; i < 2000; i++ {
+		if err := DiffStd(g.Pattern()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package syntax
+
+import (
+	"errors"
+	"strings"
+)
+
+// Edit describes a pattern text modification.
+type Edit struct {
+	// Offset is a modification start offset.
+	Offset int
+
+	// OldLen is a number of replaced bytes.
+	OldLen int
+
+	// NewText is a text that is inserted at Offset.
+	NewText string
+}
+
+// Reparse applies the edit to prev.Pattern and returns an updated AST.
+//
+// It's intended for the editor integrations that re-parse the pattern
+// after every keystroke. Only the innermost group or char class that
+// contains the edit is parsed again, the rest of the tree is re-used:
+// positions of the nodes that follow the edit are shifted by the
+// edit size difference. If the edit can't be handled locally,
+// the whole pattern is parsed from scratch.
+//
+// prev is not modified. Unlike Parse, the result is not owned by the parser.
+func (p *Parser) Reparse(prev *Regexp, edit Edit) (*Regexp, error) {
+	if edit.Offset < 0 || edit.OldLen < 0 || edit.Offset+edit.OldLen > len(prev.Pattern) {
+		return nil, errors.New("edit is out of the pattern bounds")
+	}
+	pattern := prev.Pattern[:edit.Offset] + edit.NewText + prev.Pattern[edit.Offset+edit.OldLen:]
+	delta := len(edit.NewText) - edit.OldLen
+
+	re := prev.Clone()
+	re.Pattern = pattern
+	// Warnings positions can't be updated locally.
+	canReparseLocally := len(prev.Warnings) == 0 && !p.opts.reportsWarnings() &&
+		!p.opts.checksWholePattern()
+	if target := findReparseTarget(&re.Expr, edit); canReparseLocally && target != nil {
+		fragment := pattern[target.Begin() : int(target.End())+delta]
+		sub, err := p.Parse(fragment)
+		if err == nil && isReparseCompatible(*target, sub.Expr, len(fragment)) {
+			repl := sub.Expr.Clone()
+			shiftPositions(&repl, nil, 0, int(target.Begin()))
+			shiftPositions(&re.Expr, target, edit.Offset+edit.OldLen, delta)
+			*target = repl
+			setExprValues(&re.Expr, pattern)
+			return re, nil
+		}
+	}
+
+	result, err := p.Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return result.Clone(), nil
+}
+
+// checksWholePattern reports whether some of the options
+// can't be applied to a pattern fragment.
+func (opts *ParserOptions) checksWholePattern() bool {
+	return opts.Backrefs != BackrefsUnresolved ||
+		opts.DupNames != DupNamesAccept ||
+		opts.Limits != (Limits{})
+}
+
+// findReparseTarget returns the innermost e sub-expression that
+// can be parsed again to apply the edit.
+func findReparseTarget(e *Expr, edit Edit) *Expr {
+	if e.Begin() > uint16(edit.Offset) || int(e.End()) < edit.Offset+edit.OldLen {
+		return nil
+	}
+	for i := range e.Args {
+		if target := findReparseTarget(&e.Args[i], edit); target != nil {
+			return target
+		}
+	}
+	open, ok := delimitedBodyBegin(*e)
+	if !ok {
+		return nil
+	}
+	// Modifications of the opening token (like `(` => `(?:`)
+	// can't be handled locally, therefore the strict comparison.
+	if open < edit.Offset && edit.Offset+edit.OldLen < int(e.End()) {
+		return e
+	}
+	return nil
+}
+
+// delimitedBodyBegin returns the offset of the first enclosed
+// expression byte for group-like expressions.
+func delimitedBodyBegin(e Expr) (int, bool) {
+	switch e.Op {
+	case OpCapture, OpCharClass:
+		return int(e.Begin()) + len("("), true
+	case OpNegCharClass:
+		return int(e.Begin()) + len("[^"), true
+	case OpGroup, OpAtomicGroup, OpPositiveLookahead, OpNegativeLookahead:
+		return int(e.Begin()) + len("(?:"), true
+	case OpPositiveLookbehind, OpNegativeLookbehind:
+		return int(e.Begin()) + len("(?<="), true
+	case OpNamedCapture, OpGroupWithFlags:
+		return int(e.Args[1].End()) + len(">"), true
+	case OpScriptRun:
+		return int(e.Args[0].Begin()), true
+	default:
+		return 0, false
+	}
+}
+
+func isReparseCompatible(old, e Expr, size int) bool {
+	if e.Op != old.Op || e.Begin() != 0 || int(e.End()) != size {
+		return false
+	}
+	return !hasBrokenGroupName(e)
+}
+
+// hasBrokenGroupName reports whether e contains a group name that
+// was parsed as flags because its closing delimiter is not found.
+// The delimiter could be located outside of the re-parsed fragment.
+func hasBrokenGroupName(e Expr) bool {
+	switch e.Op {
+	case OpFlagOnlyGroup, OpGroupWithFlags:
+		flags := e.LastArg().Value
+		if strings.HasPrefix(flags, "P") || strings.HasPrefix(flags, "<") || strings.HasPrefix(flags, "'") {
+			return true
+		}
+	}
+	for _, a := range e.Args {
+		if hasBrokenGroupName(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftPositions adds delta to all e positions that are >= offset.
+// The skip sub-expression is not traversed.
+func shiftPositions(e *Expr, skip *Expr, offset, delta int) {
+	if e == skip {
+		return
+	}
+	if int(e.Pos.Begin) >= offset {
+		e.Pos.Begin = uint16(int(e.Pos.Begin) + delta)
+	}
+	if int(e.Pos.End) >= offset {
+		e.Pos.End = uint16(int(e.Pos.End) + delta)
+	}
+	for i := range e.Args {
+		shiftPositions(&e.Args[i], skip, offset, delta)
+	}
+}
+
+func setExprValues(e *Expr, pattern string) {
+	for i := range e.Args {
+		setExprValues(&e.Args[i], pattern)
+	}
+	e.Value = pattern[e.Begin():e.End()]
+}
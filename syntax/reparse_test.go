@@ -0,0 +1,74 @@
+package syntax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReparse(t *testing.T) {
+	tests := []struct {
+		pattern string
+		edit    Edit
+	}{
+		{`a(bc)d`, Edit{Offset: 3, NewText: `x`}},
+		{`a(bc)d`, Edit{Offset: 3, OldLen: 1, NewText: `|y+`}},
+		{`a(bc)d`, Edit{Offset: 2, OldLen: 2}},
+		{`(a)(b(c|d))+e`, Edit{Offset: 8, OldLen: 1, NewText: `ddd`}},
+		{`x[abc]y`, Edit{Offset: 3, NewText: `-z`}},
+		{`x[^abc]{2}`, Edit{Offset: 4, OldLen: 1, NewText: `\d`}},
+		{`(?P<name>ab)c`, Edit{Offset: 10, NewText: `?`}},
+		{`(?i:ab)c`, Edit{Offset: 5, NewText: `(?:z)`}},
+		{`(?<=ab)c`, Edit{Offset: 5, OldLen: 1}},
+
+		// Edits that require a full re-parse.
+		{`abc`, Edit{Offset: 1, NewText: `|`}},
+		{`(ab)`, Edit{Offset: 1, NewText: `?:`}},
+		{`[ab]`, Edit{Offset: 1, NewText: `^`}},
+		{`(ab)c`, Edit{Offset: 3, OldLen: 1}},
+		{`(ab)c>`, Edit{Offset: 1, NewText: `(?<x`}},
+		{`(z(?:y)b)(c(>))`, Edit{Offset: 2, NewText: `(?<x`}},
+		{`(ab)c\E`, Edit{Offset: 2, NewText: `\Q`}},
+		{`(a)`, Edit{Offset: 2, NewText: `(`}},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		prev, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		prev = prev.Clone()
+		prevSyntax := formatSyntax(prev)
+
+		have, haveErr := p.Reparse(prev, test.edit)
+
+		edited := test.pattern[:test.edit.Offset] + test.edit.NewText + test.pattern[test.edit.Offset+test.edit.OldLen:]
+		want, wantErr := p.Parse(edited)
+		if (haveErr == nil) != (wantErr == nil) {
+			t.Errorf("reparse(%q, %+v) error mismatch:\nhave: %v\nwant: %v",
+				test.pattern, test.edit, haveErr, wantErr)
+			continue
+		}
+		if formatSyntax(prev) != prevSyntax {
+			t.Errorf("reparse(%q, %+v) modified the previous regexp", test.pattern, test.edit)
+		}
+		if wantErr != nil {
+			continue
+		}
+		if !reflect.DeepEqual(have, want.Clone()) {
+			t.Errorf("reparse(%q, %+v) result mismatch:\nhave: %s %+v\nwant: %s %+v",
+				test.pattern, test.edit, formatSyntax(have), have.Expr, formatSyntax(want), want.Expr)
+		}
+	}
+}
+
+func TestReparseBadEdit(t *testing.T) {
+	p := NewParser(nil)
+	re, err := p.Parse(`abc`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Reparse(re, Edit{Offset: 2, OldLen: 2}); err == nil {
+		t.Errorf("expected out of bounds edit error")
+	}
+}
@@ -0,0 +1,251 @@
+// Package ruleset loads named pattern collections from files.
+package ruleset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Rule is a named pattern.
+type Rule struct {
+	Name string
+
+	Regexp *syntax.Regexp
+
+	// Line and Column locate the pattern string inside the file, 1-based.
+	Line   int
+	Column int
+}
+
+// Set is an immutable collection of rules.
+type Set struct {
+	// Rules are sorted in the file order.
+	Rules []*Rule
+
+	byName map[string]*Rule
+}
+
+// Get returns the rule with the specified name, or nil if there is none.
+func (s *Set) Get(name string) *Rule {
+	return s.byName[name]
+}
+
+// Error is a rules file problem.
+type Error struct {
+	Filename string
+	Line     int
+	Column   int
+
+	// Rule is the problematic rule name.
+	// It's empty for the errors that are not related to any rule.
+	Rule string
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("%s:%d:%d: %v", e.Filename, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %v", e.Filename, e.Line, e.Column, e.Rule, e.Err)
+}
+
+// ErrorList is a list of all errors found in a rules file.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+	}
+}
+
+// Load reads and parses the rules file, see Parse.
+func Load(filename string, opts *syntax.ParserOptions) (*Set, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filename, data, opts)
+}
+
+// Parse parses the rules file contents.
+//
+// The file is a JSON object that maps rule names to their patterns:
+//
+//	{
+//	  "ipv4": "^\\d{1,3}(?:\\.\\d{1,3}){3}$",
+//	  "uuid": "^[0-9a-f]{8}(?:-[0-9a-f]{4}){3}-[0-9a-f]{12}$"
+//	}
+//
+// All patterns are parsed with opts. If any of them can't be parsed,
+// an ErrorList that describes all problems is returned.
+// Duplicated rule names are reported as errors too.
+func Parse(filename string, data []byte, opts *syntax.ParserOptions) (*Set, error) {
+	l := rulesLoader{
+		filename: filename,
+		data:     data,
+		parser:   syntax.NewParser(opts),
+		set:      &Set{byName: make(map[string]*Rule)},
+		seen:     make(map[string]bool),
+	}
+	if err := l.load(); err != nil {
+		line, column := l.position(int(l.dec.InputOffset()))
+		l.errors = append(l.errors, &Error{
+			Filename: filename,
+			Line:     line,
+			Column:   column,
+			Err:      err,
+		})
+	}
+	if len(l.errors) != 0 {
+		return nil, l.errors
+	}
+	return l.set, nil
+}
+
+type rulesLoader struct {
+	filename string
+	data     []byte
+	dec      *json.Decoder
+	parser   *syntax.Parser
+	set      *Set
+	errors   ErrorList
+	seen     map[string]bool
+}
+
+// load returns the JSON syntax errors, the rules
+// problems are collected in l.errors.
+func (l *rulesLoader) load() error {
+	l.dec = json.NewDecoder(bytes.NewReader(l.data))
+	if tok, err := l.dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("expected a JSON object")
+	}
+	for l.dec.More() {
+		tok, err := l.dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		tok, err = l.dec.Token()
+		if err != nil {
+			return err
+		}
+		pattern, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a pattern string", name)
+		}
+		end := int(l.dec.InputOffset())
+		start := bytes.LastIndexByte(l.data[:end-1], '"')
+		for isEscaped(l.data, start) {
+			start = bytes.LastIndexByte(l.data[:start], '"')
+		}
+		l.addRule(name, pattern, start, end)
+	}
+	if _, err := l.dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// isEscaped reports whether the data[i] char is escaped by `\`.
+func isEscaped(data []byte, i int) bool {
+	slashes := 0
+	for i > 0 && data[i-1] == '\\' {
+		slashes++
+		i--
+	}
+	return slashes%2 == 1
+}
+
+// addRule adds the pattern that spans the data[start:end] JSON string.
+func (l *rulesLoader) addRule(name, pattern string, start, end int) {
+	line, column := l.position(start)
+	if l.seen[name] {
+		l.errorf(name, line, column, "duplicated rule name")
+		return
+	}
+	l.seen[name] = true
+	re, err := l.parser.Parse(pattern)
+	if err != nil {
+		if err, ok := err.(syntax.ParseError); ok && string(l.data[start+1:end-1]) == pattern {
+			// The JSON string has no escapes, so the error can be located precisely.
+			column += 1 + int(err.Pos.Begin)
+		}
+		l.errorf(name, line, column, "%v", err)
+		return
+	}
+	rule := &Rule{Name: name, Regexp: re.Clone(), Line: line, Column: column}
+	l.set.Rules = append(l.set.Rules, rule)
+	l.set.byName[name] = rule
+}
+
+func (l *rulesLoader) errorf(name string, line, column int, format string, args ...interface{}) {
+	l.errors = append(l.errors, &Error{
+		Filename: l.filename,
+		Line:     line,
+		Column:   column,
+		Rule:     name,
+		Err:      fmt.Errorf(format, args...),
+	})
+}
+
+// position converts the data offset to the 1-based line and column.
+func (l *rulesLoader) position(offset int) (line, column int) {
+	if offset > len(l.data) {
+		offset = len(l.data)
+	}
+	before := string(l.data[:offset])
+	line = strings.Count(before, "\n") + 1
+	column = offset - strings.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// Loader keeps the most recently loaded rules file contents.
+//
+// It's safe for concurrent use.
+type Loader struct {
+	filename string
+	opts     syntax.ParserOptions
+	current  atomic.Value // *Set
+}
+
+// NewLoader loads the rules file, see Load.
+func NewLoader(filename string, opts *syntax.ParserOptions) (*Loader, error) {
+	l := &Loader{filename: filename}
+	if opts != nil {
+		l.opts = *opts
+	}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Current returns the most recently loaded rules.
+func (l *Loader) Current() *Set {
+	return l.current.Load().(*Set)
+}
+
+// Reload reads the rules file again.
+//
+// The rules are replaced atomically: if the new file
+// has any errors, the current rules are kept.
+func (l *Loader) Reload() error {
+	set, err := Load(l.filename, &l.opts)
+	if err != nil {
+		return err
+	}
+	l.current.Store(set)
+	return nil
+}
@@ -0,0 +1,101 @@
+package ruleset
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`{
+  "digits": "^\\d+$",
+  "word": "\\w+"
+}`)
+	set, err := Parse("rules.json", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Rules) != 2 {
+		t.Fatalf("rules:\nhave: %d\nwant: 2", len(set.Rules))
+	}
+	r := set.Get("word")
+	if r == nil || r.Regexp.Pattern != `\w+` || r.Line != 3 || r.Column != 11 {
+		t.Errorf("word rule: %+v", r)
+	}
+	if set.Get("missing") != nil {
+		t.Errorf("unexpected rule for a missing name")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{
+			"{\n  \"a\": \"x[\",\n  \"b\": \"y)\",\n  \"a\": \"y\"\n}",
+			`rules.json:2:10: a: unexpected token: Concat (and 1 more errors)`,
+		},
+		{
+			"{\n  \"a\": \"x\\\"(\"}",
+			`rules.json:2:8: a: unexpected token: None`,
+		},
+		{
+			"{\"a\": \"x\", \"b\": 1}",
+			`rules.json:1:18: b: expected a pattern string`,
+		},
+		{
+			"[]",
+			`rules.json:1:2: expected a JSON object`,
+		},
+		{
+			"{\"a\": \"x\"",
+			`rules.json:1:10: unexpected end of JSON input`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse("rules.json", []byte(test.data), nil)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Parse(%q):\nhave: %v\nwant: %s", test.data, err, test.want)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ruleset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "rules.json")
+	write := func(data string) {
+		if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"a": "x+"}`)
+	l, err := NewLoader(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := l.Current()
+
+	write(`{"a": "x+", "b": "("}`)
+	if err := l.Reload(); err == nil {
+		t.Errorf("expected a reload error")
+	}
+	if l.Current() != first {
+		t.Errorf("rules are replaced after a failed reload")
+	}
+
+	write(`{"a": "y+", "b": "z"}`)
+	if err := l.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if r := l.Current().Get("a"); r == nil || r.Regexp.Pattern != "y+" {
+		t.Errorf("rules are not reloaded: %+v", r)
+	}
+}
@@ -0,0 +1,258 @@
+// Package safe compiles the untrusted patterns with the regexp package.
+//
+// The patterns are screened with the syntax package analyses before
+// the compilation, so the expensive patterns are rejected with the
+// structured reasons instead of consuming the service resources.
+//
+// The regexp matching time is linear in the input length, but its
+// factor is the compiled program size, which can grow exponentially
+// with the pattern length, like for `((a{100}){100}){100}`.
+// Instead of the matching timeouts (a running match can't be
+// interrupted), the inputs are checked against the cost budget.
+package safe
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	stdsyntax "regexp/syntax"
+	"strconv"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+	"github.com/quasilyte/regex/syntax/compat"
+	"github.com/quasilyte/regex/syntax/interop"
+)
+
+// Policy describes the accepted patterns. Zero values mean "no limit".
+type Policy struct {
+	// MaxPatternLen is a max pattern length in bytes.
+	MaxPatternLen int
+
+	// Limits are applied during the parsing.
+	Limits syntax.Limits
+
+	// MaxSize is a max analysis.ExpandedSize of the pattern.
+	MaxSize int
+
+	// MaxComplexity is a max analysis.Complexity total score.
+	MaxComplexity int
+
+	// MaxCost is a max matching cost: the input length multiplied
+	// by the pattern size. Longer inputs are rejected by the Regexp
+	// methods with ErrInputTooLong.
+	MaxCost int
+}
+
+// DefaultPolicy is used when the policy is nil.
+var DefaultPolicy = Policy{
+	MaxPatternLen: 4096,
+	Limits: syntax.Limits{
+		MaxDepth:       100,
+		MaxRepeatCount: 1000,
+	},
+	MaxSize:       10000,
+	MaxComplexity: 2000,
+	MaxCost:       1 << 30,
+}
+
+// ReasonKind classifies the pattern rejection reasons.
+type ReasonKind byte
+
+const (
+	// ReasonSyntax is a pattern that can't be parsed.
+	ReasonSyntax ReasonKind = iota + 1
+
+	// ReasonLimit is a pattern that exceeds Policy.MaxPatternLen or Policy.Limits.
+	ReasonLimit
+
+	// ReasonSize is a pattern that exceeds Policy.MaxSize.
+	ReasonSize
+
+	// ReasonComplexity is a pattern that exceeds Policy.MaxComplexity.
+	ReasonComplexity
+
+	// ReasonUnsupported is a pattern part that the regexp package rejects.
+	ReasonUnsupported
+)
+
+func (k ReasonKind) String() string {
+	switch k {
+	case ReasonSyntax:
+		return "syntax"
+	case ReasonLimit:
+		return "limit"
+	case ReasonSize:
+		return "size"
+	case ReasonComplexity:
+		return "complexity"
+	case ReasonUnsupported:
+		return "unsupported"
+	default:
+		return "?"
+	}
+}
+
+// Reason describes why a pattern is rejected.
+type Reason struct {
+	Kind    ReasonKind
+	Message string
+
+	// Pos is a location of the offending pattern part.
+	// It's zero for the reasons that concern the whole pattern.
+	Pos syntax.Position
+}
+
+// Error is returned for the rejected patterns.
+type Error struct {
+	Reasons []Reason
+}
+
+func (e *Error) Error() string {
+	message := e.Reasons[0].Message
+	if len(e.Reasons) > 1 {
+		message += " (and " + strconv.Itoa(len(e.Reasons)-1) + " more)"
+	}
+	return message
+}
+
+// ErrInputTooLong is returned for the inputs that exceed Policy.MaxCost.
+var ErrInputTooLong = errors.New("input is too long for the pattern")
+
+// Regexp is a pattern compiled by Compile.
+// It's safe for concurrent use.
+type Regexp struct {
+	// Std is the compiled pattern.
+	// Its methods don't check the Policy.MaxCost.
+	Std *regexp.Regexp
+
+	// Pattern is the compiled pattern text.
+	// It differs from the source pattern if it was rewritten.
+	Pattern string
+
+	size    int
+	maxCost int
+}
+
+// Compile screens the pattern with the policy and compiles it.
+//
+// The PCRE forms that have a regexp equivalent are rewritten,
+// see interop.ToStdSyntaxString. If the pattern is rejected,
+// an *Error is returned.
+func Compile(pattern string, policy *Policy) (*Regexp, error) {
+	if policy == nil {
+		policy = &DefaultPolicy
+	}
+	if policy.MaxPatternLen != 0 && len(pattern) > policy.MaxPatternLen {
+		return nil, reject(Reason{
+			Kind:    ReasonLimit,
+			Message: "pattern is longer than " + strconv.Itoa(policy.MaxPatternLen) + " bytes",
+		})
+	}
+
+	p := syntax.NewParser(&syntax.ParserOptions{Limits: policy.Limits})
+	re, err := p.Parse(pattern)
+	if err != nil {
+		reason := Reason{Kind: ReasonSyntax, Message: err.Error()}
+		switch err := err.(type) {
+		case syntax.ParseError:
+			reason.Pos = err.Pos
+		case syntax.LimitError:
+			reason.Kind = ReasonLimit
+			reason.Pos = err.Pos
+		}
+		return nil, reject(reason)
+	}
+
+	var reasons []Reason
+	converted, convErr := interop.ToStdSyntaxString(re, stdsyntax.Perl)
+	if convErr != nil {
+		reasons = unsupported(re)
+	}
+	size := analysis.ExpandedSize(re)
+	if policy.MaxSize != 0 && size > policy.MaxSize {
+		reasons = append(reasons, Reason{
+			Kind:    ReasonSize,
+			Message: fmt.Sprintf("pattern size %d exceeds the limit of %d", size, policy.MaxSize),
+		})
+	}
+	if policy.MaxComplexity != 0 {
+		if score := analysis.Complexity(re).Total; score > policy.MaxComplexity {
+			reasons = append(reasons, Reason{
+				Kind:    ReasonComplexity,
+				Message: fmt.Sprintf("pattern complexity %d exceeds the limit of %d", score, policy.MaxComplexity),
+			})
+		}
+	}
+	if convErr != nil && len(reasons) == 0 {
+		// The regexp package rejects the pattern for a reason
+		// that is not covered by the checks above.
+		reasons = append(reasons, Reason{Kind: ReasonUnsupported, Message: convErr.Error()})
+	}
+	if len(reasons) != 0 {
+		return nil, reject(reasons...)
+	}
+
+	std, err := regexp.Compile(converted)
+	if err != nil {
+		return nil, reject(Reason{Kind: ReasonUnsupported, Message: err.Error()})
+	}
+	return &Regexp{Std: std, Pattern: converted, size: size, maxCost: policy.MaxCost}, nil
+}
+
+// unsupported returns the compat.GoRegexp issues, except for
+// the comments and quotes that are rewritten by the conversion.
+func unsupported(re *syntax.Regexp) []Reason {
+	rewritten := make(map[syntax.Position]bool)
+	var walk func(e syntax.Expr)
+	walk = func(e syntax.Expr) {
+		if e.Op == syntax.OpComment || e.Op == syntax.OpQuote {
+			rewritten[e.Pos] = true
+		}
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(re.Expr)
+
+	var reasons []Reason
+	for _, issue := range compat.GoRegexp.Issues(re) {
+		if !rewritten[issue.Pos] {
+			reasons = append(reasons, Reason{Kind: ReasonUnsupported, Message: issue.Message, Pos: issue.Pos})
+		}
+	}
+	return reasons
+}
+
+func reject(reasons ...Reason) *Error {
+	return &Error{Reasons: reasons}
+}
+
+// CheckInput returns ErrInputTooLong if the input of length n
+// exceeds the Policy.MaxCost.
+func (re *Regexp) CheckInput(n int) error {
+	if re.maxCost == 0 || re.size == 0 {
+		return nil
+	}
+	if n > re.maxCost/re.size {
+		return ErrInputTooLong
+	}
+	return nil
+}
+
+// MatchString is like regexp.Regexp.MatchString, but it checks the input cost.
+func (re *Regexp) MatchString(s string) (bool, error) {
+	if err := re.CheckInput(len(s)); err != nil {
+		return false, err
+	}
+	return re.Std.MatchString(s), nil
+}
+
+// FindStringSubmatchIndex is like regexp.Regexp.FindStringSubmatchIndex,
+// but it checks the input cost.
+func (re *Regexp) FindStringSubmatchIndex(s string) ([]int, error) {
+	if err := re.CheckInput(len(s)); err != nil {
+		return nil, err
+	}
+	return re.Std.FindStringSubmatchIndex(s), nil
+}
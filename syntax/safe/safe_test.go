@@ -0,0 +1,80 @@
+package safe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a+b`, `a+b`},
+		{`(?<year>\d{4})-\Q.\E`, `(?P<year>[0-9][0-9][0-9][0-9])-\.`},
+		{`x(?#comment)y`, `xy`},
+	}
+
+	for _, test := range tests {
+		re, err := Compile(test.pattern, nil)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.pattern, err)
+			continue
+		}
+		if re.Pattern != test.want {
+			t.Errorf("Compile(%q):\nhave: %s\nwant: %s", test.pattern, re.Pattern, test.want)
+		}
+	}
+}
+
+func TestCompileRejected(t *testing.T) {
+	tests := []struct {
+		pattern string
+		policy  *Policy
+		want    string
+	}{
+		{`a(`, nil, `syntax`},
+		{strings.Repeat("a", 5000), nil, `limit`},
+		{`a{1001}`, nil, `limit`},
+		{`((a{100}){100}){100}`, &Policy{MaxSize: 10000}, `size`},
+		{`(?=a)((a{100}){100}){100}`, nil, `unsupported size complexity`},
+		{`(a+)+\1`, nil, `unsupported`},
+		{`(?=a)b++`, nil, `unsupported unsupported`},
+		{`((((((((a*)*)*)*)*)*)*)*)*`, &Policy{MaxComplexity: 100}, `complexity`},
+	}
+
+	for _, test := range tests {
+		_, err := Compile(test.pattern, test.policy)
+		rejected, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Compile(%q): unexpected error: %v", test.pattern, err)
+			continue
+		}
+		var kinds []string
+		for _, r := range rejected.Reasons {
+			kinds = append(kinds, r.Kind.String())
+		}
+		if have := strings.Join(kinds, " "); have != test.want {
+			t.Errorf("Compile(%q) reasons:\nhave: %s (%v)\nwant: %s", test.pattern, have, err, test.want)
+		}
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	policy := &Policy{MaxCost: 100, Limits: syntax.Limits{MaxDepth: 10}}
+	re, err := Compile(`a{10}`, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := re.MatchString(strings.Repeat("a", 5)); err != nil {
+		t.Errorf("short input: %v", err)
+	}
+	if _, err := re.MatchString(strings.Repeat("a", 50)); err != ErrInputTooLong {
+		t.Errorf("long input:\nhave: %v\nwant: %v", err, ErrInputTooLong)
+	}
+	if _, err := re.FindStringSubmatchIndex(strings.Repeat("a", 50)); err != ErrInputTooLong {
+		t.Errorf("long input:\nhave: %v\nwant: %v", err, ErrInputTooLong)
+	}
+}
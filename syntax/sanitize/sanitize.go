@@ -0,0 +1,149 @@
+// Package sanitize converts the user input into safe patterns.
+package sanitize
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Options configure the conversion.
+type Options struct {
+	// Dialect is the target pattern dialect.
+	Dialect syntax.Dialect
+
+	// Allow lists the metachars that keep their special meaning:
+	//
+	//	*  matches any chars, like a glob wildcard
+	//	?  matches a single char, like a glob wildcard
+	//	.  matches a single char
+	//	|  separates the alternatives
+	//	^  anchors the alternative to the line start, if it's the first char
+	//	$  anchors the alternative to the line end, if it's the last char
+	//
+	// All other chars, including the not allowed metachars,
+	// are matched literally.
+	Allow string
+
+	// IgnoreCase makes the pattern case-insensitive.
+	IgnoreCase bool
+}
+
+// ForSearch converts the search box input into a pattern.
+//
+// The result never contains the user-written repetitions or groups.
+// Empty alternatives are dropped, consecutive `*` are collapsed and `*`
+// at the unanchored alternative edges are dropped, as they don't change
+// the set of the matched texts. Empty input, or an input with a `*`-only
+// alternative, results in the empty pattern that matches any text.
+//
+// For DialectPCRE, the `*` wildcards are atomic and lazy, like
+// `a(?>.*?b)`, so a backtracking engine doesn't try all the ways to split
+// the input between several wildcards. Only the wildcard before a `$`
+// anchor stays greedy. The matched texts are the same, but the
+// matches are the shortest ones.
+//
+// An error is returned if the Dialect doesn't support the `(?i)` flag.
+func ForSearch(input string, opts *Options) (*syntax.Regexp, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	allowed := func(ch byte) bool {
+		return strings.IndexByte(opts.Allow, ch) != -1
+	}
+
+	alternatives := []string{input}
+	if allowed('|') {
+		alternatives = strings.Split(input, "|")
+	}
+	var parts []string
+	for _, alt := range alternatives {
+		p := convertAlternative(alt, opts.Dialect, allowed)
+		if p == "" && alt != "" {
+			// Only the `*` were trimmed, the alternative matches any text.
+			parts = nil
+			break
+		}
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	pattern := strings.Join(parts, "|")
+	if opts.IgnoreCase && pattern != "" {
+		if _, _, err := syntax.ParseFlags("i", opts.Dialect); err != nil {
+			return nil, err
+		}
+		pattern = "(?i)" + pattern
+	}
+	return syntax.NewParser(nil).Parse(pattern)
+}
+
+func convertAlternative(s string, d syntax.Dialect, allowed func(ch byte) bool) string {
+	var b strings.Builder
+	if allowed('^') && strings.HasPrefix(s, "^") {
+		b.WriteByte('^')
+		s = s[1:]
+	} else if allowed('*') {
+		s = strings.TrimLeft(s, "*")
+	}
+	anchoredEnd := allowed('$') && strings.HasSuffix(s, "$")
+	if anchoredEnd {
+		s = s[:len(s)-1]
+	} else if allowed('*') {
+		s = strings.TrimRight(s, "*")
+	}
+
+	segments := []string{s}
+	if allowed('*') {
+		segments = splitWildcards(s)
+	}
+	for i, seg := range segments {
+		text := convertSegment(seg, d, allowed)
+		switch {
+		case i == 0:
+			b.WriteString(text)
+		case d == syntax.DialectPCRE && !(anchoredEnd && i == len(segments)-1):
+			// The earliest segment match is never worse for the
+			// following segments, so it's never retried.
+			b.WriteString("(?>.*?" + text + ")")
+		default:
+			b.WriteString(".*" + text)
+		}
+	}
+
+	if anchoredEnd {
+		b.WriteByte('$')
+	}
+	return b.String()
+}
+
+// splitWildcards splits s by the `*` wildcards.
+// Consecutive wildcards are treated as a single one.
+func splitWildcards(s string) []string {
+	parts := strings.Split(s, "*")
+	segments := parts[:1]
+	for i, part := range parts[1:] {
+		if part == "" && i != len(parts)-2 {
+			continue
+		}
+		segments = append(segments, part)
+	}
+	return segments
+}
+
+// convertSegment converts the text between the `*` wildcards.
+func convertSegment(s string, d syntax.Dialect, allowed func(ch byte) bool) string {
+	var b strings.Builder
+	literal := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch != '?' && ch != '.') || !allowed(ch) {
+			continue
+		}
+		b.WriteString(syntax.QuoteMeta(s[literal:i], d))
+		b.WriteByte('.')
+		literal = i + 1
+	}
+	b.WriteString(syntax.QuoteMeta(s[literal:], d))
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestForSearch(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		want  string
+	}{
+		{`a.b*c`, Options{}, `a\.b\*c`},
+		{`a.b*c`, Options{Allow: "*"}, `a\.b.*c`},
+		{`a.b**c`, Options{Allow: "*."}, `a.b.*c`},
+		{`*foo*`, Options{Allow: "*"}, `foo`},
+		{`^*foo`, Options{Allow: "*^"}, `^.*foo`},
+		{`f?o`, Options{Allow: "?"}, `f.o`},
+		{`foo|bar||(baz)`, Options{Allow: "|"}, `foo|bar|\(baz\)`},
+		{`foo|bar`, Options{}, `foo\|bar`},
+		{`^foo$|a^b$c`, Options{Allow: "^$|"}, `^foo$|a\^b\$c`},
+		{`^foo$`, Options{}, `\^foo\$`},
+		{`Foo|bar`, Options{Allow: "|", IgnoreCase: true}, `(?i)Foo|bar`},
+		{`a b#`, Options{Dialect: syntax.DialectPCRE}, `a\ b\#`},
+		{`*`, Options{Allow: "*"}, ``},
+		{`a|*`, Options{Allow: "*|"}, ``},
+		{`*|a`, Options{Allow: "*|"}, ``},
+		{`a||**|b`, Options{Allow: "*|", IgnoreCase: true}, ``},
+		{`a|*`, Options{Allow: "|"}, `a|\*`},
+		{``, Options{IgnoreCase: true}, ``},
+		{`*a*a**a*`, Options{Allow: "*", Dialect: syntax.DialectPCRE}, `a(?>.*?a)(?>.*?a)`},
+		{`^*a*b.c$`, Options{Allow: "*.^$", Dialect: syntax.DialectPCRE}, `^(?>.*?a).*b.c$`},
+		{`a*b*$`, Options{Allow: "*$", Dialect: syntax.DialectPCRE}, `a(?>.*?b).*$`},
+		{`a*b`, Options{Allow: "*", Dialect: syntax.DialectPCRE, IgnoreCase: true}, `(?i)a(?>.*?b)`},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		re, err := ForSearch(test.input, &opts)
+		if err != nil {
+			t.Errorf("ForSearch(%q): %v", test.input, err)
+			continue
+		}
+		if re.Pattern != test.want {
+			t.Errorf("ForSearch(%q, %+v):\nhave: %s\nwant: %s", test.input, test.opts, re.Pattern, test.want)
+		}
+	}
+}
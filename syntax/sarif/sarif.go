@@ -0,0 +1,256 @@
+// Package sarif serializes regexp diagnostics as SARIF 2.1.0 logs.
+//
+// SARIF is the format consumed by GitHub code scanning and most other
+// static analysis platforms, so results produced with this package can
+// be uploaded as is.
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Level is a SARIF result level.
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNote    Level = "note"
+)
+
+// RuleParseError is a rule ID that is used for pattern parsing errors
+// that have no diagnostic code.
+const RuleParseError = "parse-error"
+
+// Tool describes the program that produced the results.
+type Tool struct {
+	Name           string
+	Version        string
+	InformationURI string
+}
+
+// Result is a single diagnostic that is reported inside a file.
+type Result struct {
+	RuleID  string
+	Level   Level
+	Message string
+
+	// URI is a path or URI of the file that contains the pattern.
+	URI string
+
+	// Offset is a pattern start offset inside the file (in bytes).
+	// Pos is added to it to get the reported region.
+	Offset int
+
+	// Pos is a diagnostic location inside the pattern.
+	Pos syntax.Position
+
+	// Line and Column are the 1-based region start, the Column
+	// is counted in Unicode code points. They are reported if
+	// the Line is not zero, see Locate.
+	Line   int
+	Column int
+
+	// Confidence is reported as the "confidence" result property.
+	// The zero value omits the property.
+	Confidence syntax.Confidence
+}
+
+// ParseErrorResult converts a syntax.ParseError or a syntax.LimitError
+// into a Result. The rule ID is the error code.
+//
+// The offset argument is a pattern start offset inside the file
+// identified by uri. If err is not a parse or limit error, ok is false.
+func ParseErrorResult(uri string, offset int, err error) (result Result, ok bool) {
+	result = Result{
+		Level:  LevelError,
+		URI:    uri,
+		Offset: offset,
+	}
+	var perr syntax.ParseError
+	var lerr syntax.LimitError
+	switch {
+	case errors.As(err, &perr):
+		result.RuleID = RuleParseError
+		if perr.Code != 0 {
+			result.RuleID = perr.Code.String()
+		}
+		result.Message = perr.Message
+		result.Pos = perr.Pos
+	case errors.As(err, &lerr):
+		result.RuleID = lerr.Code().String()
+		result.Message = lerr.Error()
+		result.Pos = lerr.Pos
+	default:
+		return Result{}, false
+	}
+	return result, true
+}
+
+// WarningResult converts a syntax.Warning into a Result.
+//
+// The rule ID is the warning code and the level is derived
+// from the warning severity. The offset argument is a pattern
+// start offset inside the file identified by uri.
+func WarningResult(uri string, offset int, w syntax.Warning) Result {
+	level := LevelWarning
+	switch w.Severity {
+	case syntax.SeverityInfo:
+		level = LevelNote
+	case syntax.SeverityError:
+		level = LevelError
+	}
+	return Result{
+		RuleID:     w.Code.String(),
+		Level:      level,
+		Message:    w.Message,
+		URI:        uri,
+		Offset:     offset,
+		Pos:        w.Pos,
+		Confidence: w.Confidence,
+	}
+}
+
+// Locate sets the results Line and Column. The data is the contents
+// of the file the results point into.
+func Locate(results []Result, data []byte) {
+	for i := range results {
+		r := &results[i]
+		offset := r.Offset + int(r.Pos.Begin)
+		if offset > len(data) {
+			continue
+		}
+		before := data[:offset]
+		lineStart := bytes.LastIndexByte(before, '\n') + 1
+		r.Line = bytes.Count(before, []byte("\n")) + 1
+		r.Column = utf8.RuneCount(before[lineStart:]) + 1
+	}
+}
+
+// Write encodes results as a single-run SARIF log.
+//
+// The regions are reported as the byte ranges, and as
+// the start lines and columns if they are known.
+func Write(w io.Writer, tool Tool, results []Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           tool.Name,
+			Version:        tool.Version,
+			InformationURI: tool.InformationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results:    make([]sarifResult, 0, len(results)),
+		ColumnKind: "unicodeCodePoints",
+	}
+
+	seenRules := make(map[string]bool)
+	for _, r := range results {
+		if !seenRules[r.RuleID] {
+			seenRules[r.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: r.RuleID})
+		}
+		level := r.Level
+		if level == "" {
+			level = LevelWarning
+		}
+		var props *sarifProperties
+		if r.Confidence != 0 {
+			props = &sarifProperties{Confidence: r.Confidence.String()}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     r.RuleID,
+			Level:      level,
+			Message:    sarifMessage{Text: r.Message},
+			Properties: props,
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: r.URI},
+					Region: sarifRegion{
+						StartLine:   r.Line,
+						StartColumn: r.Column,
+						ByteOffset:  r.Offset + int(r.Pos.Begin),
+						ByteLength:  int(r.Pos.End) - int(r.Pos.Begin),
+					},
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	Results    []sarifResult `json:"results"`
+	ColumnKind string        `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	Level      Level            `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Confidence string `json:"confidence"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
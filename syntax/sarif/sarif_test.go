@@ -0,0 +1,158 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestParseErrorResult(t *testing.T) {
+	p := syntax.NewParser(nil)
+	_, err := p.Parse(`foo(bar`)
+	if err == nil {
+		t.Fatal("expected a parse error")
+	}
+	r, ok := ParseErrorResult("main.go", 10, err)
+	if !ok {
+		t.Fatalf("ParseErrorResult(%v): not ok", err)
+	}
+	if r.RuleID != "RE1002" || r.Level != LevelError {
+		t.Errorf("unexpected rule/level: %s/%s", r.RuleID, r.Level)
+	}
+	if r.Message != err.Error() {
+		t.Errorf("message mismatch:\nhave: %s\nwant: %s", r.Message, err.Error())
+	}
+
+	if _, ok := ParseErrorResult("main.go", 0, errors.New("other")); ok {
+		t.Errorf("non-parse errors should not be converted")
+	}
+}
+
+func TestParseErrorResultLimit(t *testing.T) {
+	p := syntax.NewParser(&syntax.ParserOptions{Limits: syntax.Limits{MaxDepth: 2}})
+	_, err := p.Parse(`a((b))`)
+	if err == nil {
+		t.Fatal("expected a limit error")
+	}
+	r, ok := ParseErrorResult("main.go", 10, err)
+	if !ok {
+		t.Fatalf("ParseErrorResult(%v): not ok", err)
+	}
+	if r.RuleID != "RE1201" || r.Level != LevelError {
+		t.Errorf("unexpected rule/level: %s/%s", r.RuleID, r.Level)
+	}
+	if r.Message != err.Error() || r.Pos != (syntax.Position{Begin: 2, End: 3}) {
+		t.Errorf("unexpected message/pos: %s/%v", r.Message, r.Pos)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	results := []Result{
+		{RuleID: "r1", Message: "m1", URI: "a.go", Offset: 10, Pos: syntax.Position{Begin: 2, End: 5}},
+		{RuleID: "r1", Level: LevelNote, Message: "m2", URI: "b.go"},
+		{RuleID: "r2", Level: LevelError, Message: "m3", URI: "b.go"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, Tool{Name: "regexlint"}, results); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn, ByteOffset, ByteLength int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: %s", buf.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "regexlint" {
+		t.Errorf("tool name mismatch: %q", run.Tool.Driver.Name)
+	}
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("expected 2 unique rules, found %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, found %d", len(run.Results))
+	}
+	if run.Results[0].Level != "warning" {
+		t.Errorf("default level mismatch: %q", run.Results[0].Level)
+	}
+	region := run.Results[0].Locations[0].PhysicalLocation.Region
+	if region.ByteOffset != 12 || region.ByteLength != 3 || region.StartLine != 0 {
+		t.Errorf("region mismatch: %+v", region)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	data := []byte("x\nαβ = \"a(b\"\n")
+	results := []Result{
+		{Offset: 10, Pos: syntax.Position{Begin: 1, End: 3}},
+		{Offset: 0},
+	}
+	Locate(results, data)
+	if r := results[0]; r.Line != 2 || r.Column != 8 {
+		t.Errorf("first result location: %d:%d", r.Line, r.Column)
+	}
+	if r := results[1]; r.Line != 1 || r.Column != 1 {
+		t.Errorf("second result location: %d:%d", r.Line, r.Column)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Tool{Name: "regexlint"}, results[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"startLine": 2,`)) ||
+		!bytes.Contains(buf.Bytes(), []byte(`"startColumn": 8,`)) {
+		t.Errorf("start line and column are missing:\n%s", buf.String())
+	}
+}
+
+func TestWarningResult(t *testing.T) {
+	p := syntax.NewParser(&syntax.ParserOptions{HighByteEscapes: syntax.EscapeWarn})
+	re, err := p.Parse(`a\xFF`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(re.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, found %d", len(re.Warnings))
+	}
+	r := WarningResult("main.go", 10, re.Warnings[0])
+	if r.RuleID != "RE1102" || r.Level != LevelWarning || r.Confidence != syntax.ConfidenceMedium {
+		t.Errorf("unexpected rule/level/confidence: %s/%s/%s", r.RuleID, r.Level, r.Confidence)
+	}
+	if r.Offset != 10 || r.Pos != re.Warnings[0].Pos {
+		t.Errorf("unexpected location: %d %v", r.Offset, r.Pos)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Tool{Name: "regexlint"}, []Result{r}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"confidence": "medium"`)) {
+		t.Errorf("confidence property is missing:\n%s", buf.String())
+	}
+}
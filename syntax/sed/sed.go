@@ -0,0 +1,356 @@
+// Package sed parses the sed `s/pattern/replacement/flags` commands.
+package sed
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Options configure the command parsing.
+type Options struct {
+	// Extended enables the ERE pattern syntax, like `sed -E`.
+	// By default, the pattern is a BRE where `\(` is a group and `(` is a char.
+	Extended bool
+
+	// Parser are the options for the pattern parser.
+	Parser *syntax.ParserOptions
+}
+
+// Command is a parsed substitution command.
+type Command struct {
+	Source string
+	Delim  byte
+
+	// Pattern is the regexp as it's written inside the command.
+	Pattern string
+
+	// Regexp is the parsed Pattern. It's converted to the ERE form before
+	// the parsing, so the Regexp.Pattern may differ from the Pattern:
+	// escaped delimiters are unescaped and BRE operators are rewritten.
+	//
+	// An empty pattern reuses the last regexp in sed, Regexp is nil for it.
+	Regexp *syntax.Regexp
+
+	Replacement []Part
+
+	Flags Flags
+}
+
+// Flags are the substitution command flags.
+type Flags struct {
+	// Global is `g`: replace all matches.
+	Global bool
+
+	// Occurrence is `N`: replace only the Nth match.
+	// It's 0 if no number is specified.
+	Occurrence int
+
+	// Print is `p`: print the pattern space after the substitution.
+	Print bool
+
+	// IgnoreCase is `i` or `I`.
+	IgnoreCase bool
+
+	// Multiline is `m` or `M`.
+	Multiline bool
+
+	// Eval is `e`: execute the pattern space as a command.
+	Eval bool
+
+	// WriteFile is a `w filename` argument.
+	WriteFile string
+}
+
+// PartKind identifies a replacement part.
+type PartKind byte
+
+const (
+	// PartLiteral is a text, including the unescaped chars.
+	PartLiteral PartKind = iota
+
+	// PartMatch is `&` or `\0`, the entire match.
+	PartMatch
+
+	// PartGroup is `\1`-`\9`, a group reference.
+	PartGroup
+
+	// PartCase is a GNU case conversion: `\U`, `\L`, `\u`, `\l` or `\E`.
+	PartCase
+)
+
+// Part is a replacement template element.
+type Part struct {
+	Kind PartKind
+
+	// Value is the literal text for PartLiteral and the escape for PartCase.
+	Value string
+
+	// Group is a referenced group index for PartGroup.
+	Group int
+}
+
+// Parse parses the `s` command.
+//
+// Any char except a backslash and a newline can be used as a delimiter.
+// The command must not be followed by other commands.
+func Parse(command string, opts *Options) (*Command, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if len(command) < 2 || command[0] != 's' {
+		return nil, errors.New("expected `s' command")
+	}
+	delim := command[1]
+	if delim == '\\' || delim == '\n' {
+		return nil, errors.New("delimiter can't be a backslash or a newline")
+	}
+
+	pattern, rest, ok := splitSection(command[2:], delim, true)
+	if !ok {
+		return nil, errors.New("unterminated `s' command")
+	}
+	replacement, flags, ok := splitSection(rest, delim, false)
+	if !ok {
+		return nil, errors.New("unterminated `s' command")
+	}
+
+	cmd := &Command{Source: command, Delim: delim, Pattern: pattern}
+	var err error
+	cmd.Flags, err = parseFlags(flags)
+	if err != nil {
+		return nil, err
+	}
+	if pattern != "" {
+		p := syntax.NewParser(opts.Parser)
+		cmd.Regexp, err = p.Parse(convertPattern(pattern, delim, opts.Extended))
+		if err != nil {
+			return nil, err
+		}
+	}
+	cmd.Replacement = ParseReplacement(replacement, delim)
+
+	if cmd.Regexp != nil {
+		groups := countCaptures(cmd.Regexp.Expr)
+		for _, part := range cmd.Replacement {
+			if part.Kind == PartGroup && part.Group > groups {
+				return nil, errors.New("invalid reference \\" + strconv.Itoa(part.Group) + " on `s' command's RHS")
+			}
+		}
+	}
+	return cmd, nil
+}
+
+// splitSection returns the s prefix up to the unescaped delim
+// and the rest of s after the delim.
+//
+// If brackets is true, the delim inside a bracket expression
+// doesn't end the section, like in `s/[/]/x/`.
+func splitSection(s string, delim byte, brackets bool) (section, rest string, ok bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case delim:
+			return s[:i], s[i+1:], true
+		case '[':
+			if brackets {
+				i = bracketEnd(s, i) - 1
+			}
+		}
+	}
+	return "", "", false
+}
+
+func parseFlags(s string) (Flags, error) {
+	var flags Flags
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case 'g':
+			flags.Global = true
+		case 'p':
+			flags.Print = true
+		case 'i', 'I':
+			flags.IgnoreCase = true
+		case 'm', 'M':
+			flags.Multiline = true
+		case 'e':
+			flags.Eval = true
+		case 'w':
+			flags.WriteFile = strings.TrimLeft(s[i+1:], " \t")
+			if flags.WriteFile == "" {
+				return flags, errors.New("missing filename in r/R/w/W commands")
+			}
+			return flags, nil
+		case ' ', '\t':
+		default:
+			if ch < '0' || ch > '9' || flags.Occurrence != 0 {
+				return flags, errors.New("unknown option to `s'")
+			}
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			flags.Occurrence, _ = strconv.Atoi(s[i:j])
+			if flags.Occurrence == 0 {
+				return flags, errors.New("number option to `s' command may not be zero")
+			}
+			i = j - 1
+		}
+	}
+	return flags, nil
+}
+
+// breOperators are the chars that are operators in ERE and
+// literal chars in BRE. In BRE, they are operators when escaped.
+const breOperators = "(){}+?|"
+
+// ConvertBRE returns the BRE pattern in the ERE form,
+// so it can be parsed by the syntax package.
+//
+// The `\(`, `\)`, `\{`, `\}`, `\+`, `\?` and `\|` escapes become operators,
+// while the plain chars become escaped. Bracket expressions are copied as is.
+//
+// The `*` at the expression start, the `^` that is not at the start and
+// the `$` that is not at the end are literal chars in BRE, so they are escaped.
+func ConvertBRE(pattern string) string {
+	return convertPattern(pattern, 0, false)
+}
+
+// convertPattern returns the sed pattern in the ERE form.
+// delim is the command delimiter, 0 means that there is no delimiter.
+func convertPattern(s string, delim byte, extended bool) string {
+	var b strings.Builder
+	// atStart reports whether the BRE expression starts at s[i],
+	// after `\(`, `\|` or an anchor `^`.
+	atStart := true
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		wasStart := atStart
+		atStart = false
+		switch {
+		case ch == '[':
+			end := bracketEnd(s, i)
+			b.WriteString(s[i:end])
+			i = end - 1
+		case ch == '\\' && i+1 < len(s):
+			i++
+			switch next := s[i]; {
+			case next == delim && delim != 0:
+				b.WriteString(syntax.QuoteMeta(string(next), syntax.DialectRE2))
+			case !extended && strings.IndexByte(breOperators, next) != -1:
+				b.WriteByte(next)
+				atStart = next == '(' || next == '|'
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			}
+		case !extended && strings.IndexByte(breOperators, ch) != -1:
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case !extended && ch == '*' && wasStart:
+			b.WriteString(`\*`)
+		case !extended && ch == '^':
+			if !wasStart {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(ch)
+			atStart = wasStart
+		case !extended && ch == '$' && !breExprEnd(s[i+1:]):
+			b.WriteString(`\$`)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// breExprEnd reports whether the BRE expression ends before s,
+// so the preceding `$` is an anchor.
+func breExprEnd(s string) bool {
+	return s == "" || strings.HasPrefix(s, `\)`) || strings.HasPrefix(s, `\|`)
+}
+
+// bracketEnd returns the index after the bracket expression that starts at s[begin].
+// The `]` is a literal char at the expression start, `[:alpha:]` classes are skipped.
+func bracketEnd(s string, begin int) int {
+	i := begin + 1
+	if i < len(s) && s[i] == '^' {
+		i++
+	}
+	if i < len(s) && s[i] == ']' {
+		i++
+	}
+	for i < len(s) {
+		if s[i] == ']' {
+			return i + 1
+		}
+		if s[i] == '[' && i+1 < len(s) && strings.IndexByte(":.=", s[i+1]) != -1 {
+			if end := strings.Index(s[i+2:], s[i+1:i+2]+"]"); end != -1 {
+				i += end + 4
+				continue
+			}
+		}
+		i++
+	}
+	return len(s)
+}
+
+// ParseReplacement parses the replacement section of the `s` command.
+//
+// delim is the command delimiter, its escapes are unescaped.
+func ParseReplacement(s string, delim byte) []Part {
+	var parts []Part
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() != 0 {
+			parts = append(parts, Part{Kind: PartLiteral, Value: lit.String()})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch == '&' {
+			flush()
+			parts = append(parts, Part{Kind: PartMatch, Value: "&"})
+			continue
+		}
+		if ch != '\\' || i == len(s)-1 {
+			lit.WriteByte(ch)
+			continue
+		}
+		i++
+		switch next := s[i]; {
+		case next == '0' && delim != '0':
+			flush()
+			parts = append(parts, Part{Kind: PartMatch, Value: s[i-1 : i+1]})
+		case next >= '1' && next <= '9':
+			flush()
+			parts = append(parts, Part{Kind: PartGroup, Value: s[i-1 : i+1], Group: int(next - '0')})
+		case strings.IndexByte("ULulE", next) != -1:
+			flush()
+			parts = append(parts, Part{Kind: PartCase, Value: s[i-1 : i+1]})
+		case next == 'n' && delim != 'n':
+			lit.WriteByte('\n')
+		case next == 't' && delim != 't':
+			lit.WriteByte('\t')
+		default:
+			lit.WriteByte(next)
+		}
+	}
+	flush()
+	return parts
+}
+
+func countCaptures(e syntax.Expr) int {
+	n := 0
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		n++
+	}
+	for _, a := range e.Args {
+		n += countCaptures(a)
+	}
+	return n
+}
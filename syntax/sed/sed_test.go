@@ -0,0 +1,147 @@
+package sed
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		command string
+		opts    *Options
+		pattern string
+		parts   []Part
+		flags   Flags
+	}{
+		{
+			command: `s/a\(b*\)c/[\1]/g`,
+			pattern: `a(b*)c`,
+			parts: []Part{
+				{Kind: PartLiteral, Value: "["},
+				{Kind: PartGroup, Value: `\1`, Group: 1},
+				{Kind: PartLiteral, Value: "]"},
+			},
+			flags: Flags{Global: true},
+		},
+		{
+			command: `s|(x)+\|y|&\n|2p`,
+			pattern: `\(x\)\+\|y`,
+			parts: []Part{
+				{Kind: PartMatch, Value: "&"},
+				{Kind: PartLiteral, Value: "\n"},
+			},
+			flags: Flags{Occurrence: 2, Print: true},
+		},
+		{
+			command: `s|(x)+\|y|\U\1\E|`,
+			opts:    &Options{Extended: true},
+			pattern: `(x)+\|y`,
+			parts: []Part{
+				{Kind: PartCase, Value: `\U`},
+				{Kind: PartGroup, Value: `\1`, Group: 1},
+				{Kind: PartCase, Value: `\E`},
+			},
+		},
+		{
+			command: `s,/usr\,[]a]x\{2\},a\,b\&,Iw out.txt`,
+			pattern: `/usr,[]a]x{2}`,
+			parts:   []Part{{Kind: PartLiteral, Value: "a,b&"}},
+			flags:   Flags{IgnoreCase: true, WriteFile: "out.txt"},
+		},
+		{
+			command: `s.a\.b.c.`,
+			pattern: `a\.b`,
+			parts:   []Part{{Kind: PartLiteral, Value: "c"}},
+		},
+		{
+			command: `s/[[:alpha:]]*//`,
+			pattern: `[[:alpha:]]*`,
+		},
+		{
+			command: `s/[/]/X/`,
+			pattern: `[/]`,
+			parts:   []Part{{Kind: PartLiteral, Value: "X"}},
+		},
+		{
+			command: `s/*x/Y/`,
+			pattern: `\*x`,
+			parts:   []Part{{Kind: PartLiteral, Value: "Y"}},
+		},
+		{
+			command: `s/\(*a\)\|**b/Z/`,
+			pattern: `(\*a)|\**b`,
+			parts:   []Part{{Kind: PartLiteral, Value: "Z"}},
+		},
+		{
+			command: `s/^*a^b$c$/Z/`,
+			pattern: `^\*a\^b\$c$`,
+			parts:   []Part{{Kind: PartLiteral, Value: "Z"}},
+		},
+		{
+			command: `s/x*\(^y$\)$/Z/`,
+			pattern: `x*(^y$)$`,
+			parts:   []Part{{Kind: PartLiteral, Value: "Z"}},
+		},
+		{
+			command: `s/x/<\0>/`,
+			pattern: `x`,
+			parts: []Part{
+				{Kind: PartLiteral, Value: "<"},
+				{Kind: PartMatch, Value: `\0`},
+				{Kind: PartLiteral, Value: ">"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cmd, err := Parse(test.command, test.opts)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.command, err)
+			continue
+		}
+		if cmd.Regexp.Pattern != test.pattern {
+			t.Errorf("Parse(%q) pattern:\nhave: %s\nwant: %s", test.command, cmd.Regexp.Pattern, test.pattern)
+		}
+		if !reflect.DeepEqual(cmd.Replacement, test.parts) {
+			t.Errorf("Parse(%q) replacement:\nhave: %+v\nwant: %+v", test.command, cmd.Replacement, test.parts)
+		}
+		if cmd.Flags != test.flags {
+			t.Errorf("Parse(%q) flags:\nhave: %+v\nwant: %+v", test.command, cmd.Flags, test.flags)
+		}
+	}
+}
+
+func TestParseEmptyPattern(t *testing.T) {
+	cmd, err := Parse(`s//x/`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Regexp != nil || cmd.Pattern != "" {
+		t.Errorf("unexpected pattern: %+v", cmd)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{`y/a/b/`, "expected `s' command"},
+		{`s\a\b\`, "delimiter can't be a backslash or a newline"},
+		{`s/a/b`, "unterminated `s' command"},
+		{`s/a\/b`, "unterminated `s' command"},
+		{`s/a/b/q`, "unknown option to `s'"},
+		{`s/a/b/1g2`, "unknown option to `s'"},
+		{`s/a/b/0`, "number option to `s' command may not be zero"},
+		{`s/a/b/w`, "missing filename in r/R/w/W commands"},
+		{`s/\(a\)/\2/`, "invalid reference \\2 on `s' command's RHS"},
+		{`s/[a/x/`, "unterminated `s' command"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.command, nil)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Parse(%q):\nhave: %v\nwant: %s", test.command, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package serve exposes the pattern analyses over HTTP.
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/format"
+	"github.com/quasilyte/regex/syntax/playground"
+)
+
+// maxRequestSize limits the request body size.
+const maxRequestSize = 1 << 20
+
+// Request is a JSON body of all endpoints requests.
+type Request struct {
+	Pattern string             `json:"pattern"`
+	Options playground.Options `json:"options"`
+}
+
+// NewHandler returns a handler that serves the following endpoints:
+//
+//	/parse      the pattern AST
+//	/explain    the AST with node descriptions and named groups docs
+//	/lint       warnings, complexity and dialect compatibility issues
+//	/translate  the pattern rewritten for the options dialect
+//
+// All endpoints accept a POST with a JSON-encoded Request and respond
+// with a subset of the playground.Result fields. The translate endpoint
+// responds with the "pattern" field and the "compat" field that lists
+// the features that can't be translated.
+//
+// Pattern errors are reported with the 200 status inside the response
+// "error" field, the malformed requests get the 400 status.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/parse", endpoint(func(req *Request, r *playground.Result) interface{} {
+		return selectFields(r, "tree")
+	}))
+	mux.Handle("/explain", endpoint(func(req *Request, r *playground.Result) interface{} {
+		return selectFields(r, "tree", "captures")
+	}))
+	mux.Handle("/lint", endpoint(func(req *Request, r *playground.Result) interface{} {
+		return selectFields(r, "warnings", "complexity", "compat")
+	}))
+	mux.Handle("/translate", endpoint(translate))
+	return mux
+}
+
+type errorResponse struct {
+	Error playground.Diagnostic `json:"error"`
+}
+
+func endpoint(respond func(*Request, *playground.Result) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+				Error: playground.Diagnostic{Message: "method not allowed"},
+			})
+			return
+		}
+		var req Request
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err := dec.Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error: playground.Diagnostic{Message: "decode request: " + err.Error()},
+			})
+			return
+		}
+		result, err := playground.Inspect(req.Pattern, req.Options)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error: playground.Diagnostic{Message: err.Error()},
+			})
+			return
+		}
+		if result.Error != nil {
+			writeJSON(w, http.StatusOK, errorResponse{Error: *result.Error})
+			return
+		}
+		writeJSON(w, http.StatusOK, respond(&req, result))
+	})
+}
+
+// selectFields returns a copy of r with only the specified fields set.
+func selectFields(r *playground.Result, fields ...string) *playground.Result {
+	var out playground.Result
+	for _, f := range fields {
+		switch f {
+		case "tree":
+			out.Tree = r.Tree
+		case "captures":
+			out.Captures = r.Captures
+		case "warnings":
+			out.Warnings = r.Warnings
+		case "complexity":
+			out.Complexity = r.Complexity
+		case "compat":
+			out.Compat = r.Compat
+		}
+	}
+	return &out
+}
+
+type translateResponse struct {
+	Pattern string             `json:"pattern"`
+	Compat  *playground.Compat `json:"compat"`
+}
+
+// translate removes the comments and the free-spacing layout
+// and uses the `(?P<name>re)` groups syntax for RE2.
+func translate(req *Request, r *playground.Result) interface{} {
+	if r.Compat.Dialect != syntax.DialectRE2.String() {
+		return translateResponse{Pattern: req.Pattern, Compat: r.Compat}
+	}
+	flags, _, _ := syntax.ParseFlags(req.Options.Flags, syntax.DialectPCRE)
+	re, err := syntax.NewParser(nil).Parse(format.Compact(req.Pattern, flags))
+	if err != nil {
+		return translateResponse{Pattern: req.Pattern, Compat: r.Compat}
+	}
+	pattern := format.Canonical(re, format.StyleOptions{NamedGroups: format.NamedGroupsP})
+	result, err := playground.Inspect(pattern, req.Options)
+	if err != nil || result.Error != nil {
+		return translateResponse{Pattern: req.Pattern, Compat: r.Compat}
+	}
+	return translateResponse{Pattern: pattern, Compat: result.Compat}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	// An encoding error means that the client is gone.
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			"POST", "/parse", `{"pattern":"a."}`, 200,
+			`{"tree":{"op":"Concat","begin":0,"end":2,"value":"a.","description":"sequence","args":[` +
+				`{"op":"Char","begin":0,"end":1,"value":"a","description":"literal \"a\""},` +
+				`{"op":"Dot","begin":1,"end":2,"value":".","description":"any char"}]}}`,
+		},
+		{
+			"POST", "/explain", `{"pattern":"(?P<x>y)"}`, 200,
+			`{"tree":{"op":"NamedCapture","begin":0,"end":8,"value":"(?P<x>y)","description":"capture group \"x\"","args":[` +
+				`{"op":"Char","begin":6,"end":7,"value":"y","description":"literal \"y\""},` +
+				`{"op":"String","begin":4,"end":5,"value":"x"}]},` +
+				`"captures":[{"name":"x","index":1,"matches":"literal \"y\""}]}`,
+		},
+		{
+			"POST", "/lint", `{"pattern":"(?<=a)b"}`, 200,
+			`{"complexity":6,"compat":{"dialect":"RE2","unsupported":["lookbehind"]}}`,
+		},
+		{
+			"POST", "/lint", `{"pattern":"(?<=a)b","options":{"dialect":"PCRE"}}`, 200,
+			`{"complexity":6,"compat":{"dialect":"PCRE"}}`,
+		},
+		{
+			"POST", "/translate", `{"pattern":"(?x) (?<year> \\d{4} ) # year\n(?=-)"}`, 200,
+			`{"pattern":"(?P<year>\\d{4})(?=-)","compat":{"dialect":"RE2","unsupported":["lookahead"]}}`,
+		},
+		{
+			"POST", "/translate", `{"pattern":"(?<x>a) b","options":{"dialect":"PCRE"}}`, 200,
+			`{"pattern":"(?<x>a) b","compat":{"dialect":"PCRE"}}`,
+		},
+		{
+			"POST", "/parse", `{"pattern":"a("}`, 200,
+			`{"error":{"code":"RE1001","message":"unexpected token: None","begin":0,"end":0}}`,
+		},
+		{
+			"POST", "/parse", `{"pattern":"a","options":{"dialect":"JS"}}`, 400,
+			`{"error":{"message":"unknown dialect: JS","begin":0,"end":0}}`,
+		},
+		{
+			"POST", "/parse", `{`, 400,
+			`{"error":{"message":"decode request: unexpected EOF","begin":0,"end":0}}`,
+		},
+		{
+			"GET", "/parse", ``, 405,
+			`{"error":{"message":"method not allowed","begin":0,"end":0}}`,
+		},
+	}
+
+	h := NewHandler()
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		have := strings.TrimSuffix(rec.Body.String(), "\n")
+		if rec.Code != test.status || have != test.want {
+			t.Errorf("%s %s %s:\nhave: %d %s\nwant: %d %s",
+				test.method, test.path, test.body, rec.Code, have, test.status, test.want)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s: content type is %q", test.method, test.path, ct)
+		}
+	}
+}
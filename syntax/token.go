@@ -0,0 +1,185 @@
+package syntax
+
+// TokenKind classifies a pattern token.
+type TokenKind byte
+
+const (
+	TokenNone TokenKind = iota
+
+	TokenChar        // A literal char
+	TokenEscapeChar  // `\n` `\d` `\b`
+	TokenEscapeMeta  // `\.` `\(`
+	TokenEscapeOctal // `\12`
+	TokenEscapeHex   // `\x41` `\x{41}`
+	TokenEscapeUni   // `\pL` `\p{Greek}`
+	TokenQuote       // `\Q...\E`
+	TokenPosixClass  // `[:alpha:]`
+	TokenComment     // `(?#...)`
+	TokenRepeat      // `{2}` `{1,}`
+	TokenPlaceholder // `%s` `${name}` `{{.Var}}`
+
+	TokenMinus         // `-`, it can also be a literal char inside a char class
+	TokenLbracket      // `[`
+	TokenLbracketCaret // `[^`
+	TokenRbracket      // `]`
+	TokenDollar        // `$`
+	TokenCaret         // `^`
+	TokenQuestion      // `?`
+	TokenDot           // `.`
+	TokenPlus          // `+`
+	TokenStar          // `*`
+	TokenPipe          // `|`
+
+	TokenLparen                   // `(`
+	TokenLparenName               // `(?P<name>` `(?<name>` `(?'name'`
+	TokenLparenFlags              // `(?flags` and `(?flags:`
+	TokenLparenAtomic             // `(?>`
+	TokenLparenPositiveLookahead  // `(?=`
+	TokenLparenNegativeLookahead  // `(?!`
+	TokenLparenPositiveLookbehind // `(?<=`
+	TokenLparenNegativeLookbehind // `(?<!`
+	TokenRparen                   // `)`
+)
+
+var tokenKindNames = [...]string{
+	TokenNone:                     "None",
+	TokenChar:                     "Char",
+	TokenEscapeChar:               "EscapeChar",
+	TokenEscapeMeta:               "EscapeMeta",
+	TokenEscapeOctal:              "EscapeOctal",
+	TokenEscapeHex:                "EscapeHex",
+	TokenEscapeUni:                "EscapeUni",
+	TokenQuote:                    "Quote",
+	TokenPosixClass:               "PosixClass",
+	TokenComment:                  "Comment",
+	TokenRepeat:                   "Repeat",
+	TokenPlaceholder:              "Placeholder",
+	TokenMinus:                    "Minus",
+	TokenLbracket:                 "Lbracket",
+	TokenLbracketCaret:            "LbracketCaret",
+	TokenRbracket:                 "Rbracket",
+	TokenDollar:                   "Dollar",
+	TokenCaret:                    "Caret",
+	TokenQuestion:                 "Question",
+	TokenDot:                      "Dot",
+	TokenPlus:                     "Plus",
+	TokenStar:                     "Star",
+	TokenPipe:                     "Pipe",
+	TokenLparen:                   "Lparen",
+	TokenLparenName:               "LparenName",
+	TokenLparenFlags:              "LparenFlags",
+	TokenLparenAtomic:             "LparenAtomic",
+	TokenLparenPositiveLookahead:  "LparenPositiveLookahead",
+	TokenLparenNegativeLookahead:  "LparenNegativeLookahead",
+	TokenLparenPositiveLookbehind: "LparenPositiveLookbehind",
+	TokenLparenNegativeLookbehind: "LparenNegativeLookbehind",
+	TokenRparen:                   "Rparen",
+}
+
+func (k TokenKind) String() string {
+	if int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return "TokenKind(?)"
+}
+
+// Token is a lexical pattern element.
+type Token struct {
+	Kind TokenKind
+	Pos  Position
+}
+
+var tokenKindOf = [...]TokenKind{
+	tokChar:                     TokenChar,
+	tokPosixClass:               TokenPosixClass,
+	tokRepeat:                   TokenRepeat,
+	tokEscapeChar:               TokenEscapeChar,
+	tokEscapeMeta:               TokenEscapeMeta,
+	tokEscapeOctal:              TokenEscapeOctal,
+	tokEscapeUni:                TokenEscapeUni,
+	tokEscapeUniFull:            TokenEscapeUni,
+	tokEscapeHex:                TokenEscapeHex,
+	tokEscapeHexFull:            TokenEscapeHex,
+	tokComment:                  TokenComment,
+	tokPlaceholder:              TokenPlaceholder,
+	tokQ:                        TokenQuote,
+	tokMinus:                    TokenMinus,
+	tokLbracket:                 TokenLbracket,
+	tokLbracketCaret:            TokenLbracketCaret,
+	tokRbracket:                 TokenRbracket,
+	tokDollar:                   TokenDollar,
+	tokCaret:                    TokenCaret,
+	tokQuestion:                 TokenQuestion,
+	tokDot:                      TokenDot,
+	tokPlus:                     TokenPlus,
+	tokStar:                     TokenStar,
+	tokPipe:                     TokenPipe,
+	tokLparen:                   TokenLparen,
+	tokLparenName:               TokenLparenName,
+	tokLparenNameAngle:          TokenLparenName,
+	tokLparenNameQuote:          TokenLparenName,
+	tokLparenFlags:              TokenLparenFlags,
+	tokLparenAtomic:             TokenLparenAtomic,
+	tokLparenPositiveLookahead:  TokenLparenPositiveLookahead,
+	tokLparenPositiveLookbehind: TokenLparenPositiveLookbehind,
+	tokLparenNegativeLookahead:  TokenLparenNegativeLookahead,
+	tokLparenNegativeLookbehind: TokenLparenNegativeLookbehind,
+	tokRparen:                   TokenRparen,
+}
+
+// Tokenizer splits patterns into tokens.
+//
+// It's cheaper than a full parsing, so it can be used by the
+// syntax highlighters and other tools that don't need an AST.
+// The zero value is ready to use. Internal buffers are reused
+// between the Tokenize calls.
+type Tokenizer struct {
+	// Latin1 has the same meaning as the ParserOptions.Latin1.
+	Latin1 bool
+
+	// Placeholders has the same meaning as the ParserOptions.Placeholders.
+	Placeholders PlaceholderSyntax
+
+	lexer  lexer
+	tokens []Token
+}
+
+// Tokens is a convenience wrapper around the Tokenizer.Tokenize.
+func Tokens(pattern string) ([]Token, error) {
+	var t Tokenizer
+	return t.Tokenize(pattern)
+}
+
+// Tokenize returns the pattern tokens in the source order.
+//
+// The returned slice is valid until the next Tokenize call.
+// Tokens follow the lexical structure of the pattern, so the chars
+// that are interpreted as literals by the parser (like `-` at the
+// char class end) may be reported as meta tokens.
+// Incomplete tokens like unterminated groups names result in a ParseError.
+func (t *Tokenizer) Tokenize(pattern string) (tokens []Token, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if err2, ok := r.(ParseError); ok {
+			tokens = nil
+			err = err2
+			return
+		}
+		panic(r)
+	}()
+
+	t.lexer.latin1 = t.Latin1
+	t.lexer.placeholders = t.Placeholders
+	t.lexer.Init(pattern)
+	t.tokens = t.tokens[:0]
+	for _, tok := range t.lexer.tokens {
+		if tok.kind == tokConcat {
+			continue
+		}
+		t.tokens = append(t.tokens, Token{Kind: tokenKindOf[tok.kind], Pos: tok.pos})
+	}
+	return t.tokens, nil
+}
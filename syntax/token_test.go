@@ -0,0 +1,63 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{``, ``},
+		{`ab`, `Char(a) Char(b)`},
+		{`a+|b*?`, `Char(a) Plus(+) Pipe(|) Char(b) Star(*) Question(?)`},
+		{`x{2,}\.\d\x{41}\101\pL`, `Char(x) Repeat({2,}) EscapeMeta(\.) EscapeChar(\d) EscapeHex(\x{41}) EscapeOctal(\101) EscapeUni(\pL)`},
+		{`[^a-z[:digit:]]`, `LbracketCaret([^) Char(a) Minus(-) Char(z) PosixClass([:digit:]) Rbracket(])`},
+		{`(?P<n>x)(?i:y)(?#c)`, `LparenName((?P<n>) Char(x) Rparen()) LparenFlags((?i:) Char(y) Rparen()) Comment((?#c))`},
+		{`(?=a)(?<!b)(?>c)`, `LparenPositiveLookahead((?=) Char(a) Rparen()) LparenNegativeLookbehind((?<!) Char(b) Rparen()) LparenAtomic((?>) Char(c) Rparen())`},
+		{`^\Qa.b\E$`, `Caret(^) Quote(\Qa.b\E) Dollar($)`},
+		{`юя`, `Char(ю) Char(я)`},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokens(test.pattern)
+		if err != nil {
+			t.Errorf("tokens(%q): %v", test.pattern, err)
+			continue
+		}
+		parts := make([]string, len(tokens))
+		for i, tok := range tokens {
+			parts[i] = fmt.Sprintf("%s(%s)", tok.Kind, test.pattern[tok.Pos.Begin:tok.Pos.End])
+		}
+		have := strings.Join(parts, " ")
+		if have != test.want {
+			t.Errorf("tokens(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestTokensError(t *testing.T) {
+	_, err := Tokens(`(?`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok := err.(ParseError); !ok {
+		t.Errorf("expected a ParseError, got %T", err)
+	}
+
+	var tokenizer Tokenizer
+	tokenizer.Latin1 = true
+	tokens, err := tokenizer.Tokenize("\xff\xfe")
+	if err != nil || len(tokens) != 2 {
+		t.Errorf("latin1 tokens: %v %v", tokens, err)
+	}
+
+	tokenizer = Tokenizer{Placeholders: PlaceholdersAll}
+	tokens, err = tokenizer.Tokenize(`%s+{{.X}}`)
+	if err != nil || len(tokens) != 3 || tokens[0].Kind != TokenPlaceholder || tokens[2].Kind != TokenPlaceholder {
+		t.Errorf("placeholder tokens: %v %v", tokens, err)
+	}
+}
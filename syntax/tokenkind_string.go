@@ -22,34 +22,36 @@ func _() {
 	_ = x[tokEscapeHex-11]
 	_ = x[tokEscapeHexFull-12]
 	_ = x[tokComment-13]
-	_ = x[tokQ-14]
-	_ = x[tokMinus-15]
-	_ = x[tokLbracket-16]
-	_ = x[tokLbracketCaret-17]
-	_ = x[tokRbracket-18]
-	_ = x[tokDollar-19]
-	_ = x[tokCaret-20]
-	_ = x[tokQuestion-21]
-	_ = x[tokDot-22]
-	_ = x[tokPlus-23]
-	_ = x[tokStar-24]
-	_ = x[tokPipe-25]
-	_ = x[tokLparen-26]
-	_ = x[tokLparenName-27]
-	_ = x[tokLparenNameAngle-28]
-	_ = x[tokLparenNameQuote-29]
-	_ = x[tokLparenFlags-30]
-	_ = x[tokLparenAtomic-31]
-	_ = x[tokLparenPositiveLookahead-32]
-	_ = x[tokLparenPositiveLookbehind-33]
-	_ = x[tokLparenNegativeLookahead-34]
-	_ = x[tokLparenNegativeLookbehind-35]
-	_ = x[tokRparen-36]
+	_ = x[tokPlaceholder-14]
+	_ = x[tokQ-15]
+	_ = x[tokMinus-16]
+	_ = x[tokLbracket-17]
+	_ = x[tokLbracketCaret-18]
+	_ = x[tokRbracket-19]
+	_ = x[tokDollar-20]
+	_ = x[tokCaret-21]
+	_ = x[tokQuestion-22]
+	_ = x[tokDot-23]
+	_ = x[tokPlus-24]
+	_ = x[tokStar-25]
+	_ = x[tokPipe-26]
+	_ = x[tokLparen-27]
+	_ = x[tokLparenName-28]
+	_ = x[tokLparenNameAngle-29]
+	_ = x[tokLparenNameQuote-30]
+	_ = x[tokLparenFlags-31]
+	_ = x[tokLparenAtomic-32]
+	_ = x[tokLparenPositiveLookahead-33]
+	_ = x[tokLparenPositiveLookbehind-34]
+	_ = x[tokLparenNegativeLookahead-35]
+	_ = x[tokLparenNegativeLookbehind-36]
+	_ = x[tokLparenScriptRun-37]
+	_ = x[tokRparen-38]
 }
 
-const _tokenKind_name = "NoneCharGroupFlagsPosixClassConcatRepeatEscapeCharEscapeMetaEscapeOctalEscapeUniEscapeUniFullEscapeHexEscapeHexFullComment\\Q-[[^]$^?.+*|((?P<name>(?<name>(?'name'(?flags(?>(?=(?<=(?!(?<!)"
+const _tokenKind_name = "NoneCharGroupFlagsPosixClassConcatRepeatEscapeCharEscapeMetaEscapeOctalEscapeUniEscapeUniFullEscapeHexEscapeHexFullCommentPlaceholder\\Q-[[^]$^?.+*|((?P<name>(?<name>(?'name'(?flags(?>(?=(?<=(?!(?<!(*sr:)"
 
-var _tokenKind_index = [...]uint8{0, 4, 8, 18, 28, 34, 40, 50, 60, 71, 80, 93, 102, 115, 122, 124, 125, 126, 128, 129, 130, 131, 132, 133, 134, 135, 136, 137, 146, 154, 162, 169, 172, 175, 179, 182, 186, 187}
+var _tokenKind_index = [...]uint8{0, 4, 8, 18, 28, 34, 40, 50, 60, 71, 80, 93, 102, 115, 122, 133, 135, 136, 137, 139, 140, 141, 142, 143, 144, 145, 146, 147, 148, 157, 165, 173, 180, 183, 186, 190, 193, 197, 202, 203}
 
 func (i tokenKind) String() string {
 	if i >= tokenKind(len(_tokenKind_index)-1) {
@@ -0,0 +1,45 @@
+package transform
+
+import (
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// DemoteGroups converts the capturing groups that are not needed
+// to match the pattern into the non-capturing ones,
+// see analysis.DemotableGroups.
+//
+// The result matches the same strings, so it can be used
+// whenever the submatches are not requested.
+// ok is false if there is nothing to demote.
+func DemoteGroups(re *syntax.Regexp) (pattern string, ok bool) {
+	indexes := analysis.DemotableGroups(re)
+	if len(indexes) == 0 {
+		return re.Pattern, false
+	}
+	demote := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		demote[i] = true
+	}
+
+	var edits []edit
+	index := 0
+	walk(re.Expr, func(e syntax.Expr) {
+		switch e.Op {
+		case syntax.OpCapture, syntax.OpNamedCapture:
+			index++
+		default:
+			return
+		}
+		if !demote[index] {
+			return
+		}
+		end := int(e.Begin()) + len("(")
+		if e.Op == syntax.OpNamedCapture {
+			// Skip the name and its closing `>` or `'`.
+			end = int(e.Args[1].End()) + 1
+		}
+		edits = append(edits, edit{int(e.Begin()), end, "(?:"})
+	})
+	return applyEdits(re.Pattern, edits), true
+}
@@ -0,0 +1,31 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestDemoteGroups(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(a|b)+c`, `(?:a|b)+c`},
+		{`(?P<x>a)(?<y>b)(?'z'c)`, `(?:a)(?:b)(?:c)`},
+		{`(a)(b)\1`, `(a)(?:b)\1`},
+		{`(?:a)`, `(?:a)`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, ok := DemoteGroups(re)
+		if have != test.want || ok != (test.want != test.pattern) {
+			t.Errorf("DemoteGroups(%q):\nhave: %s %v\nwant: %s", test.pattern, have, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package transform
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Cleanup removes the sub-expressions that don't affect the matching.
+//
+// It's intended to be used after other rewrites that can leave
+// such nodes behind. The removed nodes are:
+//
+//   - unreachable alternation branches: duplicates of earlier branches
+//     and branches that can never match, like `(?!)` or `[^\d\D]`
+//   - zero-width no-op groups, like `(?:)` and `(?=)`
+//   - zero repetitions, like `x{0}`; only the last char of a quote
+//     is repeated, so `\Qab\E{0}` becomes `\Qa\E`
+//
+// Sub-expressions that contain capturing groups are never removed,
+// so the group numbering is preserved.
+//
+// e is not modified. Positions and values of the rewritten nodes
+// are not updated, use Print to get the resulting pattern.
+func Cleanup(e syntax.Expr) syntax.Expr {
+	return emptyIfNoop(cleanup(e))
+}
+
+func cleanup(e syntax.Expr) syntax.Expr {
+	switch e.Op {
+	case syntax.OpConcat:
+		args := make([]syntax.Expr, 0, len(e.Args))
+		for i, a := range e.Args {
+			a = cleanup(a)
+			if isNoop(a) && !isJoinAmbiguous(args, e.Args[i+1:]) {
+				continue
+			}
+			args = append(args, a)
+		}
+		if len(args) == 1 {
+			return args[0]
+		}
+		e.Args = args
+		return e
+
+	case syntax.OpAlt:
+		args := make([]syntax.Expr, 0, len(e.Args))
+		seen := make(map[string]bool, len(e.Args))
+		for _, a := range e.Args {
+			a = emptyIfNoop(cleanup(a))
+			if !hasCapture(a) {
+				s := Print(a)
+				if seen[s] || neverMatches(a) {
+					continue
+				}
+				seen[s] = true
+			}
+			args = append(args, a)
+		}
+		switch len(args) {
+		case 0:
+			// All branches are dead, any of them can represent the result.
+			return cleanup(e.Args[0])
+		case 1:
+			return args[0]
+		}
+		e.Args = args
+		return e
+
+	case syntax.OpCharClass, syntax.OpNegCharClass, syntax.OpCharRange, syntax.OpLiteral:
+		return e
+
+	default:
+		if q, ok := zeroRepeatedQuote(e); ok {
+			return trimLastQuoted(q)
+		}
+		if len(e.Args) != 0 {
+			args := make([]syntax.Expr, len(e.Args))
+			copy(args, e.Args)
+			args[0] = cleanup(args[0])
+			e.Args = args
+		}
+		return e
+	}
+}
+
+// emptyIfNoop replaces a no-op e with an empty expression.
+func emptyIfNoop(e syntax.Expr) syntax.Expr {
+	if isNoop(e) {
+		return syntax.Expr{Op: syntax.OpEmptyMatch, Pos: e.Pos}
+	}
+	return e
+}
+
+// isNoop reports whether e always matches an empty string
+// and can be removed without changing the pattern meaning.
+func isNoop(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpEmptyMatch, syntax.OpConcat:
+		return e.IsEmptyMatch()
+	case syntax.OpGroup, syntax.OpGroupWithFlags, syntax.OpAtomicGroup, syntax.OpScriptRun,
+		syntax.OpPositiveLookahead, syntax.OpPositiveLookbehind,
+		syntax.OpStar, syntax.OpPlus, syntax.OpQuestion,
+		syntax.OpNonGreedy, syntax.OpPossessive:
+		return isNoop(e.Args[0])
+	case syntax.OpRepeat:
+		if isNoop(e.Args[0]) {
+			return true
+		}
+		if e.Args[0].Op == syntax.OpQuote && utf8.RuneCountInString(e.Args[0].QuotedLiteral()) > 1 {
+			// Only the last quoted char is repeated.
+			return false
+		}
+		return isZeroRepeat(e.Args[1].Value) && !hasCapture(e.Args[0])
+	default:
+		return false
+	}
+}
+
+// neverMatches reports whether e can't match any string.
+func neverMatches(e syntax.Expr) bool {
+	switch e.Op {
+	case syntax.OpConcat:
+		for _, a := range e.Args {
+			if neverMatches(a) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpGroup, syntax.OpAtomicGroup, syntax.OpScriptRun:
+		return neverMatches(e.Args[0])
+	case syntax.OpNegativeLookahead, syntax.OpNegativeLookbehind:
+		return isNoop(e.Args[0])
+	case syntax.OpNegCharClass:
+		classes := make(map[string]bool, len(e.Args))
+		for _, a := range e.Args {
+			if a.Op == syntax.OpEscapeChar {
+				classes[a.Value] = true
+			}
+		}
+		for _, pair := range [][2]string{{`\d`, `\D`}, {`\s`, `\S`}, {`\w`, `\W`}} {
+			if classes[pair[0]] && classes[pair[1]] {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// isJoinAmbiguous reports whether the last element of prev
+// and the first element of next could be combined into
+// a different token if printed next to each other, like `\1` and `0`.
+func isJoinAmbiguous(prev, next []syntax.Expr) bool {
+	if len(prev) == 0 || len(next) == 0 {
+		return false
+	}
+	switch prev[len(prev)-1].Op {
+	case syntax.OpEscapeChar, syntax.OpEscapeOctal, syntax.OpEscapeHex, syntax.OpEscapeUni:
+	default:
+		return false
+	}
+	s := Print(next[0])
+	return s != "" && isNameChar(s, 0)
+}
+
+func hasCapture(e syntax.Expr) bool {
+	if e.Op == syntax.OpCapture || e.Op == syntax.OpNamedCapture {
+		return true
+	}
+	for _, a := range e.Args {
+		if hasCapture(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// isZeroRepeat reports whether repeat is `{0}` or `{0,0}`.
+// zeroRepeatedQuote returns the quote that is repeated zero times by e,
+// like in `\Qab\E{0}` and `\Qab\E{0}?`.
+func zeroRepeatedQuote(e syntax.Expr) (syntax.Expr, bool) {
+	if e.Op == syntax.OpNonGreedy || e.Op == syntax.OpPossessive {
+		e = e.Args[0]
+	}
+	if e.Op != syntax.OpRepeat || e.Args[0].Op != syntax.OpQuote || !isZeroRepeat(e.Args[1].Value) {
+		return syntax.Expr{}, false
+	}
+	return e.Args[0], true
+}
+
+// trimLastQuoted returns the q quote without its last char.
+func trimLastQuoted(q syntax.Expr) syntax.Expr {
+	s := q.QuotedLiteral()
+	_, size := utf8.DecodeLastRuneInString(s)
+	s = s[:len(s)-size]
+	if s == "" {
+		return syntax.Expr{Op: syntax.OpEmptyMatch, Pos: q.Pos}
+	}
+	lit := q.Args[0]
+	lit.Value = s
+	q.Args = []syntax.Expr{lit}
+	q.Value = `\Q` + s + `\E`
+	return q
+}
+
+func isZeroRepeat(repeat string) bool {
+	repeat = strings.TrimSuffix(strings.TrimPrefix(repeat, "{"), "}")
+	for _, n := range strings.Split(repeat, ",") {
+		if strings.Trim(n, "0") != "" || n == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `abc`},
+		{`a(?:)b`, `ab`},
+		{`a(?:(?:))*b(?=)c(?i:)`, `abc`},
+		{`(?:)`, ``},
+		{`ax{0}b(?:yz){0,0}`, `ab`},
+		{`a(x){0}`, `a(x){0}`},
+		{`a{0,1}b{00}`, `a{0,1}`},
+		{`a|b|a`, `a|b`},
+		{`(?:a|a)+`, `(?:a)+`},
+		{`(a)|(a)`, `(a)|(a)`},
+		{`x(?!)|y`, `y`},
+		{`[^\d\D]|[^\s]|x`, `[^\s]|x`},
+		{`(?!)|(?!)`, `(?!)`},
+		{`(?!a)|b`, `(?!a)|b`},
+		{`\1(?:)0`, `\1(?:)0`},
+		{`\x4(?:)-`, `\x4-`},
+		{`a(?:b(?:)|c|b)d`, `a(?:b|c)d`},
+		{`(?:(?:)|x|)y`, `(?:|x)y`},
+		{`a\Qbc\E{0}`, `a\Qb\E`},
+		{`a\Qbc\E{0}?d`, `a\Qb\Ed`},
+		{`a\Qb\E{0}c`, `ac`},
+		{`a\Qbc\E{1}`, `a\Qbc\E{1}`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		before := Print(re.Expr)
+		have := Print(Cleanup(re.Expr))
+		if have != test.want {
+			t.Errorf("cleanup(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+		if after := Print(re.Expr); after != before {
+			t.Errorf("cleanup(%q) modified the argument: %s", test.pattern, after)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// Print returns the pattern text for e.
+//
+// Unlike Expr.Value, the result reflects the current e structure,
+// so it can be used to get the pattern after the AST was rewritten.
+// Leaf nodes are printed as is, using their Value.
+func Print(e syntax.Expr) string {
+	var b strings.Builder
+	writeExpr(&b, e)
+	return b.String()
+}
+
+func writeExpr(b *strings.Builder, e syntax.Expr) {
+	switch e.Op {
+	case syntax.OpEmptyMatch:
+		// Nothing to print.
+	case syntax.OpConcat, syntax.OpLiteral:
+		writeArgs(b, e.Args, "")
+	case syntax.OpAlt:
+		writeArgs(b, e.Args, "|")
+
+	case syntax.OpStar:
+		writeWrapped(b, "", e.Args[0], "*")
+	case syntax.OpPlus:
+		writeWrapped(b, "", e.Args[0], "+")
+	case syntax.OpQuestion, syntax.OpNonGreedy:
+		writeWrapped(b, "", e.Args[0], "?")
+	case syntax.OpPossessive:
+		writeWrapped(b, "", e.Args[0], "+")
+	case syntax.OpRepeat:
+		writeWrapped(b, "", e.Args[0], e.Args[1].Value)
+
+	case syntax.OpCharClass:
+		b.WriteString("[")
+		writeArgs(b, e.Args, "")
+		b.WriteString("]")
+	case syntax.OpNegCharClass:
+		b.WriteString("[^")
+		writeArgs(b, e.Args, "")
+		b.WriteString("]")
+	case syntax.OpCharRange:
+		writeWrapped(b, "", e.Args[0], "-")
+		writeExpr(b, e.Args[1])
+
+	case syntax.OpCapture:
+		writeWrapped(b, "(", e.Args[0], ")")
+	case syntax.OpNamedCapture:
+		name := e.Args[1].Value
+		switch e.Form {
+		case syntax.FormNamedCaptureAngle:
+			writeWrapped(b, "(?<"+name+">", e.Args[0], ")")
+		case syntax.FormNamedCaptureQuote:
+			writeWrapped(b, "(?'"+name+"'", e.Args[0], ")")
+		default:
+			writeWrapped(b, "(?P<"+name+">", e.Args[0], ")")
+		}
+	case syntax.OpGroup:
+		writeWrapped(b, "(?:", e.Args[0], ")")
+	case syntax.OpGroupWithFlags:
+		writeWrapped(b, "(?"+e.Args[1].Value+":", e.Args[0], ")")
+	case syntax.OpAtomicGroup:
+		writeWrapped(b, "(?>", e.Args[0], ")")
+	case syntax.OpPositiveLookahead:
+		writeWrapped(b, "(?=", e.Args[0], ")")
+	case syntax.OpNegativeLookahead:
+		writeWrapped(b, "(?!", e.Args[0], ")")
+	case syntax.OpPositiveLookbehind:
+		writeWrapped(b, "(?<=", e.Args[0], ")")
+	case syntax.OpNegativeLookbehind:
+		writeWrapped(b, "(?<!", e.Args[0], ")")
+	case syntax.OpScriptRun:
+		prefix := e.Value[:strings.IndexByte(e.Value, ':')+len(":")]
+		writeWrapped(b, prefix, e.Args[0], ")")
+
+	default:
+		b.WriteString(e.Value)
+	}
+}
+
+func writeWrapped(b *strings.Builder, prefix string, e syntax.Expr, suffix string) {
+	b.WriteString(prefix)
+	writeExpr(b, e)
+	b.WriteString(suffix)
+}
+
+func writeArgs(b *strings.Builder, args []syntax.Expr, sep string) {
+	for i, a := range args {
+		if i != 0 {
+			b.WriteString(sep)
+		}
+		writeExpr(b, a)
+	}
+}
@@ -0,0 +1,34 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestPrint(t *testing.T) {
+	patterns := []string{
+		``,
+		`abc`,
+		`a|b|`,
+		`(?:a|b)*?c+d?e++`,
+		`x{2}y{1,}z{1,3}?`,
+		`[a-z[:alpha:]\d][^\]x-]`,
+		`(a)(?P<b>b)(?<c>c)(?'d'd)`,
+		`(?i:x)(?i)(?>x)(?#comment)`,
+		`(?=a)(?!b)(?<=c)(?<!d)`,
+		`\x41\x{42}\101\pL\p{Greek}\Qa|b\E`,
+		`^\d+.$|\Qunclosed`,
+	}
+
+	p := syntax.NewParser(nil)
+	for _, pattern := range patterns {
+		re, err := p.Parse(pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", pattern, err)
+		}
+		if have := Print(re.Expr); have != pattern {
+			t.Errorf("print(%q):\nhave: %s\nwant: %s", pattern, have, pattern)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+// RenameGroup renames a named capturing group and updates all
+// named references to it.
+//
+// Recognized references are `\k<name>`, `\k'name'`, `\k{name}`,
+// `\g{name}`, `\g<name>`, `(?P=name)`, `(?P>name)` and `(?&name)`.
+func RenameGroup(re *syntax.Regexp, oldName, newName string) (string, error) {
+	if !isValidGroupName(newName) {
+		return "", fmt.Errorf("invalid group name %q", newName)
+	}
+	if oldName == newName {
+		return re.Pattern, nil
+	}
+
+	var edits []edit
+	found := false
+	var walkErr error
+	walk(re.Expr, func(e syntax.Expr) {
+		switch e.Op {
+		case syntax.OpNamedCapture:
+			name := e.Args[1]
+			switch name.Value {
+			case oldName:
+				found = true
+				edits = append(edits, edit{int(name.Begin()), int(name.End()), newName})
+			case newName:
+				walkErr = fmt.Errorf("group %q already exists", newName)
+			}
+		case syntax.OpFlagOnlyGroup:
+			flags := e.Args[0]
+			for _, prefix := range []string{"P=", "P>", "&"} {
+				if flags.Value == prefix+oldName {
+					begin := int(flags.Begin()) + len(prefix)
+					edits = append(edits, edit{begin, int(flags.End()), newName})
+				}
+			}
+		case syntax.OpEscapeChar:
+			if e.Value != `\k` && e.Value != `\g` {
+				break
+			}
+			rest := re.Pattern[e.End():]
+			for _, quotes := range []string{"<>", "''", "{}"} {
+				ref := quotes[:1] + oldName + quotes[1:]
+				if strings.HasPrefix(rest, ref) {
+					begin := int(e.End()) + 1
+					edits = append(edits, edit{begin, begin + len(oldName), newName})
+				}
+			}
+		}
+	})
+	if walkErr != nil {
+		return "", walkErr
+	}
+	if !found {
+		return "", fmt.Errorf("group %q not found", oldName)
+	}
+	return applyEdits(re.Pattern, edits), nil
+}
+
+// RenameTemplateGroup renames the group references inside a
+// regexp.Expand style replacement template (`$name` and `${name}`).
+func RenameTemplateGroup(template, oldName, newName string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(template, '$')
+		if i == -1 || i == len(template)-1 {
+			break
+		}
+		b.WriteString(template[:i+1])
+		template = template[i+1:]
+		switch {
+		case template[0] == '$':
+			b.WriteByte('$')
+			template = template[1:]
+		case strings.HasPrefix(template, "{"+oldName+"}"):
+			b.WriteString("{" + newName + "}")
+			template = template[len(oldName)+2:]
+		case strings.HasPrefix(template, oldName) && !isNameChar(template, len(oldName)):
+			b.WriteString(newName)
+			template = template[len(oldName):]
+		}
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// NameGroups converts positional capturing groups into named ones.
+//
+// The names map is keyed by the group index (starting from 1).
+// As the group numbering is not affected, numerical references stay valid.
+// New groups use the same syntax form as the first named group
+// inside the pattern or `(?P<name>)` if there are none.
+func NameGroups(re *syntax.Regexp, names map[int]string) (string, error) {
+	taken := make(map[string]bool)
+	form := syntax.FormDefault
+	formSet := false
+	walk(re.Expr, func(e syntax.Expr) {
+		if e.Op == syntax.OpNamedCapture {
+			taken[e.Args[1].Value] = true
+			if !formSet {
+				form = e.Form
+				formSet = true
+			}
+		}
+	})
+	for _, name := range names {
+		if !isValidGroupName(name) {
+			return "", fmt.Errorf("invalid group name %q", name)
+		}
+		if taken[name] {
+			return "", fmt.Errorf("group %q already exists", name)
+		}
+		taken[name] = true
+	}
+
+	var edits []edit
+	var walkErr error
+	index := 0
+	walk(re.Expr, func(e syntax.Expr) {
+		switch e.Op {
+		case syntax.OpCapture, syntax.OpNamedCapture:
+			index++
+		default:
+			return
+		}
+		name, ok := names[index]
+		if !ok {
+			return
+		}
+		if e.Op == syntax.OpNamedCapture {
+			walkErr = fmt.Errorf("group %d is already named", index)
+			return
+		}
+		var opening string
+		switch form {
+		case syntax.FormNamedCaptureAngle:
+			opening = "(?<" + name + ">"
+		case syntax.FormNamedCaptureQuote:
+			opening = "(?'" + name + "'"
+		default:
+			opening = "(?P<" + name + ">"
+		}
+		edits = append(edits, edit{int(e.Begin()), int(e.Begin()) + len("("), opening})
+	})
+	if walkErr != nil {
+		return "", walkErr
+	}
+	if len(edits) != len(names) {
+		return "", fmt.Errorf("pattern has only %d capturing groups", index)
+	}
+	return applyEdits(re.Pattern, edits), nil
+}
+
+func isNameChar(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	ch := s[i]
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// walk calls visit for every e sub-expression in the source order.
+func walk(e syntax.Expr, visit func(syntax.Expr)) {
+	visit(e)
+	if e.Op == syntax.OpNamedCapture || e.Op == syntax.OpGroupWithFlags {
+		// Args[1] is a name or flags, they're located before Args[0].
+		visit(e.Args[1])
+		walk(e.Args[0], visit)
+		return
+	}
+	for _, a := range e.Args {
+		walk(a, visit)
+	}
+}
@@ -0,0 +1,126 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestRenameGroup(t *testing.T) {
+	tests := []struct {
+		pattern string
+		old     string
+		new     string
+		want    string
+	}{
+		{`(?P<x>a)`, `x`, `y`, `(?P<y>a)`},
+		{`(?<year>\d{4})-\k<year>`, `year`, `y`, `(?<y>\d{4})-\k<y>`},
+		{`(?'w'\w+)\k'w'\k{w}\g{w}`, `w`, `word`, `(?'word'\w+)\k'word'\k{word}\g{word}`},
+		{`(?P<x>a)(?P=x)(?P>x)(?&x)`, `x`, `id`, `(?P<id>a)(?P=id)(?P>id)(?&id)`},
+		{`(?P<x>a)\k<xx>(?P<xx>b)`, `x`, `z`, `(?P<z>a)\k<xx>(?P<xx>b)`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, err := RenameGroup(re, test.old, test.new)
+		if err != nil {
+			t.Errorf("rename(%q): %v", test.pattern, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("rename(%q, %s, %s):\nhave: %s\nwant: %s",
+				test.pattern, test.old, test.new, have, test.want)
+		}
+	}
+}
+
+func TestRenameGroupErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		old     string
+		new     string
+		want    string
+	}{
+		{`(?P<x>a)`, `y`, `z`, `group "y" not found`},
+		{`(?P<x>a)(?P<y>b)`, `x`, `y`, `group "y" already exists`},
+		{`(?P<x>a)`, `x`, `1a`, `invalid group name "1a"`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		_, err = RenameGroup(re, test.old, test.new)
+		have := "<nil>"
+		if err != nil {
+			have = err.Error()
+		}
+		if have != test.want {
+			t.Errorf("rename(%q) error:\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
+
+func TestRenameTemplateGroup(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`$x`, `$y`},
+		{`${x}z`, `${y}z`},
+		{`$xz $x-$$x`, `$xz $y-$$x`},
+		{`$1 $`, `$1 $`},
+	}
+	for _, test := range tests {
+		have := RenameTemplateGroup(test.template, "x", "y")
+		if have != test.want {
+			t.Errorf("rename(%q):\nhave: %s\nwant: %s", test.template, have, test.want)
+		}
+	}
+}
+
+func TestNameGroups(t *testing.T) {
+	tests := []struct {
+		pattern string
+		names   map[int]string
+		want    string
+	}{
+		{`(a)(b)`, map[int]string{2: "b"}, `(a)(?P<b>b)`},
+		{`(a(b))(c)`, map[int]string{1: "x", 2: "y", 3: "z"}, `(?P<x>a(?P<y>b))(?P<z>c)`},
+		{`(?<x>a)(b)`, map[int]string{2: "y"}, `(?<x>a)(?<y>b)`},
+		{`(?:a)(b)\1`, map[int]string{1: "y"}, `(?:a)(?P<y>b)\1`},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, err := NameGroups(re, test.names)
+		if err != nil {
+			t.Errorf("nameGroups(%q): %v", test.pattern, err)
+			continue
+		}
+		if have != test.want {
+			t.Errorf("nameGroups(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+
+	re, err := p.Parse(`(?P<x>a)(b)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NameGroups(re, map[int]string{1: "y"}); err == nil {
+		t.Errorf("expected already named group error")
+	}
+	if _, err := NameGroups(re, map[int]string{3: "y"}); err == nil {
+		t.Errorf("expected out of range group error")
+	}
+}
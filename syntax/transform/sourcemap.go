@@ -0,0 +1,104 @@
+package transform
+
+import (
+	"sort"
+	"strings"
+)
+
+// Origin is a location inside a pattern fragment.
+type Origin struct {
+	// Source is a fragment name, like a template file name.
+	Source string
+
+	// Offset is a byte offset inside the fragment.
+	Offset int
+}
+
+// SourceMap maps the generated pattern offsets to their origins.
+type SourceMap struct {
+	segments []segment
+}
+
+// segment is a generated pattern range that was copied from the source.
+type segment struct {
+	begin  int
+	end    int
+	source string
+	offset int
+}
+
+// Lookup returns the origin of the generated pattern byte at offset.
+// For the bytes that were not copied from any fragment ok is false.
+func (m *SourceMap) Lookup(offset int) (origin Origin, ok bool) {
+	i := sort.Search(len(m.segments), func(i int) bool {
+		return m.segments[i].end > offset
+	})
+	if i == len(m.segments) || m.segments[i].begin > offset {
+		return origin, false
+	}
+	seg := m.segments[i]
+	return Origin{Source: seg.source, Offset: seg.offset + offset - seg.begin}, true
+}
+
+// Builder assembles a pattern from fragments and records
+// where every part of the result came from.
+//
+// A typical use is to report the pattern parsing errors:
+//
+//	var b transform.Builder
+//	b.WriteFragment(userPattern, "config.yml", 0)
+//	b.WriteString("$")
+//	_, err := p.Parse(b.String())
+//	if err, ok := err.(syntax.ParseError); ok {
+//		origin, ok := b.SourceMap().Lookup(int(err.Pos.Begin))
+//		// ...
+//	}
+//
+// The zero value is an empty builder ready to use.
+type Builder struct {
+	buf      strings.Builder
+	segments []segment
+}
+
+// WriteString appends a generated text that has no origin.
+func (b *Builder) WriteString(s string) {
+	b.buf.WriteString(s)
+}
+
+// WriteFragment appends a text that was copied from
+// the source fragment starting at the offset.
+func (b *Builder) WriteFragment(s, source string, offset int) {
+	if s == "" {
+		return
+	}
+	begin := b.buf.Len()
+	b.buf.WriteString(s)
+	b.segments = append(b.segments, segment{
+		begin:  begin,
+		end:    b.buf.Len(),
+		source: source,
+		offset: offset,
+	})
+}
+
+// WriteMapped appends a text that was produced by another Builder.
+// The m origins are preserved, so the source maps can be chained.
+func (b *Builder) WriteMapped(s string, m *SourceMap) {
+	begin := b.buf.Len()
+	b.buf.WriteString(s)
+	for _, seg := range m.segments {
+		seg.begin += begin
+		seg.end += begin
+		b.segments = append(b.segments, seg)
+	}
+}
+
+// String returns the assembled pattern.
+func (b *Builder) String() string {
+	return b.buf.String()
+}
+
+// SourceMap returns the source map of the assembled pattern.
+func (b *Builder) SourceMap() *SourceMap {
+	return &SourceMap{segments: append([]segment(nil), b.segments...)}
+}
@@ -0,0 +1,60 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestSourceMap(t *testing.T) {
+	var inner Builder
+	inner.WriteString("(")
+	inner.WriteFragment("[0-9]+", "digits", 0)
+	inner.WriteString(")")
+
+	var b Builder
+	b.WriteString("^")
+	b.WriteFragment("name=", "main", 10)
+	b.WriteMapped(inner.String(), inner.SourceMap())
+	b.WriteFragment("", "empty", 0)
+	b.WriteFragment(`x\`, "tail", 3)
+
+	pattern := b.String()
+	if pattern != `^name=([0-9]+)x\` {
+		t.Fatalf("unexpected pattern: %s", pattern)
+	}
+
+	tests := []struct {
+		offset int
+		want   Origin
+		ok     bool
+	}{
+		{0, Origin{}, false},
+		{1, Origin{"main", 10}, true},
+		{5, Origin{"main", 14}, true},
+		{6, Origin{}, false},
+		{7, Origin{"digits", 0}, true},
+		{12, Origin{"digits", 5}, true},
+		{13, Origin{}, false},
+		{14, Origin{"tail", 3}, true},
+		{15, Origin{"tail", 4}, true},
+		{16, Origin{}, false},
+	}
+	m := b.SourceMap()
+	for _, test := range tests {
+		have, ok := m.Lookup(test.offset)
+		if have != test.want || ok != test.ok {
+			t.Errorf("Lookup(%d):\nhave: %v %v\nwant: %v %v", test.offset, have, ok, test.want, test.ok)
+		}
+	}
+
+	_, err := syntax.NewParser(nil).Parse(pattern)
+	perr, ok := err.(syntax.ParseError)
+	if !ok {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	origin, ok := m.Lookup(int(perr.Pos.Begin))
+	if !ok || origin.Source != "tail" {
+		t.Errorf("error origin:\nhave: %v %v\nwant: tail", origin, ok)
+	}
+}
@@ -0,0 +1,50 @@
+// Package transform implements regexp pattern rewriting.
+//
+// Transformations operate on the parsed AST, but produce a new pattern
+// text: all parts of the source pattern that are not affected by the
+// transformation are preserved as is.
+package transform
+
+import (
+	"sort"
+	"strings"
+)
+
+// edit is a single pattern text modification.
+type edit struct {
+	begin int
+	end   int
+	text  string
+}
+
+// applyEdits applies non-overlapping edits to s.
+func applyEdits(s string, edits []edit) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].begin < edits[j].begin
+	})
+	var b strings.Builder
+	offset := 0
+	for _, e := range edits {
+		b.WriteString(s[offset:e.begin])
+		b.WriteString(e.text)
+		offset = e.end
+	}
+	b.WriteString(s[offset:])
+	return b.String()
+}
+
+func isValidGroupName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		switch {
+		case ch == '_', ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z':
+		case ch >= '0' && ch <= '9' && i != 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package transform
+
+import (
+	"github.com/quasilyte/regex/syntax"
+	"github.com/quasilyte/regex/syntax/analysis"
+)
+
+// TrimEdgeWildcards removes the redundant `.*` and `.+` from
+// the pattern edges, see analysis.EdgeWildcards.
+//
+// The notes describe the semantic differences of the result,
+// ok is false if there is nothing to trim.
+func TrimEdgeWildcards(re *syntax.Regexp) (pattern string, notes []string, ok bool) {
+	wildcards := analysis.EdgeWildcards(re)
+	if len(wildcards) == 0 {
+		return re.Pattern, nil, false
+	}
+	edits := make([]edit, len(wildcards))
+	for i, w := range wildcards {
+		edits[i] = edit{begin: int(w.Pos.Begin), end: int(w.Pos.End), text: w.Replacement}
+		if i == 0 || w.Note != notes[len(notes)-1] {
+			notes = append(notes, w.Note)
+		}
+	}
+	return applyEdits(re.Pattern, edits), notes, true
+}
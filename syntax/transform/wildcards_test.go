@@ -0,0 +1,33 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/quasilyte/regex/syntax"
+)
+
+func TestTrimEdgeWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		notes   int
+	}{
+		{`.*foo.*`, `foo`, 1},
+		{`(?i).+?(foo|bar)`, `(?i).(foo|bar)`, 1},
+		{`^.*foo.*`, `foo`, 2},
+		{`foo`, `foo`, 0},
+	}
+
+	p := syntax.NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		have, notes, ok := TrimEdgeWildcards(re)
+		if have != test.want || len(notes) != test.notes || ok != (test.notes != 0) {
+			t.Errorf("TrimEdgeWildcards(%q):\nhave: %s %q %v\nwant: %s (%d notes)",
+				test.pattern, have, notes, ok, test.want, test.notes)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package syntax
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// UnicodeClassCheck selects a `\p{Name}` validation mode.
+type UnicodeClassCheck byte
+
+const (
+	// UnicodeClassesUnchecked accepts any class name.
+	UnicodeClassesUnchecked UnicodeClassCheck = iota
+
+	// UnicodeClassesGo accepts the names that are supported by Go regexp:
+	// general categories, scripts and "Any".
+	UnicodeClassesGo
+
+	// UnicodeClassesPCRE accepts the Go names along with the
+	// PCRE-specific ones, like "L&" and "Xan", and the PCRE2
+	// script properties, like "sc=Greek" and "scx=Greek".
+	UnicodeClassesPCRE
+)
+
+var (
+	unicodeClassesOnce sync.Once
+	unicodeClassesGo   map[string]bool
+	unicodeClassesPCRE map[string]bool
+	unicodeScripts     map[string]bool
+)
+
+func initUnicodeClasses() {
+	unicodeClassesGo = map[string]bool{"Any": true}
+	for name := range unicode.Categories {
+		unicodeClassesGo[name] = true
+	}
+	unicodeScripts = make(map[string]bool, len(unicode.Scripts))
+	for name := range unicode.Scripts {
+		unicodeClassesGo[name] = true
+		unicodeScripts[name] = true
+	}
+	unicodeClassesPCRE = make(map[string]bool, len(unicodeClassesGo))
+	for name := range unicodeClassesGo {
+		unicodeClassesPCRE[name] = true
+	}
+	for _, name := range []string{"L&", "Lc", "Xan", "Xps", "Xsp", "Xuc", "Xwd"} {
+		unicodeClassesPCRE[name] = true
+	}
+}
+
+func (p *Parser) checkUnicodeClass(pos Position, name string) {
+	if p.opts.UnicodeClasses == UnicodeClassesUnchecked {
+		return
+	}
+	unicodeClassesOnce.Do(initUnicodeClasses)
+	known := unicodeClassesGo
+	if p.opts.UnicodeClasses == UnicodeClassesPCRE {
+		known = unicodeClassesPCRE
+	}
+	name = strings.TrimPrefix(name, "^")
+	if known[name] {
+		return
+	}
+	if script, _, ok := ScriptClass(name); ok && p.opts.UnicodeClasses == UnicodeClassesPCRE {
+		p.checkUnicodeScript(pos, script)
+		return
+	}
+	message := "unknown Unicode class name: " + name
+	if suggestion := suggestUnicodeClass(known, name); suggestion != "" {
+		message += ", did you mean " + suggestion + "?"
+	}
+	throw(CodeUnknownUnicodeClass, pos, message)
+}
+
+func (p *Parser) checkUnicodeScript(pos Position, name string) {
+	if unicodeScripts[name] {
+		return
+	}
+	message := "unknown Unicode script name: " + name
+	if suggestion := suggestUnicodeClass(unicodeScripts, name); suggestion != "" {
+		message += ", did you mean " + suggestion + "?"
+	}
+	throw(CodeUnknownUnicodeClass, pos, message)
+}
+
+// ScriptClass parses a PCRE2 script property class name,
+// like `sc=Greek`, `scx:Greek` or `Script_Extensions=Greek`.
+//
+// extensions is true for the Script_Extensions (scx) property that
+// also includes the chars that are used with several scripts,
+// like the U+0964 DEVANAGARI DANDA for Bengali.
+// If name is not a script property, ok is false.
+func ScriptClass(name string) (script string, extensions, ok bool) {
+	sep := strings.IndexAny(name, "=:")
+	if sep == -1 {
+		return "", false, false
+	}
+	switch normalizePropertyName(name[:sep]) {
+	case "sc", "script":
+	case "scx", "scriptextensions":
+		extensions = true
+	default:
+		return "", false, false
+	}
+	return name[sep+1:], extensions, true
+}
+
+// normalizePropertyName applies the PCRE2 loose matching rules:
+// the case, spaces, hyphens and underscores are ignored.
+func normalizePropertyName(s string) string {
+	s = strings.ToLower(s)
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(s)
+}
+
+// suggestUnicodeClass returns a known name that is the closest to the given one.
+// If there are no similar names, an empty string is returned.
+func suggestUnicodeClass(known map[string]bool, name string) string {
+	// Allow up to 1 typo per 3 chars, but at least 1.
+	maxDist := len(name)/3 + 1
+	bestDist := maxDist + 1
+	best := ""
+	names := make([]string, 0, len(known))
+	for k := range known {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+		// Replacing every char is not a typo fix.
+		if d := editDistance(k, name); d < bestDist && d < len(name) {
+			best = k
+			bestDist = d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between x and y.
+func editDistance(x, y string) int {
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(y)]
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,66 @@
+package syntax
+
+import (
+	"testing"
+)
+
+func TestScriptClass(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		extensions bool
+		ok         bool
+	}{
+		{`sc=Greek`, `Greek`, false, true},
+		{`Script:Greek`, `Greek`, false, true},
+		{`scx=Greek`, `Greek`, true, true},
+		{`Script_Extensions=Cyrillic`, `Cyrillic`, true, true},
+		{`script extensions:Han`, `Han`, true, true},
+		{`Greek`, ``, false, false},
+		{`gc=Lu`, ``, false, false},
+	}
+
+	for _, test := range tests {
+		script, extensions, ok := ScriptClass(test.name)
+		if script != test.script || extensions != test.extensions || ok != test.ok {
+			t.Errorf("ScriptClass(%q):\nhave: %q %v %v\nwant: %q %v %v",
+				test.name, script, extensions, ok, test.script, test.extensions, test.ok)
+		}
+	}
+}
+
+func TestUnicodeClassCheck(t *testing.T) {
+	tests := []struct {
+		mode    UnicodeClassCheck
+		pattern string
+		want    string
+	}{
+		{UnicodeClassesUnchecked, `\p{Foo}`, ``},
+		{UnicodeClassesGo, `\pL\p{Greek}\p{^Lu}\PN\p{Any}[\p{Han}]`, ``},
+		{UnicodeClassesGo, `\p{Gree}`, `unknown Unicode class name: Gree, did you mean Greek?`},
+		{UnicodeClassesGo, `\p{greek}`, `unknown Unicode class name: greek, did you mean Greek?`},
+		{UnicodeClassesGo, `[\p{^Cyrilic}]`, `unknown Unicode class name: Cyrilic, did you mean Cyrillic?`},
+		{UnicodeClassesGo, `\pQ`, `unknown Unicode class name: Q`},
+		{UnicodeClassesGo, `\p{Xan}`, `unknown Unicode class name: Xan, did you mean Han?`},
+		{UnicodeClassesGo, `\p{SomethingElse}`, `unknown Unicode class name: SomethingElse`},
+		{UnicodeClassesPCRE, `\p{Xan}\p{L&}`, ``},
+		{UnicodeClassesPCRE, `\p{Xwf}`, `unknown Unicode class name: Xwf, did you mean Xwd?`},
+		{UnicodeClassesPCRE, `\p{scx=Greek}\p{sc:Latin}\P{Script_Extensions=Han}`, ``},
+		{UnicodeClassesPCRE, `\p{scx=Gree}`, `unknown Unicode script name: Gree, did you mean Greek?`},
+		{UnicodeClassesPCRE, `\p{scx=L}`, `unknown Unicode script name: L`},
+		{UnicodeClassesPCRE, `\p{gc=L}`, `unknown Unicode class name: gc=L`},
+		{UnicodeClassesGo, `\p{scx=Greek}`, `unknown Unicode class name: scx=Greek, did you mean Greek?`},
+	}
+
+	for _, test := range tests {
+		p := NewParser(&ParserOptions{UnicodeClasses: test.mode})
+		_, err := p.Parse(test.pattern)
+		have := ""
+		if err != nil {
+			have = err.Error()
+		}
+		if have != test.want {
+			t.Errorf("parse(%q):\nhave: %s\nwant: %s", test.pattern, have, test.want)
+		}
+	}
+}
@@ -1,5 +1,14 @@
 package syntax
 
+import (
+	"unsafe"
+)
+
+// bytesToString converts b to a string without copying.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
 func isSpace(ch byte) bool {
 	switch ch {
 	case '\r', '\n', '\t', '\f', '\v', ' ':
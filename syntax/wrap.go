@@ -0,0 +1,39 @@
+package syntax
+
+// Literal returns a regexp that matches the text s literally.
+//
+// The special chars are escaped with QuoteMeta for the dialect d.
+// A LimitError is returned if the quoted pattern is too long.
+func Literal(s string, d Dialect) (*Regexp, error) {
+	return parseWrapped(QuoteMeta(s, d))
+}
+
+// WholeWord returns a regexp that matches re only if the match
+// is not a part of a longer word.
+//
+// For DialectPCRE the word edges are checked with lookarounds, so re
+// can start or end with a non-word char. RE2 has no lookarounds,
+// so `\b` assertions are used instead: they only work as expected
+// if re matches begin and end with a word char.
+func WholeWord(re *Regexp, d Dialect) (*Regexp, error) {
+	if d == DialectPCRE {
+		return parseWrapped(`(?<!\w)(?:` + re.Pattern + `)(?!\w)`)
+	}
+	return parseWrapped(`\b(?:` + re.Pattern + `)\b`)
+}
+
+// WholeLine returns a regexp that matches re only if the match
+// spans the entire input line.
+//
+// The line is the entire input unless the `m` flag is set.
+func WholeLine(re *Regexp) (*Regexp, error) {
+	return parseWrapped(`^(?:` + re.Pattern + `)$`)
+}
+
+func parseWrapped(pattern string) (*Regexp, error) {
+	re, err := NewParser(nil).Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.Clone(), nil
+}
@@ -0,0 +1,70 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		s    string
+		d    Dialect
+		want string
+	}{
+		{`a.b`, DialectRE2, `a\.b`},
+		{`(x)|[y]`, DialectRE2, `\(x\)\|\[y\]`},
+		{`a #b`, DialectRE2, `a #b`},
+		{`a #b`, DialectPCRE, `a\ \#b`},
+	}
+
+	for _, test := range tests {
+		re, err := Literal(test.s, test.d)
+		if err != nil {
+			t.Errorf("Literal(%q, %s): %v", test.s, test.d, err)
+			continue
+		}
+		if re.Pattern != test.want {
+			t.Errorf("Literal(%q, %s):\nhave: %s\nwant: %s", test.s, test.d, re.Pattern, test.want)
+		}
+	}
+
+	_, err := Literal(strings.Repeat(".", 40000), DialectRE2)
+	if _, ok := err.(LimitError); !ok {
+		t.Errorf("Literal of a long string: unexpected error %v", err)
+	}
+}
+
+func TestWholeWordLine(t *testing.T) {
+	tests := []struct {
+		pattern string
+		word    string
+		wordRE2 string
+		line    string
+	}{
+		{`foo|bar`, `(?<!\w)(?:foo|bar)(?!\w)`, `\b(?:foo|bar)\b`, `^(?:foo|bar)$`},
+		{`\+\d+`, `(?<!\w)(?:\+\d+)(?!\w)`, `\b(?:\+\d+)\b`, `^(?:\+\d+)$`},
+	}
+
+	p := NewParser(nil)
+	for _, test := range tests {
+		re, err := p.Parse(test.pattern)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", test.pattern, err)
+		}
+		check := func(name string, have *Regexp, err error, want string) {
+			if err != nil {
+				t.Errorf("%s(%q): %v", name, test.pattern, err)
+				return
+			}
+			if have.Pattern != want {
+				t.Errorf("%s(%q):\nhave: %s\nwant: %s", name, test.pattern, have.Pattern, want)
+			}
+		}
+		word, err := WholeWord(re, DialectPCRE)
+		check("WholeWord", word, err, test.word)
+		wordRE2, err := WholeWord(re, DialectRE2)
+		check("WholeWord", wordRE2, err, test.wordRE2)
+		line, err := WholeLine(re)
+		check("WholeLine", line, err, test.line)
+	}
+}